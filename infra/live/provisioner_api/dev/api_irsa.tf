@@ -64,6 +64,17 @@ data "aws_iam_policy_document" "api" {
     resources = [module.sqs.queue_arn]
   }
 
+  # SQS: consume side of the status queue the provisioner's SNS topic feeds.
+  statement {
+    actions = [
+      "sqs:ReceiveMessage",
+      "sqs:DeleteMessage",
+      "sqs:GetQueueAttributes",
+      "sqs:GetQueueUrl",
+    ]
+    resources = [aws_sqs_queue.api_status.arn]
+  }
+
   # Cognito: signup/login flow. User pool ARN is read from SSM (published by
   # shared/identity) instead of terraform_remote_state, matching the rest of
   # the SSM-decoupled graph.
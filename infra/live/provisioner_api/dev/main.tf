@@ -76,6 +76,7 @@ module "sqs" {
   max_message_size          = var.max_message_size
   message_retention_seconds = var.message_retention_seconds
   receive_wait_time_seconds = var.receive_wait_time_seconds
+  fifo_queue                = var.fifo_queue
 
   # SSM parameter configuration
   ssm_parameter_name = var.ssm_parameter_name
@@ -62,6 +62,18 @@ data "aws_iam_policy_document" "provisioner" {
     ]
     resources = [module.sqs.queue_arn]
   }
+
+  # SQS: the consumer moves poison messages to the dead-letter queue itself.
+  statement {
+    actions   = ["sqs:SendMessage"]
+    resources = [module.sqs.dlq_arn]
+  }
+
+  # SNS: status-change events back to the API.
+  statement {
+    actions   = ["sns:Publish"]
+    resources = [aws_sns_topic.resource_status.arn]
+  }
 }
 
 resource "aws_iam_policy" "provisioner" {
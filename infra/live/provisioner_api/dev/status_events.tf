@@ -0,0 +1,63 @@
+# =============================================================================
+# RESOURCE STATUS EVENTS
+# The provisioner publishes ResourceStatusChanged events to an SNS topic; the
+# API consumes them from a queue subscribed with raw message delivery, so the
+# event body and its W3C trace-context attributes reach SQS unchanged. Both
+# coordinates are published to Parameter Store for the workloads to resolve.
+# =============================================================================
+
+resource "aws_sns_topic" "resource_status" {
+  name = "resource-status-changed"
+  tags = local.tags
+}
+
+resource "aws_sqs_queue" "api_status" {
+  name                      = "api_status_queue"
+  message_retention_seconds = var.message_retention_seconds
+  receive_wait_time_seconds = var.receive_wait_time_seconds
+  tags                      = local.tags
+}
+
+data "aws_iam_policy_document" "api_status_queue" {
+  statement {
+    actions   = ["sqs:SendMessage"]
+    resources = [aws_sqs_queue.api_status.arn]
+
+    principals {
+      type        = "Service"
+      identifiers = ["sns.amazonaws.com"]
+    }
+
+    condition {
+      test     = "ArnEquals"
+      variable = "aws:SourceArn"
+      values   = [aws_sns_topic.resource_status.arn]
+    }
+  }
+}
+
+resource "aws_sqs_queue_policy" "api_status" {
+  queue_url = aws_sqs_queue.api_status.id
+  policy    = data.aws_iam_policy_document.api_status_queue.json
+}
+
+resource "aws_sns_topic_subscription" "api_status" {
+  topic_arn            = aws_sns_topic.resource_status.arn
+  protocol             = "sqs"
+  endpoint             = aws_sqs_queue.api_status.arn
+  raw_message_delivery = true
+}
+
+resource "aws_ssm_parameter" "status_topic_arn" {
+  name  = "/INTERNAL_DEVELOPER_PLATFORM/STATUS_TOPIC_ARN"
+  type  = "String"
+  value = aws_sns_topic.resource_status.arn
+  tags  = local.tags
+}
+
+resource "aws_ssm_parameter" "status_queue_url" {
+  name  = "/INTERNAL_DEVELOPER_PLATFORM/STATUS_QUEUE_URL"
+  type  = "String"
+  value = aws_sqs_queue.api_status.url
+  tags  = local.tags
+}
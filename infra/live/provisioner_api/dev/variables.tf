@@ -172,6 +172,12 @@ variable "receive_wait_time_seconds" {
   type        = number
 }
 
+variable "fifo_queue" {
+  description = "Create the provisioner queue and its dead-letter queue as FIFO queues"
+  type        = bool
+  default     = false
+}
+
 variable "ssm_parameter_name" {
   description = "Name of the SSM parameter for storing the queue URL"
   type        = string
@@ -1,12 +1,54 @@
+# A FIFO queue's name must end in ".fifo", and its DLQ must be FIFO too.
+locals {
+  name_suffix = var.fifo_queue ? ".fifo" : ""
+}
+
 resource "aws_sqs_queue" "provisioner_queue" {
-  name                      = var.queue_name
+  name                      = "${var.queue_name}${local.name_suffix}"
   delay_seconds             = var.delay_seconds
   max_message_size          = var.max_message_size
   message_retention_seconds = var.message_retention_seconds
   receive_wait_time_seconds = var.receive_wait_time_seconds
 
+  # On a FIFO queue the API groups messages by resource ID and sets a
+  # deduplication ID on each, so content-based deduplication stays optional.
+  fifo_queue                  = var.fifo_queue
+  content_based_deduplication = var.fifo_queue ? var.content_based_deduplication : null
+
+  # After max_receive_count failed deliveries SQS moves a message to the DLQ.
+  # The provisioner also dead-letters poison messages itself; this is the
+  # backstop when it cannot (crash mid-processing, DLQ URL unavailable).
+  redrive_policy = jsonencode({
+    deadLetterTargetArn = aws_sqs_queue.provisioner_dlq.arn
+    maxReceiveCount     = var.max_receive_count
+  })
+
+  tags = merge(var.tags, {
+    Project     = var.project
+    Environment = var.environment
+  })
+}
+
+resource "aws_sqs_queue" "provisioner_dlq" {
+  name                      = "${var.queue_name}_dlq${local.name_suffix}"
+  message_retention_seconds = var.dlq_message_retention_seconds
+
+  # The provisioner sets a group and deduplication ID when dead-lettering
+  # to a FIFO DLQ.
+  fifo_queue                  = var.fifo_queue
+  content_based_deduplication = var.fifo_queue ? var.content_based_deduplication : null
+
   tags = merge(var.tags, {
     Project     = var.project
     Environment = var.environment
   })
-}
\ No newline at end of file
+}
+
+resource "aws_sqs_queue_redrive_allow_policy" "provisioner_dlq" {
+  queue_url = aws_sqs_queue.provisioner_dlq.id
+
+  redrive_allow_policy = jsonencode({
+    redrivePermission = "byQueue"
+    sourceQueueArns   = [aws_sqs_queue.provisioner_queue.arn]
+  })
+}
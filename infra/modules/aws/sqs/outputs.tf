@@ -27,3 +27,13 @@ output "ssm_parameter_name" {
   description = "Name of the SSM parameter storing the queue URL"
   value       = aws_ssm_parameter.provisioner_queue_url.name
 }
+
+output "dlq_arn" {
+  description = "ARN of the dead-letter queue"
+  value       = aws_sqs_queue.provisioner_dlq.arn
+}
+
+output "dlq_url" {
+  description = "URL of the dead-letter queue"
+  value       = aws_sqs_queue.provisioner_dlq.url
+}
@@ -4,4 +4,12 @@ resource "aws_ssm_parameter" "provisioner_queue_url" {
   value = aws_sqs_queue.provisioner_queue.url
 
   tags = var.tags
-}
\ No newline at end of file
+}
+
+resource "aws_ssm_parameter" "provisioner_dlq_url" {
+  name  = var.dlq_ssm_parameter_name
+  type  = var.ssm_parameter_type
+  value = aws_sqs_queue.provisioner_dlq.url
+
+  tags = var.tags
+}
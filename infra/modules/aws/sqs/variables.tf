@@ -28,6 +28,35 @@ variable "receive_wait_time_seconds" {
   type        = number
 }
 
+variable "fifo_queue" {
+  description = "Create FIFO queues; \".fifo\" is appended to the queue and dead-letter queue names"
+  type        = bool
+  default     = false
+}
+
+variable "content_based_deduplication" {
+  description = "Deduplicate FIFO messages by a hash of their body instead of requiring a deduplication ID"
+  type        = bool
+  default     = false
+}
+
+# =============================================================================
+# DEAD-LETTER QUEUE CONFIGURATION
+# Variables for the redrive queue poison messages are moved to
+# =============================================================================
+
+variable "max_receive_count" {
+  description = "Deliveries after which SQS moves a message to the dead-letter queue; keep in step with the provisioner's SQS_MAX_RECEIVES"
+  type        = number
+  default     = 5
+}
+
+variable "dlq_message_retention_seconds" {
+  description = "The number of seconds the dead-letter queue retains a message"
+  type        = number
+  default     = 1209600
+}
+
 # =============================================================================
 # SSM PARAMETER CONFIGURATION
 # Variables for Systems Manager Parameter Store integration
@@ -38,6 +67,12 @@ variable "ssm_parameter_name" {
   type        = string
 }
 
+variable "dlq_ssm_parameter_name" {
+  description = "Name of the SSM parameter for storing the dead-letter queue URL"
+  type        = string
+  default     = "/INTERNAL_DEVELOPER_PLATFORM/PROVISIONER_DLQ_URL"
+}
+
 variable "ssm_parameter_type" {
   description = "Type of the SSM parameter"
   type        = string
@@ -18,7 +18,8 @@ export default function () {
     const params = {
         headers: {
             'Content-Type': 'application/json',
-            'x-api-key': 'your_api_key_here',
+            // Personal API token with the provision:write scope (POST /v1/tokens)
+            'Authorization': `Bearer ${__ENV.API_TOKEN}`,
         },
     };
 
@@ -0,0 +1,98 @@
+-- Read model behind GET /v1/resources and GET /v1/resources/{id}. One row per
+-- accepted provisioning request; the status column follows the request through
+-- its lifecycle.
+CREATE TABLE IF NOT EXISTS resource_records (
+    id TEXT PRIMARY KEY,
+    resource_type TEXT NOT NULL,
+    cloud_provider TEXT NOT NULL,
+    specification TEXT NOT NULL,
+    status TEXT NOT NULL,
+    requested_by TEXT NOT NULL,
+    region TEXT NOT NULL DEFAULT '',
+    tags JSONB NOT NULL DEFAULT '{}',
+    trace_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+-- Keyset pagination order (newest first, ties broken by id).
+CREATE INDEX IF NOT EXISTS resource_records_listing_idx
+    ON resource_records (created_at DESC, id DESC);
+
+-- Transactional outbox. A row is written in the same transaction as the
+-- request's resource_records row and deleted once the relay has published it
+-- to the provisioning queue.
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id TEXT PRIMARY KEY,
+    resource_id TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    trace_context JSONB NOT NULL DEFAULT '{}',
+    idempotency_key TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    next_attempt_at TIMESTAMPTZ NOT NULL
+);
+
+-- The relay's claim query: due messages, oldest first.
+CREATE INDEX IF NOT EXISTS outbox_messages_due_idx
+    ON outbox_messages (next_attempt_at, created_at);
+
+-- Personal API tokens. Only the SHA-256 of each secret is stored; groups are
+-- the owner's at issuance.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    owner TEXT NOT NULL,
+    owner_username TEXT NOT NULL DEFAULT '',
+    groups JSONB NOT NULL DEFAULT '[]',
+    scopes JSONB NOT NULL,
+    token_hash TEXT NOT NULL UNIQUE,
+    hint TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    last_used_at TIMESTAMPTZ,
+    revoked_at TIMESTAMPTZ
+);
+
+-- An owner's token listing, newest first.
+CREATE INDEX IF NOT EXISTS api_tokens_owner_idx
+    ON api_tokens (owner, created_at DESC, id DESC);
+
+-- Audit log of mutating API calls. Append-only: each row carries the hash of
+-- the previous one (see model.AuditEntry), and the trigger below rejects
+-- updates and deletes so entries can only be added.
+CREATE TABLE IF NOT EXISTS audit_log (
+    seq BIGINT PRIMARY KEY,
+    id TEXT NOT NULL UNIQUE,
+    time TIMESTAMPTZ NOT NULL,
+    actor TEXT NOT NULL DEFAULT '',
+    actor_username TEXT NOT NULL DEFAULT '',
+    client_id TEXT NOT NULL DEFAULT '',
+    api_token_id TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL,
+    target TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    status INTEGER NOT NULL,
+    outcome TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    trace_id TEXT NOT NULL DEFAULT '',
+    prev_hash TEXT NOT NULL,
+    hash TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_actor_idx ON audit_log (actor, seq DESC);
+CREATE INDEX IF NOT EXISTS audit_log_target_idx ON audit_log (target, seq DESC);
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+CREATE TRIGGER audit_log_append_only
+    BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
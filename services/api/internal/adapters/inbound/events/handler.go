@@ -0,0 +1,61 @@
+// Package events consumes the provisioner's ResourceStatusChanged events —
+// from Kafka in local mode, from the SNS-fed status queue on SQS otherwise —
+// and applies them to the resource read model through the
+// ResourceStatusUpdater port.
+package events
+
+import (
+	"context"
+	"errors"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// StatusHandler decodes one event body and applies it. It is shared by the
+// Kafka and SQS consumers, which acknowledge a message only when Handle
+// returns nil.
+type StatusHandler struct {
+	updater inbound.ResourceStatusUpdater
+	logger  logger.Logger
+}
+
+// NewStatusHandler creates a handler applying events through updater.
+func NewStatusHandler(updater inbound.ResourceStatusUpdater, log logger.Logger) *StatusHandler {
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	return &StatusHandler{updater: updater, logger: log}
+}
+
+// Handle applies the event in body. Events that can never apply — malformed,
+// for an unknown resource, or an illegal (duplicate or stale) transition — are
+// logged and dropped by returning nil. Any other error is returned so the
+// caller redelivers the event.
+func (h *StatusHandler) Handle(ctx context.Context, body []byte) error {
+	event, err := model.DecodeResourceStatusChanged(body)
+	if err == nil {
+		err = h.updater.ApplyStatusChange(ctx, event)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if isPermanent(err) {
+		h.logger.WithContext(ctx).Warn("dropping status event",
+			logger.F("resource_id", event.ResourceID),
+			logger.F("new_status", event.NewStatus),
+			logger.F("error", err.Error()),
+		)
+		return nil
+	}
+	return err
+}
+
+func isPermanent(err error) bool {
+	return errors.Is(err, domainerrors.ErrInvalidInput) ||
+		errors.Is(err, domainerrors.ErrNotFound) ||
+		errors.Is(err, domainerrors.ErrInvalidStateTransition)
+}
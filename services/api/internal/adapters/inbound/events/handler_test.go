@@ -0,0 +1,54 @@
+package events
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+const statusEvent = `{"event_type":"ResourceStatusChanged","resource_id":"vm-001","old_status":"pending","new_status":"in_progress","reason":"accepted by provisioner","occurred_at":"2026-01-24T10:30:00Z"}`
+
+func TestStatusHandler_AppliesDecodedEvent(t *testing.T) {
+	updater := &mocks.FakeResourceStatusUpdater{}
+	h := NewStatusHandler(updater, nil)
+
+	err := h.Handle(context.Background(), []byte(statusEvent))
+
+	assert.NoError(t, err)
+	if assert.Len(t, updater.Applied, 1) {
+		assert.Equal(t, "vm-001", updater.Applied[0].ResourceID)
+		assert.Equal(t, "in_progress", updater.Applied[0].NewStatus)
+	}
+}
+
+func TestStatusHandler_DropsMalformedEvent(t *testing.T) {
+	updater := &mocks.FakeResourceStatusUpdater{}
+	h := NewStatusHandler(updater, nil)
+
+	for _, body := range []string{"not json", `{"event_type":"Other","resource_id":"vm-001","new_status":"completed"}`, `{"event_type":"ResourceStatusChanged","new_status":"completed"}`} {
+		assert.NoError(t, h.Handle(context.Background(), []byte(body)), body)
+	}
+	assert.Empty(t, updater.Applied)
+}
+
+func TestStatusHandler_DropsEventsThatCanNeverApply(t *testing.T) {
+	for name, err := range map[string]error{
+		"unknown resource":   domainerrors.NotFound("resource", "vm-001"),
+		"illegal transition": domainerrors.InvalidStatusTransition("vm-001", "completed", "in_progress"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			h := NewStatusHandler(&mocks.FakeResourceStatusUpdater{ErrToReturn: err}, nil)
+			assert.NoError(t, h.Handle(context.Background(), []byte(statusEvent)))
+		})
+	}
+}
+
+func TestStatusHandler_ReturnsTransientErrors(t *testing.T) {
+	h := NewStatusHandler(&mocks.FakeResourceStatusUpdater{ErrToReturn: domainerrors.Internal("db down", assert.AnError)}, nil)
+
+	assert.Error(t, h.Handle(context.Background(), []byte(statusEvent)))
+}
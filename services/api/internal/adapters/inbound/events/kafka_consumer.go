@@ -0,0 +1,110 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// tracerName scopes the consumers' spans.
+const tracerName = "internal-developer-platform.api/events"
+
+// KafkaConsumerConfig configures the Kafka status consumer.
+type KafkaConsumerConfig struct {
+	Brokers []string
+	Topic   string
+	GroupID string
+	// RetryDelay is the pause before re-applying an event that failed with a
+	// transient error.
+	RetryDelay time.Duration
+}
+
+// KafkaConsumer reads status events from Kafka with a consumer group.
+type KafkaConsumer struct {
+	reader     *kafka.Reader
+	handler    *StatusHandler
+	tracer     trace.Tracer
+	logger     logger.Logger
+	retryDelay time.Duration
+}
+
+// NewKafkaConsumer creates a consumer for the configured topic.
+func NewKafkaConsumer(cfg KafkaConsumerConfig, handler *StatusHandler, log logger.Logger) *KafkaConsumer {
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	retryDelay := cfg.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = time.Second
+	}
+	return &KafkaConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.Brokers,
+			Topic:   cfg.Topic,
+			GroupID: cfg.GroupID,
+		}),
+		handler:    handler,
+		tracer:     otel.Tracer(tracerName),
+		logger:     log,
+		retryDelay: retryDelay,
+	}
+}
+
+// Run consumes until ctx is cancelled. Offsets are committed only after an
+// event is applied or dropped. Kafka offsets are cumulative, so a transient
+// failure is retried in place rather than skipped.
+func (c *KafkaConsumer) Run(ctx context.Context) error {
+	c.logger.Info("Consuming resource status events from Kafka",
+		logger.F("topic", c.reader.Config().Topic),
+		logger.F("group", c.reader.Config().GroupID),
+	)
+
+	for ctx.Err() == nil {
+		message, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			c.logger.Error("Failed to fetch status event", logger.F("error", err.Error()))
+			continue
+		}
+
+		msgCtx := otel.GetTextMapPropagator().Extract(ctx, kafkaHeaderCarrier(message.Headers))
+		processCtx, span := c.tracer.Start(msgCtx, "ApplyStatusEvent", trace.WithSpanKind(trace.SpanKindConsumer))
+		for {
+			err := c.handler.Handle(processCtx, message.Value)
+			if err == nil {
+				break
+			}
+			span.RecordError(err)
+			c.logger.WithContext(processCtx).Error("Failed to apply status event; retrying",
+				logger.F("offset", message.Offset),
+				logger.F("error", err.Error()),
+			)
+			select {
+			case <-ctx.Done():
+				span.End()
+				return ctx.Err()
+			case <-time.After(c.retryDelay):
+			}
+		}
+
+		if err := c.reader.CommitMessages(processCtx, message); err != nil {
+			span.RecordError(err)
+			c.logger.WithContext(processCtx).Error("Failed to commit status event", logger.F("error", err.Error()))
+		}
+		span.End()
+	}
+
+	return ctx.Err()
+}
+
+// Close releases the underlying reader.
+func (c *KafkaConsumer) Close() error {
+	return c.reader.Close()
+}
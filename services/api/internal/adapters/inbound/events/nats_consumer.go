@@ -0,0 +1,108 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// NATSConsumer reads status events from a JetStream durable consumer.
+type NATSConsumer struct {
+	consumer   jetstream.Consumer
+	handler    *StatusHandler
+	tracer     trace.Tracer
+	logger     logger.Logger
+	retryDelay time.Duration
+}
+
+// NewNATSConsumer creates a consumer reading from consumer, which must use
+// explicit acks. retryDelay is the pause before re-applying an event that
+// failed with a transient error; it defaults to a second.
+func NewNATSConsumer(consumer jetstream.Consumer, handler *StatusHandler, retryDelay time.Duration, log logger.Logger) *NATSConsumer {
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	if retryDelay <= 0 {
+		retryDelay = time.Second
+	}
+	return &NATSConsumer{
+		consumer:   consumer,
+		handler:    handler,
+		tracer:     otel.Tracer(tracerName),
+		logger:     log,
+		retryDelay: retryDelay,
+	}
+}
+
+// Run consumes until ctx is cancelled, acknowledging each event once it is
+// applied or dropped. As with Kafka, a transient failure is retried in place
+// rather than left for redelivery: a later event for the same resource must
+// not overtake it, or it would be dropped as an illegal transition.
+func (c *NATSConsumer) Run(ctx context.Context) error {
+	messages, err := c.consumer.Messages()
+	if err != nil {
+		return err
+	}
+	// Stopping the iterator unblocks Next on shutdown.
+	stopOnCancel := context.AfterFunc(ctx, messages.Stop)
+	defer stopOnCancel()
+	defer messages.Stop()
+
+	info := c.consumer.CachedInfo()
+	c.logger.Info("Consuming resource status events from NATS JetStream",
+		logger.F("stream", info.Stream),
+		logger.F("durable", info.Name),
+	)
+
+	for ctx.Err() == nil {
+		message, err := messages.Next()
+		if err != nil {
+			if errors.Is(err, jetstream.ErrMsgIteratorClosed) || ctx.Err() != nil {
+				break
+			}
+			c.logger.Error("Failed to fetch status event", logger.F("error", err.Error()))
+			continue
+		}
+		if err := c.process(ctx, message); err != nil {
+			return err
+		}
+	}
+
+	return ctx.Err()
+}
+
+// process applies one event, retrying until it is applied or dropped, then
+// acknowledges it. It returns only ctx's error, on cancellation.
+func (c *NATSConsumer) process(ctx context.Context, message jetstream.Msg) error {
+	msgCtx := otel.GetTextMapPropagator().Extract(ctx, natsHeaderCarrier(message.Headers()))
+	processCtx, span := c.tracer.Start(msgCtx, "ApplyStatusEvent", trace.WithSpanKind(trace.SpanKindConsumer))
+	defer span.End()
+
+	for {
+		err := c.handler.Handle(processCtx, message.Data())
+		if err == nil {
+			break
+		}
+		span.RecordError(err)
+		c.logger.WithContext(processCtx).Error("Failed to apply status event; retrying", logger.F("error", err.Error()))
+		// Hold off redelivery while the event is retried here.
+		_ = message.InProgress()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.retryDelay):
+		}
+	}
+
+	if err := message.Ack(); err != nil {
+		span.RecordError(err)
+		c.logger.WithContext(processCtx).Error("Failed to acknowledge status event", logger.F("error", err.Error()))
+	}
+	return nil
+}
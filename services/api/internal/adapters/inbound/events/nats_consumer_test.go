@@ -0,0 +1,87 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+// fakeNATSMsg is a delivered status event that records how it was settled.
+// Methods it does not override panic through the nil embedded interface.
+type fakeNATSMsg struct {
+	jetstream.Msg
+	data       []byte
+	headers    nats.Header
+	acked      bool
+	inProgress int
+}
+
+func (m *fakeNATSMsg) Data() []byte         { return m.data }
+func (m *fakeNATSMsg) Headers() nats.Header { return m.headers }
+func (m *fakeNATSMsg) Ack() error           { m.acked = true; return nil }
+func (m *fakeNATSMsg) InProgress() error    { m.inProgress++; return nil }
+
+func TestNATSConsumer_AppliesAndAcks(t *testing.T) {
+	updater := &mocks.FakeResourceStatusUpdater{}
+	c := NewNATSConsumer(nil, NewStatusHandler(updater, nil), time.Millisecond, nil)
+	message := &fakeNATSMsg{data: []byte(statusEvent), headers: nats.Header{}}
+
+	require.NoError(t, c.process(context.Background(), message))
+
+	assert.True(t, message.acked)
+	assert.Len(t, updater.Applied, 1)
+}
+
+func TestNATSConsumer_RetriesTransientFailureInPlace(t *testing.T) {
+	updater := &mocks.FakeResourceStatusUpdater{ErrToReturn: domainerrors.Internal("db down", assert.AnError), FailTimes: 2}
+	c := NewNATSConsumer(nil, NewStatusHandler(updater, nil), time.Millisecond, nil)
+	message := &fakeNATSMsg{data: []byte(statusEvent), headers: nats.Header{}}
+
+	require.NoError(t, c.process(context.Background(), message))
+
+	assert.Len(t, updater.Applied, 3)
+	assert.Equal(t, 2, message.inProgress, "redelivery is held off while retrying")
+	assert.True(t, message.acked)
+}
+
+func TestNATSConsumer_StopsRetryingOnCancel(t *testing.T) {
+	updater := &mocks.FakeResourceStatusUpdater{ErrToReturn: domainerrors.Internal("db down", assert.AnError)}
+	c := NewNATSConsumer(nil, NewStatusHandler(updater, nil), time.Hour, nil)
+	message := &fakeNATSMsg{data: []byte(statusEvent), headers: nats.Header{}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.ErrorIs(t, c.process(ctx, message), context.Canceled)
+	assert.False(t, message.acked, "an unapplied event is left for redelivery")
+}
+
+func TestNATSConsumer_ContinuesTheProvisionerTrace(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+	recorder := tracetest.NewSpanRecorder()
+	c := NewNATSConsumer(nil, NewStatusHandler(&mocks.FakeResourceStatusUpdater{}, nil), time.Millisecond, nil)
+	c.tracer = sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+
+	message := &fakeNATSMsg{
+		data:    []byte(statusEvent),
+		headers: nats.Header{"traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}},
+	}
+	require.NoError(t, c.process(context.Background(), message))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+}
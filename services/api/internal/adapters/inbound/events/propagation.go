@@ -0,0 +1,83 @@
+package events
+
+import (
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// The provisioner injects W3C trace context into each status event, so the
+// span applying it here joins the provisioning trace. These read-only carriers
+// adapt the transport's metadata for the global propagator's Extract.
+
+// kafkaHeaderCarrier is a read-only TextMapCarrier over Kafka message headers.
+type kafkaHeaderCarrier []kafka.Header
+
+var _ propagation.TextMapCarrier = kafkaHeaderCarrier(nil)
+
+func (c kafkaHeaderCarrier) Get(key string) string {
+	for _, h := range c {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c kafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, len(c))
+	for i, h := range c {
+		keys[i] = h.Key
+	}
+	return keys
+}
+
+// Set is unused on the consume side but required by the interface.
+func (c kafkaHeaderCarrier) Set(string, string) {}
+
+// sqsAttributeCarrier is a read-only TextMapCarrier over SQS message
+// attributes. With raw message delivery the SNS attributes the provisioner set
+// arrive here unchanged.
+type sqsAttributeCarrier map[string]sqstypes.MessageAttributeValue
+
+var _ propagation.TextMapCarrier = sqsAttributeCarrier(nil)
+
+func (c sqsAttributeCarrier) Get(key string) string {
+	if v, ok := c[key]; ok && v.StringValue != nil {
+		return *v.StringValue
+	}
+	return ""
+}
+
+func (c sqsAttributeCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Set is unused on the consume side but required by the interface.
+func (c sqsAttributeCarrier) Set(string, string) {}
+
+// natsHeaderCarrier is a read-only TextMapCarrier over NATS message headers.
+// NATS headers are case-sensitive, like Kafka's.
+type natsHeaderCarrier nats.Header
+
+var _ propagation.TextMapCarrier = natsHeaderCarrier(nil)
+
+func (c natsHeaderCarrier) Get(key string) string {
+	return nats.Header(c).Get(key)
+}
+
+func (c natsHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Set is unused on the consume side but required by the interface.
+func (c natsHeaderCarrier) Set(string, string) {}
@@ -0,0 +1,83 @@
+package events
+
+import (
+	"context"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// SQSConsumer long-polls the status queue, which subscribes to the
+// provisioner's SNS topic with raw message delivery.
+type SQSConsumer struct {
+	client   *sqs.Client
+	queueURL string
+	handler  *StatusHandler
+	tracer   trace.Tracer
+	logger   logger.Logger
+}
+
+// NewSQSConsumer creates a consumer for the given queue.
+func NewSQSConsumer(client *sqs.Client, queueURL string, handler *StatusHandler, log logger.Logger) *SQSConsumer {
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	return &SQSConsumer{
+		client:   client,
+		queueURL: queueURL,
+		handler:  handler,
+		tracer:   otel.Tracer(tracerName),
+		logger:   log,
+	}
+}
+
+// Run polls until ctx is cancelled, deleting each message once it is applied
+// or dropped. A message that fails transiently stays on the queue and is
+// redelivered after its visibility timeout.
+func (c *SQSConsumer) Run(ctx context.Context) error {
+	c.logger.Info("Consuming resource status events from SQS", logger.F("queue_url", c.queueURL))
+
+	for ctx.Err() == nil {
+		output, err := c.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:            aws.String(c.queueURL),
+			MaxNumberOfMessages: 10,
+			WaitTimeSeconds:     20,
+			// Without this the trace-context attributes are dropped.
+			MessageAttributeNames: []string{"All"},
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			c.logger.Error("Failed to receive status events", logger.F("error", err.Error()))
+			continue
+		}
+
+		for _, message := range output.Messages {
+			msgCtx := otel.GetTextMapPropagator().Extract(ctx, sqsAttributeCarrier(message.MessageAttributes))
+			processCtx, span := c.tracer.Start(msgCtx, "ApplyStatusEvent", trace.WithSpanKind(trace.SpanKindConsumer))
+
+			if err := c.handler.Handle(processCtx, []byte(aws.ToString(message.Body))); err != nil {
+				span.RecordError(err)
+				c.logger.WithContext(processCtx).Error("Failed to apply status event; leaving it for redelivery", logger.F("error", err.Error()))
+				span.End()
+				continue
+			}
+
+			if _, err := c.client.DeleteMessage(processCtx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(c.queueURL),
+				ReceiptHandle: message.ReceiptHandle,
+			}); err != nil {
+				span.RecordError(err)
+				c.logger.WithContext(processCtx).Error("Failed to delete status event", logger.F("error", err.Error()))
+			}
+			span.End()
+		}
+	}
+
+	return ctx.Err()
+}
@@ -0,0 +1,105 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+)
+
+// APITokenHandler serves /v1/tokens, where signed-in users manage their
+// personal API tokens.
+type APITokenHandler struct {
+	tokenService inbound.APITokenService
+}
+
+func NewAPITokenHandler(tokenService inbound.APITokenService) *APITokenHandler {
+	return &APITokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// Issue creates an API token for the caller. The secret is in this response
+// only; it cannot be retrieved again.
+func (h *APITokenHandler) Issue(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	req := DecodeAndValidate[model.IssueAPITokenRequest](w, r, requestID)
+	if req == nil {
+		return // Response already sent by DecodeAndValidate
+	}
+
+	issued, err := h.tokenService.IssueAPIToken(r.Context(), *req)
+	if err != nil {
+		respondWithTokenError(w, requestID, err, "Failed to issue API token")
+		return
+	}
+	setAuditTarget(r.Context(), issued.ID)
+
+	RespondWithJSON(w, http.StatusCreated, NewAPIResponse(issued, requestID))
+}
+
+// List returns the caller's API tokens, revoked and expired ones included,
+// without their secrets.
+func (h *APITokenHandler) List(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	tokens, err := h.tokenService.ListAPITokens(r.Context())
+	if err != nil {
+		respondWithTokenError(w, requestID, err, "Failed to list API tokens")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(tokens, requestID))
+}
+
+// Revoke revokes one of the caller's API tokens.
+func (h *APITokenHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	if err := h.tokenService.RevokeAPIToken(r.Context(), r.PathValue("id")); err != nil {
+		respondWithTokenError(w, requestID, err, "Failed to revoke API token")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(MessageResponse{
+		Message: "API token revoked",
+		Status:  "REVOKED",
+	}, requestID))
+}
+
+// respondWithTokenError maps a failed token operation: invalid input is a 400
+// on the offending field, an unknown token a 404, a caller who may not manage
+// tokens a 401 or 403, and anything else a generic 500.
+func respondWithTokenError(w http.ResponseWriter, requestID string, err error, fallback string) {
+	var domainErr *domainerrors.DomainError
+	switch {
+	case errors.Is(err, domainerrors.ErrInvalidInput) && errors.As(err, &domainErr):
+		field, _ := domainErr.Details["field"].(string)
+		RespondWithValidationError(w, requestID, []ValidationError{
+			{Field: field, Message: domainErr.Message},
+		})
+	case errors.Is(err, domainerrors.ErrNotFound):
+		RespondWithError(w, http.StatusNotFound, ErrorResponse{
+			Code:      ErrCodeNotFound,
+			Message:   "API token not found",
+			RequestID: requestID,
+		})
+	case errors.Is(err, domainerrors.ErrUnauthorized):
+		respondUnauthorized(w, requestID, "Missing bearer token")
+	case errors.Is(err, domainerrors.ErrForbidden) && errors.As(err, &domainErr):
+		RespondWithError(w, http.StatusForbidden, ErrorResponse{
+			Code:      ErrCodeForbidden,
+			Message:   "Not permitted: " + domainErr.Message,
+			RequestID: requestID,
+		})
+	default:
+		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
+			Code:      ErrCodeInternalError,
+			Message:   fallback,
+			RequestID: requestID,
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/apitoken"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/application/service"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+// newTokenRouter routes with API tokens accepted next to a fake JWT verifier
+// that signs every other bearer token in as user-1.
+func newTokenRouter(t *testing.T) (http.Handler, *mocks.FakeResourcePublisher) {
+	t.Helper()
+	tokens := service.NewAPITokenService(apitoken.NewMemoryStore(), service.APITokenConfig{}, nil)
+	publisher := &mocks.FakeResourcePublisher{}
+
+	config := DefaultRouterConfig()
+	config.TokenVerifier = tokens.VerifierWith(&mocks.FakeTokenVerifier{Principal: model.Principal{Subject: "user-1"}})
+	config.APITokenHandler = NewAPITokenHandler(tokens)
+	router := NewRouterWithConfig(NewResourceHandler(service.NewResourceService(publisher, nil, nil, nil)), nil, nil, nil, config)
+	return router, publisher
+}
+
+func serve(router http.Handler, method, path, token string, body any) *httptest.ResponseRecorder {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func issueToken(t *testing.T, router http.Handler, scopes ...string) model.IssuedAPIToken {
+	t.Helper()
+	rec := serve(router, http.MethodPost, "/v1/tokens", "session-jwt", model.IssueAPITokenRequest{Name: "ci", Scopes: scopes})
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var resp APIResponse[model.IssuedAPIToken]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+func TestAPITokens_ProvisionAsOwner(t *testing.T) {
+	router, publisher := newTokenRouter(t)
+	issued := issueToken(t, router, model.ScopeProvisionWrite)
+
+	rec := serve(router, http.MethodPost, "/v1/provision", issued.Token, model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending",
+	})
+
+	assert.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Equal(t, "user-1", publisher.LastSent.RequestedBy)
+}
+
+func TestAPITokens_ScopesLimitRoutes(t *testing.T) {
+	router, publisher := newTokenRouter(t)
+	issued := issueToken(t, router, model.ScopeProvisionValidate)
+	resource := model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending"}
+
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodPost, "/v1/provision:validate", issued.Token, resource).Code)
+
+	rec := serve(router, http.MethodPost, "/v1/provision", issued.Token, resource)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), model.ScopeProvisionWrite)
+	assert.Equal(t, 0, publisher.TimesCalled)
+
+	// A token cannot mint or manage tokens, whatever its scopes.
+	assert.Equal(t, http.StatusForbidden, serve(router, http.MethodGet, "/v1/tokens", issued.Token, nil).Code)
+}
+
+func TestAPITokens_ListOmitsSecretsAndRevokeStopsToken(t *testing.T) {
+	router, _ := newTokenRouter(t)
+	issued := issueToken(t, router, model.ScopeProvisionValidate)
+
+	rec := serve(router, http.MethodGet, "/v1/tokens", "session-jwt", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.NotContains(t, rec.Body.String(), issued.Token)
+	var list APIResponse[[]model.APIToken]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &list))
+	require.Len(t, list.Data, 1)
+	assert.Equal(t, issued.ID, list.Data[0].ID)
+	assert.Equal(t, issued.Hint, list.Data[0].Hint)
+
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodDelete, "/v1/tokens/"+issued.ID, "session-jwt", nil).Code)
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodDelete, "/v1/tokens/unknown", "session-jwt", nil).Code)
+
+	rec = serve(router, http.MethodPost, "/v1/provision:validate", issued.Token, model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending",
+	})
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestAPITokens_IssueValidatesBody(t *testing.T) {
+	router, _ := newTokenRouter(t)
+
+	cases := map[string]model.IssueAPITokenRequest{
+		"no name":       {Scopes: []string{model.ScopeProvisionWrite}},
+		"no scopes":     {Name: "ci"},
+		"unknown scope": {Name: "ci", Scopes: []string{"admin"}},
+		"beyond max":    {Name: "ci", Scopes: []string{model.ScopeProvisionWrite}, ExpiresInDays: 400},
+	}
+	for name, body := range cases {
+		t.Run(name, func(t *testing.T) {
+			rec := serve(router, http.MethodPost, "/v1/tokens", "session-jwt", body)
+			assert.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestAPITokens_RoutesNeedAuthentication(t *testing.T) {
+	tokens := service.NewAPITokenService(apitoken.NewMemoryStore(), service.APITokenConfig{}, nil)
+	config := DefaultRouterConfig()
+	config.APITokenHandler = NewAPITokenHandler(tokens)
+	router := NewRouterWithConfig(nil, nil, nil, nil, config)
+
+	assert.Equal(t, http.StatusNotFound, serve(router, http.MethodGet, "/v1/tokens", "", nil).Code,
+		"without token verification there are no owners, so no token routes")
+
+	router, _ = newTokenRouter(t)
+	assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/v1/tokens", "", nil).Code)
+}
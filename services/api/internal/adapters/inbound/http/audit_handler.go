@@ -0,0 +1,129 @@
+package http
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+)
+
+// AuditHandler serves /v1/audit, where platform admins read and verify the
+// audit log.
+type AuditHandler struct {
+	auditService inbound.AuditService
+}
+
+func NewAuditHandler(auditService inbound.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// List returns one page of audit entries, newest first, filtered by the
+// actor, action, target, outcome, since and until query parameters. Pass a
+// page's next_before as before to get the next one.
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+	query := r.URL.Query()
+
+	q := model.AuditQuery{
+		Actor:   query.Get("actor"),
+		Action:  query.Get("action"),
+		Target:  query.Get("target"),
+		Outcome: query.Get("outcome"),
+	}
+	var errs []ValidationError
+	if raw := query.Get("since"); raw != "" {
+		q.Since, errs = parseTimeParam(errs, "since", raw)
+	}
+	if raw := query.Get("until"); raw != "" {
+		q.Until, errs = parseTimeParam(errs, "until", raw)
+	}
+	if raw := query.Get("before"); raw != "" {
+		q.Before, errs = parsePositiveParam(errs, "before", raw)
+	}
+	if raw := query.Get("limit"); raw != "" {
+		var limit int64
+		limit, errs = parsePositiveParam(errs, "limit", raw)
+		q.Limit = int(min(limit, math.MaxInt32)) // the service clamps it further
+	}
+	if len(errs) > 0 {
+		RespondWithValidationError(w, requestID, errs)
+		return
+	}
+
+	page, err := h.auditService.ListAuditEntries(r.Context(), q)
+	if err != nil {
+		respondWithAuditError(w, requestID, err, "Failed to list audit entries")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(page, requestID))
+}
+
+// Verify checks the hash chain of the whole audit log.
+func (h *AuditHandler) Verify(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	result, err := h.auditService.VerifyAuditLog(r.Context())
+	if err != nil {
+		respondWithAuditError(w, requestID, err, "Failed to verify audit log")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(result, requestID))
+}
+
+// parseTimeParam parses an RFC 3339 query parameter, adding to errs if it is
+// malformed.
+func parseTimeParam(errs []ValidationError, field, raw string) (time.Time, []ValidationError) {
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		errs = append(errs, ValidationError{Field: field, Message: field + " must be an RFC 3339 time", Value: raw})
+	}
+	return t, errs
+}
+
+// parsePositiveParam parses a positive integer query parameter, adding to
+// errs if it is malformed.
+func parsePositiveParam(errs []ValidationError, field, raw string) (int64, []ValidationError) {
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 1 {
+		errs = append(errs, ValidationError{Field: field, Message: field + " must be a positive integer", Value: raw})
+		return 0, errs
+	}
+	return n, errs
+}
+
+// respondWithAuditError maps a failed audit query: invalid input is a 400 on
+// the offending field, a caller who is not a platform admin a 401 or 403, and
+// anything else a generic 500.
+func respondWithAuditError(w http.ResponseWriter, requestID string, err error, fallback string) {
+	var domainErr *domainerrors.DomainError
+	switch {
+	case errors.Is(err, domainerrors.ErrInvalidInput) && errors.As(err, &domainErr):
+		field, _ := domainErr.Details["field"].(string)
+		RespondWithValidationError(w, requestID, []ValidationError{
+			{Field: field, Message: domainErr.Message},
+		})
+	case errors.Is(err, domainerrors.ErrUnauthorized):
+		respondUnauthorized(w, requestID, "Missing bearer token")
+	case errors.Is(err, domainerrors.ErrForbidden) && errors.As(err, &domainErr):
+		RespondWithError(w, http.StatusForbidden, ErrorResponse{
+			Code:      ErrCodeForbidden,
+			Message:   "Not permitted: " + domainErr.Message,
+			RequestID: requestID,
+		})
+	default:
+		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
+			Code:      ErrCodeInternalError,
+			Message:   fallback,
+			RequestID: requestID,
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/audit"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/application/service"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+// tokenPrincipals signs each bearer token in as the principal it names.
+type tokenPrincipals map[string]model.Principal
+
+func (p tokenPrincipals) Verify(_ context.Context, token string) (*model.Principal, error) {
+	principal, ok := p[token]
+	if !ok {
+		return nil, outbound.ErrAPITokenNotFound
+	}
+	return &principal, nil
+}
+
+func newAuditRouter(t *testing.T) (http.Handler, *audit.MemoryLog) {
+	t.Helper()
+	log := audit.NewMemoryLog()
+	config := DefaultRouterConfig()
+	config.TokenVerifier = tokenPrincipals{
+		"user":   {Subject: "user-1", Username: "dev@example.com"},
+		"admin":  {Subject: "admin-1", Groups: []string{"platform-admins"}},
+		"reader": {Subject: "reporting", ClientID: "reporting", Machine: true, Scopes: []string{model.ScopeResourcesRead}},
+	}
+	config.AuditService = service.NewAuditService(log, service.AuditConfig{}, nil)
+	resources := NewResourceHandler(service.NewResourceService(&mocks.FakeResourcePublisher{}, nil, nil, nil))
+	auth := NewAuthHandler(&mocks.FakeAuthService{}, logger.NopLogger{})
+	router := NewRouterWithConfig(resources, nil, auth, nil, config)
+	return router, log
+}
+
+func TestAuditMiddleware_RecordsMutatingCalls(t *testing.T) {
+	router, log := newAuditRouter(t)
+	resource := model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending"}
+
+	serve(router, http.MethodPost, "/v1/provision", "user", resource)
+	serve(router, http.MethodPost, "/v1/provision", "reader", resource)
+	serve(router, http.MethodGet, "/v1/resources", "reader", nil)
+
+	entries, err := log.Query(context.Background(), model.AuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "reads are not audited")
+
+	denied, provisioned := entries[0], entries[1]
+	assert.Equal(t, "reporting", denied.Actor)
+	assert.Equal(t, http.StatusForbidden, denied.Status)
+	assert.Equal(t, model.AuditOutcomeDenied, denied.Outcome)
+
+	assert.Equal(t, "user-1", provisioned.Actor)
+	assert.Equal(t, model.AuditActionProvision, provisioned.Action)
+	assert.Equal(t, "vm-001", provisioned.Target)
+	assert.Equal(t, "/v1/provision", provisioned.Path)
+	body, _ := json.Marshal(resource)
+	assert.Equal(t, hashBody(body), provisioned.RequestHash)
+}
+
+func TestAuditMiddleware_RecordsPanics(t *testing.T) {
+	log := audit.NewMemoryLog()
+	handler := AuditMiddleware(service.NewAuditService(log, service.AuditConfig{}, nil), model.AuditActionSignOut, nil)(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") }))
+
+	assert.Panics(t, func() { serve(handler, http.MethodPost, "/v1/auth/signout", "", nil) })
+
+	entries, _ := log.Query(context.Background(), model.AuditQuery{})
+	require.Len(t, entries, 1)
+	assert.Equal(t, model.AuditOutcomeError, entries[0].Outcome)
+}
+
+func TestAuditMiddleware_RecordsAuthCallsWithSecretsRedacted(t *testing.T) {
+	router, log := newAuditRouter(t)
+	signUp := map[string]string{"email": "dev@example.com", "password": "SecureP@ss123"}
+	clientToken := map[string]string{"client_id": "ci-bot", "client_secret": "s3cr3t"}
+
+	serve(router, http.MethodPost, "/v1/auth/signup", "", signUp)
+	serve(router, http.MethodPost, "/v1/auth/token", "", clientToken)
+	serve(router, http.MethodPost, "/v1/auth/refresh", "", map[string]string{"refresh_token": "eyJ..."})
+
+	entries, err := log.Query(context.Background(), model.AuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	refresh, token, signup := entries[0], entries[1], entries[2]
+
+	assert.Equal(t, model.AuditActionSignUp, signup.Action)
+	assert.Equal(t, "dev@example.com", signup.Target)
+	assert.Empty(t, signup.Actor, "auth routes are unauthenticated")
+	plain, _ := json.Marshal(signUp)
+	assert.NotEqual(t, hashBody(plain), signup.RequestHash, "the password must not be hashed")
+	redacted, _ := json.Marshal(map[string]string{"email": "dev@example.com", "password": "[REDACTED]"})
+	assert.Equal(t, hashBody(redacted), signup.RequestHash)
+
+	assert.Equal(t, model.AuditActionClientCredentials, token.Action)
+	assert.Equal(t, "ci-bot", token.Target)
+	redacted, _ = json.Marshal(map[string]string{"client_id": "ci-bot", "client_secret": "[REDACTED]"})
+	assert.Equal(t, hashBody(redacted), token.RequestHash)
+
+	assert.Equal(t, model.AuditActionRefreshTokens, refresh.Action)
+	assert.Empty(t, refresh.Target)
+}
+
+func TestAuditMiddleware_RejectsOversizedBodies(t *testing.T) {
+	log := audit.NewMemoryLog()
+	called := false
+	handler := AuditMiddleware(service.NewAuditService(log, service.AuditConfig{}, nil), model.AuditActionSignUp, nil, "password")(
+		http.HandlerFunc(func(http.ResponseWriter, *http.Request) { called = true }))
+
+	rec := serve(handler, http.MethodPost, "/v1/auth/signup", "", strings.Repeat("x", maxRequestBodyBytes+1))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.False(t, called)
+}
+
+func TestAuditHandler_AdminsOnly(t *testing.T) {
+	router, _ := newAuditRouter(t)
+	serve(router, http.MethodPost, "/v1/auth/signout", "user", nil)
+
+	assert.Equal(t, http.StatusForbidden, serve(router, http.MethodGet, "/v1/audit", "user", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/v1/audit", "", nil).Code)
+
+	rec := serve(router, http.MethodGet, "/v1/audit?action=auth.signout&limit=10", "admin", nil)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	var page APIResponse[model.AuditPage]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &page))
+	require.Len(t, page.Data.Entries, 1)
+	assert.Equal(t, "user-1", page.Data.Entries[0].Actor)
+
+	rec = serve(router, http.MethodGet, "/v1/audit/verify", "admin", nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var verification APIResponse[model.AuditVerification]
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &verification))
+	assert.True(t, verification.Data.Valid)
+	assert.Equal(t, int64(1), verification.Data.Entries)
+}
+
+func TestAuditHandler_ValidatesQuery(t *testing.T) {
+	router, _ := newAuditRouter(t)
+
+	rec := serve(router, http.MethodGet, "/v1/audit?since=yesterday&before=0", "admin", nil)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), "since")
+	assert.Contains(t, rec.Body.String(), "before")
+}
@@ -0,0 +1,145 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+)
+
+// AuditTarget names the object a request acts on, from the request and its
+// body. Handlers that only learn the target while handling (e.g. the ID of a
+// token they create) report it with setAuditTarget instead.
+type AuditTarget func(r *http.Request, body []byte) string
+
+// AuditMiddleware records every request to a mutating route in the audit
+// log once it has been handled: the caller (it runs inside AuthMiddleware),
+// action, target, request hash and response status. The entry is written
+// even if the client has gone away; a failure to write it is logged by the
+// audit service and does not change the response, which is already sent.
+//
+// The body fields named in redact (e.g. passwords) are blanked before the
+// request is hashed; a body that is not a JSON object is then not hashed at
+// all. The body is read up to maxRequestBodyBytes, like the handlers read it.
+func AuditMiddleware(audit inbound.AuditService, action string, target AuditTarget, redact ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxRequestBodyBytes))
+			if err != nil {
+				if respondIfTooLarge(w, err, r.Header.Get("X-Request-Id")) {
+					return
+				}
+				RespondWithError(w, http.StatusBadRequest, ErrorResponse{
+					Code:      ErrCodeInvalidJSON,
+					Message:   "Failed to read request body",
+					RequestID: r.Header.Get("X-Request-Id"),
+				})
+				return
+			}
+			_ = r.Body.Close()
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			note := &auditNote{}
+			if target != nil {
+				note.target = target(r, body)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), auditNoteKey{}, note))
+			requestHash := hashBody(body)
+			if len(redact) > 0 {
+				requestHash = hashRedacted(body, redact)
+			}
+
+			rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			defer func() {
+				// A panic is answered with a 500 by RecoveryMiddleware.
+				status := rec.status
+				p := recover()
+				if p != nil {
+					status = http.StatusInternalServerError
+				}
+				_ = audit.RecordAudit(context.WithoutCancel(r.Context()), model.AuditEntry{
+					Action:      action,
+					Target:      note.target,
+					Method:      r.Method,
+					Path:        r.URL.Path,
+					RequestHash: requestHash,
+					Status:      status,
+					RequestID:   r.Header.Get("X-Request-Id"),
+				})
+				if p != nil {
+					panic(p)
+				}
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// auditNote carries what a handler learns about the audited request.
+type auditNote struct {
+	target string
+}
+
+type auditNoteKey struct{}
+
+// setAuditTarget reports the object the request acted on, for requests that
+// pass through AuditMiddleware.
+func setAuditTarget(ctx context.Context, target string) {
+	if note, ok := ctx.Value(auditNoteKey{}).(*auditNote); ok {
+		note.target = target
+	}
+}
+
+// auditPathID targets the object named by the {id} path segment.
+func auditPathID(r *http.Request, _ []byte) string {
+	return r.PathValue("id")
+}
+
+// auditBodyID targets the object named by the body's id field.
+var auditBodyID = auditBodyField("id")
+
+// auditBodyField targets the object named by the body's string field name,
+// e.g. the email of the account an auth request acts on.
+func auditBodyField(name string) AuditTarget {
+	return func(_ *http.Request, body []byte) string {
+		var fields map[string]json.RawMessage
+		if json.Unmarshal(body, &fields) != nil {
+			return ""
+		}
+		var v string
+		_ = json.Unmarshal(fields[name], &v)
+		return v
+	}
+}
+
+// hashRedacted hashes a JSON object body with the fields in redact replaced,
+// or returns "" if the body is not a JSON object.
+func hashRedacted(body []byte, redact []string) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(body, &fields) != nil || fields == nil {
+		return ""
+	}
+	for _, name := range redact {
+		if _, ok := fields[name]; ok {
+			fields[name] = json.RawMessage(`"[REDACTED]"`)
+		}
+	}
+	redacted, err := json.Marshal(fields)
+	if err != nil {
+		return ""
+	}
+	return hashBody(redacted)
+}
+
+// hashBody is the hex SHA-256 of a request body, shared by the idempotency
+// and audit middleware so an audit entry can be matched to its key.
+func hashBody(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
@@ -1,8 +1,10 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
@@ -31,6 +33,62 @@ func getRequestID(r *http.Request) string {
 	return ""
 }
 
+// authErrorStatus is the HTTP status for each domain error code an auth
+// operation can fail with. Codes not listed are server-side failures.
+var authErrorStatus = map[string]int{
+	domainerrors.ErrCodeValidationFailed:        http.StatusBadRequest,
+	domainerrors.ErrCodeInvalidEmail:            http.StatusBadRequest,
+	domainerrors.ErrCodeWeakPassword:            http.StatusBadRequest,
+	domainerrors.ErrCodeInvalidConfirmationCode: http.StatusBadRequest,
+	domainerrors.ErrCodeInvalidCredentials:      http.StatusUnauthorized,
+	domainerrors.ErrCodeInvalidToken:            http.StatusUnauthorized,
+	domainerrors.ErrCodeTokenExpired:            http.StatusUnauthorized,
+	domainerrors.ErrCodeAuthFailed:              http.StatusUnauthorized,
+	domainerrors.ErrCodeEmailNotVerified:        http.StatusForbidden,
+	domainerrors.ErrCodePasswordResetRequired:   http.StatusForbidden,
+	domainerrors.ErrCodeUserNotFound:            http.StatusNotFound,
+	domainerrors.ErrCodeUserAlreadyExists:       http.StatusConflict,
+	domainerrors.ErrCodeTooManyRequests:         http.StatusTooManyRequests,
+	domainerrors.ErrCodeCodeDeliveryFailed:      http.StatusBadGateway,
+	domainerrors.ErrCodeExternalService:         http.StatusBadGateway,
+}
+
+// respondWithAuthError answers a failed auth operation with the status its
+// domain code maps to. Client errors carry the domain code and message so the
+// caller can act on them (e.g. EMAIL_NOT_VERIFIED -> resend confirmation);
+// server-side failures are logged and answered generically with fallback.
+func (h *AuthHandler) respondWithAuthError(w http.ResponseWriter, r *http.Request, op string, err error, fallback string) {
+	requestID := getRequestID(r)
+
+	status := http.StatusInternalServerError
+	var domainErr *domainerrors.DomainError
+	if errors.As(err, &domainErr) {
+		if s, ok := authErrorStatus[domainErr.Code]; ok {
+			status = s
+		}
+	}
+
+	if status >= http.StatusInternalServerError {
+		h.logger.WithError(err).Error(op + ": service error")
+		RespondWithError(w, status, ErrorResponse{
+			Code:      ErrCodeInternalError,
+			Message:   fallback,
+			RequestID: requestID,
+		})
+		return
+	}
+
+	h.logger.WithError(err).WithField("code", domainErr.Code).Warn(op + ": rejected")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	}
+	RespondWithError(w, status, ErrorResponse{
+		Code:      domainErr.Code,
+		Message:   domainErr.Message,
+		RequestID: requestID,
+	})
+}
+
 // SignUp handles user registration requests.
 func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 	requestID := getRequestID(r)
@@ -42,12 +100,7 @@ func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.authService.SignUp(r.Context(), *req); err != nil {
-		h.logger.WithError(err).Error("auth.signup: service error")
-		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
-			Code:      ErrCodeInternalError,
-			Message:   "Failed to create user",
-			RequestID: requestID,
-		})
+		h.respondWithAuthError(w, r, "auth.signup", err, "Failed to create user")
 		return
 	}
 
@@ -70,12 +123,7 @@ func (h *AuthHandler) SignIn(w http.ResponseWriter, r *http.Request) {
 
 	resp, err := h.authService.SignIn(r.Context(), *req)
 	if err != nil {
-		h.logger.WithError(err).Warn("auth.signin: unauthorized")
-		RespondWithError(w, http.StatusUnauthorized, ErrorResponse{
-			Code:      ErrCodeUnauthorized,
-			Message:   "Invalid credentials",
-			RequestID: requestID,
-		})
+		h.respondWithAuthError(w, r, "auth.signin", err, "Failed to sign in")
 		return
 	}
 
@@ -94,12 +142,7 @@ func (h *AuthHandler) ConfirmSignUp(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.authService.ConfirmSignUp(r.Context(), *req); err != nil {
-		h.logger.WithError(err).Error("auth.confirm: service error")
-		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
-			Code:      ErrCodeInternalError,
-			Message:   "Failed to confirm user",
-			RequestID: requestID,
-		})
+		h.respondWithAuthError(w, r, "auth.confirm", err, "Failed to confirm user")
 		return
 	}
 
@@ -109,3 +152,131 @@ func (h *AuthHandler) ConfirmSignUp(w http.ResponseWriter, r *http.Request) {
 		Status:  "CONFIRMED",
 	}, requestID))
 }
+
+// RefreshTokens exchanges a refresh token for new access and ID tokens.
+func (h *AuthHandler) RefreshTokens(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	req := DecodeAndValidate[model.RefreshTokenRequest](w, r, requestID)
+	if req == nil {
+		return // Response already sent by DecodeAndValidate
+	}
+
+	resp, err := h.authService.RefreshTokens(r.Context(), *req)
+	if err != nil {
+		h.respondWithAuthError(w, r, "auth.refresh", err, "Failed to refresh tokens")
+		return
+	}
+
+	h.logger.Info("auth.refresh: tokens refreshed")
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(resp, requestID))
+}
+
+// ClientCredentials issues a service client an access token that acts as the
+// client itself, limited to its scopes (OAuth2 client credentials grant).
+func (h *AuthHandler) ClientCredentials(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	req := DecodeAndValidate[model.ClientCredentialsRequest](w, r, requestID)
+	if req == nil {
+		return // Response already sent by DecodeAndValidate
+	}
+
+	resp, err := h.authService.ClientCredentials(r.Context(), *req)
+	if err != nil {
+		h.respondWithAuthError(w, r, "auth.token", err, "Failed to issue client token")
+		return
+	}
+
+	h.logger.Info("auth.token: client token issued", logger.F("client_id", req.ClientID))
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(resp, requestID))
+}
+
+// SignOut revokes every refresh token issued to the caller (global sign-out).
+// The caller is identified by the access token in the Authorization header.
+func (h *AuthHandler) SignOut(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	token, ok := bearerToken(r)
+	if !ok {
+		respondUnauthorized(w, requestID, "Missing bearer token")
+		return
+	}
+
+	if err := h.authService.SignOut(r.Context(), model.SignOutRequest{AccessToken: token}); err != nil {
+		h.respondWithAuthError(w, r, "auth.signout", err, "Failed to sign out")
+		return
+	}
+
+	h.logger.Info("auth.signout: user signed out")
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(MessageResponse{
+		Message: "Signed out of all sessions",
+		Status:  "SIGNED_OUT",
+	}, requestID))
+}
+
+// ForgotPassword emails a password reset code. The response is the same
+// whether or not the email has an account.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	req := DecodeAndValidate[model.ForgotPasswordRequest](w, r, requestID)
+	if req == nil {
+		return // Response already sent by DecodeAndValidate
+	}
+
+	if err := h.authService.ForgotPassword(r.Context(), *req); err != nil && !errors.Is(err, domainerrors.ErrNotFound) {
+		h.respondWithAuthError(w, r, "auth.forgot_password", err, "Failed to start password reset")
+		return
+	}
+
+	h.logger.WithField("email", req.Email).Info("auth.forgot_password: reset requested")
+	RespondWithJSON(w, http.StatusAccepted, NewAPIResponse(MessageResponse{
+		Message: "If the account exists, a password reset code has been sent",
+		Status:  "CODE_SENT",
+	}, requestID))
+}
+
+// ConfirmForgotPassword sets a new password using the emailed reset code.
+func (h *AuthHandler) ConfirmForgotPassword(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	req := DecodeAndValidate[model.ConfirmForgotPasswordRequest](w, r, requestID)
+	if req == nil {
+		return // Response already sent by DecodeAndValidate
+	}
+
+	if err := h.authService.ConfirmForgotPassword(r.Context(), *req); err != nil {
+		h.respondWithAuthError(w, r, "auth.confirm_forgot_password", err, "Failed to reset password")
+		return
+	}
+
+	h.logger.WithField("email", req.Email).Info("auth.confirm_forgot_password: password reset")
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(MessageResponse{
+		Message: "Password reset successfully",
+		Status:  "PASSWORD_RESET",
+	}, requestID))
+}
+
+// ResendConfirmation emails a new sign-up confirmation code. Like
+// ForgotPassword, the response does not reveal whether the email has an
+// account.
+func (h *AuthHandler) ResendConfirmation(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	req := DecodeAndValidate[model.ResendConfirmationRequest](w, r, requestID)
+	if req == nil {
+		return // Response already sent by DecodeAndValidate
+	}
+
+	if err := h.authService.ResendConfirmation(r.Context(), *req); err != nil && !errors.Is(err, domainerrors.ErrNotFound) {
+		h.respondWithAuthError(w, r, "auth.resend_confirmation", err, "Failed to resend confirmation code")
+		return
+	}
+
+	h.logger.WithField("email", req.Email).Info("auth.resend_confirmation: code requested")
+	RespondWithJSON(w, http.StatusAccepted, NewAPIResponse(MessageResponse{
+		Message: "If the account exists and is unconfirmed, a new confirmation code has been sent",
+		Status:  "CODE_SENT",
+	}, requestID))
+}
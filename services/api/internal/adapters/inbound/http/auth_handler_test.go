@@ -0,0 +1,184 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+func postJSON(handler http.HandlerFunc, body any) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func TestRefreshTokens_ReturnsNewTokens(t *testing.T) {
+	service := &mocks.FakeAuthService{ResponseToReturn: &model.AuthResponse{AccessToken: "new-access", TokenType: "Bearer"}}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	rec := postJSON(handler.RefreshTokens, model.RefreshTokenRequest{RefreshToken: "refresh-1"})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "refresh-1", service.LastRefresh.RefreshToken)
+	var resp APIResponse[model.AuthResponse]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "new-access", resp.Data.AccessToken)
+}
+
+func TestRefreshTokens_RevokedTokenIs401(t *testing.T) {
+	service := &mocks.FakeAuthService{ErrToReturn: domainerrors.Unauthorized("revoked")}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	rec := postJSON(handler.RefreshTokens, model.RefreshTokenRequest{RefreshToken: "refresh-1"})
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestClientCredentials_SplitsScopes(t *testing.T) {
+	service := &mocks.FakeAuthService{ResponseToReturn: &model.AuthResponse{AccessToken: "m2m", TokenType: "Bearer", ExpiresIn: 3600}}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	rec := postJSON(handler.ClientCredentials, model.ClientCredentialsRequest{
+		ClientID: "ci-bot", ClientSecret: "s3cret", Scope: "provision:write resources:read",
+	})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ci-bot", service.LastClientCredentials.ClientID)
+	var resp APIResponse[model.AuthResponse]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "m2m", resp.Data.AccessToken)
+}
+
+func TestClientCredentials_BadSecretIs401(t *testing.T) {
+	service := &mocks.FakeAuthService{ErrToReturn: domainerrors.NewDomainError(
+		domainerrors.ErrCodeInvalidCredentials, "invalid client credentials", domainerrors.ErrUnauthorized)}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	rec := postJSON(handler.ClientCredentials, model.ClientCredentialsRequest{ClientID: "ci-bot", ClientSecret: "wrong"})
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), domainerrors.ErrCodeInvalidCredentials)
+}
+
+func TestSignOut_UsesBearerToken(t *testing.T) {
+	service := &mocks.FakeAuthService{}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/auth/signout", nil)
+	req.Header.Set("Authorization", "Bearer access-1")
+	rec := httptest.NewRecorder()
+	handler.SignOut(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "access-1", service.LastSignOut.AccessToken)
+}
+
+func TestSignOut_WithoutTokenIs401(t *testing.T) {
+	handler := NewAuthHandler(&mocks.FakeAuthService{}, logger.NopLogger{})
+
+	rec := httptest.NewRecorder()
+	handler.SignOut(rec, httptest.NewRequest(http.MethodPost, "/v1/auth/signout", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// Unknown emails get the same answer as known ones, so the endpoints cannot
+// be used to discover accounts.
+func TestCodeDelivery_DoesNotRevealUnknownUsers(t *testing.T) {
+	notFound := domainerrors.NewDomainError(domainerrors.ErrCodeUserNotFound, "user not found", domainerrors.ErrNotFound)
+
+	for name, call := range map[string]func(*AuthHandler) *httptest.ResponseRecorder{
+		"forgot password": func(h *AuthHandler) *httptest.ResponseRecorder {
+			return postJSON(h.ForgotPassword, model.ForgotPasswordRequest{Email: "user@example.com"})
+		},
+		"resend confirmation": func(h *AuthHandler) *httptest.ResponseRecorder {
+			return postJSON(h.ResendConfirmation, model.ResendConfirmationRequest{Email: "user@example.com"})
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			known := call(NewAuthHandler(&mocks.FakeAuthService{}, logger.NopLogger{}))
+			unknown := call(NewAuthHandler(&mocks.FakeAuthService{ErrToReturn: notFound}, logger.NopLogger{}))
+			outage := call(NewAuthHandler(&mocks.FakeAuthService{ErrToReturn: assert.AnError}, logger.NopLogger{}))
+
+			assert.Equal(t, http.StatusAccepted, known.Code)
+			assert.Equal(t, known.Code, unknown.Code)
+			assert.Equal(t, http.StatusInternalServerError, outage.Code)
+		})
+	}
+}
+
+func TestConfirmForgotPassword_BadCodeIs400WithDomainCode(t *testing.T) {
+	service := &mocks.FakeAuthService{ErrToReturn: domainerrors.NewDomainError(
+		domainerrors.ErrCodeInvalidConfirmationCode, "reset code is invalid or expired", domainerrors.ErrInvalidInput)}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	rec := postJSON(handler.ConfirmForgotPassword, model.ConfirmForgotPasswordRequest{
+		Email: "user@example.com", ConfirmationCode: "123456", NewPassword: "N3wSecureP@ss",
+	})
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp ErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, domainerrors.ErrCodeInvalidConfirmationCode, resp.Code)
+	assert.Equal(t, "N3wSecureP@ss", service.LastConfirmForgotPassword.NewPassword)
+}
+
+func TestConfirmForgotPassword_ValidatesBody(t *testing.T) {
+	service := &mocks.FakeAuthService{}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	rec := postJSON(handler.ConfirmForgotPassword, model.ConfirmForgotPasswordRequest{Email: "user@example.com", ConfirmationCode: "123456"})
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Empty(t, service.LastConfirmForgotPassword.Email, "invalid requests never reach the service")
+}
+
+func TestAuthHandler_StatusFollowsDomainCode(t *testing.T) {
+	cases := map[string]struct {
+		code   string
+		status int
+	}{
+		"duplicate user": {domainerrors.ErrCodeUserAlreadyExists, http.StatusConflict},
+		"weak password":  {domainerrors.ErrCodeWeakPassword, http.StatusBadRequest},
+		"not confirmed":  {domainerrors.ErrCodeEmailNotVerified, http.StatusForbidden},
+		"throttled":      {domainerrors.ErrCodeTooManyRequests, http.StatusTooManyRequests},
+		"bad password":   {domainerrors.ErrCodeInvalidCredentials, http.StatusUnauthorized},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			service := &mocks.FakeAuthService{ErrToReturn: domainerrors.NewDomainError(tc.code, "reason", assert.AnError)}
+			handler := NewAuthHandler(service, logger.NopLogger{})
+
+			rec := postJSON(handler.SignUp, model.SignUpRequest{Email: "user@example.com", Password: "SecureP@ss123"})
+
+			assert.Equal(t, tc.status, rec.Code)
+			var resp ErrorResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tc.code, resp.Code, "clients get the domain code to act on")
+		})
+	}
+}
+
+func TestAuthHandler_ServerErrorsAreGeneric(t *testing.T) {
+	service := &mocks.FakeAuthService{ErrToReturn: domainerrors.Internal("cognito exploded: arn:aws:...", assert.AnError)}
+	handler := NewAuthHandler(service, logger.NopLogger{})
+
+	rec := postJSON(handler.SignIn, model.SignInRequest{Email: "user@example.com", Password: "x"})
+
+	assert.Equal(t, http.StatusBadGateway, rec.Code)
+	var resp ErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, ErrCodeInternalError, resp.Code)
+	assert.NotContains(t, resp.Message, "arn:aws", "provider details must not leak")
+}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/jwt"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/application/service"
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+// principalEcho records the principal the middleware attached.
+func principalEcho(got *model.Principal) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*got, _ = model.PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func TestAuthMiddleware_AttachesPrincipal(t *testing.T) {
+	verifier := &mocks.FakeTokenVerifier{Principal: model.Principal{Subject: "user-1"}}
+	var got model.Principal
+	handler := AuthMiddleware(verifier, logger.NopLogger{})(principalEcho(&got))
+
+	req := httptest.NewRequest(http.MethodPost, "/v1/provision", nil)
+	req.Header.Set("Authorization", "bearer abc.def.ghi")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "abc.def.ghi", verifier.LastToken)
+	assert.Equal(t, "user-1", got.Subject)
+}
+
+func TestAuthMiddleware_Rejects(t *testing.T) {
+	cases := map[string]struct {
+		header   string
+		verifier *mocks.FakeTokenVerifier
+		status   int
+	}{
+		"no header":     {"", &mocks.FakeTokenVerifier{}, http.StatusUnauthorized},
+		"basic auth":    {"Basic dXNlcjpwYXNz", &mocks.FakeTokenVerifier{}, http.StatusUnauthorized},
+		"empty bearer":  {"Bearer ", &mocks.FakeTokenVerifier{}, http.StatusUnauthorized},
+		"invalid token": {"Bearer x", &mocks.FakeTokenVerifier{Err: domainerrors.Unauthorized("bad token")}, http.StatusUnauthorized},
+		"keys offline":  {"Bearer x", &mocks.FakeTokenVerifier{Err: domainerrors.Internal("jwks", assert.AnError)}, http.StatusInternalServerError},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			called := false
+			handler := AuthMiddleware(tc.verifier, logger.NopLogger{})(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+				called = true
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/provision", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			assert.Equal(t, tc.status, rec.Code)
+			assert.False(t, called, "the handler must not run")
+			if tc.status == http.StatusUnauthorized {
+				assert.Contains(t, rec.Header().Get("WWW-Authenticate"), "Bearer")
+			}
+		})
+	}
+}
+
+// The whole path with a locally generated key set: a token signed by the
+// trusted key reaches the service, which attributes the request to the token
+// subject rather than the requested_by in the body.
+func TestRouter_ProvisionRequiresValidToken(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := jwt.NewSigner(key, "local-1")
+	keys, err := jwt.NewStaticKeySet(signer.JWKS())
+	require.NoError(t, err)
+	verifier, err := jwt.NewVerifier(keys, jwt.Config{Issuer: "https://issuer.test", Audiences: []string{"client-1"}, TokenUse: "access"})
+	require.NoError(t, err)
+
+	publisher := &mocks.FakeResourcePublisher{}
+	handler := NewResourceHandler(service.NewResourceService(publisher, nil, nil, nil))
+	config := DefaultRouterConfig()
+	config.TokenVerifier = verifier
+	router := NewRouterWithConfig(handler, nil, nil, nil, config)
+
+	body, _ := json.Marshal(model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro",
+		Status: "pending", RequestedBy: "someone-else",
+	})
+	provision := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/provision", bytes.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, provision("").Code)
+	assert.Equal(t, 0, publisher.TimesCalled)
+
+	token, err := signer.Sign(map[string]any{
+		"iss": "https://issuer.test", "sub": "user-42", "client_id": "client-1", "token_use": "access",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	rec := provision(token)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code, rec.Body.String())
+	assert.Equal(t, "user-42", publisher.LastSent.RequestedBy)
+}
+
+func TestRouter_JWKSEndpoint(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	signer := jwt.NewSigner(key, "local-1")
+	get := func(router http.Handler) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, get(NewRouterWithConfig(nil, nil, nil, nil, DefaultRouterConfig())).Code)
+
+	config := DefaultRouterConfig()
+	config.JWKSHandler = jwt.JWKSHandler(signer.JWKS())
+	rec := get(NewRouterWithConfig(nil, nil, nil, nil, config))
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	var set jwt.JWKS
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &set))
+	require.Len(t, set.Keys, 1)
+	assert.Equal(t, "local-1", set.Keys[0].KeyID)
+}
+
+func TestProvisionHandler_MissingRequesterIsValidationError(t *testing.T) {
+	handler := NewResourceHandler(service.NewResourceService(&mocks.FakeResourcePublisher{}, nil, nil, nil))
+	body, _ := json.Marshal(model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending"})
+	req := httptest.NewRequest(http.MethodPost, "/provision", bytes.NewReader(body)).WithContext(context.Background())
+	rec := httptest.NewRecorder()
+
+	handler.Provision(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	var resp ErrorResponse
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	if assert.Len(t, resp.Details, 1) {
+		assert.Equal(t, "requested_by", resp.Details[0].Field)
+	}
+}
+
+func TestScopeMiddleware(t *testing.T) {
+	cases := map[string]struct {
+		principal model.Principal
+		status    int
+	}{
+		"session":            {model.Principal{Subject: "user-1"}, http.StatusNoContent},
+		"token with scope":   {model.Principal{Subject: "user-1", APITokenID: "t1", Scopes: []string{model.ScopeProvisionWrite}}, http.StatusNoContent},
+		"token out of scope": {model.Principal{Subject: "user-1", APITokenID: "t1", Scopes: []string{model.ScopeProvisionValidate}}, http.StatusForbidden},
+		"machine with scope": {model.Principal{Subject: "ci-bot", Machine: true, Scopes: []string{model.ScopeProvisionWrite}}, http.StatusNoContent},
+		"machine unscoped":   {model.Principal{Subject: "ci-bot", Machine: true}, http.StatusForbidden},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got model.Principal
+			handler := ScopeMiddleware(model.ScopeProvisionWrite)(principalEcho(&got))
+			req := httptest.NewRequest(http.MethodPost, "/v1/provision", nil)
+			req = req.WithContext(model.WithPrincipal(req.Context(), tc.principal))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			assert.Equal(t, tc.status, rec.Code)
+		})
+	}
+}
+
+func TestRouter_MachineScopesPerRoute(t *testing.T) {
+	publisher := &mocks.FakeResourcePublisher{}
+	config := DefaultRouterConfig()
+	config.TokenVerifier = &mocks.FakeTokenVerifier{Principal: model.Principal{
+		Subject: "reporting", ClientID: "reporting", Machine: true, Scopes: []string{model.ScopeResourcesRead},
+	}}
+	router := NewRouterWithConfig(NewResourceHandler(service.NewResourceService(publisher, nil, nil, nil)), nil, nil, nil, config)
+	resource := model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending"}
+
+	assert.Equal(t, http.StatusOK, serve(router, http.MethodGet, "/v1/resources", "m2m", nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(router, http.MethodGet, "/v1/resources", "", nil).Code)
+
+	rec := serve(router, http.MethodPost, "/v1/provision", "m2m", resource)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Contains(t, rec.Body.String(), model.ScopeProvisionWrite)
+	assert.Equal(t, http.StatusForbidden, serve(router, http.MethodPost, "/v1/provision:validate", "m2m", resource).Code)
+	assert.Equal(t, 0, publisher.TimesCalled)
+}
@@ -3,8 +3,6 @@ package http
 import (
 	"bytes"
 	"context"
-	"crypto/sha256"
-	"encoding/hex"
 	"io"
 	"net/http"
 	"time"
@@ -61,9 +59,11 @@ func IdempotencyMiddleware(store outbound.IdempotencyStore, ttl time.Duration) f
 			}
 			_ = r.Body.Close()
 			r.Body = io.NopCloser(bytes.NewReader(body))
+			// Downstream, the key travels with the request it was accepted
+			// under, down to the publisher's deduplication ID.
+			r = r.WithContext(outbound.WithIdempotencyKey(r.Context(), key))
 
-			hash := sha256.Sum256(body)
-			requestHash := hex.EncodeToString(hash[:])
+			requestHash := hashBody(body)
 
 			existing, created, err := store.Reserve(r.Context(), key, requestHash, ttl)
 			if err != nil {
@@ -142,6 +142,20 @@ func TestIdempotencyMiddleware_FirstCallReachesHandlerAndStoresResponse(t *testi
 	assert.JSONEq(t, `{"id":"abc"}`, string(stored.Body))
 }
 
+func TestIdempotencyMiddleware_PassesKeyDownstream(t *testing.T) {
+	var got string
+	mw := IdempotencyMiddleware(newFakeStore(), time.Hour)
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = outbound.IdempotencyKeyFromContext(r.Context())
+		w.WriteHeader(http.StatusAccepted)
+	}))
+
+	key := uuid.New().String()
+	h.ServeHTTP(httptest.NewRecorder(), newRequest(t, key, `{"x":1}`))
+
+	assert.Equal(t, key, got)
+}
+
 func TestIdempotencyMiddleware_DuplicateReplaysCachedResponse(t *testing.T) {
 	store := newFakeStore()
 	mw := IdempotencyMiddleware(store, time.Hour)
@@ -0,0 +1,157 @@
+package http
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// RateLimitPolicy sizes the rate limit of each route.
+type RateLimitPolicy struct {
+	// Default applies to routes without an entry in Routes.
+	Default outbound.RateLimit
+
+	// Routes overrides Default by route pattern, as registered on the mux
+	// (e.g. "POST /v1/provision"). A zero limit leaves the route unlimited.
+	Routes map[string]outbound.RateLimit
+
+	// ClientIP limits each client IP across the authenticated routes before
+	// its credentials are checked, so callers presenting bad or no tokens are
+	// limited too. A zero limit leaves it unlimited.
+	ClientIP outbound.RateLimit
+
+	// TrustForwardedFor keys anonymous callers by the address the load
+	// balancer appended to X-Forwarded-For rather than the peer address. Set
+	// it only behind a proxy that appends one, or callers can pick their own.
+	TrustForwardedFor bool
+}
+
+func (p RateLimitPolicy) limitFor(pattern string) outbound.RateLimit {
+	if limit, ok := p.Routes[pattern]; ok {
+		return limit
+	}
+	return p.Default
+}
+
+// RateLimitMiddleware gives each caller a bucket per route: authenticated
+// callers are keyed by principal (it runs inside AuthMiddleware), anyone else
+// by client IP. Every limited response carries X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (Unix seconds when the bucket
+// is full again); a refused call gets a 429 with Retry-After.
+//
+// If the limiter fails the request is let through: an outage of the rate
+// limit store should not take the API down with it.
+func RateLimitMiddleware(limiter outbound.RateLimiter, policy RateLimitPolicy, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			limit := policy.limitFor(r.Pattern)
+			if limit.Unlimited() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			caller := rateLimitCaller(r, policy.TrustForwardedFor)
+			if allowRequest(w, r, limiter, r.Pattern+"|"+caller, limit, log) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// ClientIPRateLimitMiddleware gives each client IP one bucket, sized by
+// policy.ClientIP, shared by every route it wraps. It runs in front of
+// AuthMiddleware, so requests are limited before their credentials are
+// verified; RateLimitMiddleware inside it still limits each principal. A
+// refused call gets a 429 like RateLimitMiddleware's, and a failing limiter
+// lets the request through.
+func ClientIPRateLimitMiddleware(limiter outbound.RateLimiter, policy RateLimitPolicy, log logger.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if policy.ClientIP.Unlimited() {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := "client|ip:" + clientIP(r, policy.TrustForwardedFor)
+			if allowRequest(w, r, limiter, key, policy.ClientIP, log) {
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// allowRequest takes a token from key's bucket and sets the rate limit
+// headers. If the bucket is empty it writes a 429 and returns false.
+func allowRequest(w http.ResponseWriter, r *http.Request, limiter outbound.RateLimiter, key string, limit outbound.RateLimit, log logger.Logger) bool {
+	d, err := limiter.Allow(r.Context(), key, limit)
+	if err != nil {
+		log.WithContext(r.Context()).WithError(err).Warn("rate limiter failed; allowing request",
+			logger.F("route", r.Pattern),
+			logger.F("key", key),
+		)
+		return true
+	}
+
+	h := w.Header()
+	h.Set("X-RateLimit-Limit", strconv.Itoa(d.Limit))
+	h.Set("X-RateLimit-Remaining", strconv.Itoa(d.Remaining))
+	h.Set("X-RateLimit-Reset", strconv.FormatInt(ceilUnix(d.ResetAt), 10))
+	if !d.Allowed {
+		retryAfter := ceilSeconds(d.RetryAfter)
+		h.Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+		RespondWithError(w, http.StatusTooManyRequests, ErrorResponse{
+			Code:      ErrCodeRateLimited,
+			Message:   "Rate limit exceeded; retry after " + strconv.FormatInt(retryAfter, 10) + "s",
+			RequestID: r.Header.Get("X-Request-Id"),
+		})
+		return false
+	}
+	return true
+}
+
+// rateLimitCaller names the bucket owner: the authenticated principal, or
+// else the client IP.
+func rateLimitCaller(r *http.Request, trustForwardedFor bool) string {
+	if principal, ok := model.PrincipalFromContext(r.Context()); ok && principal.Subject != "" {
+		return "principal:" + principal.Subject
+	}
+	return "ip:" + clientIP(r, trustForwardedFor)
+}
+
+// clientIP is the caller's address: the last X-Forwarded-For hop when
+// trustForwardedFor is set and there is one, the peer address otherwise.
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		// The last hop is the one our load balancer appended; anything before
+		// it came from the client.
+		if xff := r.Header.Values("X-Forwarded-For"); len(xff) > 0 {
+			hops := strings.Split(xff[len(xff)-1], ",")
+			if ip := strings.TrimSpace(hops[len(hops)-1]); ip != "" {
+				return ip
+			}
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return host
+}
+
+// ceilSeconds rounds d up to whole seconds, and at least one: Retry-After
+// has no finer unit, and "0" would invite an immediate retry.
+func ceilSeconds(d time.Duration) int64 {
+	return max(1, int64((d+time.Second-1)/time.Second))
+}
+
+// ceilUnix rounds t up to whole Unix seconds.
+func ceilUnix(t time.Time) int64 {
+	if t.Nanosecond() > 0 {
+		return t.Unix() + 1
+	}
+	return t.Unix()
+}
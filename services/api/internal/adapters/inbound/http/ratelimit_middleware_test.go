@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/audit"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/ratelimit"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/application/service"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+func newRateLimitedRouter(t *testing.T, limiter outbound.RateLimiter, policy RateLimitPolicy) (http.Handler, *audit.MemoryLog) {
+	t.Helper()
+	log := audit.NewMemoryLog()
+	config := DefaultRouterConfig()
+	config.TokenVerifier = tokenPrincipals{
+		"alice": {Subject: "alice"},
+		"bob":   {Subject: "bob"},
+	}
+	config.AuditService = service.NewAuditService(log, service.AuditConfig{}, nil)
+	config.RateLimiter = limiter
+	config.RateLimits = policy
+	resources := NewResourceHandler(service.NewResourceService(&mocks.FakeResourcePublisher{}, nil, nil, nil))
+	auth := NewAuthHandler(&mocks.FakeAuthService{}, logger.NopLogger{})
+	return NewRouterWithConfig(resources, nil, auth, nil, config), log
+}
+
+func TestRateLimitMiddleware_LimitsEachPrincipal(t *testing.T) {
+	router, log := newRateLimitedRouter(t, ratelimit.NewMemoryLimiter(), RateLimitPolicy{
+		Routes: map[string]outbound.RateLimit{"POST /v1/provision": {Requests: 2, Window: time.Minute}},
+	})
+	resource := model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending"}
+
+	first := serve(router, http.MethodPost, "/v1/provision", "alice", resource)
+	assert.Equal(t, http.StatusAccepted, first.Code)
+	assert.Equal(t, "2", first.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("X-RateLimit-Remaining"))
+	reset, err := strconv.ParseInt(first.Header().Get("X-RateLimit-Reset"), 10, 64)
+	require.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(30*time.Second).Unix(), reset, 2)
+
+	serve(router, http.MethodPost, "/v1/provision", "alice", resource)
+	refused := serve(router, http.MethodPost, "/v1/provision", "alice", resource)
+	assert.Equal(t, http.StatusTooManyRequests, refused.Code)
+	assert.Equal(t, "0", refused.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "30", refused.Header().Get("Retry-After"))
+	var body ErrorResponse
+	require.NoError(t, json.Unmarshal(refused.Body.Bytes(), &body))
+	assert.Equal(t, ErrCodeRateLimited, body.Code)
+
+	assert.Equal(t, http.StatusAccepted, serve(router, http.MethodPost, "/v1/provision", "bob", resource).Code,
+		"each principal has its own bucket")
+
+	entries, err := log.Query(context.Background(), model.AuditQuery{Actor: "alice"})
+	require.NoError(t, err)
+	assert.Len(t, entries, 2, "refused calls are not audited")
+}
+
+func TestRateLimitMiddleware_LimitsAnonymousCallersByIP(t *testing.T) {
+	router, _ := newRateLimitedRouter(t, ratelimit.NewMemoryLimiter(), RateLimitPolicy{
+		Default:           outbound.RateLimit{Requests: 1, Window: time.Minute},
+		TrustForwardedFor: true,
+	})
+	signIn := func(forwardedFor string) int {
+		req := httptest.NewRequest(http.MethodPost, "/v1/auth/signin", nil)
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.NotEqual(t, http.StatusTooManyRequests, signIn("203.0.113.7, 198.51.100.1"))
+	assert.Equal(t, http.StatusTooManyRequests, signIn("192.0.2.99, 198.51.100.1"),
+		"only the hop appended by the load balancer counts")
+	assert.NotEqual(t, http.StatusTooManyRequests, signIn("198.51.100.2"))
+}
+
+func TestRateLimitMiddleware_UnlimitedRoutes(t *testing.T) {
+	router, _ := newRateLimitedRouter(t, ratelimit.NewMemoryLimiter(), RateLimitPolicy{
+		Default: outbound.RateLimit{Requests: 1, Window: time.Minute},
+		Routes:  map[string]outbound.RateLimit{"POST /v1/auth/signin": {}},
+	})
+
+	for range 3 {
+		rec := serve(router, http.MethodPost, "/v1/auth/signin", "", nil)
+		assert.NotEqual(t, http.StatusTooManyRequests, rec.Code)
+		assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+type unavailableLimiter struct{}
+
+func (unavailableLimiter) Allow(context.Context, string, outbound.RateLimit) (outbound.RateLimitDecision, error) {
+	return outbound.RateLimitDecision{}, errors.New("connection refused")
+}
+
+func TestRateLimitMiddleware_AllowsRequestsWhenLimiterFails(t *testing.T) {
+	router, _ := newRateLimitedRouter(t, unavailableLimiter{}, RateLimitPolicy{
+		Default: outbound.RateLimit{Requests: 1, Window: time.Minute},
+	})
+	resource := model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending"}
+
+	for range 2 {
+		rec := serve(router, http.MethodPost, "/v1/provision", "alice", resource)
+		assert.Equal(t, http.StatusAccepted, rec.Code)
+		assert.Empty(t, rec.Header().Get("X-RateLimit-Limit"))
+	}
+}
+
+func TestClientIPRateLimit_LimitsCallersBeforeTheirTokensAreChecked(t *testing.T) {
+	router, log := newRateLimitedRouter(t, ratelimit.NewMemoryLimiter(), RateLimitPolicy{
+		ClientIP: outbound.RateLimit{Requests: 2, Window: time.Minute},
+	})
+
+	for range 2 {
+		assert.NotEqual(t, http.StatusTooManyRequests, serve(router, http.MethodGet, "/v1/resources", "forged", nil).Code)
+	}
+	refused := serve(router, http.MethodGet, "/v1/resources", "forged", nil)
+	assert.Equal(t, http.StatusTooManyRequests, refused.Code, "bad tokens are limited by client IP")
+	assert.NotEmpty(t, refused.Header().Get("Retry-After"))
+
+	resource := model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending"}
+	assert.Equal(t, http.StatusTooManyRequests, serve(router, http.MethodPost, "/v1/provision", "alice", resource).Code,
+		"the client IP bucket is shared by every authenticated route")
+	entries, err := log.Query(context.Background(), model.AuditQuery{})
+	require.NoError(t, err)
+	assert.Empty(t, entries, "calls refused before authentication are not audited")
+}
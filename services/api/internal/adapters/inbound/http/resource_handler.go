@@ -1,8 +1,12 @@
 package http
 
 import (
+	"errors"
 	"net/http"
+	"net/url"
+	"strconv"
 
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
 )
@@ -29,11 +33,18 @@ func (h *ResourceHandler) Provision(w http.ResponseWriter, r *http.Request) {
 
 	err := h.resourceService.SendProvisioningRequest(r.Context(), *resource)
 	if err != nil {
-		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
-			Code:      ErrCodeInternalError,
-			Message:   "Failed to process provisioning request",
-			RequestID: requestID,
-		})
+		var domainErr *domainerrors.DomainError
+		if errors.Is(err, domainerrors.ErrPolicyViolation) && errors.As(err, &domainErr) {
+			violations, _ := domainErr.Details["violations"].([]model.PolicyViolation)
+			RespondWithError(w, http.StatusUnprocessableEntity, ErrorResponse{
+				Code:      ErrCodePolicyViolation,
+				Message:   "Request violates provisioning policy",
+				RequestID: requestID,
+				Details:   violationErrors(violations),
+			})
+			return
+		}
+		respondWithAdmissionError(w, requestID, err, "Failed to process provisioning request")
 		return
 	}
 
@@ -41,5 +52,189 @@ func (h *ResourceHandler) Provision(w http.ResponseWriter, r *http.Request) {
 		Message:   "Request accepted for processing",
 		RequestID: requestID,
 		Status:    "ACCEPTED",
+		TrackURL:  resourceTrackURL(resource.ID),
+	}, requestID))
+}
+
+// ValidateProvision is a dry run of Provision: the request goes through the
+// same validation, authorization and policy checks but is not accepted.
+// Guardrail violations are reported in a 200 response rather than as an
+// error, so clients can show them all at once.
+func (h *ResourceHandler) ValidateProvision(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+
+	resource := DecodeAndValidate[model.Resource](w, r, requestID)
+	if resource == nil {
+		return // Response already sent by DecodeAndValidate
+	}
+
+	violations, err := h.resourceService.ValidateProvisioningRequest(r.Context(), *resource)
+	if err != nil {
+		respondWithAdmissionError(w, requestID, err, "Failed to validate provisioning request")
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(ProvisionValidationResponse{
+		Valid:      len(violations) == 0,
+		Violations: violationErrors(violations),
 	}, requestID))
 }
+
+// respondWithAdmissionError maps a failure to accept a provisioning request:
+// invalid input is a 400 on the offending field, an access policy denial a
+// 403 carrying its reason, an ID that is already taken a 409, and anything
+// else a generic 500.
+func respondWithAdmissionError(w http.ResponseWriter, requestID string, err error, fallback string) {
+	var domainErr *domainerrors.DomainError
+	switch {
+	case errors.Is(err, domainerrors.ErrInvalidInput) && errors.As(err, &domainErr):
+		field, _ := domainErr.Details["field"].(string)
+		RespondWithValidationError(w, requestID, []ValidationError{
+			{Field: field, Message: domainErr.Message},
+		})
+	case errors.Is(err, domainerrors.ErrForbidden) && errors.As(err, &domainErr):
+		RespondWithError(w, http.StatusForbidden, ErrorResponse{
+			Code:      ErrCodeForbidden,
+			Message:   "Not permitted: " + domainErr.Message,
+			RequestID: requestID,
+		})
+	case errors.Is(err, domainerrors.ErrAlreadyExists) && errors.As(err, &domainErr):
+		RespondWithError(w, http.StatusConflict, ErrorResponse{
+			Code:      ErrCodeConflict,
+			Message:   domainErr.Message,
+			RequestID: requestID,
+		})
+	default:
+		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
+			Code:      ErrCodeInternalError,
+			Message:   fallback,
+			RequestID: requestID,
+		})
+	}
+}
+
+// violationErrors lists policy violations in the error details format. It
+// never returns nil, so a valid dry run reports an empty list.
+func violationErrors(violations []model.PolicyViolation) []ValidationError {
+	details := make([]ValidationError, 0, len(violations))
+	for _, v := range violations {
+		detail := ValidationError{Field: v.Field, Message: v.Message, Rule: v.Rule}
+		if v.Value != "" {
+			detail.Value = v.Value
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+// GetResource returns the current record and provisioning status of a
+// previously submitted resource.
+func (h *ResourceHandler) GetResource(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+	id := r.PathValue("id")
+
+	record, err := h.resourceService.GetResource(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, domainerrors.ErrNotFound) {
+			RespondWithError(w, http.StatusNotFound, ErrorResponse{
+				Code:      ErrCodeNotFound,
+				Message:   "Resource not found",
+				RequestID: requestID,
+			})
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
+			Code:      ErrCodeInternalError,
+			Message:   "Failed to load resource",
+			RequestID: requestID,
+		})
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, NewAPIResponse(record, requestID))
+}
+
+// resourceTrackURL is the status link returned with a 202 so clients can poll
+// GET /v1/resources/{id} instead of searching logs.
+func resourceTrackURL(id string) string {
+	return APIVersionPrefix + "/resources/" + url.PathEscape(id)
+}
+
+// ResourceListParams are the query parameters accepted by GET /v1/resources.
+// Validation tags mirror model.Resource so filters only take values a stored
+// resource can actually have. A zero Limit leaves the page size to the service.
+type ResourceListParams struct {
+	ResourceType  string `validate:"omitempty,oneof=VM RDS S3 Lambda VPC ELB"`
+	CloudProvider string `validate:"omitempty,oneof=AWS Azure GCP"`
+	Status        string `validate:"omitempty,oneof=pending in_progress completed failed retrying cancelled"`
+	RequestedBy   string `validate:"omitempty,max=100"`
+	Cursor        string `validate:"omitempty,max=512"`
+	Limit         int    `validate:"omitempty,gte=1,lte=100"`
+}
+
+// ListResources returns a cursor-paginated, filterable listing of submitted
+// resources, newest first.
+func (h *ResourceHandler) ListResources(w http.ResponseWriter, r *http.Request) {
+	requestID := getRequestID(r)
+	query := r.URL.Query()
+
+	params := ResourceListParams{
+		ResourceType:  query.Get("resource_type"),
+		CloudProvider: query.Get("cloud_provider"),
+		Status:        query.Get("status"),
+		RequestedBy:   query.Get("requested_by"),
+		Cursor:        query.Get("cursor"),
+	}
+	if raw := query.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			RespondWithValidationError(w, requestID, []ValidationError{
+				{Field: "limit", Message: "limit must be an integer", Value: raw},
+			})
+			return
+		}
+		params.Limit = limit
+	}
+	if errs := ValidateStruct(params); len(errs) > 0 {
+		RespondWithValidationError(w, requestID, errs)
+		return
+	}
+
+	filter := model.ResourceFilter{
+		ResourceType:  params.ResourceType,
+		CloudProvider: params.CloudProvider,
+		Status:        params.Status,
+		RequestedBy:   params.RequestedBy,
+	}
+	page, err := h.resourceService.ListResources(r.Context(), filter, params.Cursor, params.Limit)
+	if err != nil {
+		if errors.Is(err, domainerrors.ErrInvalidInput) {
+			RespondWithValidationError(w, requestID, []ValidationError{
+				{Field: "cursor", Message: "cursor is invalid or expired"},
+			})
+			return
+		}
+		RespondWithError(w, http.StatusInternalServerError, ErrorResponse{
+			Code:      ErrCodeInternalError,
+			Message:   "Failed to list resources",
+			RequestID: requestID,
+		})
+		return
+	}
+
+	RespondWithJSON(w, http.StatusOK, NewPaginatedResponse(page.Records, requestID, PaginationMeta{
+		// Cursor pagination has no page number; Page stays zero.
+		PerPage:    params.Limit,
+		TotalItems: page.TotalItems,
+		TotalPages: totalPages(page.TotalItems, params.Limit),
+		NextCursor: page.NextCursor,
+		PrevCursor: page.PrevCursor,
+	}))
+}
+
+func totalPages(total, perPage int) int {
+	if perPage <= 0 {
+		return 0
+	}
+	return (total + perPage - 1) / perPage
+}
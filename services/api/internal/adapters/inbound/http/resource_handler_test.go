@@ -3,6 +3,7 @@ package http
 import (
 	"bytes"
 	"encoding/json"
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
 	"github.com/stretchr/testify/assert"
@@ -50,3 +51,257 @@ func TestProvisionerHandler_Returns400BadRequest(t *testing.T) {
 	// Assert
 	assert.Equal(t, http.StatusBadRequest, rec.Code)
 }
+
+func TestProvisionerHandler_AcceptedResponseLinksToStatus(t *testing.T) {
+	handler := NewResourceHandler(&mocks.FakeResourceService{})
+
+	body, _ := json.Marshal(model.Resource{
+		ID:            "vm-001",
+		ResourceType:  "VM",
+		CloudProvider: "AWS",
+		Specification: "t2.micro",
+		Status:        "pending",
+		RequestedBy:   "rafael",
+	})
+	req := httptest.NewRequest(http.MethodPost, "/provision", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.Provision(rec, req)
+
+	var resp APIResponse[AcceptedResponse]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "/v1/resources/vm-001", resp.Data.TrackURL)
+}
+
+func TestGetResourceHandler_Returns200WithRecord(t *testing.T) {
+	mockService := &mocks.FakeResourceService{
+		RecordToReturn: &model.ResourceRecord{
+			Resource: model.Resource{ID: "vm-001", ResourceType: "VM", Status: "in_progress"},
+		},
+	}
+	router := NewRouter(NewResourceHandler(mockService), NewHealthHandler(), nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resources/vm-001", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "vm-001", mockService.LastRequestedID)
+
+	var resp APIResponse[model.ResourceRecord]
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "in_progress", resp.Data.Status)
+}
+
+func TestGetResourceHandler_Returns404WhenMissing(t *testing.T) {
+	mockService := &mocks.FakeResourceService{
+		GetErrToReturn: domainerrors.NotFound("resource", "vm-404"),
+	}
+	handler := NewResourceHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resources/vm-404", nil)
+	req.SetPathValue("id", "vm-404")
+	rec := httptest.NewRecorder()
+
+	handler.GetResource(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Contains(t, rec.Body.String(), ErrCodeNotFound)
+}
+
+func TestGetResourceHandler_Returns500OnServiceError(t *testing.T) {
+	handler := NewResourceHandler(&mocks.FakeResourceService{GetErrToReturn: assert.AnError})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resources/vm-001", nil)
+	req.SetPathValue("id", "vm-001")
+	rec := httptest.NewRecorder()
+
+	handler.GetResource(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestListResourcesHandler_Returns200WithPagination(t *testing.T) {
+	mockService := &mocks.FakeResourceService{
+		PageToReturn: &model.ResourcePage{
+			Records:    []model.ResourceRecord{{Resource: model.Resource{ID: "vm-002"}}, {Resource: model.Resource{ID: "vm-001"}}},
+			TotalItems: 5,
+			NextCursor: "next-token",
+		},
+	}
+	router := NewRouter(NewResourceHandler(mockService), NewHealthHandler(), nil, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resources?cloud_provider=AWS&status=pending&limit=2", nil)
+	rec := httptest.NewRecorder()
+
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, model.ResourceFilter{CloudProvider: "AWS", Status: "pending"}, mockService.LastFilter)
+	assert.Equal(t, 2, mockService.LastLimit)
+
+	var resp PaginatedResponse[model.ResourceRecord]
+	assert.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+	assert.Len(t, resp.Data, 2)
+	assert.Equal(t, 5, resp.Pagination.TotalItems)
+	assert.Equal(t, 3, resp.Pagination.TotalPages)
+	assert.Equal(t, "next-token", resp.Pagination.NextCursor)
+}
+
+func TestListResourcesHandler_LeavesDefaultLimitToService(t *testing.T) {
+	mockService := &mocks.FakeResourceService{PageToReturn: &model.ResourcePage{}}
+	handler := NewResourceHandler(mockService)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resources", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListResources(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Zero(t, mockService.LastLimit, "the service applies DefaultPageSize")
+}
+
+func TestListResourcesHandler_Returns400OnInvalidQuery(t *testing.T) {
+	for _, query := range []string{"limit=abc", "limit=-1", "limit=500", "status=bogus", "cloud_provider=IBM"} {
+		t.Run(query, func(t *testing.T) {
+			handler := NewResourceHandler(&mocks.FakeResourceService{})
+
+			req := httptest.NewRequest(http.MethodGet, "/v1/resources?"+query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ListResources(rec, req)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+		})
+	}
+}
+
+func TestListResourcesHandler_Returns400OnInvalidCursor(t *testing.T) {
+	handler := NewResourceHandler(&mocks.FakeResourceService{
+		ListErrToReturn: domainerrors.InvalidInput("invalid pagination cursor"),
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resources?cursor=garbage", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListResources(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestListResourcesHandler_Returns500OnServiceError(t *testing.T) {
+	handler := NewResourceHandler(&mocks.FakeResourceService{ListErrToReturn: assert.AnError})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/resources", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ListResources(rec, req)
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
+
+func TestProvisionerHandler_Returns403WhenDenied(t *testing.T) {
+	mockService := &mocks.FakeResourceService{
+		ErrToReturn: domainerrors.NewDomainError(domainerrors.ErrCodeAccessDenied, "your groups (interns) may not provision VM on AWS", domainerrors.ErrForbidden),
+	}
+	handler := NewResourceHandler(mockService)
+
+	body, _ := json.Marshal(model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro",
+		Status: "pending", RequestedBy: "rafael",
+	})
+	rec := httptest.NewRecorder()
+	handler.Provision(rec, httptest.NewRequest(http.MethodPost, "/provision", bytes.NewReader(body)))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	var resp ErrorResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, ErrCodeForbidden, resp.Code)
+	assert.Equal(t, "Not permitted: your groups (interns) may not provision VM on AWS", resp.Message)
+}
+
+func TestProvisionerHandler_Returns409ForExistingID(t *testing.T) {
+	mockService := &mocks.FakeResourceService{ErrToReturn: domainerrors.AlreadyExists("resource", "vm-001")}
+	handler := NewResourceHandler(mockService)
+
+	rec := httptest.NewRecorder()
+	handler.Provision(rec, httptest.NewRequest(http.MethodPost, "/provision", bytes.NewReader(validResourceBody())))
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	var resp ErrorResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, ErrCodeConflict, resp.Code)
+	assert.Equal(t, "resource with id 'vm-001' already exists", resp.Message)
+}
+
+func validResourceBody() []byte {
+	body, _ := json.Marshal(model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "m5.4xlarge",
+		Status: "pending", RequestedBy: "rafael", Region: "us-east-1",
+	})
+	return body
+}
+
+func TestProvisionerHandler_Returns422WithViolations(t *testing.T) {
+	violations := []model.PolicyViolation{
+		{Rule: "vm-instance-families", Field: "specification", Message: `specification "m5.4xlarge" is not allowed`, Value: "m5.4xlarge"},
+		{Rule: "cost-allocation", Field: "tags.owner", Message: `tag "owner" is required`},
+	}
+	mockService := &mocks.FakeResourceService{
+		ErrToReturn: domainerrors.NewDomainError(domainerrors.ErrCodePolicyViolation, "request violates provisioning policy", domainerrors.ErrPolicyViolation).
+			WithDetail("violations", violations),
+	}
+	handler := NewResourceHandler(mockService)
+
+	rec := httptest.NewRecorder()
+	handler.Provision(rec, httptest.NewRequest(http.MethodPost, "/provision", bytes.NewReader(validResourceBody())))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	var resp ErrorResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.Equal(t, ErrCodePolicyViolation, resp.Code)
+	assert.Equal(t, []ValidationError{
+		{Field: "specification", Message: `specification "m5.4xlarge" is not allowed`, Value: "m5.4xlarge", Rule: "vm-instance-families"},
+		{Field: "tags.owner", Message: `tag "owner" is required`, Rule: "cost-allocation"},
+	}, resp.Details)
+}
+
+func TestValidateProvision_ReportsViolations(t *testing.T) {
+	mockService := &mocks.FakeResourceService{
+		ViolationsToReturn: []model.PolicyViolation{{Rule: "approved-regions", Field: "region", Message: "region not allowed", Value: "us-east-1"}},
+	}
+	router := NewRouterWithConfig(NewResourceHandler(mockService), nil, nil, nil, DefaultRouterConfig())
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/provision:validate", bytes.NewReader(validResourceBody())))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp APIResponse[ProvisionValidationResponse]
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+	assert.False(t, resp.Data.Valid)
+	assert.Equal(t, []ValidationError{{Field: "region", Message: "region not allowed", Value: "us-east-1", Rule: "approved-regions"}}, resp.Data.Violations)
+	assert.Equal(t, 0, mockService.TimesCalled, "a dry run never submits")
+	assert.Equal(t, "us-east-1", mockService.LastValidated.Region)
+}
+
+func TestValidateProvision_ValidRequest(t *testing.T) {
+	handler := NewResourceHandler(&mocks.FakeResourceService{})
+
+	rec := httptest.NewRecorder()
+	handler.ValidateProvision(rec, httptest.NewRequest(http.MethodPost, "/provision:validate", bytes.NewReader(validResourceBody())))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"valid":true,"violations":[]`)
+}
+
+func TestValidateProvision_AccessDenialIs403(t *testing.T) {
+	handler := NewResourceHandler(&mocks.FakeResourceService{
+		ValidateErrToReturn: domainerrors.NewDomainError(domainerrors.ErrCodeAccessDenied, "denied", domainerrors.ErrForbidden),
+	})
+
+	rec := httptest.NewRecorder()
+	handler.ValidateProvision(rec, httptest.NewRequest(http.MethodPost, "/provision:validate", bytes.NewReader(validResourceBody())))
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+}
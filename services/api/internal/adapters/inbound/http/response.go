@@ -96,3 +96,10 @@ type AcceptedResponse struct {
 	Status    string `json:"status"`
 	TrackURL  string `json:"trackUrl,omitempty"`
 }
+
+// ProvisionValidationResponse is the result of a dry-run provisioning request.
+// Valid requests would be accepted by POST /provision as submitted.
+type ProvisionValidationResponse struct {
+	Valid      bool              `json:"valid"`
+	Violations []ValidationError `json:"violations"`
+}
@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
 )
@@ -29,6 +31,34 @@ type RouterConfig struct {
 	// IdempotencyTTL controls how long stored responses are replayable.
 	IdempotencyTTL time.Duration
 
+	// TokenVerifier authenticates callers of the provisioning, resource and
+	// token routes, each of which also requires its scope from service
+	// clients and API tokens. If nil, the routes are unauthenticated and
+	// requested_by is taken from the body — useful for tests and for local
+	// mode without an identity provider.
+	TokenVerifier outbound.TokenVerifier
+
+	// APITokenHandler serves personal API token management at /v1/tokens. The
+	// routes are registered only when it and TokenVerifier are both set, since
+	// tokens belong to an authenticated user.
+	APITokenHandler *APITokenHandler
+
+	// AuditService records every call to a mutating route in the audit log
+	// and, with TokenVerifier set, serves the log to platform admins at
+	// /v1/audit. If nil, nothing is audited.
+	AuditService inbound.AuditService
+
+	// RateLimiter and RateLimits limit how often each caller may call the
+	// API and auth routes (health, metrics and docs are never limited). If
+	// RateLimiter is nil, nothing is limited.
+	RateLimiter outbound.RateLimiter
+	RateLimits  RateLimitPolicy
+
+	// JWKSHandler publishes the signing keys of the local identity provider at
+	// GET /.well-known/jwks.json. If nil, the route is not registered — it is
+	// only set when the API issues its own tokens.
+	JWKSHandler http.Handler
+
 	// MetricsHandler serves the Prometheus scrape endpoint at GET /metrics. If nil,
 	// the route is not registered — useful for tests that don't exercise telemetry.
 	MetricsHandler http.Handler
@@ -53,21 +83,120 @@ func NewRouter(resourceHandler *ResourceHandler, healthHandler *HealthHandler, a
 func NewRouterWithConfig(resourceHandler *ResourceHandler, healthHandler *HealthHandler, authHandler *AuthHandler, swaggerHandler *SwaggerHandler, config RouterConfig) http.Handler {
 	mux := http.NewServeMux()
 
+	// A nil logger (e.g. in tests) degrades to a no-op rather than panicking.
+	log := config.Logger
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+
 	// =============================================================================
 	// API v1 Routes
 	// All API endpoints use path-based versioning for backward compatibility
 	// =============================================================================
 
-	// POST /v1/provision is wrapped in the idempotency middleware so retries are deduped.
-	provisionHandler := http.HandlerFunc(resourceHandler.Provision)
+	// limited applies the route's rate limit.
+	limited := func(h http.Handler) http.Handler {
+		if config.RateLimiter == nil {
+			return h
+		}
+		return RateLimitMiddleware(config.RateLimiter, config.RateLimits, log)(h)
+	}
+
+	// verified wraps h in the auth middleware, behind the client IP limit so
+	// requests are limited before their credentials are checked, and with
+	// the per-principal limit inside it so callers are limited by principal.
+	verified := func(h http.Handler) http.Handler {
+		h = AuthMiddleware(config.TokenVerifier, log)(limited(h))
+		if config.RateLimiter == nil {
+			return h
+		}
+		return ClientIPRateLimitMiddleware(config.RateLimiter, config.RateLimits, log)(h)
+	}
+
+	// authenticated wraps a route in the auth middleware and, for service
+	// clients and API tokens, requires one of scopes. Without a verifier
+	// routes are left open.
+	authenticated := func(h http.Handler, scopes ...string) http.Handler {
+		if config.TokenVerifier == nil {
+			return limited(h)
+		}
+		if len(scopes) > 0 {
+			h = ScopeMiddleware(scopes...)(h)
+		}
+		return verified(h)
+	}
+
+	// audited is authenticated for mutating routes, recording each call in
+	// the audit log: inside the auth middleware so entries name the caller,
+	// and outside the scope check so calls it denies are recorded too. Calls
+	// refused by the rate limit never reach the audit log, so a throttled
+	// caller cannot flood it.
+	audited := func(h http.Handler, action string, target AuditTarget, scopes ...string) http.Handler {
+		if config.TokenVerifier != nil && len(scopes) > 0 {
+			h = ScopeMiddleware(scopes...)(h)
+		}
+		if config.AuditService != nil {
+			h = AuditMiddleware(config.AuditService, action, target)(h)
+		}
+		if config.TokenVerifier == nil {
+			return limited(h)
+		}
+		return verified(h)
+	}
+
+	// auditedAnonymously records each call to an unauthenticated auth route,
+	// with the body fields in redact blanked before it is hashed. The rate
+	// limit sits outside, as for audited.
+	auditedAnonymously := func(h http.Handler, action string, target AuditTarget, redact ...string) http.Handler {
+		if config.AuditService != nil {
+			h = AuditMiddleware(config.AuditService, action, target, redact...)(h)
+		}
+		return limited(h)
+	}
+
+	// POST /v1/provision is wrapped in the idempotency middleware so retries are
+	// deduped, and in the auth middleware outside it so unauthenticated requests
+	// never reserve an idempotency key. Replays are audited like first calls.
+	var provisionHandler http.Handler = http.HandlerFunc(resourceHandler.Provision)
 	if config.IdempotencyStore != nil {
 		ttl := config.IdempotencyTTL
 		if ttl == 0 {
 			ttl = 24 * time.Hour
 		}
-		mux.Handle("POST "+APIVersionPrefix+"/provision", IdempotencyMiddleware(config.IdempotencyStore, ttl)(provisionHandler))
-	} else {
-		mux.Handle("POST "+APIVersionPrefix+"/provision", provisionHandler)
+		provisionHandler = IdempotencyMiddleware(config.IdempotencyStore, ttl)(provisionHandler)
+	}
+	mux.Handle("POST "+APIVersionPrefix+"/provision", audited(
+		provisionHandler, model.AuditActionProvision, auditBodyID, model.ScopeProvisionWrite))
+
+	// POST /v1/provision:validate dry-runs a provisioning request. It changes
+	// nothing, so it is authenticated (principals drive authorization and
+	// guardrails) but not idempotency-keyed; whoever may provision may also
+	// dry-run.
+	mux.Handle("POST "+APIVersionPrefix+"/provision:validate", authenticated(
+		http.HandlerFunc(resourceHandler.ValidateProvision), model.ScopeProvisionValidate, model.ScopeProvisionWrite))
+
+	// Handle GET /v1/resources
+	mux.Handle("GET "+APIVersionPrefix+"/resources", authenticated(
+		http.HandlerFunc(resourceHandler.ListResources), model.ScopeResourcesRead))
+
+	// Handle GET /v1/resources/{id}
+	mux.Handle("GET "+APIVersionPrefix+"/resources/{id}", authenticated(
+		http.HandlerFunc(resourceHandler.GetResource), model.ScopeResourcesRead))
+
+	// Handle /v1/tokens: a signed-in user's personal API tokens
+	if config.APITokenHandler != nil && config.TokenVerifier != nil {
+		mux.Handle("POST "+APIVersionPrefix+"/tokens", audited(
+			http.HandlerFunc(config.APITokenHandler.Issue), model.AuditActionIssueAPIToken, nil))
+		mux.Handle("GET "+APIVersionPrefix+"/tokens", authenticated(http.HandlerFunc(config.APITokenHandler.List)))
+		mux.Handle("DELETE "+APIVersionPrefix+"/tokens/{id}", audited(
+			http.HandlerFunc(config.APITokenHandler.Revoke), model.AuditActionRevokeAPIToken, auditPathID))
+	}
+
+	// Handle /v1/audit: the audit log, for platform admins
+	if config.AuditService != nil && config.TokenVerifier != nil {
+		auditHandler := NewAuditHandler(config.AuditService)
+		mux.Handle("GET "+APIVersionPrefix+"/audit", authenticated(http.HandlerFunc(auditHandler.List)))
+		mux.Handle("GET "+APIVersionPrefix+"/audit/verify", authenticated(http.HandlerFunc(auditHandler.Verify)))
 	}
 
 	// Handle GET /v1/health
@@ -79,14 +208,48 @@ func NewRouterWithConfig(resourceHandler *ResourceHandler, healthHandler *Health
 		mux.Handle("GET /metrics", config.MetricsHandler)
 	}
 
+	// Serve the local identity provider's token signing keys at the standard
+	// unversioned path, next to the issuer, like Cognito's
+	if config.JWKSHandler != nil {
+		mux.Handle("GET /.well-known/jwks.json", config.JWKSHandler)
+	}
+
 	// Handle POST /v1/auth/signup
-	mux.HandleFunc("POST "+APIVersionPrefix+"/auth/signup", authHandler.SignUp)
+	mux.Handle("POST "+APIVersionPrefix+"/auth/signup", auditedAnonymously(
+		http.HandlerFunc(authHandler.SignUp), model.AuditActionSignUp, auditBodyField("email"), "password"))
 
 	// Handle POST /v1/auth/signin
-	mux.HandleFunc("POST "+APIVersionPrefix+"/auth/signin", authHandler.SignIn)
+	mux.Handle("POST "+APIVersionPrefix+"/auth/signin", auditedAnonymously(
+		http.HandlerFunc(authHandler.SignIn), model.AuditActionSignIn, auditBodyField("email"), "password"))
 
 	// Handle POST /v1/auth/confirm
-	mux.HandleFunc("POST "+APIVersionPrefix+"/auth/confirm", authHandler.ConfirmSignUp)
+	mux.Handle("POST "+APIVersionPrefix+"/auth/confirm", auditedAnonymously(
+		http.HandlerFunc(authHandler.ConfirmSignUp), model.AuditActionConfirmSignUp, auditBodyField("email"), "confirmation_code"))
+
+	// Handle POST /v1/auth/refresh
+	mux.Handle("POST "+APIVersionPrefix+"/auth/refresh", auditedAnonymously(
+		http.HandlerFunc(authHandler.RefreshTokens), model.AuditActionRefreshTokens, nil, "refresh_token"))
+
+	// Handle POST /v1/auth/token (client credentials, for service clients)
+	mux.Handle("POST "+APIVersionPrefix+"/auth/token", auditedAnonymously(
+		http.HandlerFunc(authHandler.ClientCredentials), model.AuditActionClientCredentials, auditBodyField("client_id"), "client_secret"))
+
+	// Handle POST /v1/auth/signout (identified by the bearer access token)
+	mux.Handle("POST "+APIVersionPrefix+"/auth/signout", audited(
+		http.HandlerFunc(authHandler.SignOut), model.AuditActionSignOut, nil))
+
+	// Handle POST /v1/auth/forgot-password
+	mux.Handle("POST "+APIVersionPrefix+"/auth/forgot-password", auditedAnonymously(
+		http.HandlerFunc(authHandler.ForgotPassword), model.AuditActionForgotPassword, auditBodyField("email")))
+
+	// Handle POST /v1/auth/confirm-forgot-password
+	mux.Handle("POST "+APIVersionPrefix+"/auth/confirm-forgot-password", auditedAnonymously(
+		http.HandlerFunc(authHandler.ConfirmForgotPassword), model.AuditActionConfirmForgotPassword, auditBodyField("email"),
+		"confirmation_code", "new_password"))
+
+	// Handle POST /v1/auth/resend-confirmation
+	mux.Handle("POST "+APIVersionPrefix+"/auth/resend-confirmation", auditedAnonymously(
+		http.HandlerFunc(authHandler.ResendConfirmation), model.AuditActionResendConfirmation, auditBodyField("email")))
 
 	// Handle Swagger UI - must be registered before other /swagger routes
 	// The httpSwagger.Handler expects to receive requests with /swagger/ prefix in RequestURI
@@ -94,12 +257,6 @@ func NewRouterWithConfig(resourceHandler *ResourceHandler, healthHandler *Health
 	mux.Handle("GET "+APIVersionPrefix+"/swagger", swaggerUI)
 	mux.Handle("GET "+APIVersionPrefix+"/swagger/", swaggerUI)
 
-	// A nil logger (e.g. in tests) degrades to a no-op rather than panicking.
-	log := config.Logger
-	if log == nil {
-		log = logger.NopLogger{}
-	}
-
 	// =============================================================================
 	// Middleware Chain
 	// Applied in order (outermost first):
@@ -2,6 +2,7 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -14,11 +15,13 @@ import (
 // Standardized error response structure for validation failures (DVA-C02 best practice)
 // =============================================================================
 
-// ValidationError represents a single field validation error
+// ValidationError represents a single field validation error. Rule is set
+// when the error is a provisioning policy violation.
 type ValidationError struct {
 	Field   string `json:"field"`
 	Message string `json:"message"`
 	Value   any    `json:"value,omitempty"`
+	Rule    string `json:"rule,omitempty"`
 }
 
 // ErrorResponse represents a standardized API error response
@@ -30,14 +33,22 @@ type ErrorResponse struct {
 	Details   []ValidationError `json:"details,omitempty"`
 }
 
+// maxRequestBodyBytes caps the request bodies the API reads, in the
+// handlers and in the middleware that buffers them.
+const maxRequestBodyBytes = 1 << 20
+
 // Common error codes
 const (
 	ErrCodeValidation             = "VALIDATION_ERROR"
 	ErrCodeInvalidJSON            = "INVALID_JSON"
+	ErrCodeRequestTooLarge        = "REQUEST_TOO_LARGE"
 	ErrCodeMissingHeader          = "MISSING_HEADER"
 	ErrCodeInternalError          = "INTERNAL_ERROR"
 	ErrCodeUnauthorized           = "UNAUTHORIZED"
+	ErrCodeForbidden              = "FORBIDDEN"
+	ErrCodePolicyViolation        = "POLICY_VIOLATION"
 	ErrCodeNotFound               = "NOT_FOUND"
+	ErrCodeConflict               = "CONFLICT"
 	ErrCodeRateLimited            = "RATE_LIMITED"
 	ErrCodeIdempotencyKeyInvalid  = "IDEMPOTENCY_KEY_INVALID"
 	ErrCodeIdempotencyKeyMismatch = "IDEMPOTENCY_KEY_MISMATCH"
@@ -166,7 +177,11 @@ func RespondWithJSON(w http.ResponseWriter, statusCode int, payload any) {
 func DecodeAndValidate[T any](w http.ResponseWriter, r *http.Request, requestID string) *T {
 	var payload T
 
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
 	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		if respondIfTooLarge(w, err, requestID) {
+			return nil
+		}
 		RespondWithError(w, http.StatusBadRequest, ErrorResponse{
 			Code:      ErrCodeInvalidJSON,
 			Message:   "Invalid JSON in request body",
@@ -183,6 +198,21 @@ func DecodeAndValidate[T any](w http.ResponseWriter, r *http.Request, requestID
 	return &payload
 }
 
+// respondIfTooLarge answers a 413 when err is from reading a body past
+// maxRequestBodyBytes, and reports whether it did.
+func respondIfTooLarge(w http.ResponseWriter, err error, requestID string) bool {
+	var tooLarge *http.MaxBytesError
+	if !errors.As(err, &tooLarge) {
+		return false
+	}
+	RespondWithError(w, http.StatusRequestEntityTooLarge, ErrorResponse{
+		Code:      ErrCodeRequestTooLarge,
+		Message:   fmt.Sprintf("Request body exceeds %d bytes", maxRequestBodyBytes),
+		RequestID: requestID,
+	})
+	return true
+}
+
 // ValidateRequiredHeader checks if a required header is present
 func ValidateRequiredHeader(w http.ResponseWriter, r *http.Request, headerName, requestID string) bool {
 	if r.Header.Get(headerName) == "" {
@@ -0,0 +1,122 @@
+// Package accesspolicy loads the provisioning access policy from a versioned
+// YAML file:
+//
+//	version: 1
+//	rules:
+//	  - name: team-payments
+//	    groups: [team-payments]
+//	    resource_types: [VM, S3]
+//	    cloud_providers: [AWS]
+//	  - name: ci-deployer
+//	    client_ids: [ci-bot]
+//	    resource_types: [VM]
+//	    cloud_providers: [AWS]
+//
+// Groups are identity provider (Cognito) groups; "*" matches any value.
+// Service clients have no groups, so rules name them by client_ids or by the
+// scopes their tokens carry.
+package accesspolicy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/valueobjects"
+)
+
+// SupportedVersions are the policy file versions this build understands. A
+// file with any other version is rejected rather than half-understood.
+var SupportedVersions = []int{1}
+
+type fileV1 struct {
+	Version int      `yaml:"version"`
+	Rules   []ruleV1 `yaml:"rules"`
+}
+
+type ruleV1 struct {
+	Name           string   `yaml:"name"`
+	Groups         []string `yaml:"groups"`
+	ClientIDs      []string `yaml:"client_ids"`
+	Scopes         []string `yaml:"scopes"`
+	ResourceTypes  []string `yaml:"resource_types"`
+	CloudProviders []string `yaml:"cloud_providers"`
+}
+
+// LoadFile reads and validates the policy at path.
+func LoadFile(path string) (model.AccessPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return model.AccessPolicy{}, fmt.Errorf("read access policy: %w", err)
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return model.AccessPolicy{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return policy, nil
+}
+
+// Parse decodes and validates a policy document. Unknown fields, unsupported
+// versions and unknown resource types or cloud providers are errors, so a
+// typo fails at startup instead of silently denying requests.
+func Parse(data []byte) (model.AccessPolicy, error) {
+	var header struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return model.AccessPolicy{}, fmt.Errorf("decode access policy: %w", err)
+	}
+	if !slices.Contains(SupportedVersions, header.Version) {
+		return model.AccessPolicy{}, fmt.Errorf("unsupported access policy version %d (supported: %v)", header.Version, SupportedVersions)
+	}
+
+	var file fileV1
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return model.AccessPolicy{}, fmt.Errorf("decode access policy: %w", err)
+	}
+
+	policy := model.AccessPolicy{Version: file.Version}
+	for _, r := range file.Rules {
+		if err := checkValues(r); err != nil {
+			return model.AccessPolicy{}, err
+		}
+		policy.Rules = append(policy.Rules, model.AccessRule{
+			Name:           r.Name,
+			Groups:         r.Groups,
+			ClientIDs:      r.ClientIDs,
+			Scopes:         r.Scopes,
+			ResourceTypes:  r.ResourceTypes,
+			CloudProviders: r.CloudProviders,
+		})
+	}
+	if err := policy.Validate(); err != nil {
+		return model.AccessPolicy{}, fmt.Errorf("invalid access policy: %w", err)
+	}
+	return policy, nil
+}
+
+func checkValues(r ruleV1) error {
+	for _, t := range r.ResourceTypes {
+		if t == model.AnyValue {
+			continue
+		}
+		if _, err := valueobjects.NewResourceType(t); err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+	for _, p := range r.CloudProviders {
+		if p == model.AnyValue {
+			continue
+		}
+		if _, err := valueobjects.NewCloudProvider(p); err != nil {
+			return fmt.Errorf("rule %q: %w", r.Name, err)
+		}
+	}
+	return nil
+}
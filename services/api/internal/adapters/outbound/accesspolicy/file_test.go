@@ -0,0 +1,85 @@
+package accesspolicy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+const samplePolicy = `
+version: 1
+rules:
+  - name: platform-admins
+    groups: [platform-admins]
+    resource_types: ["*"]
+    cloud_providers: ["*"]
+  - name: team-payments
+    groups: [team-payments]
+    resource_types: [VM, S3]
+    cloud_providers: [AWS, AZURE]
+  - name: ci-deployer
+    client_ids: [ci-bot]
+    scopes: [provision:write]
+    resource_types: [VM]
+    cloud_providers: [AWS]
+`
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "access-policy.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(samplePolicy), 0o600))
+
+	policy, err := LoadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, policy.Version)
+	require.Len(t, policy.Rules, 3)
+	assert.Equal(t, model.AccessRule{
+		Name:           "team-payments",
+		Groups:         []string{"team-payments"},
+		ResourceTypes:  []string{"VM", "S3"},
+		CloudProviders: []string{"AWS", "AZURE"},
+	}, policy.Rules[1])
+
+	team := model.Principal{Groups: []string{"team-payments"}}
+	assert.NoError(t, policy.Authorize(team, model.Resource{ResourceType: "S3", CloudProvider: "Azure"}))
+	assert.ErrorIs(t, policy.Authorize(team, model.Resource{ResourceType: "S3", CloudProvider: "GCP"}), domainerrors.ErrForbidden)
+
+	assert.Equal(t, []string{"ci-bot"}, policy.Rules[2].ClientIDs)
+	assert.Equal(t, []string{"provision:write"}, policy.Rules[2].Scopes)
+	bot := model.Principal{Subject: "ci-bot", ClientID: "ci-bot", Machine: true}
+	assert.NoError(t, policy.Authorize(bot, model.Resource{ResourceType: "VM", CloudProvider: "AWS"}))
+}
+
+func TestLoadFile_Missing(t *testing.T) {
+	_, err := LoadFile(filepath.Join(t.TempDir(), "nope.yaml"))
+	assert.Error(t, err)
+}
+
+func TestParse_Rejects(t *testing.T) {
+	cases := map[string]string{
+		"no version":       "rules: []",
+		"future version":   "version: 2\nrules: []",
+		"unknown field":    "version: 1\nrules:\n  - name: r\n    group: [a]\n    resource_types: [VM]\n    cloud_providers: [AWS]",
+		"unknown type":     "version: 1\nrules:\n  - name: r\n    groups: [a]\n    resource_types: [Mainframe]\n    cloud_providers: [AWS]",
+		"unknown provider": "version: 1\nrules:\n  - name: r\n    groups: [a]\n    resource_types: [VM]\n    cloud_providers: [Oracle]",
+		"missing groups":   "version: 1\nrules:\n  - name: r\n    resource_types: [VM]\n    cloud_providers: [AWS]",
+		"not yaml":         "version: [",
+	}
+	for name, doc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(doc))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadFile_Example(t *testing.T) {
+	_, err := LoadFile("../../../../policies/access-policy.example.yaml")
+	assert.NoError(t, err)
+}
@@ -0,0 +1,105 @@
+// Package apitoken provides storage adapters for the APITokenStore port.
+package apitoken
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// MemoryStore implements outbound.APITokenStore in process memory. It backs
+// local mode and tests; tokens do not survive a restart and are not shared
+// between replicas.
+type MemoryStore struct {
+	mu     sync.RWMutex
+	tokens map[string]model.APIToken
+}
+
+var _ outbound.APITokenStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty in-memory token store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{tokens: make(map[string]model.APIToken)}
+}
+
+// Create stores a new token.
+func (s *MemoryStore) Create(_ context.Context, token model.APIToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[token.ID] = clone(token)
+	return nil
+}
+
+// GetByHash returns a copy of the token with hash, or
+// outbound.ErrAPITokenNotFound.
+func (s *MemoryStore) GetByHash(_ context.Context, hash string) (*model.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, token := range s.tokens {
+		if token.Hash == hash {
+			t := clone(token)
+			return &t, nil
+		}
+	}
+	return nil, outbound.ErrAPITokenNotFound
+}
+
+// ListByOwner returns copies of the owner's tokens, newest first.
+func (s *MemoryStore) ListByOwner(_ context.Context, owner string) ([]model.APIToken, error) {
+	s.mu.RLock()
+	tokens := make([]model.APIToken, 0)
+	for _, token := range s.tokens {
+		if token.Owner == owner {
+			tokens = append(tokens, clone(token))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID > tokens[j].ID
+	})
+	return tokens, nil
+}
+
+// Revoke marks the owner's token revoked, keeping an earlier revocation.
+func (s *MemoryStore) Revoke(_ context.Context, owner, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[id]
+	if !ok || token.Owner != owner {
+		return outbound.ErrAPITokenNotFound
+	}
+	if token.RevokedAt == nil {
+		token.RevokedAt = &at
+		s.tokens[id] = token
+	}
+	return nil
+}
+
+// TouchLastUsed sets the token's last-used time.
+func (s *MemoryStore) TouchLastUsed(_ context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, ok := s.tokens[id]
+	if !ok {
+		return outbound.ErrAPITokenNotFound
+	}
+	token.LastUsedAt = &at
+	s.tokens[id] = token
+	return nil
+}
+
+// clone copies the token's slices so callers cannot mutate stored state.
+func clone(token model.APIToken) model.APIToken {
+	token.Groups = slices.Clone(token.Groups)
+	token.Scopes = slices.Clone(token.Scopes)
+	return token
+}
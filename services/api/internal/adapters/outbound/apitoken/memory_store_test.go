@@ -0,0 +1,72 @@
+package apitoken
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+func testToken(id, owner string, created time.Time) model.APIToken {
+	return model.APIToken{
+		ID: id, Name: "ci", Owner: owner, Scopes: []string{model.ScopeProvisionWrite},
+		Hash: "hash-" + id, Hint: "…abcd", CreatedAt: created, ExpiresAt: created.Add(time.Hour),
+	}
+}
+
+func TestMemoryStore_CreateGetList(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, store.Create(ctx, testToken("a", "user-1", base)))
+	require.NoError(t, store.Create(ctx, testToken("b", "user-1", base.Add(time.Minute))))
+	require.NoError(t, store.Create(ctx, testToken("c", "user-2", base)))
+
+	got, err := store.GetByHash(ctx, "hash-b")
+	require.NoError(t, err)
+	assert.Equal(t, "b", got.ID)
+
+	_, err = store.GetByHash(ctx, "missing")
+	assert.ErrorIs(t, err, outbound.ErrAPITokenNotFound)
+
+	tokens, err := store.ListByOwner(ctx, "user-1")
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "b", tokens[0].ID, "newest first")
+}
+
+func TestMemoryStore_RevokeKeepsFirstTimeAndChecksOwner(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Create(ctx, testToken("a", "user-1", base)))
+
+	assert.ErrorIs(t, store.Revoke(ctx, "user-2", "a", base), outbound.ErrAPITokenNotFound)
+	require.NoError(t, store.Revoke(ctx, "user-1", "a", base.Add(time.Minute)))
+	require.NoError(t, store.Revoke(ctx, "user-1", "a", base.Add(time.Hour)))
+
+	got, err := store.GetByHash(ctx, "hash-a")
+	require.NoError(t, err)
+	require.NotNil(t, got.RevokedAt)
+	assert.Equal(t, base.Add(time.Minute), *got.RevokedAt)
+}
+
+func TestMemoryStore_TouchLastUsed(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	base := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, store.Create(ctx, testToken("a", "user-1", base)))
+
+	require.NoError(t, store.TouchLastUsed(ctx, "a", base.Add(time.Second)))
+	assert.ErrorIs(t, store.TouchLastUsed(ctx, "missing", base), outbound.ErrAPITokenNotFound)
+
+	got, err := store.GetByHash(ctx, "hash-a")
+	require.NoError(t, err)
+	assert.Equal(t, base.Add(time.Second), *got.LastUsedAt)
+}
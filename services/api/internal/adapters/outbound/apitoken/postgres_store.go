@@ -0,0 +1,161 @@
+package apitoken
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// PostgresStore implements outbound.APITokenStore on the api_tokens table
+// (db/init.sql), so every replica accepts the same tokens.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+var _ outbound.APITokenStore = (*PostgresStore)(nil)
+
+// NewPostgresStore wraps an open database handle. The caller owns Close().
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const tokenColumns = `id, name, owner, owner_username, groups, scopes, token_hash, hint, created_at, expires_at, last_used_at, revoked_at`
+
+// Create inserts a new token.
+func (s *PostgresStore) Create(ctx context.Context, t model.APIToken) error {
+	groups, err := encodeList(t.Groups)
+	if err != nil {
+		return fmt.Errorf("encode groups of api token %s: %w", t.ID, err)
+	}
+	scopes, err := encodeList(t.Scopes)
+	if err != nil {
+		return fmt.Errorf("encode scopes of api token %s: %w", t.ID, err)
+	}
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO api_tokens (`+tokenColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, NULL, NULL)`,
+		t.ID, t.Name, t.Owner, t.OwnerUsername, groups, scopes, t.Hash, t.Hint, t.CreatedAt, t.ExpiresAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert api token %s: %w", t.ID, err)
+	}
+	return nil
+}
+
+// GetByHash returns the token with hash, or outbound.ErrAPITokenNotFound.
+func (s *PostgresStore) GetByHash(ctx context.Context, hash string) (*model.APIToken, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+tokenColumns+` FROM api_tokens WHERE token_hash = $1`, hash)
+	token, err := scanToken(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, outbound.ErrAPITokenNotFound
+		}
+		return nil, fmt.Errorf("select api token: %w", err)
+	}
+	return &token, nil
+}
+
+// ListByOwner returns the owner's tokens, newest first.
+func (s *PostgresStore) ListByOwner(ctx context.Context, owner string) ([]model.APIToken, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT `+tokenColumns+` FROM api_tokens
+		WHERE owner = $1
+		ORDER BY created_at DESC, id DESC`, owner)
+	if err != nil {
+		return nil, fmt.Errorf("list api tokens: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	tokens := make([]model.APIToken, 0)
+	for rows.Next() {
+		token, err := scanToken(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan api token: %w", err)
+		}
+		tokens = append(tokens, token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list api tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// Revoke marks the owner's token revoked, keeping an earlier revocation.
+func (s *PostgresStore) Revoke(ctx context.Context, owner, id string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `
+		UPDATE api_tokens SET revoked_at = COALESCE(revoked_at, $3)
+		WHERE id = $1 AND owner = $2`, id, owner, at)
+	if err != nil {
+		return fmt.Errorf("revoke api token %s: %w", id, err)
+	}
+	return expectOneRow(res, id)
+}
+
+// TouchLastUsed sets the token's last-used time.
+func (s *PostgresStore) TouchLastUsed(ctx context.Context, id string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE api_tokens SET last_used_at = $2 WHERE id = $1`, id, at)
+	if err != nil {
+		return fmt.Errorf("touch api token %s: %w", id, err)
+	}
+	return expectOneRow(res, id)
+}
+
+func expectOneRow(res sql.Result, id string) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update api token %s: %w", id, err)
+	}
+	if n == 0 {
+		return outbound.ErrAPITokenNotFound
+	}
+	return nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanToken(row rowScanner) (model.APIToken, error) {
+	var (
+		t                 model.APIToken
+		groups, scopes    []byte
+		lastUsed, revoked sql.NullTime
+	)
+	if err := row.Scan(&t.ID, &t.Name, &t.Owner, &t.OwnerUsername, &groups, &scopes, &t.Hash, &t.Hint,
+		&t.CreatedAt, &t.ExpiresAt, &lastUsed, &revoked); err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(groups, &t.Groups); err != nil {
+		return t, fmt.Errorf("decode groups of api token %s: %w", t.ID, err)
+	}
+	if err := json.Unmarshal(scopes, &t.Scopes); err != nil {
+		return t, fmt.Errorf("decode scopes of api token %s: %w", t.ID, err)
+	}
+	t.CreatedAt = t.CreatedAt.UTC()
+	t.ExpiresAt = t.ExpiresAt.UTC()
+	if lastUsed.Valid {
+		at := lastUsed.Time.UTC()
+		t.LastUsedAt = &at
+	}
+	if revoked.Valid {
+		at := revoked.Time.UTC()
+		t.RevokedAt = &at
+	}
+	return t, nil
+}
+
+// encodeList stores a nil list as an empty JSON array.
+func encodeList(values []string) (string, error) {
+	if values == nil {
+		values = []string{}
+	}
+	b, err := json.Marshal(values)
+	return string(b), err
+}
@@ -0,0 +1,56 @@
+package apitoken
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/infrastructure"
+)
+
+// newTestPostgresStore returns a store on the test database, or skips the test
+// if not set. Set POSTGRES_TEST_DSN to a database with db/init.sql applied to
+// exercise the real implementation.
+func newTestPostgresStore(t *testing.T) *PostgresStore {
+	t.Helper()
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set; skipping Postgres integration test")
+	}
+	db, err := infrastructure.NewPostgresDB(context.Background(), infrastructure.PostgresConfig{URL: dsn, PingTimeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewPostgresStore(db)
+}
+
+func TestPostgresStore_Lifecycle(t *testing.T) {
+	store := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	owner := uuid.NewString()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	token := testToken(uuid.NewString(), owner, now)
+	token.Hash = uuid.NewString()
+	token.Groups = []string{"team-payments"}
+	require.NoError(t, store.Create(ctx, token))
+
+	got, err := store.GetByHash(ctx, token.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, token, *got)
+
+	require.NoError(t, store.TouchLastUsed(ctx, token.ID, now.Add(time.Second)))
+	require.NoError(t, store.Revoke(ctx, owner, token.ID, now.Add(time.Minute)))
+	assert.ErrorIs(t, store.Revoke(ctx, uuid.NewString(), token.ID, now), outbound.ErrAPITokenNotFound)
+
+	tokens, err := store.ListByOwner(ctx, owner)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.True(t, now.Add(time.Second).Equal(*tokens[0].LastUsedAt))
+	assert.True(t, now.Add(time.Minute).Equal(*tokens[0].RevokedAt))
+}
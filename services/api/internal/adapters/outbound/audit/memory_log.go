@@ -0,0 +1,74 @@
+// Package audit provides storage adapters for the AuditLog port.
+package audit
+
+import (
+	"context"
+	"sync"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// MemoryLog implements outbound.AuditLog in process memory. It backs local
+// mode and tests; the log does not survive a restart and is not shared
+// between replicas.
+type MemoryLog struct {
+	mu      sync.RWMutex
+	entries []model.AuditEntry
+}
+
+var _ outbound.AuditLog = (*MemoryLog)(nil)
+
+// NewMemoryLog returns an empty in-memory audit log.
+func NewMemoryLog() *MemoryLog {
+	return &MemoryLog{}
+}
+
+// Append chains entry after the newest entry and stores it.
+func (l *MemoryLog) Append(_ context.Context, entry model.AuditEntry) (model.AuditEntry, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var prev *model.AuditEntry
+	if n := len(l.entries); n > 0 {
+		prev = &l.entries[n-1]
+	}
+	entry = entry.Chain(prev)
+	l.entries = append(l.entries, entry)
+	return entry, nil
+}
+
+// Query returns matching entries, newest first.
+func (l *MemoryLog) Query(_ context.Context, q model.AuditQuery) ([]model.AuditEntry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	out := make([]model.AuditEntry, 0)
+	for i := len(l.entries) - 1; i >= 0 && (q.Limit <= 0 || len(out) < q.Limit); i-- {
+		if e := l.entries[i]; matches(q, e) {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// Range returns entries after the given sequence number, oldest first.
+func (l *MemoryLog) Range(_ context.Context, after int64, limit int) ([]model.AuditEntry, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	// Seq n lives at index n-1.
+	start := min(max(after, 0), int64(len(l.entries)))
+	end := min(start+int64(limit), int64(len(l.entries)))
+	return append([]model.AuditEntry{}, l.entries[start:end]...), nil
+}
+
+func matches(q model.AuditQuery, e model.AuditEntry) bool {
+	return (q.Actor == "" || e.Actor == q.Actor) &&
+		(q.Action == "" || e.Action == q.Action) &&
+		(q.Target == "" || e.Target == q.Target) &&
+		(q.Outcome == "" || e.Outcome == q.Outcome) &&
+		(q.Since.IsZero() || !e.Time.Before(q.Since)) &&
+		(q.Until.IsZero() || e.Time.Before(q.Until)) &&
+		(q.Before <= 0 || e.Seq < q.Before)
+}
@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+func testEntry(i int, actor string, at time.Time) model.AuditEntry {
+	return model.AuditEntry{
+		ID:      fmt.Sprintf("entry-%d", i),
+		Time:    at,
+		Actor:   actor,
+		Action:  model.AuditActionProvision,
+		Target:  fmt.Sprintf("vm-%03d", i),
+		Method:  "POST",
+		Path:    "/v1/provision",
+		Status:  202,
+		Outcome: model.AuditOutcomeSuccess,
+	}
+}
+
+// exerciseLog appends five entries by two actors and checks chaining,
+// filtering and paging.
+func exerciseLog(t *testing.T, log outbound.AuditLog, actors [2]string) {
+	t.Helper()
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Microsecond)
+
+	var appended []model.AuditEntry
+	for i := range 5 {
+		e, err := log.Append(ctx, testEntry(i, actors[i%2], start.Add(time.Duration(i)*time.Second)))
+		require.NoError(t, err)
+		appended = append(appended, e)
+	}
+	for i := 1; i < len(appended); i++ {
+		assert.Equal(t, appended[i-1].Seq+1, appended[i].Seq)
+		assert.Equal(t, appended[i-1].Hash, appended[i].PrevHash)
+	}
+
+	got, err := log.Query(ctx, model.AuditQuery{Actor: actors[0], Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, got, 3)
+	assert.Equal(t, appended[4], got[0], "newest first, stored as appended")
+
+	got, err = log.Query(ctx, model.AuditQuery{Actor: actors[0], Before: appended[4].Seq, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, appended[2].ID, got[0].ID)
+
+	got, err = log.Query(ctx, model.AuditQuery{Actor: actors[1], Since: start.Add(2 * time.Second)})
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	assert.Equal(t, appended[3].ID, got[0].ID)
+
+	ranged, err := log.Range(ctx, appended[0].Seq, 2)
+	require.NoError(t, err)
+	assert.Equal(t, appended[1:3], ranged)
+}
+
+func TestMemoryLog(t *testing.T) {
+	log := NewMemoryLog()
+	exerciseLog(t, log, [2]string{"user-1", "user-2"})
+
+	all, err := log.Range(context.Background(), 0, 100)
+	require.NoError(t, err)
+	require.Len(t, all, 5)
+	_, err = model.VerifyAuditChain(nil, all)
+	assert.NoError(t, err)
+}
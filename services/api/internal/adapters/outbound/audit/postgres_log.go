@@ -0,0 +1,154 @@
+package audit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// appendLockKey is the transaction-scoped advisory lock serializing appends
+// across replicas, so two entries never chain off the same predecessor.
+const appendLockKey = 0x61756469 // "audi"
+
+// PostgresLog implements outbound.AuditLog on the audit_log table
+// (db/init.sql), whose trigger rejects updates and deletes.
+type PostgresLog struct {
+	db *sql.DB
+}
+
+var _ outbound.AuditLog = (*PostgresLog)(nil)
+
+// NewPostgresLog wraps an open database handle. The caller owns Close().
+func NewPostgresLog(db *sql.DB) *PostgresLog {
+	return &PostgresLog{db: db}
+}
+
+const entryColumns = `seq, id, time, actor, actor_username, client_id, api_token_id, action, target, method, path,
+	request_hash, status, outcome, request_id, trace_id, prev_hash, hash`
+
+// Append chains entry after the newest entry and inserts it.
+func (l *PostgresLog) Append(ctx context.Context, entry model.AuditEntry) (model.AuditEntry, error) {
+	tx, err := l.db.BeginTx(ctx, nil)
+	if err != nil {
+		return entry, fmt.Errorf("begin audit append: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, appendLockKey); err != nil {
+		return entry, fmt.Errorf("lock audit log: %w", err)
+	}
+
+	var prev *model.AuditEntry
+	last, err := scanEntry(tx.QueryRowContext(ctx, `SELECT `+entryColumns+` FROM audit_log ORDER BY seq DESC LIMIT 1`))
+	switch {
+	case err == nil:
+		prev = &last
+	case !errors.Is(err, sql.ErrNoRows):
+		return entry, fmt.Errorf("select last audit entry: %w", err)
+	}
+
+	entry = entry.Chain(prev)
+	_, err = tx.ExecContext(ctx, `
+		INSERT INTO audit_log (`+entryColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)`,
+		entry.Seq, entry.ID, entry.Time, entry.Actor, entry.ActorUsername, entry.ClientID, entry.APITokenID,
+		entry.Action, entry.Target, entry.Method, entry.Path, entry.RequestHash, entry.Status, entry.Outcome,
+		entry.RequestID, entry.TraceID, entry.PrevHash, entry.Hash,
+	)
+	if err != nil {
+		return entry, fmt.Errorf("insert audit entry %d: %w", entry.Seq, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return entry, fmt.Errorf("commit audit entry %d: %w", entry.Seq, err)
+	}
+	return entry, nil
+}
+
+// Query returns matching entries, newest first.
+func (l *PostgresLog) Query(ctx context.Context, q model.AuditQuery) ([]model.AuditEntry, error) {
+	var (
+		where []string
+		args  []any
+	)
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		where = append(where, fmt.Sprintf(cond, len(args)))
+	}
+	if q.Actor != "" {
+		add("actor = $%d", q.Actor)
+	}
+	if q.Action != "" {
+		add("action = $%d", q.Action)
+	}
+	if q.Target != "" {
+		add("target = $%d", q.Target)
+	}
+	if q.Outcome != "" {
+		add("outcome = $%d", q.Outcome)
+	}
+	if !q.Since.IsZero() {
+		add("time >= $%d", q.Since)
+	}
+	if !q.Until.IsZero() {
+		add("time < $%d", q.Until)
+	}
+	if q.Before > 0 {
+		add("seq < $%d", q.Before)
+	}
+
+	query := `SELECT ` + entryColumns + ` FROM audit_log`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY seq DESC`
+	if q.Limit > 0 {
+		args = append(args, q.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	return l.list(ctx, query, args...)
+}
+
+// Range returns entries after the given sequence number, oldest first.
+func (l *PostgresLog) Range(ctx context.Context, after int64, limit int) ([]model.AuditEntry, error) {
+	return l.list(ctx, `SELECT `+entryColumns+` FROM audit_log WHERE seq > $1 ORDER BY seq LIMIT $2`, after, limit)
+}
+
+func (l *PostgresLog) list(ctx context.Context, query string, args ...any) ([]model.AuditEntry, error) {
+	rows, err := l.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	entries := make([]model.AuditEntry, 0)
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("query audit log: %w", err)
+	}
+	return entries, nil
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row rowScanner) (model.AuditEntry, error) {
+	var e model.AuditEntry
+	err := row.Scan(&e.Seq, &e.ID, &e.Time, &e.Actor, &e.ActorUsername, &e.ClientID, &e.APITokenID,
+		&e.Action, &e.Target, &e.Method, &e.Path, &e.RequestHash, &e.Status, &e.Outcome,
+		&e.RequestID, &e.TraceID, &e.PrevHash, &e.Hash)
+	e.Time = e.Time.UTC()
+	return e, err
+}
@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/infrastructure"
+)
+
+// newTestPostgresLog returns a log on the test database, or skips the test if
+// not set. Set POSTGRES_TEST_DSN to a database with db/init.sql applied to
+// exercise the real implementation.
+func newTestPostgresLog(t *testing.T) *PostgresLog {
+	t.Helper()
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set; skipping Postgres integration test")
+	}
+	db, err := infrastructure.NewPostgresDB(context.Background(), infrastructure.PostgresConfig{URL: dsn, PingTimeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewPostgresLog(db)
+}
+
+func TestPostgresLog(t *testing.T) {
+	log := newTestPostgresLog(t)
+	// The table is append-only, so other runs' entries stay; fresh actors
+	// keep this run's queries to its own entries.
+	exerciseLog(t, log, [2]string{uuid.NewString(), uuid.NewString()})
+}
+
+func TestPostgresLog_RejectsUpdates(t *testing.T) {
+	log := newTestPostgresLog(t)
+	ctx := context.Background()
+	_, err := log.Append(ctx, testEntry(0, uuid.NewString(), time.Now()))
+	require.NoError(t, err)
+
+	_, err = log.db.ExecContext(ctx, `UPDATE audit_log SET target = 'forged'`)
+	assert.ErrorContains(t, err, "append-only")
+	_, err = log.db.ExecContext(ctx, `DELETE FROM audit_log`)
+	assert.ErrorContains(t, err, "append-only")
+}
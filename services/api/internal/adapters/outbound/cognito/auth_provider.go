@@ -2,6 +2,7 @@ package cognito
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
@@ -16,17 +17,23 @@ import (
 type CognitoAuthProvider struct {
 	client   *cognitoidentityprovider.Client
 	clientID string
+	tokens   tokenEndpoint
 }
 
 // Ensure CognitoAuthProvider implements the AuthProvider interface.
 var _ outbound.AuthProvider = (*CognitoAuthProvider)(nil)
 
 // NewCognitoAuthProvider creates a new CognitoAuthProvider.
-func NewCognitoAuthProvider(client *cognitoidentityprovider.Client, clientID string) *CognitoAuthProvider {
-	return &CognitoAuthProvider{
+func NewCognitoAuthProvider(client *cognitoidentityprovider.Client, clientID string, opts ...Option) *CognitoAuthProvider {
+	p := &CognitoAuthProvider{
 		client:   client,
 		clientID: clientID,
+		tokens:   tokenEndpoint{httpClient: http.DefaultClient},
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // SignUp registers a new user in Cognito.
@@ -43,11 +50,7 @@ func (p *CognitoAuthProvider) SignUp(ctx context.Context, email, password string
 		},
 	})
 	if err != nil {
-		return errors.NewDomainError(
-			errors.ErrCodeUserAlreadyExists,
-			"failed to register user",
-			err,
-		)
+		return domainError(err, "failed to register user")
 	}
 	return nil
 }
@@ -63,11 +66,12 @@ func (p *CognitoAuthProvider) SignIn(ctx context.Context, email, password string
 		},
 	})
 	if err != nil {
-		return nil, errors.NewDomainError(
-			errors.ErrCodeInvalidCredentials,
-			"authentication failed",
-			err,
-		)
+		// An unknown user reads as a wrong password, so sign in does not
+		// reveal which emails have accounts.
+		if is[*types.UserNotFoundException](err) {
+			return nil, wrap(errors.ErrCodeInvalidCredentials, "invalid credentials", errors.ErrUnauthorized, err)
+		}
+		return nil, domainError(err, "authentication failed")
 	}
 
 	if output.AuthenticationResult == nil {
@@ -78,13 +82,18 @@ func (p *CognitoAuthProvider) SignIn(ctx context.Context, email, password string
 		)
 	}
 
+	return authResponse(output.AuthenticationResult), nil
+}
+
+// authResponse converts Cognito's authentication result to the domain model.
+func authResponse(result *types.AuthenticationResultType) *model.AuthResponse {
 	return &model.AuthResponse{
-		AccessToken:  aws.ToString(output.AuthenticationResult.AccessToken),
-		RefreshToken: aws.ToString(output.AuthenticationResult.RefreshToken),
-		IdToken:      aws.ToString(output.AuthenticationResult.IdToken),
-		ExpiresIn:    output.AuthenticationResult.ExpiresIn,
-		TokenType:    aws.ToString(output.AuthenticationResult.TokenType),
-	}, nil
+		AccessToken:  aws.ToString(result.AccessToken),
+		RefreshToken: aws.ToString(result.RefreshToken),
+		IdToken:      aws.ToString(result.IdToken),
+		ExpiresIn:    result.ExpiresIn,
+		TokenType:    aws.ToString(result.TokenType),
+	}
 }
 
 // ConfirmSignUp confirms a user's email with the confirmation code.
@@ -95,11 +104,97 @@ func (p *CognitoAuthProvider) ConfirmSignUp(ctx context.Context, email, confirma
 		ConfirmationCode: aws.String(confirmationCode),
 	})
 	if err != nil {
-		return errors.NewDomainError(
-			errors.ErrCodeInvalidConfirmationCode,
-			"failed to confirm user registration",
-			err,
+		// Unknown and already-confirmed users read as a bad code.
+		if is[*types.UserNotFoundException](err) || is[*types.NotAuthorizedException](err) {
+			return wrap(errors.ErrCodeInvalidConfirmationCode, "code is invalid or expired", errors.ErrInvalidInput, err)
+		}
+		return domainError(err, "failed to confirm user registration")
+	}
+	return nil
+}
+
+// RefreshTokens exchanges a refresh token for new access and ID tokens.
+// Cognito does not rotate the refresh token, so none is returned.
+func (p *CognitoAuthProvider) RefreshTokens(ctx context.Context, refreshToken string) (*model.AuthResponse, error) {
+	output, err := p.client.InitiateAuth(ctx, &cognitoidentityprovider.InitiateAuthInput{
+		AuthFlow: types.AuthFlowTypeRefreshTokenAuth,
+		ClientId: aws.String(p.clientID),
+		AuthParameters: map[string]string{
+			"REFRESH_TOKEN": refreshToken,
+		},
+	})
+	if err != nil {
+		if is[*types.NotAuthorizedException](err) {
+			return nil, wrap(errors.ErrCodeInvalidToken, "refresh token is invalid, expired or revoked", errors.ErrUnauthorized, err)
+		}
+		return nil, domainError(err, "failed to refresh tokens")
+	}
+
+	if output.AuthenticationResult == nil {
+		return nil, errors.NewDomainError(
+			errors.ErrCodeAuthFailed,
+			"token refresh failed: no result returned",
+			errors.ErrUnauthorized,
 		)
 	}
+
+	return authResponse(output.AuthenticationResult), nil
+}
+
+// GlobalSignOut revokes every refresh token issued to the access token's
+// user. Access and ID tokens already issued stay valid until they expire.
+func (p *CognitoAuthProvider) GlobalSignOut(ctx context.Context, accessToken string) error {
+	_, err := p.client.GlobalSignOut(ctx, &cognitoidentityprovider.GlobalSignOutInput{
+		AccessToken: aws.String(accessToken),
+	})
+	if err != nil {
+		if is[*types.NotAuthorizedException](err) {
+			return wrap(errors.ErrCodeInvalidToken, "access token is invalid, expired or revoked", errors.ErrUnauthorized, err)
+		}
+		return domainError(err, "failed to sign out")
+	}
+	return nil
+}
+
+// ForgotPassword emails the user a code to reset their password with.
+func (p *CognitoAuthProvider) ForgotPassword(ctx context.Context, email string) error {
+	_, err := p.client.ForgotPassword(ctx, &cognitoidentityprovider.ForgotPasswordInput{
+		ClientId: aws.String(p.clientID),
+		Username: aws.String(email),
+	})
+	if err != nil {
+		return domainError(err, "failed to send password reset code")
+	}
+	return nil
+}
+
+// ConfirmForgotPassword sets a new password using the emailed reset code.
+func (p *CognitoAuthProvider) ConfirmForgotPassword(ctx context.Context, email, confirmationCode, newPassword string) error {
+	_, err := p.client.ConfirmForgotPassword(ctx, &cognitoidentityprovider.ConfirmForgotPasswordInput{
+		ClientId:         aws.String(p.clientID),
+		Username:         aws.String(email),
+		ConfirmationCode: aws.String(confirmationCode),
+		Password:         aws.String(newPassword),
+	})
+	if err != nil {
+		// An unknown user reads as a bad code, so the endpoint does not
+		// reveal which emails have accounts.
+		if is[*types.UserNotFoundException](err) {
+			return wrap(errors.ErrCodeInvalidConfirmationCode, "code is invalid or expired", errors.ErrInvalidInput, err)
+		}
+		return domainError(err, "failed to reset password")
+	}
+	return nil
+}
+
+// ResendConfirmationCode emails a new sign-up confirmation code.
+func (p *CognitoAuthProvider) ResendConfirmationCode(ctx context.Context, email string) error {
+	_, err := p.client.ResendConfirmationCode(ctx, &cognitoidentityprovider.ResendConfirmationCodeInput{
+		ClientId: aws.String(p.clientID),
+		Username: aws.String(email),
+	})
+	if err != nil {
+		return domainError(err, "failed to resend confirmation code")
+	}
 	return nil
 }
@@ -0,0 +1,130 @@
+package cognito
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// Option configures a CognitoAuthProvider.
+type Option func(*CognitoAuthProvider)
+
+// WithTokenEndpoint enables the client-credentials grant against the user
+// pool domain's OAuth2 token endpoint, e.g.
+// https://<domain>.auth.<region>.amazoncognito.com/oauth2/token. Requested
+// scopes are prefixed with resourceServer, the identifier of the Cognito
+// resource server that defines them, when it is set.
+func WithTokenEndpoint(tokenURL, resourceServer string) Option {
+	return func(p *CognitoAuthProvider) {
+		p.tokens.url = tokenURL
+		p.tokens.resourceServer = resourceServer
+	}
+}
+
+// WithHTTPClient sets the client used to call the token endpoint.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *CognitoAuthProvider) {
+		p.tokens.httpClient = client
+	}
+}
+
+type tokenEndpoint struct {
+	url            string
+	resourceServer string
+	httpClient     *http.Client
+}
+
+// tokenResponse is the token endpoint's success body.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int32  `json:"expires_in"`
+	TokenType   string `json:"token_type"`
+}
+
+// tokenError is the token endpoint's error body, as in RFC 6749 section 5.2.
+type tokenError struct {
+	Error string `json:"error"`
+}
+
+// ClientCredentials exchanges an app client's credentials for an access
+// token through the OAuth2 client-credentials grant. Cognito only issues
+// these tokens from the user pool domain, not from the identity provider
+// API, so the call goes to the token endpoint directly.
+func (p *CognitoAuthProvider) ClientCredentials(ctx context.Context, clientID, clientSecret string, scopes []string) (*model.AuthResponse, error) {
+	if p.tokens.url == "" {
+		return nil, errors.NewDomainError(errors.ErrCodeExternalService,
+			"client credentials are not configured", errors.ErrUnavailable)
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(scopes) > 0 {
+		qualified := make([]string, len(scopes))
+		for i, scope := range scopes {
+			qualified[i] = scope
+			if p.tokens.resourceServer != "" {
+				qualified[i] = p.tokens.resourceServer + "/" + scope
+			}
+		}
+		form.Set("scope", strings.Join(qualified, " "))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.tokens.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, errors.Internal("failed to build token request", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(clientID), url.QueryEscape(clientSecret))
+
+	resp, err := p.tokens.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.Internal("failed to issue client token", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Internal("failed to read token response", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var tokenErr tokenError
+		_ = json.Unmarshal(body, &tokenErr)
+		return nil, tokenEndpointError(resp.StatusCode, tokenErr.Error)
+	}
+
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return nil, errors.Internal("failed to decode token response", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.NewDomainError(errors.ErrCodeExternalService,
+			"token endpoint returned no access token", errors.ErrUnavailable)
+	}
+	return &model.AuthResponse{
+		AccessToken: token.AccessToken,
+		ExpiresIn:   token.ExpiresIn,
+		TokenType:   token.TokenType,
+	}, nil
+}
+
+// tokenEndpointError translates a token endpoint error code into a domain
+// error.
+func tokenEndpointError(status int, code string) error {
+	cause := fmt.Errorf("token endpoint returned %d %s", status, code)
+	switch code {
+	case "invalid_client", "unauthorized_client":
+		return wrap(errors.ErrCodeInvalidCredentials, "invalid client credentials", errors.ErrUnauthorized, cause)
+	case "invalid_scope", "invalid_request", "unsupported_grant_type":
+		return wrap(errors.ErrCodeValidationFailed, "invalid client credentials request: "+code, errors.ErrInvalidInput, cause)
+	}
+	if status == http.StatusTooManyRequests {
+		return wrap(errors.ErrCodeTooManyRequests, "too many requests; try again later", errors.ErrUnavailable, cause)
+	}
+	return errors.Internal("failed to issue client token", cause)
+}
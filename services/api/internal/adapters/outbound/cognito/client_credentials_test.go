@@ -0,0 +1,79 @@
+package cognito
+
+import (
+	"context"
+	stderrors "errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+)
+
+func TestClientCredentials_RequestsQualifiedScopes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, ok := r.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "ci-bot", id)
+		assert.Equal(t, "s3cret", secret)
+		assert.Equal(t, "client_credentials", r.FormValue("grant_type"))
+		assert.Equal(t, "idp-api/provision:write idp-api/resources:read", r.FormValue("scope"))
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"m2m-token","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+	defer srv.Close()
+
+	p := NewCognitoAuthProvider(nil, "app-client", WithTokenEndpoint(srv.URL, "idp-api"), WithHTTPClient(srv.Client()))
+	resp, err := p.ClientCredentials(context.Background(), "ci-bot", "s3cret", []string{"provision:write", "resources:read"})
+
+	require.NoError(t, err)
+	assert.Equal(t, "m2m-token", resp.AccessToken)
+	assert.Equal(t, int32(3600), resp.ExpiresIn)
+	assert.Equal(t, "Bearer", resp.TokenType)
+}
+
+func TestClientCredentials_MapsTokenEndpointErrors(t *testing.T) {
+	cases := []struct {
+		status   int
+		body     string
+		code     string
+		sentinel error
+	}{
+		{http.StatusBadRequest, `{"error":"invalid_client"}`, errors.ErrCodeInvalidCredentials, errors.ErrUnauthorized},
+		{http.StatusBadRequest, `{"error":"unauthorized_client"}`, errors.ErrCodeInvalidCredentials, errors.ErrUnauthorized},
+		{http.StatusBadRequest, `{"error":"invalid_scope"}`, errors.ErrCodeValidationFailed, errors.ErrInvalidInput},
+		{http.StatusTooManyRequests, ``, errors.ErrCodeTooManyRequests, errors.ErrUnavailable},
+		{http.StatusInternalServerError, `oops`, errors.ErrCodeExternalService, nil},
+	}
+	for _, tc := range cases {
+		t.Run(tc.code+"/"+tc.body, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			p := NewCognitoAuthProvider(nil, "app-client", WithTokenEndpoint(srv.URL, ""), WithHTTPClient(srv.Client()))
+			_, err := p.ClientCredentials(context.Background(), "ci-bot", "s3cret", nil)
+
+			var domainErr *errors.DomainError
+			require.True(t, stderrors.As(err, &domainErr))
+			assert.Equal(t, tc.code, domainErr.Code)
+			if tc.sentinel != nil {
+				assert.ErrorIs(t, err, tc.sentinel)
+			}
+		})
+	}
+}
+
+func TestClientCredentials_NotConfigured(t *testing.T) {
+	p := NewCognitoAuthProvider(nil, "app-client")
+
+	_, err := p.ClientCredentials(context.Background(), "ci-bot", "s3cret", nil)
+
+	assert.ErrorIs(t, err, errors.ErrUnavailable)
+}
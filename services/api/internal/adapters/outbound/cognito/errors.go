@@ -0,0 +1,68 @@
+package cognito
+
+import (
+	stderrors "errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+)
+
+// domainError translates a Cognito exception into a domain error carrying the
+// matching code, with the domain sentinel and the original exception both in
+// its chain. message describes the failed operation and is used when Cognito
+// gives nothing more specific. Operations that must not reveal whether an
+// account exists translate UserNotFoundException themselves before calling
+// this.
+func domainError(err error, message string) error {
+	var (
+		exists       *types.UsernameExistsException
+		weak         *types.InvalidPasswordException
+		unconfirmed  *types.UserNotConfirmedException
+		mismatch     *types.CodeMismatchException
+		expired      *types.ExpiredCodeException
+		notAuth      *types.NotAuthorizedException
+		notFound     *types.UserNotFoundException
+		resetNeeded  *types.PasswordResetRequiredException
+		invalidParam *types.InvalidParameterException
+		tooMany      *types.TooManyRequestsException
+		limit        *types.LimitExceededException
+		failedTries  *types.TooManyFailedAttemptsException
+		delivery     *types.CodeDeliveryFailureException
+	)
+	switch {
+	case stderrors.As(err, &exists):
+		return wrap(errors.ErrCodeUserAlreadyExists, "an account with this email already exists", errors.ErrAlreadyExists, err)
+	case stderrors.As(err, &weak):
+		return wrap(errors.ErrCodeWeakPassword, aws.ToString(weak.Message), errors.ErrInvalidInput, err)
+	case stderrors.As(err, &unconfirmed):
+		return wrap(errors.ErrCodeEmailNotVerified, "email address has not been confirmed", errors.ErrForbidden, err)
+	case stderrors.As(err, &mismatch), stderrors.As(err, &expired):
+		return wrap(errors.ErrCodeInvalidConfirmationCode, "code is invalid or expired", errors.ErrInvalidInput, err)
+	case stderrors.As(err, &notAuth):
+		return wrap(errors.ErrCodeInvalidCredentials, "invalid credentials", errors.ErrUnauthorized, err)
+	case stderrors.As(err, &notFound):
+		return wrap(errors.ErrCodeUserNotFound, "user not found", errors.ErrNotFound, err)
+	case stderrors.As(err, &resetNeeded):
+		return wrap(errors.ErrCodePasswordResetRequired, "password must be reset before signing in", errors.ErrForbidden, err)
+	case stderrors.As(err, &invalidParam):
+		return wrap(errors.ErrCodeValidationFailed, aws.ToString(invalidParam.Message), errors.ErrInvalidInput, err)
+	case stderrors.As(err, &tooMany), stderrors.As(err, &limit), stderrors.As(err, &failedTries):
+		return wrap(errors.ErrCodeTooManyRequests, "too many requests; try again later", errors.ErrUnavailable, err)
+	case stderrors.As(err, &delivery):
+		return wrap(errors.ErrCodeCodeDeliveryFailed, message, errors.ErrUnavailable, err)
+	}
+	return errors.Internal(message, err)
+}
+
+// is reports whether err is (or wraps) a Cognito exception of type T.
+func is[T error](err error) bool {
+	var target T
+	return stderrors.As(err, &target)
+}
+
+func wrap(code, message string, sentinel, cause error) *errors.DomainError {
+	return errors.NewDomainError(code, message, fmt.Errorf("%w: %w", sentinel, cause))
+}
@@ -0,0 +1,68 @@
+package cognito
+
+import (
+	stderrors "errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+)
+
+func TestDomainError_MapsCognitoExceptions(t *testing.T) {
+	cases := []struct {
+		err      error
+		code     string
+		sentinel error
+	}{
+		{&types.UsernameExistsException{}, errors.ErrCodeUserAlreadyExists, errors.ErrAlreadyExists},
+		{&types.InvalidPasswordException{Message: aws.String("Password must have symbol characters")}, errors.ErrCodeWeakPassword, errors.ErrInvalidInput},
+		{&types.UserNotConfirmedException{}, errors.ErrCodeEmailNotVerified, errors.ErrForbidden},
+		{&types.CodeMismatchException{}, errors.ErrCodeInvalidConfirmationCode, errors.ErrInvalidInput},
+		{&types.ExpiredCodeException{}, errors.ErrCodeInvalidConfirmationCode, errors.ErrInvalidInput},
+		{&types.NotAuthorizedException{}, errors.ErrCodeInvalidCredentials, errors.ErrUnauthorized},
+		{&types.UserNotFoundException{}, errors.ErrCodeUserNotFound, errors.ErrNotFound},
+		{&types.PasswordResetRequiredException{}, errors.ErrCodePasswordResetRequired, errors.ErrForbidden},
+		{&types.InvalidParameterException{}, errors.ErrCodeValidationFailed, errors.ErrInvalidInput},
+		{&types.TooManyRequestsException{}, errors.ErrCodeTooManyRequests, errors.ErrUnavailable},
+		{&types.LimitExceededException{}, errors.ErrCodeTooManyRequests, errors.ErrUnavailable},
+		{&types.TooManyFailedAttemptsException{}, errors.ErrCodeTooManyRequests, errors.ErrUnavailable},
+		{&types.CodeDeliveryFailureException{}, errors.ErrCodeCodeDeliveryFailed, errors.ErrUnavailable},
+	}
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%T", tc.err), func(t *testing.T) {
+			// The SDK returns exceptions wrapped in an operation error.
+			wrapped := fmt.Errorf("operation SignUp: %w", tc.err)
+
+			err := domainError(wrapped, "failed")
+
+			var domainErr *errors.DomainError
+			if assert.True(t, stderrors.As(err, &domainErr)) {
+				assert.Equal(t, tc.code, domainErr.Code)
+			}
+			assert.ErrorIs(t, err, tc.sentinel)
+			assert.ErrorIs(t, err, tc.err, "the Cognito exception stays in the chain for logs")
+		})
+	}
+}
+
+func TestDomainError_WeakPasswordKeepsPolicyMessage(t *testing.T) {
+	err := domainError(&types.InvalidPasswordException{Message: aws.String("Password must have symbol characters")}, "failed")
+
+	var domainErr *errors.DomainError
+	assert.True(t, stderrors.As(err, &domainErr))
+	assert.Equal(t, "Password must have symbol characters", domainErr.Message)
+}
+
+func TestDomainError_UnknownFailureIsExternalService(t *testing.T) {
+	err := domainError(&types.InternalErrorException{}, "failed to register user")
+
+	var domainErr *errors.DomainError
+	if assert.True(t, stderrors.As(err, &domainErr)) {
+		assert.Equal(t, errors.ErrCodeExternalService, domainErr.Code)
+		assert.Equal(t, "failed to register user", domainErr.Message)
+	}
+}
@@ -0,0 +1,13 @@
+package cognito
+
+import "fmt"
+
+// Issuer returns the iss claim of tokens issued by a user pool.
+func Issuer(region, userPoolID string) string {
+	return fmt.Sprintf("https://cognito-idp.%s.amazonaws.com/%s", region, userPoolID)
+}
+
+// JWKSURL returns where a user pool publishes its token signing keys.
+func JWKSURL(issuer string) string {
+	return issuer + "/.well-known/jwks.json"
+}
@@ -0,0 +1,108 @@
+// Package envelope wraps the events the API publishes in a versioned
+// CloudEvents 1.0 envelope, shared by the Kafka and SQS publishers. Envelopes
+// are sent in structured mode (attributes and data in one document), encoded
+// by a Serializer as JSON or Protobuf.
+//
+// The API owns the contract: the JSON Schema of every version of every
+// event's data lives in schemas/<type suffix>/v<n>.json, and consumers (the
+// provisioner) keep copies to validate and upcast what they receive. Adding
+// an optional property to the data is compatible; any other change needs a
+// new version, published only once consumers know how to upcast the old one.
+package envelope
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+const (
+	SpecVersion = "1.0"
+
+	// ContentTypeHeader is the header / attribute publishers stamp with the
+	// Serializer's content type, next to the message ID.
+	ContentTypeHeader = "content-type"
+
+	// Source identifies the API as the producer of its events.
+	Source = "/internal-developer-platform/api"
+
+	// EventTypePrefix is the reverse-DNS prefix of every event type.
+	EventTypePrefix = "com.internal-developer-platform."
+
+	// SchemaBaseURI prefixes every dataschema: <base><type suffix>/v<n>.json.
+	SchemaBaseURI = "https://internal-developer-platform.com/schemas/"
+)
+
+// The provisioning request accepted by the API, and the version of its data
+// the API publishes.
+const (
+	TypeResourceProvisionRequested    = EventTypePrefix + "resource.provision.requested"
+	ResourceProvisionRequestedVersion = 1
+)
+
+// Schemas holds the JSON Schema of every version of every event's data.
+//
+//go:embed schemas
+var Schemas embed.FS
+
+// Envelope is a CloudEvents envelope. DataSchema names the data's schema
+// and, in its last path segment, the version it was written with. Data is
+// always held as JSON; serializers of other formats transcode it.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	DataSchema      string          `json:"dataschema"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// SchemaURI is the dataschema of version v of eventType's data.
+func SchemaURI(eventType string, v int) string {
+	return SchemaBaseURI + strings.TrimPrefix(eventType, EventTypePrefix) + "/v" + strconv.Itoa(v) + ".json"
+}
+
+// ResourceProvisionRequested wraps a provisioning request, returning the
+// envelope and its encoding by s. Its ID is the one the caller assigned with
+// outbound.WithMessageID, or a fresh one, and its subject the resource ID.
+func ResourceProvisionRequested(ctx context.Context, s Serializer, resource model.Resource) (Envelope, []byte, error) {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return Envelope{}, nil, err
+	}
+	e := Envelope{
+		SpecVersion:     SpecVersion,
+		ID:              MessageID(ctx),
+		Type:            TypeResourceProvisionRequested,
+		Source:          Source,
+		Subject:         resource.ID,
+		Time:            time.Now().UTC(),
+		DataContentType: "application/json",
+		DataSchema:      SchemaURI(TypeResourceProvisionRequested, ResourceProvisionRequestedVersion),
+		Data:            data,
+	}
+	body, err := s.Marshal(e)
+	if err != nil {
+		return Envelope{}, nil, err
+	}
+	return e, body, nil
+}
+
+// MessageID is the ID the caller assigned, or a fresh one.
+func MessageID(ctx context.Context) string {
+	if id := outbound.MessageIDFromContext(ctx); id != "" {
+		return id
+	}
+	return uuid.NewString()
+}
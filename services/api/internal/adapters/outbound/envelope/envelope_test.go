@@ -0,0 +1,72 @@
+package envelope
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+func resolveSchema(t *testing.T, eventType string, v int) *jsonschema.Resolved {
+	t.Helper()
+	raw, err := Schemas.ReadFile("schemas/" + eventType[len(EventTypePrefix):] + "/v" + strconv.Itoa(v) + ".json")
+	require.NoError(t, err)
+	var s jsonschema.Schema
+	require.NoError(t, json.Unmarshal(raw, &s))
+	resolved, err := s.Resolve(nil)
+	require.NoError(t, err)
+	return resolved
+}
+
+func TestResourceProvisionRequested_WrapsResource(t *testing.T) {
+	ctx := outbound.WithMessageID(context.Background(), "msg-1")
+	resource := model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending",
+		RequestedBy: "rafael", Region: "us-east-1", Tags: map[string]string{"team": "platform"},
+	}
+
+	e, body, err := ResourceProvisionRequested(ctx, JSONSerializer{}, resource)
+	require.NoError(t, err)
+
+	assert.Equal(t, "msg-1", e.ID)
+	assert.Equal(t, "vm-001", e.Subject)
+	assert.WithinDuration(t, time.Now(), e.Time, time.Minute)
+	assert.Equal(t, "https://internal-developer-platform.com/schemas/resource.provision.requested/v1.json", e.DataSchema)
+
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	for _, attr := range []string{"specversion", "id", "type", "source", "subject", "time", "datacontenttype", "dataschema", "data"} {
+		assert.Contains(t, decoded, attr)
+	}
+	assert.Equal(t, SpecVersion, decoded["specversion"])
+	assert.Equal(t, TypeResourceProvisionRequested, decoded["type"])
+
+	// What the API publishes must pass the schema consumers validate it with.
+	assert.NoError(t, resolveSchema(t, TypeResourceProvisionRequested, ResourceProvisionRequestedVersion).Validate(decoded["data"]))
+}
+
+func TestResourceProvisionRequested_FreshIDs(t *testing.T) {
+	a, _, err := ResourceProvisionRequested(context.Background(), JSONSerializer{}, model.Resource{ID: "vm-001"})
+	require.NoError(t, err)
+	b, _, err := ResourceProvisionRequested(context.Background(), JSONSerializer{}, model.Resource{ID: "vm-001"})
+	require.NoError(t, err)
+
+	assert.NotEmpty(t, a.ID)
+	assert.NotEqual(t, a.ID, b.ID)
+}
+
+func TestSchema_RejectsIncompleteRequests(t *testing.T) {
+	schema := resolveSchema(t, TypeResourceProvisionRequested, ResourceProvisionRequestedVersion)
+
+	var data any
+	require.NoError(t, json.Unmarshal([]byte(`{"id":"vm-001","resource_type":"VM"}`), &data))
+	assert.Error(t, schema.Validate(data), "cloud_provider is required")
+}
@@ -0,0 +1,6 @@
+// Package eventspb holds the Protobuf messages of events published in the
+// application/cloudevents+protobuf format. The API owns them; the provisioner
+// keeps a byte-identical copy, so regenerate both after editing a .proto.
+package eventspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative cloudevent.proto resource_provision_requested_v1.proto
@@ -0,0 +1,191 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: resource_provision_requested_v1.proto
+
+package eventspb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ResourceProvisionRequestedV1 struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ResourceType  string                 `protobuf:"bytes,2,opt,name=resource_type,json=resourceType,proto3" json:"resource_type,omitempty"`
+	CloudProvider string                 `protobuf:"bytes,3,opt,name=cloud_provider,json=cloudProvider,proto3" json:"cloud_provider,omitempty"`
+	Specification string                 `protobuf:"bytes,4,opt,name=specification,proto3" json:"specification,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	RequestedBy   string                 `protobuf:"bytes,6,opt,name=requested_by,json=requestedBy,proto3" json:"requested_by,omitempty"`
+	Region        string                 `protobuf:"bytes,7,opt,name=region,proto3" json:"region,omitempty"`
+	Tags          map[string]string      `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResourceProvisionRequestedV1) Reset() {
+	*x = ResourceProvisionRequestedV1{}
+	mi := &file_resource_provision_requested_v1_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResourceProvisionRequestedV1) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResourceProvisionRequestedV1) ProtoMessage() {}
+
+func (x *ResourceProvisionRequestedV1) ProtoReflect() protoreflect.Message {
+	mi := &file_resource_provision_requested_v1_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResourceProvisionRequestedV1.ProtoReflect.Descriptor instead.
+func (*ResourceProvisionRequestedV1) Descriptor() ([]byte, []int) {
+	return file_resource_provision_requested_v1_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ResourceProvisionRequestedV1) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ResourceProvisionRequestedV1) GetResourceType() string {
+	if x != nil {
+		return x.ResourceType
+	}
+	return ""
+}
+
+func (x *ResourceProvisionRequestedV1) GetCloudProvider() string {
+	if x != nil {
+		return x.CloudProvider
+	}
+	return ""
+}
+
+func (x *ResourceProvisionRequestedV1) GetSpecification() string {
+	if x != nil {
+		return x.Specification
+	}
+	return ""
+}
+
+func (x *ResourceProvisionRequestedV1) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ResourceProvisionRequestedV1) GetRequestedBy() string {
+	if x != nil {
+		return x.RequestedBy
+	}
+	return ""
+}
+
+func (x *ResourceProvisionRequestedV1) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *ResourceProvisionRequestedV1) GetTags() map[string]string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+var File_resource_provision_requested_v1_proto protoreflect.FileDescriptor
+
+const file_resource_provision_requested_v1_proto_rawDesc = "" +
+	"\n" +
+	"%resource_provision_requested_v1.proto\x12\"internal_developer_platform.events\"\x8c\x03\n" +
+	"\x1cResourceProvisionRequestedV1\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12#\n" +
+	"\rresource_type\x18\x02 \x01(\tR\fresourceType\x12%\n" +
+	"\x0ecloud_provider\x18\x03 \x01(\tR\rcloudProvider\x12$\n" +
+	"\rspecification\x18\x04 \x01(\tR\rspecification\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12!\n" +
+	"\frequested_by\x18\x06 \x01(\tR\vrequestedBy\x12\x16\n" +
+	"\x06region\x18\a \x01(\tR\x06region\x12^\n" +
+	"\x04tags\x18\b \x03(\v2J.internal_developer_platform.events.ResourceProvisionRequestedV1.TagsEntryR\x04tags\x1a7\n" +
+	"\tTagsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01B\fZ\n" +
+	"./eventspbb\x06proto3"
+
+var (
+	file_resource_provision_requested_v1_proto_rawDescOnce sync.Once
+	file_resource_provision_requested_v1_proto_rawDescData []byte
+)
+
+func file_resource_provision_requested_v1_proto_rawDescGZIP() []byte {
+	file_resource_provision_requested_v1_proto_rawDescOnce.Do(func() {
+		file_resource_provision_requested_v1_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_resource_provision_requested_v1_proto_rawDesc), len(file_resource_provision_requested_v1_proto_rawDesc)))
+	})
+	return file_resource_provision_requested_v1_proto_rawDescData
+}
+
+var file_resource_provision_requested_v1_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_resource_provision_requested_v1_proto_goTypes = []any{
+	(*ResourceProvisionRequestedV1)(nil), // 0: internal_developer_platform.events.ResourceProvisionRequestedV1
+	nil,                                  // 1: internal_developer_platform.events.ResourceProvisionRequestedV1.TagsEntry
+}
+var file_resource_provision_requested_v1_proto_depIdxs = []int32{
+	1, // 0: internal_developer_platform.events.ResourceProvisionRequestedV1.tags:type_name -> internal_developer_platform.events.ResourceProvisionRequestedV1.TagsEntry
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_resource_provision_requested_v1_proto_init() }
+func file_resource_provision_requested_v1_proto_init() {
+	if File_resource_provision_requested_v1_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_resource_provision_requested_v1_proto_rawDesc), len(file_resource_provision_requested_v1_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_resource_provision_requested_v1_proto_goTypes,
+		DependencyIndexes: file_resource_provision_requested_v1_proto_depIdxs,
+		MessageInfos:      file_resource_provision_requested_v1_proto_msgTypes,
+	}.Build()
+	File_resource_provision_requested_v1_proto = out.File
+	file_resource_provision_requested_v1_proto_goTypes = nil
+	file_resource_provision_requested_v1_proto_depIdxs = nil
+}
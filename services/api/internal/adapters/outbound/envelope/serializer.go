@@ -0,0 +1,94 @@
+package envelope
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope/eventspb"
+)
+
+// Content types of the wire formats, stamped in the ContentTypeHeader so a
+// consumer can decode each message on its own: a topic or queue can carry
+// both formats at once, for instance while publishers switch over.
+const (
+	ContentTypeJSON     = "application/cloudevents+json"
+	ContentTypeProtobuf = "application/cloudevents+protobuf"
+)
+
+// Serializer encodes envelopes in one wire format.
+type Serializer interface {
+	// ContentType identifies the format to consumers.
+	ContentType() string
+	// Binary reports whether encodings are binary, which transports that
+	// only carry text (SQS) must base64-encode.
+	Binary() bool
+	Marshal(e Envelope) ([]byte, error)
+}
+
+var (
+	_ Serializer = JSONSerializer{}
+	_ Serializer = ProtobufSerializer{}
+)
+
+// JSONSerializer encodes envelopes in the CloudEvents JSON format.
+type JSONSerializer struct{}
+
+func (JSONSerializer) ContentType() string { return ContentTypeJSON }
+
+func (JSONSerializer) Binary() bool { return false }
+
+func (JSONSerializer) Marshal(e Envelope) ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// protoData maps each dataschema to the Protobuf message of that version of
+// the data. Every schema the API publishes needs an entry here; the
+// eventspb .proto files mirror the JSON Schemas field for field.
+var protoData = map[string]func() proto.Message{
+	SchemaURI(TypeResourceProvisionRequested, 1): func() proto.Message { return new(eventspb.ResourceProvisionRequestedV1) },
+}
+
+// ProtobufSerializer encodes envelopes in the CloudEvents Protobuf format,
+// with the data transcoded to the Protobuf message of its dataschema and
+// carried as binary data.
+type ProtobufSerializer struct{}
+
+func (ProtobufSerializer) ContentType() string { return ContentTypeProtobuf }
+
+func (ProtobufSerializer) Binary() bool { return true }
+
+func (ProtobufSerializer) Marshal(e Envelope) ([]byte, error) {
+	newData, ok := protoData[e.DataSchema]
+	if !ok {
+		return nil, fmt.Errorf("no Protobuf message for dataschema %s", e.DataSchema)
+	}
+	data := newData()
+	if err := protojson.Unmarshal(e.Data, data); err != nil {
+		return nil, fmt.Errorf("transcode data to %s: %w", data.ProtoReflect().Descriptor().FullName(), err)
+	}
+	binary, err := proto.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs := map[string]*eventspb.CloudEvent_CloudEventAttributeValue{
+		"time":            {Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeTimestamp{CeTimestamp: timestamppb.New(e.Time)}},
+		"datacontenttype": {Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeString{CeString: "application/protobuf"}},
+		"dataschema":      {Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeUri{CeUri: e.DataSchema}},
+	}
+	if e.Subject != "" {
+		attrs["subject"] = &eventspb.CloudEvent_CloudEventAttributeValue{Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeString{CeString: e.Subject}}
+	}
+	return proto.Marshal(&eventspb.CloudEvent{
+		Id:          e.ID,
+		Source:      e.Source,
+		SpecVersion: e.SpecVersion,
+		Type:        e.Type,
+		Attributes:  attrs,
+		Data:        &eventspb.CloudEvent_BinaryData{BinaryData: binary},
+	})
+}
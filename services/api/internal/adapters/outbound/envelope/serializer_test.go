@@ -0,0 +1,85 @@
+package envelope
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope/eventspb"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+func TestJSONSerializer_EncodesStructuredJSON(t *testing.T) {
+	e, body, err := ResourceProvisionRequested(context.Background(), JSONSerializer{}, model.Resource{ID: "vm-001"})
+	require.NoError(t, err)
+
+	var decoded Envelope
+	require.NoError(t, json.Unmarshal(body, &decoded))
+	assert.Equal(t, e.ID, decoded.ID)
+	assert.JSONEq(t, string(e.Data), string(decoded.Data))
+}
+
+func TestProtobufSerializer_EncodesCloudEvent(t *testing.T) {
+	ctx := outbound.WithMessageID(context.Background(), "msg-1")
+	resource := model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending",
+		RequestedBy: "rafael", Region: "us-east-1", Tags: map[string]string{"team": "platform"},
+	}
+
+	e, body, err := ResourceProvisionRequested(ctx, ProtobufSerializer{}, resource)
+	require.NoError(t, err)
+
+	var ce eventspb.CloudEvent
+	require.NoError(t, proto.Unmarshal(body, &ce))
+	assert.Equal(t, "msg-1", ce.GetId())
+	assert.Equal(t, Source, ce.GetSource())
+	assert.Equal(t, SpecVersion, ce.GetSpecVersion())
+	assert.Equal(t, TypeResourceProvisionRequested, ce.GetType())
+	assert.Equal(t, "vm-001", ce.GetAttributes()["subject"].GetCeString())
+	assert.Equal(t, "application/protobuf", ce.GetAttributes()["datacontenttype"].GetCeString())
+	assert.Equal(t, e.DataSchema, ce.GetAttributes()["dataschema"].GetCeUri())
+	assert.True(t, e.Time.Equal(ce.GetAttributes()["time"].GetCeTimestamp().AsTime()))
+
+	var data eventspb.ResourceProvisionRequestedV1
+	require.NoError(t, proto.Unmarshal(ce.GetBinaryData(), &data))
+	assert.Equal(t, "vm-001", data.GetId())
+	assert.Equal(t, "AWS", data.GetCloudProvider())
+	assert.Equal(t, "rafael", data.GetRequestedBy())
+	assert.Equal(t, "us-east-1", data.GetRegion())
+	assert.Equal(t, map[string]string{"team": "platform"}, data.GetTags())
+}
+
+func TestProtobufSerializer_RejectsUnknownSchemas(t *testing.T) {
+	_, err := ProtobufSerializer{}.Marshal(Envelope{DataSchema: SchemaURI(TypeResourceProvisionRequested, 99), Data: json.RawMessage(`{}`)})
+	assert.Error(t, err)
+
+	// Data the message has no field for must not be dropped silently.
+	_, err = ProtobufSerializer{}.Marshal(Envelope{
+		DataSchema: SchemaURI(TypeResourceProvisionRequested, 1),
+		Data:       json.RawMessage(`{"id":"vm-001","flavour":"large"}`),
+	})
+	assert.Error(t, err)
+}
+
+// Every schema the API publishes must be publishable as Protobuf.
+func TestProtobufSerializer_CoversEverySchema(t *testing.T) {
+	err := fs.WalkDir(Schemas, "schemas", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		eventType, file, _ := strings.Cut(strings.TrimPrefix(name, "schemas/"), "/")
+		v, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file, "v"), ".json"))
+		require.NoError(t, err, name)
+		assert.Contains(t, protoData, SchemaURI(EventTypePrefix+eventType, v), "no Protobuf message for %s", name)
+		return nil
+	})
+	require.NoError(t, err)
+}
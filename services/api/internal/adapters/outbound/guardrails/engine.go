@@ -0,0 +1,262 @@
+// Package guardrails is the built-in policy engine: declarative rules on what
+// may be provisioned, loaded from a versioned YAML file:
+//
+//	version: 1
+//	rules:
+//	  - name: vm-instance-families
+//	    description: VMs use burstable or general purpose instances up to large
+//	    when: {resource_types: [VM], cloud_providers: [AWS]}
+//	    field: specification
+//	    allow: ["t3.*", "m5.large", "m5.medium"]
+//	  - name: approved-regions
+//	    field: region
+//	    allow: [us-east-1, eu-west-1]
+//	  - name: cost-allocation
+//	    require_tags: [cost-center, owner]
+//
+// A rule applies when the request matches every list under when (an empty
+// list matches anything; groups match any of the caller's groups) and then
+// checks one thing: that field matches an allow pattern, that it matches no
+// deny pattern, or that the listed tags are present. Patterns are globs as in
+// path.Match. Fields are specification, region and tags.<key>.
+package guardrails
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// SupportedVersions are the guardrail file versions this build understands.
+var SupportedVersions = []int{1}
+
+// Policy is a parsed guardrail file.
+type Policy struct {
+	Version int    `yaml:"version"`
+	Rules   []Rule `yaml:"rules"`
+}
+
+// Rule is one guardrail. Exactly one of Allow, Deny (both with Field) and
+// RequireTags is set. Allow and Deny are glob patterns matched ignoring case.
+type Rule struct {
+	Name        string   `yaml:"name"`
+	Description string   `yaml:"description"`
+	When        Scope    `yaml:"when"`
+	Field       string   `yaml:"field"`
+	Allow       []string `yaml:"allow"`
+	Deny        []string `yaml:"deny"`
+	RequireTags []string `yaml:"require_tags"`
+}
+
+// Scope narrows the requests a rule applies to.
+type Scope struct {
+	ResourceTypes  []string `yaml:"resource_types"`
+	CloudProviders []string `yaml:"cloud_providers"`
+	Groups         []string `yaml:"groups"`
+}
+
+// Engine evaluates a Policy. It implements outbound.PolicyEngine.
+type Engine struct {
+	policy Policy
+}
+
+var _ outbound.PolicyEngine = (*Engine)(nil)
+
+// NewEngine validates policy and returns an engine for it.
+func NewEngine(policy Policy) (*Engine, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	return &Engine{policy: policy}, nil
+}
+
+// LoadFile reads the guardrail file at path and returns an engine for it.
+func LoadFile(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read guardrails: %w", err)
+	}
+	policy, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return NewEngine(policy)
+}
+
+// Parse decodes a guardrail file. Unknown fields and unsupported versions are
+// errors, so a typo fails at startup instead of silently allowing requests.
+func Parse(data []byte) (Policy, error) {
+	var header struct {
+		Version int `yaml:"version"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		return Policy{}, fmt.Errorf("decode guardrails: %w", err)
+	}
+	if !slices.Contains(SupportedVersions, header.Version) {
+		return Policy{}, fmt.Errorf("unsupported guardrails version %d (supported: %v)", header.Version, SupportedVersions)
+	}
+
+	var policy Policy
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&policy); err != nil {
+		return Policy{}, fmt.Errorf("decode guardrails: %w", err)
+	}
+	return policy, nil
+}
+
+// Version returns the version of the loaded policy.
+func (e *Engine) Version() int {
+	return e.policy.Version
+}
+
+// Rules returns how many rules the engine evaluates.
+func (e *Engine) Rules() int {
+	return len(e.policy.Rules)
+}
+
+// Evaluate returns every violation of every applicable rule.
+func (e *Engine) Evaluate(_ context.Context, principal model.Principal, r model.Resource) ([]model.PolicyViolation, error) {
+	var violations []model.PolicyViolation
+	for _, rule := range e.policy.Rules {
+		if rule.When.applies(principal, r) {
+			violations = append(violations, rule.check(r)...)
+		}
+	}
+	return violations, nil
+}
+
+// Validate reports the first rule that is malformed.
+func (p Policy) Validate() error {
+	seen := make(map[string]bool, len(p.Rules))
+	for i, rule := range p.Rules {
+		if rule.Name == "" {
+			return fmt.Errorf("rule %d: name is required", i)
+		}
+		if seen[rule.Name] {
+			return fmt.Errorf("rule %q: duplicate name", rule.Name)
+		}
+		seen[rule.Name] = true
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r Rule) validate() error {
+	checks := 0
+	for _, set := range [][]string{r.Allow, r.Deny, r.RequireTags} {
+		if len(set) > 0 {
+			checks++
+		}
+	}
+	if checks != 1 {
+		return fmt.Errorf("exactly one of allow, deny and require_tags must be set")
+	}
+
+	if len(r.RequireTags) > 0 {
+		if r.Field != "" {
+			return fmt.Errorf("field does not apply to require_tags")
+		}
+		return nil
+	}
+	if !validField(r.Field) {
+		return fmt.Errorf("unknown field %q (want specification, region or tags.<key>)", r.Field)
+	}
+	for _, pattern := range append(slices.Clone(r.Allow), r.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+func (s Scope) applies(p model.Principal, r model.Resource) bool {
+	return matchesAny(s.ResourceTypes, r.ResourceType) &&
+		matchesAny(s.CloudProviders, r.CloudProvider) &&
+		(len(s.Groups) == 0 || slices.ContainsFunc(p.Groups, func(g string) bool {
+			return slices.Contains(s.Groups, g)
+		}))
+}
+
+func (r Rule) check(res model.Resource) []model.PolicyViolation {
+	if len(r.RequireTags) > 0 {
+		var violations []model.PolicyViolation
+		for _, tag := range r.RequireTags {
+			if res.Tags[tag] == "" {
+				violations = append(violations, r.violation("tags."+tag, "", fmt.Sprintf("tag %q is required", tag)))
+			}
+		}
+		return violations
+	}
+
+	value := fieldValue(res, r.Field)
+	switch {
+	case len(r.Allow) > 0 && value == "":
+		return []model.PolicyViolation{r.violation(r.Field, "",
+			fmt.Sprintf("%s is required; allowed: %s", r.Field, strings.Join(r.Allow, ", ")))}
+	case len(r.Allow) > 0 && !matchesPattern(r.Allow, value):
+		return []model.PolicyViolation{r.violation(r.Field, value,
+			fmt.Sprintf("%s %q is not allowed; allowed: %s", r.Field, value, strings.Join(r.Allow, ", ")))}
+	case len(r.Deny) > 0 && value != "" && matchesPattern(r.Deny, value):
+		return []model.PolicyViolation{r.violation(r.Field, value,
+			fmt.Sprintf("%s %q is not allowed", r.Field, value))}
+	}
+	return nil
+}
+
+func (r Rule) violation(field, value, message string) model.PolicyViolation {
+	if r.Description != "" {
+		message = r.Description + ": " + message
+	}
+	return model.PolicyViolation{Rule: r.Name, Field: field, Message: message, Value: value}
+}
+
+func validField(field string) bool {
+	switch field {
+	case "specification", "region":
+		return true
+	}
+	key, ok := strings.CutPrefix(field, "tags.")
+	return ok && key != ""
+}
+
+func fieldValue(r model.Resource, field string) string {
+	switch field {
+	case "specification":
+		return r.Specification
+	case "region":
+		return r.Region
+	}
+	key, _ := strings.CutPrefix(field, "tags.")
+	return r.Tags[key]
+}
+
+// matchesAny reports whether value is in list, ignoring case; an empty list
+// matches everything.
+func matchesAny(list []string, value string) bool {
+	return len(list) == 0 || slices.ContainsFunc(list, func(v string) bool {
+		return strings.EqualFold(v, value)
+	})
+}
+
+// matchesPattern reports whether value matches any of the glob patterns,
+// ignoring case like matchesAny, so "AP-SOUTHEAST-1" cannot slip past a
+// deny of "ap-*".
+func matchesPattern(patterns []string, value string) bool {
+	value = strings.ToLower(value)
+	return slices.ContainsFunc(patterns, func(p string) bool {
+		ok, _ := path.Match(strings.ToLower(p), value)
+		return ok
+	})
+}
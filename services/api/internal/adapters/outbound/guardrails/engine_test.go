@@ -0,0 +1,172 @@
+package guardrails
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+const sampleGuardrails = `
+version: 1
+rules:
+  - name: vm-instance-families
+    description: VMs use burstable or general purpose instances up to large
+    when: {resource_types: [VM], cloud_providers: [AWS]}
+    field: specification
+    allow: ["t3.*", "m5.large"]
+  - name: no-metal
+    field: specification
+    deny: ["*.metal"]
+  - name: approved-regions
+    when: {groups: [team-payments]}
+    field: region
+    allow: [eu-west-1, eu-central-1]
+  - name: cost-allocation
+    require_tags: [cost-center, owner]
+`
+
+func newTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	policy, err := Parse([]byte(sampleGuardrails))
+	require.NoError(t, err)
+	engine, err := NewEngine(policy)
+	require.NoError(t, err)
+	return engine
+}
+
+func compliantVM() model.Resource {
+	return model.Resource{
+		ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t3.small",
+		Region: "eu-west-1", Tags: map[string]string{"cost-center": "cc-42", "owner": "payments"},
+	}
+}
+
+func rulesOf(violations []model.PolicyViolation) []string {
+	var rules []string
+	for _, v := range violations {
+		rules = append(rules, v.Rule)
+	}
+	return rules
+}
+
+func TestEngine_CompliantRequestHasNoViolations(t *testing.T) {
+	engine := newTestEngine(t)
+	payments := model.Principal{Groups: []string{"team-payments"}}
+
+	violations, err := engine.Evaluate(context.Background(), payments, compliantVM())
+
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestEngine_ReportsEveryViolation(t *testing.T) {
+	engine := newTestEngine(t)
+	payments := model.Principal{Groups: []string{"team-payments"}}
+	r := compliantVM()
+	r.Specification = "m5.4xlarge"
+	r.Region = "us-east-1"
+	r.Tags = map[string]string{"owner": "payments"}
+
+	violations, err := engine.Evaluate(context.Background(), payments, r)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"vm-instance-families", "approved-regions", "cost-allocation"}, rulesOf(violations))
+	assert.Equal(t, model.PolicyViolation{
+		Rule:    "vm-instance-families",
+		Field:   "specification",
+		Message: `VMs use burstable or general purpose instances up to large: specification "m5.4xlarge" is not allowed; allowed: t3.*, m5.large`,
+		Value:   "m5.4xlarge",
+	}, violations[0])
+	assert.Equal(t, "tags.cost-center", violations[2].Field)
+}
+
+func TestEngine_ScopesRules(t *testing.T) {
+	engine := newTestEngine(t)
+	r := compliantVM()
+	r.Region = "us-east-1"
+
+	// approved-regions only binds team-payments.
+	violations, _ := engine.Evaluate(context.Background(), model.Principal{Groups: []string{"team-search"}}, r)
+	assert.Empty(t, violations)
+
+	// vm-instance-families only binds VMs on AWS.
+	r.ResourceType, r.Specification = "RDS", "db.r5.large"
+	violations, _ = engine.Evaluate(context.Background(), model.Principal{}, r)
+	assert.Empty(t, violations)
+}
+
+func TestEngine_DenyAndMissingAllowedField(t *testing.T) {
+	engine := newTestEngine(t)
+	payments := model.Principal{Groups: []string{"team-payments"}}
+	r := compliantVM()
+	r.ResourceType, r.Specification = "RDS", "i3.metal"
+	r.Region = ""
+
+	violations, _ := engine.Evaluate(context.Background(), payments, r)
+
+	assert.Equal(t, []string{"no-metal", "approved-regions"}, rulesOf(violations))
+	assert.Equal(t, "region is required; allowed: eu-west-1, eu-central-1", violations[1].Message)
+}
+
+func TestEngine_PatternsIgnoreCase(t *testing.T) {
+	policy, err := Parse([]byte("version: 1\nrules:\n  - name: no-apac\n    field: region\n    deny: [\"ap-*\"]"))
+	require.NoError(t, err)
+	engine, err := NewEngine(policy)
+	require.NoError(t, err)
+	r := compliantVM()
+	r.Region = "AP-SOUTHEAST-1"
+
+	violations, _ := engine.Evaluate(context.Background(), model.Principal{}, r)
+	assert.Equal(t, []string{"no-apac"}, rulesOf(violations))
+
+	// An allow list matches an upper-case value the same way.
+	r.Specification = "T3.SMALL"
+	violations, _ = newTestEngine(t).Evaluate(context.Background(), model.Principal{}, r)
+	assert.NotContains(t, rulesOf(violations), "vm-instance-families")
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "guardrails.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(sampleGuardrails), 0o600))
+
+	engine, err := LoadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, engine.Version())
+	assert.Equal(t, 4, engine.Rules())
+}
+
+func TestLoadFile_Example(t *testing.T) {
+	_, err := LoadFile("../../../../policies/guardrails.example.yaml")
+	assert.NoError(t, err)
+}
+
+func TestParseAndValidate_Reject(t *testing.T) {
+	cases := map[string]string{
+		"no version":         "rules: []",
+		"future version":     "version: 2",
+		"unknown field":      "version: 1\nrules:\n  - name: r\n    field: region\n    allowed: [x]",
+		"no check":           "version: 1\nrules:\n  - name: r\n    field: region",
+		"two checks":         "version: 1\nrules:\n  - name: r\n    field: region\n    allow: [a]\n    deny: [b]",
+		"unknown field name": "version: 1\nrules:\n  - name: r\n    field: size\n    allow: [a]",
+		"bad pattern":        "version: 1\nrules:\n  - name: r\n    field: region\n    allow: ['[']",
+		"missing name":       "version: 1\nrules:\n  - field: region\n    allow: [a]",
+		"duplicate name":     "version: 1\nrules:\n  - {name: r, require_tags: [a]}\n  - {name: r, require_tags: [b]}",
+		"field with tags":    "version: 1\nrules:\n  - {name: r, field: region, require_tags: [a]}",
+	}
+	for name, doc := range cases {
+		t.Run(name, func(t *testing.T) {
+			policy, err := Parse([]byte(doc))
+			if err == nil {
+				_, err = NewEngine(policy)
+			}
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,22 @@
+package jwt
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JWKSHandler serves set as a JSON Web Key Set, for resource servers that
+// verify tokens issued with a local Signer. The set is encoded once; keys
+// only change when the process restarts.
+func JWKSHandler(set JWKS) http.Handler {
+	body, err := json.Marshal(set)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err != nil {
+			http.Error(w, "failed to encode key set", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		_, _ = w.Write(body)
+	})
+}
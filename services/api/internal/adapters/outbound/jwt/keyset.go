@@ -0,0 +1,276 @@
+// Package jwt verifies RS256-signed JSON Web Tokens against a JSON Web Key
+// Set, such as the one a Cognito user pool publishes, and signs them with a
+// local key.
+package jwt
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrUnknownKey is returned for a key ID the key set does not contain.
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// JWK is one RSA public key in a JSON Web Key Set.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use,omitempty"`
+	Algorithm string `json:"alg,omitempty"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set, the document served at an issuer's
+// /.well-known/jwks.json.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// KeySet resolves the public key a token was signed with.
+type KeySet interface {
+	Key(ctx context.Context, kid string) (*rsa.PublicKey, error)
+}
+
+// StaticKeySet is a fixed set of keys, for tokens signed locally.
+type StaticKeySet struct {
+	keys map[string]*rsa.PublicKey
+}
+
+var _ KeySet = (*StaticKeySet)(nil)
+
+// NewStaticKeySet parses a key set. Keys that are not RSA signing keys are
+// skipped.
+func NewStaticKeySet(set JWKS) (*StaticKeySet, error) {
+	keys, err := parseKeys(set)
+	if err != nil {
+		return nil, err
+	}
+	return &StaticKeySet{keys: keys}, nil
+}
+
+// Key returns the key with the given ID.
+func (s *StaticKeySet) Key(_ context.Context, kid string) (*rsa.PublicKey, error) {
+	if key, ok := s.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// RemoteKeySetConfig tunes a RemoteKeySet.
+type RemoteKeySetConfig struct {
+	// TTL is how long a fetched key set is trusted before it is fetched again.
+	TTL time.Duration
+	// MinRefreshInterval stops a stream of tokens with an unknown key ID from
+	// refetching the key set on every request.
+	MinRefreshInterval time.Duration
+	// RetryInterval is how long a failed fetch is remembered before the set is
+	// fetched again. Meanwhile cached keys are served and other lookups fail
+	// with the fetch's error, so an outage costs one fetch per interval rather
+	// than one per request.
+	RetryInterval time.Duration
+	// HTTPClient fetches the key set; nil uses a client with a 5s timeout.
+	HTTPClient *http.Client
+}
+
+// DefaultRemoteKeySetConfig returns the defaults: keys are cached for an
+// hour, an unknown key ID triggers at most one refetch a minute, and a failed
+// fetch is retried after 10 seconds.
+func DefaultRemoteKeySetConfig() RemoteKeySetConfig {
+	return RemoteKeySetConfig{
+		TTL:                time.Hour,
+		MinRefreshInterval: time.Minute,
+		RetryInterval:      10 * time.Second,
+	}
+}
+
+// RemoteKeySet fetches a key set from a URL and caches it. The set is fetched
+// again when the cache expires, or early when a token names a key it does not
+// contain, which is how a signing key rotation is picked up. If a refetch
+// fails, keys already cached keep being served.
+type RemoteKeySet struct {
+	url    string
+	config RemoteKeySetConfig
+	now    func() time.Time
+
+	mu          sync.Mutex
+	keys        map[string]*rsa.PublicKey
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	lastErr     error
+	inflight    *keyFetch
+}
+
+// keyFetch is one fetch of the key set, shared by every Key call that needs
+// it while it runs.
+type keyFetch struct {
+	done chan struct{}
+	keys map[string]*rsa.PublicKey
+	err  error
+}
+
+var _ KeySet = (*RemoteKeySet)(nil)
+
+// NewRemoteKeySet creates a key set served at url. Nothing is fetched until
+// the first Key call.
+func NewRemoteKeySet(url string, config RemoteKeySetConfig) *RemoteKeySet {
+	defaults := DefaultRemoteKeySetConfig()
+	if config.TTL <= 0 {
+		config.TTL = defaults.TTL
+	}
+	if config.MinRefreshInterval <= 0 {
+		config.MinRefreshInterval = defaults.MinRefreshInterval
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaults.RetryInterval
+	}
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: 5 * time.Second}
+	}
+	return &RemoteKeySet{url: url, config: config, now: time.Now}
+}
+
+// Key returns the key with the given ID, fetching the key set if the cache is
+// empty, expired, or missing the key. The fetch runs outside the lock and is
+// shared, so a burst of requests after expiry fetches the set once and
+// lookups that the cache can answer never wait for it.
+func (s *RemoteKeySet) Key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+	s.mu.Lock()
+	now := s.now()
+	key, ok := s.keys[kid]
+	switch {
+	case ok && now.Sub(s.fetchedAt) < s.config.TTL:
+		s.mu.Unlock()
+		return key, nil
+	case s.lastErr != nil && now.Sub(s.attemptedAt) < s.config.RetryInterval:
+		err := s.lastErr
+		s.mu.Unlock()
+		if ok {
+			return key, nil
+		}
+		return nil, err
+	case !ok && s.keys != nil && now.Sub(s.fetchedAt) < s.config.MinRefreshInterval:
+		s.mu.Unlock()
+		return nil, ErrUnknownKey
+	}
+
+	call := s.inflight
+	if call == nil {
+		call = &keyFetch{done: make(chan struct{})}
+		s.inflight = call
+		// The fetch outlives this caller if it gives up: the others waiting
+		// on it still want the result.
+		go s.refresh(context.WithoutCancel(ctx), call)
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		if ok {
+			return key, nil
+		}
+		return nil, ctx.Err()
+	}
+
+	if call.err != nil {
+		if ok {
+			return key, nil
+		}
+		return nil, call.err
+	}
+	if key, ok := call.keys[kid]; ok {
+		return key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+// refresh runs one fetch and records its outcome: the new keys on success,
+// the error and the time of the attempt either way.
+func (s *RemoteKeySet) refresh(ctx context.Context, call *keyFetch) {
+	call.keys, call.err = s.fetch(ctx)
+
+	s.mu.Lock()
+	s.attemptedAt = s.now()
+	s.lastErr = call.err
+	if call.err == nil {
+		s.keys = call.keys
+		s.fetchedAt = s.attemptedAt
+	}
+	s.inflight = nil
+	s.mu.Unlock()
+	close(call.done)
+}
+
+func (s *RemoteKeySet) fetch(ctx context.Context) (map[string]*rsa.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build key set request: %w", err)
+	}
+	resp, err := s.config.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch key set: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch key set: unexpected status %d", resp.StatusCode)
+	}
+	var set JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode key set: %w", err)
+	}
+	return parseKeys(set)
+}
+
+// parseKeys indexes a key set's RSA signing keys by key ID.
+func parseKeys(set JWKS) (map[string]*rsa.PublicKey, error) {
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.KeyType != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.KeyID, err)
+		}
+		keys[k.KeyID] = key
+	}
+	return keys, nil
+}
+
+func (k JWK) publicKey() (*rsa.PublicKey, error) {
+	n, err := base64.RawURLEncoding.DecodeString(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("decode modulus: %w", err)
+	}
+	e, err := base64.RawURLEncoding.DecodeString(k.E)
+	if err != nil {
+		return nil, fmt.Errorf("decode exponent: %w", err)
+	}
+	exponent := new(big.Int).SetBytes(e)
+	if !exponent.IsInt64() || exponent.Int64() > 1<<31-1 || exponent.Int64() < 3 {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+}
+
+// NewJWK encodes an RSA public key as a signing key with the given ID.
+func NewJWK(key *rsa.PublicKey, kid string) JWK {
+	return JWK{
+		KeyType:   "RSA",
+		KeyID:     kid,
+		Use:       "sig",
+		Algorithm: algRS256,
+		N:         base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
@@ -0,0 +1,241 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// algRS256 is the only signing algorithm accepted. Pinning it rules out
+// "none" and HMAC-with-the-public-key downgrades.
+const algRS256 = "RS256"
+
+// Reasons a token is rejected. Verify wraps them together with
+// errors.ErrUnauthorized.
+var (
+	ErrMalformedToken       = errors.New("malformed token")
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	ErrInvalidSignature     = errors.New("invalid signature")
+	ErrTokenExpired         = errors.New("token expired")
+	ErrTokenNotYetValid     = errors.New("token not yet valid")
+	ErrInvalidIssuer        = errors.New("invalid issuer")
+	ErrInvalidAudience      = errors.New("invalid audience")
+	ErrInvalidTokenUse      = errors.New("invalid token use")
+	ErrMissingSubject       = errors.New("missing subject")
+)
+
+// Config says which tokens a Verifier accepts.
+type Config struct {
+	// Issuer must equal the token's iss claim.
+	Issuer string
+	// Audiences lists the accepted app clients. A token is accepted when its
+	// aud claim (ID tokens) or client_id claim (Cognito access tokens) is
+	// one of them.
+	Audiences []string
+	// TokenUse, when set, must equal the token's token_use claim. Cognito
+	// sets "access" or "id"; set "access" so ID tokens are not accepted as
+	// API credentials.
+	TokenUse string
+	// Leeway absorbs clock skew when checking exp, nbf and iat.
+	Leeway time.Duration
+	// ResourceServer is the identifier of the API's Cognito resource server.
+	// Cognito prefixes custom scopes with it ("<identifier>/provision:write");
+	// the prefix is removed so principals carry the bare scope.
+	ResourceServer string
+}
+
+// Verifier checks RS256 tokens against a key set and a Config.
+type Verifier struct {
+	keys   KeySet
+	config Config
+	now    func() time.Time
+}
+
+var _ outbound.TokenVerifier = (*Verifier)(nil)
+
+// NewVerifier creates a Verifier. An issuer and at least one audience are
+// required, so a misconfigured verifier fails at startup rather than
+// accepting tokens meant for another application.
+func NewVerifier(keys KeySet, config Config) (*Verifier, error) {
+	if config.Issuer == "" {
+		return nil, errors.New("jwt verifier: issuer is required")
+	}
+	if len(config.Audiences) == 0 {
+		return nil, errors.New("jwt verifier: at least one audience is required")
+	}
+	return &Verifier{keys: keys, config: config, now: time.Now}, nil
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// claims are the registered claims plus the Cognito-specific ones the API
+// uses.
+type claims struct {
+	Issuer          string   `json:"iss"`
+	Subject         string   `json:"sub"`
+	Audience        audience `json:"aud"`
+	ExpiresAt       *int64   `json:"exp"`
+	NotBefore       *int64   `json:"nbf"`
+	IssuedAt        *int64   `json:"iat"`
+	ClientID        string   `json:"client_id"`
+	TokenUse        string   `json:"token_use"`
+	Scope           string   `json:"scope"`
+	Username        string   `json:"username"`
+	CognitoUsername string   `json:"cognito:username"`
+	Groups          []string `json:"cognito:groups"`
+}
+
+// audience is the aud claim, which may be a single string or an array.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*a = many
+	return nil
+}
+
+// Verify checks the token's signature and claims and returns its principal.
+func (v *Verifier) Verify(ctx context.Context, token string) (*model.Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, rejected(ErrMalformedToken)
+	}
+
+	var h header
+	if err := decodeSegment(parts[0], &h); err != nil {
+		return nil, rejected(ErrMalformedToken)
+	}
+	if h.Algorithm != algRS256 {
+		return nil, rejected(ErrUnsupportedAlgorithm)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, rejected(ErrMalformedToken)
+	}
+
+	key, err := v.keys.Key(ctx, h.KeyID)
+	if errors.Is(err, ErrUnknownKey) {
+		return nil, rejected(ErrUnknownKey)
+	}
+	if err != nil {
+		return nil, domainerrors.Internal("failed to load token signing keys", err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature); err != nil {
+		return nil, rejected(ErrInvalidSignature)
+	}
+
+	var c claims
+	if err := decodeSegment(parts[1], &c); err != nil {
+		return nil, rejected(ErrMalformedToken)
+	}
+	if err := v.checkClaims(c); err != nil {
+		return nil, rejected(err)
+	}
+
+	username := c.Username
+	if username == "" {
+		username = c.CognitoUsername
+	}
+	clientID := c.ClientID
+	if clientID == "" && len(c.Audience) > 0 {
+		clientID = c.Audience[0]
+	}
+	return &model.Principal{
+		Subject:   c.Subject,
+		Username:  username,
+		ClientID:  clientID,
+		Scopes:    v.scopes(c.Scope),
+		Groups:    c.Groups,
+		ExpiresAt: time.Unix(*c.ExpiresAt, 0).UTC(),
+		// Client-credentials tokens are issued to the app client itself: the
+		// subject is the client ID and there is no user.
+		Machine: username == "" && c.ClientID != "" && c.Subject == c.ClientID,
+	}, nil
+}
+
+// scopes splits the scope claim, removing the resource server prefix.
+func (v *Verifier) scopes(claim string) []string {
+	scopes := strings.Fields(claim)
+	if v.config.ResourceServer == "" {
+		return scopes
+	}
+	for i, scope := range scopes {
+		scopes[i] = strings.TrimPrefix(scope, v.config.ResourceServer+"/")
+	}
+	return scopes
+}
+
+func (v *Verifier) checkClaims(c claims) error {
+	now := v.now()
+	leeway := v.config.Leeway
+
+	if c.ExpiresAt == nil {
+		return ErrMalformedToken
+	}
+	if !now.Before(time.Unix(*c.ExpiresAt, 0).Add(leeway)) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != nil && now.Add(leeway).Before(time.Unix(*c.NotBefore, 0)) {
+		return ErrTokenNotYetValid
+	}
+	if c.IssuedAt != nil && now.Add(leeway).Before(time.Unix(*c.IssuedAt, 0)) {
+		return ErrTokenNotYetValid
+	}
+	if c.Issuer != v.config.Issuer {
+		return ErrInvalidIssuer
+	}
+	if v.config.TokenUse != "" && c.TokenUse != v.config.TokenUse {
+		return ErrInvalidTokenUse
+	}
+	if !slices.Contains(v.config.Audiences, c.ClientID) &&
+		!slices.ContainsFunc(c.Audience, func(aud string) bool { return slices.Contains(v.config.Audiences, aud) }) {
+		return ErrInvalidAudience
+	}
+	if c.Subject == "" {
+		return ErrMissingSubject
+	}
+	return nil
+}
+
+// rejected wraps the reason a token was refused as an unauthorized domain
+// error. Expiry gets its own code so clients know to refresh.
+func rejected(reason error) error {
+	code := domainerrors.ErrCodeInvalidToken
+	if errors.Is(reason, ErrTokenExpired) {
+		code = domainerrors.ErrCodeTokenExpired
+	}
+	return domainerrors.NewDomainError(code, "access token rejected",
+		fmt.Errorf("%w: %w", domainerrors.ErrUnauthorized, reason))
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,413 @@
+package jwt
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+)
+
+const (
+	testIssuer   = "https://cognito-idp.us-east-1.amazonaws.com/us-east-1_test"
+	testClientID = "client-123"
+)
+
+var (
+	keyOnce sync.Once
+	keyA    *rsa.PrivateKey
+	keyB    *rsa.PrivateKey
+)
+
+// testKeys generates the test key pairs once; RSA generation is slow enough
+// to matter across a dozen tests.
+func testKeys(t *testing.T) (*rsa.PrivateKey, *rsa.PrivateKey) {
+	t.Helper()
+	keyOnce.Do(func() {
+		keyA, _ = rsa.GenerateKey(rand.Reader, 2048)
+		keyB, _ = rsa.GenerateKey(rand.Reader, 2048)
+	})
+	return keyA, keyB
+}
+
+func accessClaims(now time.Time) map[string]any {
+	return map[string]any{
+		"iss":       testIssuer,
+		"sub":       "3f1c2b8e-user",
+		"client_id": testClientID,
+		"token_use": "access",
+		"scope":     "aws.cognito.signin.user.admin provision",
+		"username":  "rafael",
+		"exp":       now.Add(time.Hour).Unix(),
+		"iat":       now.Unix(),
+	}
+}
+
+func newTestVerifier(t *testing.T, signer *Signer) *Verifier {
+	t.Helper()
+	keys, err := NewStaticKeySet(signer.JWKS())
+	require.NoError(t, err)
+	v, err := NewVerifier(keys, Config{Issuer: testIssuer, Audiences: []string{testClientID}, TokenUse: "access"})
+	require.NoError(t, err)
+	return v
+}
+
+func sign(t *testing.T, signer *Signer, claims map[string]any) string {
+	t.Helper()
+	token, err := signer.Sign(claims)
+	require.NoError(t, err)
+	return token
+}
+
+func TestVerifier_AcceptsValidAccessToken(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	v := newTestVerifier(t, signer)
+	now := time.Now()
+
+	principal, err := v.Verify(context.Background(), sign(t, signer, accessClaims(now)))
+
+	require.NoError(t, err)
+	assert.Equal(t, "3f1c2b8e-user", principal.Subject)
+	assert.Equal(t, "rafael", principal.Username)
+	assert.Equal(t, testClientID, principal.ClientID)
+	assert.True(t, principal.HasScope("provision"))
+	assert.Equal(t, now.Add(time.Hour).Unix(), principal.ExpiresAt.Unix())
+}
+
+func TestVerifier_ClientCredentialsTokenIsMachine(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	keys, err := NewStaticKeySet(signer.JWKS())
+	require.NoError(t, err)
+	v, err := NewVerifier(keys, Config{
+		Issuer: testIssuer, Audiences: []string{testClientID, "svc-client"}, TokenUse: "access",
+		ResourceServer: "idp-api",
+	})
+	require.NoError(t, err)
+	now := time.Now()
+
+	principal, err := v.Verify(context.Background(), sign(t, signer, map[string]any{
+		"iss": testIssuer, "sub": "svc-client", "client_id": "svc-client", "token_use": "access",
+		"scope": "idp-api/provision:write idp-api/resources:read", "exp": now.Add(time.Hour).Unix(),
+	}))
+	require.NoError(t, err)
+	assert.True(t, principal.Machine)
+	assert.Equal(t, "svc-client", principal.Subject)
+	assert.Equal(t, []string{"provision:write", "resources:read"}, principal.Scopes)
+
+	user, err := v.Verify(context.Background(), sign(t, signer, accessClaims(now)))
+	require.NoError(t, err)
+	assert.False(t, user.Machine)
+}
+
+func TestVerifier_RejectsBadClaims(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	v := newTestVerifier(t, signer)
+	now := time.Now()
+
+	cases := map[string]struct {
+		mutate func(map[string]any)
+		want   error
+	}{
+		"expired":        {func(c map[string]any) { c["exp"] = now.Add(-time.Minute).Unix() }, ErrTokenExpired},
+		"missing exp":    {func(c map[string]any) { delete(c, "exp") }, ErrMalformedToken},
+		"not yet valid":  {func(c map[string]any) { c["nbf"] = now.Add(time.Hour).Unix() }, ErrTokenNotYetValid},
+		"wrong issuer":   {func(c map[string]any) { c["iss"] = "https://evil.example.com" }, ErrInvalidIssuer},
+		"wrong client":   {func(c map[string]any) { c["client_id"] = "someone-else" }, ErrInvalidAudience},
+		"id token":       {func(c map[string]any) { c["token_use"] = "id" }, ErrInvalidTokenUse},
+		"missing sub":    {func(c map[string]any) { delete(c, "sub") }, ErrMissingSubject},
+		"aud not string": {func(c map[string]any) { c["aud"] = 42 }, ErrMalformedToken},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			claims := accessClaims(now)
+			tc.mutate(claims)
+
+			_, err := v.Verify(context.Background(), sign(t, signer, claims))
+
+			assert.ErrorIs(t, err, tc.want)
+			assert.ErrorIs(t, err, domainerrors.ErrUnauthorized)
+		})
+	}
+}
+
+func TestVerifier_ExpiredTokenHasItsOwnCode(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	v := newTestVerifier(t, signer)
+	claims := accessClaims(time.Now())
+	claims["exp"] = time.Now().Add(-time.Minute).Unix()
+
+	_, err := v.Verify(context.Background(), sign(t, signer, claims))
+
+	var domainErr *domainerrors.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerrors.ErrCodeTokenExpired, domainErr.Code)
+}
+
+func TestVerifier_AcceptsAudienceClaim(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	keys, _ := NewStaticKeySet(signer.JWKS())
+	v, _ := NewVerifier(keys, Config{Issuer: testIssuer, Audiences: []string{testClientID}})
+	claims := accessClaims(time.Now())
+	delete(claims, "client_id")
+	claims["aud"] = []string{"other", testClientID}
+
+	principal, err := v.Verify(context.Background(), sign(t, signer, claims))
+
+	require.NoError(t, err)
+	assert.Equal(t, "other", principal.ClientID)
+}
+
+func TestVerifier_LeewayAbsorbsClockSkew(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	keys, _ := NewStaticKeySet(signer.JWKS())
+	v, _ := NewVerifier(keys, Config{Issuer: testIssuer, Audiences: []string{testClientID}, Leeway: time.Minute})
+	claims := accessClaims(time.Now())
+	claims["exp"] = time.Now().Add(-30 * time.Second).Unix()
+
+	_, err := v.Verify(context.Background(), sign(t, signer, claims))
+
+	assert.NoError(t, err)
+}
+
+func TestVerifier_RejectsForgedSignature(t *testing.T) {
+	keyA, keyB := testKeys(t)
+	v := newTestVerifier(t, NewSigner(keyA, "kid-a"))
+	// Signed with another key under the trusted key ID.
+	forged := sign(t, NewSigner(keyB, "kid-a"), accessClaims(time.Now()))
+
+	_, err := v.Verify(context.Background(), forged)
+
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifier_RejectsTamperedClaims(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	v := newTestVerifier(t, signer)
+	parts := strings.Split(sign(t, signer, accessClaims(time.Now())), ".")
+	claims := accessClaims(time.Now())
+	claims["sub"] = "someone-else"
+	payload, _ := json.Marshal(claims)
+	parts[1] = base64.RawURLEncoding.EncodeToString(payload)
+
+	_, err := v.Verify(context.Background(), strings.Join(parts, "."))
+
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+}
+
+func TestVerifier_RejectsOtherAlgorithms(t *testing.T) {
+	key, _ := testKeys(t)
+	v := newTestVerifier(t, NewSigner(key, "kid-a"))
+	h := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","kid":"kid-a"}`))
+	c, _ := json.Marshal(accessClaims(time.Now()))
+
+	_, err := v.Verify(context.Background(), h+"."+base64.RawURLEncoding.EncodeToString(c)+".")
+
+	assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+}
+
+func TestVerifier_RejectsMalformedToken(t *testing.T) {
+	key, _ := testKeys(t)
+	v := newTestVerifier(t, NewSigner(key, "kid-a"))
+
+	for _, token := range []string{"", "abc", "a.b.c", "a.b.c.d"} {
+		_, err := v.Verify(context.Background(), token)
+		assert.ErrorIs(t, err, domainerrors.ErrUnauthorized, token)
+	}
+}
+
+func TestNewVerifier_RequiresIssuerAndAudience(t *testing.T) {
+	_, err := NewVerifier(&StaticKeySet{}, Config{Audiences: []string{testClientID}})
+	assert.Error(t, err)
+	_, err = NewVerifier(&StaticKeySet{}, Config{Issuer: testIssuer})
+	assert.Error(t, err)
+}
+
+// jwksServer serves the current key set and counts fetches.
+func jwksServer(t *testing.T, set *atomic.Pointer[JWKS], fetches *atomic.Int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		_ = json.NewEncoder(w).Encode(set.Load())
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRemoteKeySet_CachesAndPicksUpRotation(t *testing.T) {
+	keyA, keyB := testKeys(t)
+	signerA, signerB := NewSigner(keyA, "kid-a"), NewSigner(keyB, "kid-b")
+	var set atomic.Pointer[JWKS]
+	initial := signerA.JWKS()
+	set.Store(&initial)
+	var fetches atomic.Int32
+	srv := jwksServer(t, &set, &fetches)
+
+	keys := NewRemoteKeySet(srv.URL, RemoteKeySetConfig{TTL: time.Hour, MinRefreshInterval: time.Minute})
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	v, _ := NewVerifier(keys, Config{Issuer: testIssuer, Audiences: []string{testClientID}})
+
+	for i := 0; i < 3; i++ {
+		_, err := v.Verify(context.Background(), sign(t, signerA, accessClaims(time.Now())))
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), fetches.Load(), "the key set is cached")
+
+	// The issuer rotates to kid-b. Within the refresh interval an unknown
+	// key is rejected without a refetch; after it, the set is refetched.
+	rotated := JWKS{Keys: append(signerA.JWKS().Keys, signerB.JWKS().Keys...)}
+	set.Store(&rotated)
+	_, err := v.Verify(context.Background(), sign(t, signerB, accessClaims(time.Now())))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	now = now.Add(2 * time.Minute)
+	_, err = v.Verify(context.Background(), sign(t, signerB, accessClaims(time.Now())))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestRemoteKeySet_ServesStaleKeysWhenRefreshFails(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	var set atomic.Pointer[JWKS]
+	initial := signer.JWKS()
+	set.Store(&initial)
+	var fetches atomic.Int32
+	srv := jwksServer(t, &set, &fetches)
+
+	keys := NewRemoteKeySet(srv.URL, RemoteKeySetConfig{TTL: time.Minute})
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	_, err := keys.Key(context.Background(), "kid-a")
+	require.NoError(t, err)
+
+	srv.Close()
+	now = now.Add(time.Hour)
+	got, err := keys.Key(context.Background(), "kid-a")
+
+	assert.NoError(t, err)
+	assert.Equal(t, key.PublicKey.N, got.N)
+}
+
+// While one fetch is running, lookups the cache can answer return at once and
+// lookups that need the fetch wait for it instead of starting their own.
+func TestRemoteKeySet_FetchesOnceWithoutHoldingTheCache(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	set := signer.JWKS()
+	var fetches atomic.Int32
+	started, release := make(chan struct{}, 8), make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fetches.Add(1) > 1 {
+			started <- struct{}{}
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+
+	keys := NewRemoteKeySet(srv.URL, RemoteKeySetConfig{TTL: time.Hour, MinRefreshInterval: time.Minute})
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	_, err := keys.Key(context.Background(), "kid-a")
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = keys.Key(context.Background(), "kid-unknown")
+		}()
+	}
+	<-started
+
+	got, err := keys.Key(context.Background(), "kid-a")
+	require.NoError(t, err, "a cached key is served while the refetch is blocked")
+	assert.Equal(t, key.PublicKey.N, got.N)
+
+	close(release)
+	wg.Wait()
+	for _, err := range errs {
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	}
+	assert.Equal(t, int32(2), fetches.Load(), "the waiting lookups share one refetch")
+}
+
+// During an outage the key set is refetched once per retry interval, not on
+// every request.
+func TestRemoteKeySet_BacksOffAfterAFailedRefresh(t *testing.T) {
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	set := signer.JWKS()
+	var fetches atomic.Int32
+	var down atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		if down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(set)
+	}))
+	t.Cleanup(srv.Close)
+
+	keys := NewRemoteKeySet(srv.URL, RemoteKeySetConfig{TTL: time.Minute, RetryInterval: 10 * time.Second})
+	now := time.Now()
+	keys.now = func() time.Time { return now }
+	_, err := keys.Key(context.Background(), "kid-a")
+	require.NoError(t, err)
+
+	down.Store(true)
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		_, err := keys.Key(context.Background(), "kid-a")
+		assert.NoError(t, err, "stale keys are served during the outage")
+	}
+	_, err = keys.Key(context.Background(), "kid-unknown")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrUnknownKey, "an unknown key during an outage is the outage's error")
+	assert.Equal(t, int32(2), fetches.Load(), "one failed refetch, then back off")
+
+	down.Store(false)
+	now = now.Add(10 * time.Second)
+	_, err = keys.Key(context.Background(), "kid-a")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), fetches.Load(), "the set is fetched again after the retry interval")
+}
+
+func TestRemoteKeySet_UnreachableIsNotUnauthorized(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+	keys := NewRemoteKeySet(srv.URL, RemoteKeySetConfig{})
+	key, _ := testKeys(t)
+	signer := NewSigner(key, "kid-a")
+	v, _ := NewVerifier(keys, Config{Issuer: testIssuer, Audiences: []string{testClientID}})
+
+	_, err := v.Verify(context.Background(), sign(t, signer, accessClaims(time.Now())))
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, domainerrors.ErrUnauthorized, "an outage must not read as bad credentials")
+}
@@ -0,0 +1,100 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// meterName scopes instruments created by this package, following the OTel
+// convention of using the instrumented package's import path.
+const meterName = "github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/kafka"
+
+// publisherMetrics records every publish once its outcome is known, on the
+// OTel messaging semconv instruments: messaging.client.operation.duration
+// (seconds from handing the message to the writer until the broker
+// acknowledged it or the writer gave up) and messaging.client.sent.messages.
+// Failures carry error.type, so the failure rate is the share of
+// messaging_client_sent_messages_total with an error_type label.
+type publisherMetrics struct {
+	duration metric.Float64Histogram
+	sent     metric.Int64Counter
+	attrs    []attribute.KeyValue
+}
+
+func newPublisherMetrics(topic string) publisherMetrics {
+	meter := otel.Meter(meterName)
+	m := publisherMetrics{
+		attrs: []attribute.KeyValue{
+			semconv.MessagingSystemKafka,
+			semconv.MessagingOperationTypePublish,
+			semconv.MessagingDestinationName(topic),
+		},
+	}
+
+	// Instrument creation only fails on an invalid name; report through the
+	// OTel error handler and publish uninstrumented rather than not at all.
+	var err error
+	if m.duration, err = meter.Float64Histogram(
+		"messaging.client.operation.duration",
+		metric.WithDescription("Duration of Kafka publishes, until acknowledged or given up"),
+		metric.WithUnit("s"),
+	); err != nil {
+		otel.Handle(err)
+		m.duration = noop.Float64Histogram{}
+	}
+	if m.sent, err = meter.Int64Counter(
+		"messaging.client.sent.messages",
+		metric.WithDescription("Number of messages published to Kafka, by outcome"),
+		metric.WithUnit("{message}"),
+	); err != nil {
+		otel.Handle(err)
+		m.sent = noop.Int64Counter{}
+	}
+	return m
+}
+
+// record reports the outcome of one publish that started at start.
+func (m publisherMetrics) record(ctx context.Context, start time.Time, err error) {
+	attrs := m.attrs
+	if err != nil {
+		attrs = append(attrs[:len(attrs):len(attrs)], semconv.ErrorTypeKey.String(errorType(err)))
+	}
+	opt := metric.WithAttributes(attrs...)
+	m.duration.Record(ctx, time.Since(start).Seconds(), opt)
+	m.sent.Add(ctx, 1, opt)
+}
+
+// errorType is a low-cardinality name for a publish failure: the Kafka error
+// code's title when the broker returned one.
+func errorType(err error) string {
+	// A synchronous write reports one error per message written.
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for _, e := range writeErrs {
+			if e != nil {
+				err = e
+				break
+			}
+		}
+	}
+
+	var kafkaErr kafka.Error
+	switch {
+	case errors.As(err, &kafkaErr):
+		return kafkaErr.Title()
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "_OTHER"
+	}
+}
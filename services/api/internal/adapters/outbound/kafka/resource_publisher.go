@@ -5,75 +5,202 @@ package kafka
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/otel"
 
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
 )
 
+// WriterConfig tunes how the publisher batches and acknowledges writes. Empty
+// fields take the writer's defaults, except RequiredAcks, which defaults to
+// "all" so a synchronous Publish means a replicated write.
+type WriterConfig struct {
+	// Async makes Publish return once the message is queued for the next
+	// batch instead of once the broker has acknowledged it. PublishAsync
+	// reports the outcome of each message in either mode.
+	Async bool
+	// BatchSize and BatchBytes cap a batch; Linger is how long a batch
+	// waits to fill before it is sent anyway.
+	BatchSize  int
+	BatchBytes int64
+	Linger     time.Duration
+	// Compression is none, gzip, snappy, lz4 or zstd.
+	Compression string
+	// RequiredAcks is none, one or all.
+	RequiredAcks string
+}
+
 // ResourcePublisher publishes resource provisioning requests to a Kafka topic.
 type ResourcePublisher struct {
-	writer *kafka.Writer
+	writer     *kafka.Writer
+	serializer envelope.Serializer
+	metrics    publisherMetrics
 }
 
-// Ensure ResourcePublisher implements the ResourcePublisher interface.
-var _ outbound.ResourcePublisher = (*ResourcePublisher)(nil)
+// Ensure ResourcePublisher implements the AsyncResourcePublisher interface.
+var _ outbound.AsyncResourcePublisher = (*ResourcePublisher)(nil)
 
 // NewResourcePublisher creates a publisher writing to the given topic on the
-// given brokers. AllowAutoTopicCreation makes the topic appear on first write,
-// which suits the local Kafka stack.
-func NewResourcePublisher(brokers []string, topic string) *ResourcePublisher {
-	return &ResourcePublisher{
-		writer: &kafka.Writer{
-			Addr:                   kafka.TCP(brokers...),
-			Topic:                  topic,
-			Balancer:               &kafka.Hash{},
-			AllowAutoTopicCreation: true,
-		},
+// given brokers, encoding events with serializer. AllowAutoTopicCreation makes
+// the topic appear on first write, which suits the local Kafka stack.
+func NewResourcePublisher(brokers []string, topic string, serializer envelope.Serializer, cfg WriterConfig) (*ResourcePublisher, error) {
+	var compression kafka.Compression
+	if cfg.Compression != "" {
+		if err := compression.UnmarshalText([]byte(cfg.Compression)); err != nil {
+			return nil, err
+		}
+	}
+	acks := kafka.RequireAll
+	if cfg.RequiredAcks != "" {
+		if err := acks.UnmarshalText([]byte(cfg.RequiredAcks)); err != nil {
+			return nil, err
+		}
 	}
+
+	p := &ResourcePublisher{
+		serializer: serializer,
+		metrics:    newPublisherMetrics(topic),
+	}
+	p.writer = &kafka.Writer{
+		Addr:                   kafka.TCP(brokers...),
+		Topic:                  topic,
+		Balancer:               &kafka.Hash{},
+		AllowAutoTopicCreation: true,
+		BatchSize:              cfg.BatchSize,
+		BatchBytes:             cfg.BatchBytes,
+		BatchTimeout:           cfg.Linger,
+		Compression:            compression,
+		RequiredAcks:           acks,
+		Async:                  cfg.Async,
+	}
+	if cfg.Async {
+		p.writer.Completion = p.delivered
+	}
+	return p, nil
 }
 
-// Publish serializes the resource and writes it to Kafka, keyed by resource ID
-// so all messages for a resource land on the same partition (ordering).
+// Publish wraps the resource in a provisioning request event and writes it to
+// Kafka, keyed by resource ID so all messages for a resource land on the same
+// partition (ordering). In sync mode it returns once the broker has
+// acknowledged the write; in async mode once the message is queued, and a
+// failed delivery shows only in the publish metrics.
 func (p *ResourcePublisher) Publish(ctx context.Context, resource model.Resource) error {
-	body, err := json.Marshal(resource)
+	message, err := p.message(ctx, resource)
+	if err != nil {
+		return err
+	}
+	if p.writer.Async {
+		return p.enqueue(ctx, message, nil)
+	}
+
+	start := time.Now()
+	err = p.writer.WriteMessages(ctx, message)
+	p.metrics.record(ctx, start, err)
+	return publishError(resource.ID, err)
+}
+
+// PublishAsync publishes like Publish and calls done once the broker has
+// acknowledged the message or the writer has given up on it. In sync mode
+// that is before PublishAsync returns.
+func (p *ResourcePublisher) PublishAsync(ctx context.Context, resource model.Resource, done func(error)) {
+	if !p.writer.Async {
+		done(p.Publish(ctx, resource))
+		return
+	}
+	message, err := p.message(ctx, resource)
+	if err == nil {
+		err = p.enqueue(ctx, message, done)
+	}
 	if err != nil {
-		return errors.NewDomainError(
+		done(err)
+	}
+}
+
+// message wraps the resource in an event and builds the Kafka message
+// carrying it.
+func (p *ResourcePublisher) message(ctx context.Context, resource model.Resource) (kafka.Message, error) {
+	event, body, err := envelope.ResourceProvisionRequested(ctx, p.serializer, resource)
+	if err != nil {
+		return kafka.Message{}, errors.NewDomainError(
 			errors.ErrCodeQueueError,
 			"failed to serialize resource for publishing",
 			err,
 		)
 	}
 
-	// Inject the active trace context (W3C traceparent/tracestate/baggage) into
-	// the message headers so the provisioner can continue this trace: its
+	// Stamp the event's ID for the provisioner's inbox and its content type,
+	// and inject the active trace context (W3C traceparent/tracestate/baggage)
+	// into the message headers so the provisioner can continue this trace: its
 	// ProcessMessage span becomes a child of this producer span and both
-	// services' logs share one trace_id. No-op when tracing is disabled.
-	var headers []kafka.Header
+	// services' logs share one trace_id. Injection is a no-op when tracing is
+	// disabled.
+	headers := []kafka.Header{
+		{Key: outbound.MessageIDAttribute, Value: []byte(event.ID)},
+		{Key: envelope.ContentTypeHeader, Value: []byte(p.serializer.ContentType())},
+	}
 	otel.GetTextMapPropagator().Inject(ctx, kafkaHeaderCarrier{headers: &headers})
 
-	err = p.writer.WriteMessages(ctx, kafka.Message{
+	return kafka.Message{
 		Key:     []byte(resource.ID),
 		Value:   body,
 		Headers: headers,
-	})
-	if err != nil {
-		return errors.NewDomainError(
-			errors.ErrCodeQueueError,
-			fmt.Sprintf("failed to publish resource %s to kafka", resource.ID),
-			err,
-		)
-	}
+	}, nil
+}
+
+// delivery travels with a queued message as its WriterData, so the writer's
+// completion callback can time it and report its outcome.
+type delivery struct {
+	ctx   context.Context
+	start time.Time
+	done  func(error)
+}
 
+// enqueue hands a message to the async writer. A non-nil error means it was
+// never queued, and done will not be called.
+func (p *ResourcePublisher) enqueue(ctx context.Context, message kafka.Message, done func(error)) error {
+	start := time.Now()
+	message.WriterData = &delivery{ctx: ctx, start: start, done: done}
+	if err := p.writer.WriteMessages(ctx, message); err != nil {
+		p.metrics.record(ctx, start, err)
+		return publishError(string(message.Key), err)
+	}
 	return nil
 }
 
-// Close flushes and releases the underlying Kafka writer.
+// delivered is the async writer's completion callback, called with each batch
+// once the broker has acknowledged it or the writer has given up on it.
+func (p *ResourcePublisher) delivered(messages []kafka.Message, err error) {
+	for _, message := range messages {
+		d, ok := message.WriterData.(*delivery)
+		if !ok {
+			continue
+		}
+		p.metrics.record(d.ctx, d.start, err)
+		if d.done != nil {
+			d.done(publishError(string(message.Key), err))
+		}
+	}
+}
+
+func publishError(resourceID string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return errors.NewDomainError(
+		errors.ErrCodeQueueError,
+		fmt.Sprintf("failed to publish resource %s to kafka", resourceID),
+		err,
+	)
+}
+
+// Close flushes and releases the underlying Kafka writer. In async mode it
+// waits for queued messages to be delivered.
 func (p *ResourcePublisher) Close() error {
 	return p.writer.Close()
 }
@@ -2,17 +2,30 @@ package kafka
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/segmentio/kafka-go"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/telemetry"
 )
 
+func newPublisher(t *testing.T, brokers []string, cfg WriterConfig) *ResourcePublisher {
+	t.Helper()
+	p, err := NewResourcePublisher(brokers, "test-topic", envelope.JSONSerializer{}, cfg)
+	require.NoError(t, err)
+	return p
+}
+
 func TestPublish_UnreachableBroker_ReturnsError(t *testing.T) {
-	p := NewResourcePublisher([]string{"127.0.0.1:1"}, "test-topic")
+	p := newPublisher(t, []string{"127.0.0.1:1"}, WriterConfig{})
 	defer func() { _ = p.Close() }()
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -22,7 +35,84 @@ func TestPublish_UnreachableBroker_ReturnsError(t *testing.T) {
 	require.Error(t, err, "publishing to an unreachable broker must error")
 }
 
+func TestPublishAsync_UnreachableBroker_ReportsFailure(t *testing.T) {
+	p := newPublisher(t, []string{"127.0.0.1:1"}, WriterConfig{Async: true, Linger: time.Millisecond})
+	defer func() { _ = p.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	p.PublishAsync(ctx, model.Resource{ID: "vm-1", ResourceType: "VM"}, func(err error) { done <- err })
+
+	select {
+	case err := <-done:
+		assert.Error(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("PublishAsync never reported the delivery")
+	}
+}
+
+func TestNewResourcePublisher_RejectsUnknownSettings(t *testing.T) {
+	_, err := NewResourcePublisher(nil, "test-topic", envelope.JSONSerializer{}, WriterConfig{Compression: "brotli"})
+	assert.Error(t, err)
+	_, err = NewResourcePublisher(nil, "test-topic", envelope.JSONSerializer{}, WriterConfig{RequiredAcks: "two"})
+	assert.Error(t, err)
+}
+
+func TestNewResourcePublisher_DefaultsToAllAcks(t *testing.T) {
+	p := newPublisher(t, []string{"127.0.0.1:9092"}, WriterConfig{Compression: "zstd"})
+	assert.Equal(t, kafka.RequireAll, p.writer.RequiredAcks)
+	assert.Equal(t, kafka.Zstd, p.writer.Compression)
+	assert.False(t, p.writer.Async)
+}
+
+// The writer's completion callback reports each message's outcome to its
+// caller and to the publish metrics.
+func TestDelivered_ReportsOutcomeAndMetrics(t *testing.T) {
+	metrics, err := telemetry.NewMetrics(telemetry.MetricsConfig{ServiceName: "test-service", Environment: "test"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = metrics.Shutdown(t.Context()) })
+	p := newPublisher(t, []string{"127.0.0.1:9092"}, WriterConfig{Async: true})
+
+	var outcomes []error
+	message := func(id string) kafka.Message {
+		return kafka.Message{Key: []byte(id), WriterData: &delivery{
+			ctx:   context.Background(),
+			start: time.Now(),
+			done:  func(err error) { outcomes = append(outcomes, err) },
+		}}
+	}
+	p.delivered([]kafka.Message{message("vm-1"), message("vm-2")}, nil)
+	p.delivered([]kafka.Message{message("vm-3")}, kafka.NotLeaderForPartition)
+
+	require.Len(t, outcomes, 3)
+	assert.NoError(t, outcomes[0])
+	assert.NoError(t, outcomes[1])
+	require.Error(t, outcomes[2])
+	assert.Contains(t, outcomes[2].Error(), "vm-3")
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	assert.Contains(t, body, "messaging_client_operation_duration_seconds_count")
+	var sent, failed string
+	for _, line := range strings.Split(body, "\n") {
+		if !strings.HasPrefix(line, "messaging_client_sent_messages_total{") {
+			continue
+		}
+		if strings.Contains(line, "error_type=") {
+			failed = line
+		} else {
+			sent = line
+		}
+	}
+	assert.True(t, strings.HasSuffix(sent, " 2"), "expected 2 delivered messages, got: %s", sent)
+	assert.Contains(t, failed, `error_type="Not Leader For Partition"`)
+	assert.True(t, strings.HasSuffix(failed, " 1"), "expected 1 failed message, got: %s", failed)
+}
+
 func TestClose_IsSafe(t *testing.T) {
-	p := NewResourcePublisher([]string{"127.0.0.1:9092"}, "test-topic")
+	p := newPublisher(t, []string{"127.0.0.1:9092"}, WriterConfig{})
 	assert.NoError(t, p.Close())
 }
@@ -0,0 +1,561 @@
+// Package localauth is an in-process identity provider for local
+// development. It stands in for Cognito so the /v1/auth routes and
+// authenticated routes work end to end without AWS: users and refresh tokens
+// live in memory, access and ID tokens are signed with a local key, and
+// confirmation and reset codes are written to the log instead of emailed.
+// It is not meant for deployed environments; everything is lost on restart.
+package localauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/jwt"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// DefaultClientID is the client_id stamped on access tokens when Config
+// leaves it empty.
+const DefaultClientID = "local"
+
+// Config controls the tokens the provider issues. Zero durations take the
+// Cognito defaults.
+type Config struct {
+	// Issuer is the iss claim of every token (required).
+	Issuer string
+	// ClientID is the app client the tokens are issued to.
+	ClientID string
+	// AccessTokenTTL bounds access and ID tokens (default 1h).
+	AccessTokenTTL time.Duration
+	// RefreshTokenTTL bounds refresh tokens (default 30 days).
+	RefreshTokenTTL time.Duration
+	// CodeTTL bounds confirmation and password reset codes (default 24h).
+	CodeTTL time.Duration
+	// ClockLeeway is allowed when verifying the provider's own access tokens.
+	ClockLeeway time.Duration
+	// BcryptCost is the password hashing cost (default bcrypt.DefaultCost).
+	BcryptCost int
+	// Clients maps the IDs of service clients that may use the
+	// client-credentials grant to their secrets.
+	Clients map[string]string
+	// Scopes are the scopes service clients may request (default
+	// model.APIScopes).
+	Scopes []string
+}
+
+// AuthProvider implements outbound.AuthProvider in memory. Error codes match
+// the Cognito adapter's, so clients see the same behaviour locally.
+type AuthProvider struct {
+	cfg      Config
+	signer   *jwt.Signer
+	verifier *jwt.Verifier
+	log      logger.Logger
+
+	// dummyHash is compared against when signing in to an unknown account, so
+	// sign in takes as long whether or not the email is registered.
+	dummyHash []byte
+
+	mu       sync.Mutex
+	users    map[string]*user
+	sessions map[string]session
+
+	now     func() time.Time
+	deliver func(ctx context.Context, email, purpose, code string)
+}
+
+// Ensure AuthProvider implements the AuthProvider interface.
+var _ outbound.AuthProvider = (*AuthProvider)(nil)
+
+type user struct {
+	sub          string
+	email        string
+	passwordHash []byte
+	confirmed    bool
+	confirmCode  *code
+	resetCode    *code
+}
+
+type code struct {
+	value     string
+	expiresAt time.Time
+}
+
+// session is an issued refresh token, keyed by its SHA-256 so a dump of the
+// map does not hand out usable tokens.
+type session struct {
+	sub       string
+	expiresAt time.Time
+}
+
+// Code purposes, as logged.
+const (
+	purposeSignUp        = "sign_up"
+	purposePasswordReset = "password_reset"
+)
+
+// NewAuthProvider creates an AuthProvider that signs tokens with signer.
+func NewAuthProvider(signer *jwt.Signer, cfg Config, log logger.Logger) (*AuthProvider, error) {
+	if cfg.ClientID == "" {
+		cfg.ClientID = DefaultClientID
+	}
+	if cfg.AccessTokenTTL == 0 {
+		cfg.AccessTokenTTL = time.Hour
+	}
+	if cfg.RefreshTokenTTL == 0 {
+		cfg.RefreshTokenTTL = 30 * 24 * time.Hour
+	}
+	if cfg.CodeTTL == 0 {
+		cfg.CodeTTL = 24 * time.Hour
+	}
+	if cfg.BcryptCost == 0 {
+		cfg.BcryptCost = bcrypt.DefaultCost
+	}
+	if cfg.Scopes == nil {
+		cfg.Scopes = model.APIScopes
+	}
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+
+	keys, err := jwt.NewStaticKeySet(signer.JWKS())
+	if err != nil {
+		return nil, err
+	}
+	verifier, err := jwt.NewVerifier(keys, jwt.Config{
+		Issuer:    cfg.Issuer,
+		Audiences: append([]string{cfg.ClientID}, slices.Sorted(maps.Keys(cfg.Clients))...),
+		TokenUse:  "access",
+		Leeway:    cfg.ClockLeeway,
+	})
+	if err != nil {
+		return nil, err
+	}
+	dummyHash, err := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), cfg.BcryptCost)
+	if err != nil {
+		return nil, fmt.Errorf("hash placeholder password: %w", err)
+	}
+
+	p := &AuthProvider{
+		cfg:       cfg,
+		signer:    signer,
+		verifier:  verifier,
+		log:       log,
+		dummyHash: dummyHash,
+		users:     make(map[string]*user),
+		sessions:  make(map[string]session),
+		now:       time.Now,
+	}
+	p.deliver = p.logCode
+	return p, nil
+}
+
+// Verifier returns a verifier that accepts this provider's access tokens.
+func (p *AuthProvider) Verifier() outbound.TokenVerifier {
+	return p.verifier
+}
+
+// SignUp registers an unconfirmed user and issues a confirmation code.
+func (p *AuthProvider) SignUp(ctx context.Context, email, password string) error {
+	if err := checkPasswordPolicy(password); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), p.cfg.BcryptCost)
+	if err != nil {
+		return errors.Internal("failed to register user", err)
+	}
+	c, err := p.newCode()
+	if err != nil {
+		return errors.Internal("failed to register user", err)
+	}
+
+	p.mu.Lock()
+	key := normalize(email)
+	if _, ok := p.users[key]; ok {
+		p.mu.Unlock()
+		return fail(errors.ErrCodeUserAlreadyExists, "an account with this email already exists", errors.ErrAlreadyExists)
+	}
+	p.users[key] = &user{
+		sub:          uuid.NewString(),
+		email:        key,
+		passwordHash: hash,
+		confirmCode:  c,
+	}
+	p.mu.Unlock()
+
+	p.deliver(ctx, key, purposeSignUp, c.value)
+	return nil
+}
+
+// SignIn checks the password and issues access, ID and refresh tokens.
+func (p *AuthProvider) SignIn(ctx context.Context, email, password string) (*model.AuthResponse, error) {
+	p.mu.Lock()
+	u, ok := p.users[normalize(email)]
+	var snapshot user
+	if ok {
+		snapshot = *u
+	}
+	p.mu.Unlock()
+
+	hash := p.dummyHash
+	if ok {
+		hash = snapshot.passwordHash
+	}
+	// An unknown user reads as a wrong password, so sign in does not reveal
+	// which emails have accounts.
+	if err := bcrypt.CompareHashAndPassword(hash, []byte(password)); err != nil || !ok {
+		return nil, fail(errors.ErrCodeInvalidCredentials, "invalid credentials", errors.ErrUnauthorized)
+	}
+	if !snapshot.confirmed {
+		return nil, fail(errors.ErrCodeEmailNotVerified, "email address has not been confirmed", errors.ErrForbidden)
+	}
+
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, errors.Internal("authentication failed", err)
+	}
+	resp, err := p.issue(snapshot)
+	if err != nil {
+		return nil, errors.Internal("authentication failed", err)
+	}
+
+	p.mu.Lock()
+	p.sessions[digest(refreshToken)] = session{sub: snapshot.sub, expiresAt: p.now().Add(p.cfg.RefreshTokenTTL)}
+	p.mu.Unlock()
+
+	resp.RefreshToken = refreshToken
+	return resp, nil
+}
+
+// ConfirmSignUp confirms a user's email with the code from SignUp.
+func (p *AuthProvider) ConfirmSignUp(ctx context.Context, email, confirmationCode string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Unknown and already-confirmed users read as a bad code.
+	u, ok := p.users[normalize(email)]
+	if !ok || u.confirmed || !u.confirmCode.matches(confirmationCode, p.now()) {
+		return invalidCode()
+	}
+	u.confirmed = true
+	u.confirmCode = nil
+	return nil
+}
+
+// RefreshTokens exchanges a refresh token for new access and ID tokens. Like
+// Cognito, the refresh token is not rotated, so none is returned.
+func (p *AuthProvider) RefreshTokens(ctx context.Context, refreshToken string) (*model.AuthResponse, error) {
+	p.mu.Lock()
+	s, ok := p.sessions[digest(refreshToken)]
+	var snapshot user
+	if ok {
+		u := p.userBySub(s.sub)
+		ok = u != nil && p.now().Before(s.expiresAt)
+		if ok {
+			snapshot = *u
+		}
+	}
+	p.mu.Unlock()
+
+	if !ok {
+		return nil, fail(errors.ErrCodeInvalidToken, "refresh token is invalid, expired or revoked", errors.ErrUnauthorized)
+	}
+	resp, err := p.issue(snapshot)
+	if err != nil {
+		return nil, errors.Internal("failed to refresh tokens", err)
+	}
+	return resp, nil
+}
+
+// GlobalSignOut revokes every refresh token issued to the access token's
+// user. Access and ID tokens already issued stay valid until they expire.
+func (p *AuthProvider) GlobalSignOut(ctx context.Context, accessToken string) error {
+	principal, err := p.verifier.Verify(ctx, accessToken)
+	if err != nil {
+		if stderrors.Is(err, errors.ErrUnauthorized) {
+			return fail(errors.ErrCodeInvalidToken, "access token is invalid, expired or revoked", errors.ErrUnauthorized)
+		}
+		return errors.Internal("failed to sign out", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, s := range p.sessions {
+		if s.sub == principal.Subject {
+			delete(p.sessions, key)
+		}
+	}
+	return nil
+}
+
+// ForgotPassword issues a password reset code.
+func (p *AuthProvider) ForgotPassword(ctx context.Context, email string) error {
+	c, err := p.newCode()
+	if err != nil {
+		return errors.Internal("failed to send password reset code", err)
+	}
+
+	p.mu.Lock()
+	u, ok := p.users[normalize(email)]
+	switch {
+	case !ok:
+		p.mu.Unlock()
+		return fail(errors.ErrCodeUserNotFound, "user not found", errors.ErrNotFound)
+	case !u.confirmed:
+		p.mu.Unlock()
+		return fail(errors.ErrCodeValidationFailed, "cannot reset password for the user as there is no verified email", errors.ErrInvalidInput)
+	}
+	u.resetCode = c
+	p.mu.Unlock()
+
+	p.deliver(ctx, u.email, purposePasswordReset, c.value)
+	return nil
+}
+
+// ConfirmForgotPassword sets a new password using the code from
+// ForgotPassword.
+func (p *AuthProvider) ConfirmForgotPassword(ctx context.Context, email, confirmationCode, newPassword string) error {
+	if err := checkPasswordPolicy(newPassword); err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), p.cfg.BcryptCost)
+	if err != nil {
+		return errors.Internal("failed to reset password", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// An unknown user reads as a bad code, so the endpoint does not reveal
+	// which emails have accounts.
+	u, ok := p.users[normalize(email)]
+	if !ok || !u.resetCode.matches(confirmationCode, p.now()) {
+		return invalidCode()
+	}
+	u.passwordHash = hash
+	u.resetCode = nil
+	return nil
+}
+
+// ResendConfirmationCode issues a new sign-up confirmation code, replacing
+// the previous one.
+func (p *AuthProvider) ResendConfirmationCode(ctx context.Context, email string) error {
+	c, err := p.newCode()
+	if err != nil {
+		return errors.Internal("failed to resend confirmation code", err)
+	}
+
+	p.mu.Lock()
+	u, ok := p.users[normalize(email)]
+	switch {
+	case !ok:
+		p.mu.Unlock()
+		return fail(errors.ErrCodeUserNotFound, "user not found", errors.ErrNotFound)
+	case u.confirmed:
+		p.mu.Unlock()
+		return fail(errors.ErrCodeValidationFailed, "user is already confirmed", errors.ErrInvalidInput)
+	}
+	u.confirmCode = c
+	p.mu.Unlock()
+
+	p.deliver(ctx, u.email, purposeSignUp, c.value)
+	return nil
+}
+
+// ClientCredentials issues an access token to a service client, limited to
+// the requested scopes or, when none are requested, to every scope the
+// provider allows. Like Cognito's, the token has no username and its subject
+// is the client ID.
+func (p *AuthProvider) ClientCredentials(ctx context.Context, clientID, clientSecret string, scopes []string) (*model.AuthResponse, error) {
+	secret, ok := p.cfg.Clients[clientID]
+	// Compare against the client ID when it is unknown, so a miss takes as
+	// long as a wrong secret.
+	if !ok {
+		secret = clientID
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(clientSecret)) != 1 || !ok {
+		return nil, fail(errors.ErrCodeInvalidCredentials, "invalid client credentials", errors.ErrUnauthorized)
+	}
+	if len(scopes) == 0 {
+		scopes = p.cfg.Scopes
+	}
+	for _, scope := range scopes {
+		if !slices.Contains(p.cfg.Scopes, scope) {
+			return nil, fail(errors.ErrCodeValidationFailed, fmt.Sprintf("invalid client credentials request: unknown scope %q", scope), errors.ErrInvalidInput)
+		}
+	}
+
+	now := p.now()
+	accessToken, err := p.signer.Sign(map[string]any{
+		"iss":       p.cfg.Issuer,
+		"sub":       clientID,
+		"client_id": clientID,
+		"token_use": "access",
+		"scope":     strings.Join(scopes, " "),
+		"iat":       now.Unix(),
+		"exp":       now.Add(p.cfg.AccessTokenTTL).Unix(),
+		"jti":       uuid.NewString(),
+	})
+	if err != nil {
+		return nil, errors.Internal("failed to issue client token", err)
+	}
+	return &model.AuthResponse{
+		AccessToken: accessToken,
+		ExpiresIn:   int32(p.cfg.AccessTokenTTL / time.Second),
+		TokenType:   "Bearer",
+	}, nil
+}
+
+// issue signs Cognito-shaped access and ID tokens for u.
+func (p *AuthProvider) issue(u user) (*model.AuthResponse, error) {
+	now := p.now()
+	exp := now.Add(p.cfg.AccessTokenTTL)
+
+	accessToken, err := p.signer.Sign(map[string]any{
+		"iss":       p.cfg.Issuer,
+		"sub":       u.sub,
+		"client_id": p.cfg.ClientID,
+		"token_use": "access",
+		"scope":     "openid email",
+		"username":  u.email,
+		"auth_time": now.Unix(),
+		"iat":       now.Unix(),
+		"exp":       exp.Unix(),
+		"jti":       uuid.NewString(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := p.signer.Sign(map[string]any{
+		"iss":              p.cfg.Issuer,
+		"sub":              u.sub,
+		"aud":              p.cfg.ClientID,
+		"token_use":        "id",
+		"email":            u.email,
+		"email_verified":   true,
+		"cognito:username": u.email,
+		"auth_time":        now.Unix(),
+		"iat":              now.Unix(),
+		"exp":              exp.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthResponse{
+		AccessToken: accessToken,
+		IdToken:     idToken,
+		ExpiresIn:   int32(p.cfg.AccessTokenTTL / time.Second),
+		TokenType:   "Bearer",
+	}, nil
+}
+
+// userBySub finds a user by subject. Callers hold p.mu.
+func (p *AuthProvider) userBySub(sub string) *user {
+	for _, u := range p.users {
+		if u.sub == sub {
+			return u
+		}
+	}
+	return nil
+}
+
+func (p *AuthProvider) newCode() (*code, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return nil, fmt.Errorf("generate code: %w", err)
+	}
+	return &code{value: fmt.Sprintf("%06d", n.Int64()), expiresAt: p.now().Add(p.cfg.CodeTTL)}, nil
+}
+
+// logCode stands in for the email Cognito would send.
+func (p *AuthProvider) logCode(ctx context.Context, email, purpose, code string) {
+	p.log.WithContext(ctx).Info("Local auth code issued (not emailed)",
+		logger.F("email", email),
+		logger.F("purpose", purpose),
+		logger.F("code", code),
+	)
+}
+
+func (c *code) matches(value string, now time.Time) bool {
+	return c != nil && now.Before(c.expiresAt) &&
+		subtle.ConstantTimeCompare([]byte(c.value), []byte(value)) == 1
+}
+
+// checkPasswordPolicy applies Cognito's default policy: at least 8
+// characters with upper and lower case letters, a number and a symbol.
+func checkPasswordPolicy(password string) error {
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+
+	var missing string
+	switch {
+	case len(password) < 8:
+		missing = "Password not long enough"
+	case !upper:
+		missing = "Password must have uppercase characters"
+	case !lower:
+		missing = "Password must have lowercase characters"
+	case !digit:
+		missing = "Password must have numeric characters"
+	case !symbol:
+		missing = "Password must have symbol characters"
+	default:
+		return nil
+	}
+	return fail(errors.ErrCodeWeakPassword, "Password did not conform with policy: "+missing, errors.ErrInvalidInput)
+}
+
+func invalidCode() error {
+	return fail(errors.ErrCodeInvalidConfirmationCode, "code is invalid or expired", errors.ErrInvalidInput)
+}
+
+func fail(code, message string, sentinel error) *errors.DomainError {
+	return errors.NewDomainError(code, message, sentinel)
+}
+
+func normalize(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate refresh token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func digest(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
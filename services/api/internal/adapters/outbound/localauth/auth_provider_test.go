@@ -0,0 +1,291 @@
+package localauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/jwt"
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+const (
+	testIssuer   = "http://localhost:5000"
+	testEmail    = "dev@example.com"
+	testPassword = "SecureP@ss123"
+
+	testClientID     = "ci-bot"
+	testClientSecret = "ci-bot-secret"
+)
+
+var (
+	keyOnce sync.Once
+	testKey *rsa.PrivateKey
+)
+
+// outbox records the codes the provider would have logged.
+type outbox struct {
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+func (o *outbox) last(email, purpose string) string {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.codes[email+"/"+purpose]
+}
+
+func newTestProvider(t *testing.T) (*AuthProvider, *outbox) {
+	t.Helper()
+	keyOnce.Do(func() { testKey, _ = rsa.GenerateKey(rand.Reader, 2048) })
+
+	p, err := NewAuthProvider(jwt.NewSigner(testKey, "local-test"), Config{
+		Issuer:     testIssuer,
+		BcryptCost: bcrypt.MinCost,
+		Clients:    map[string]string{testClientID: testClientSecret},
+	}, nil)
+	require.NoError(t, err)
+
+	out := &outbox{codes: make(map[string]string)}
+	p.deliver = func(_ context.Context, email, purpose, code string) {
+		out.mu.Lock()
+		defer out.mu.Unlock()
+		out.codes[email+"/"+purpose] = code
+	}
+	return p, out
+}
+
+// signUpConfirmed registers and confirms testEmail.
+func signUpConfirmed(t *testing.T, p *AuthProvider, out *outbox) {
+	t.Helper()
+	ctx := context.Background()
+	require.NoError(t, p.SignUp(ctx, testEmail, testPassword))
+	require.NoError(t, p.ConfirmSignUp(ctx, testEmail, out.last(testEmail, purposeSignUp)))
+}
+
+func assertCode(t *testing.T, err error, code string) {
+	t.Helper()
+	var domainErr *domainerrors.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, code, domainErr.Code)
+}
+
+func TestAuthProvider_SignUpConfirmSignIn(t *testing.T) {
+	p, out := newTestProvider(t)
+	ctx := context.Background()
+
+	require.NoError(t, p.SignUp(ctx, "Dev@Example.com", testPassword))
+	_, err := p.SignIn(ctx, testEmail, testPassword)
+	assertCode(t, err, domainerrors.ErrCodeEmailNotVerified)
+
+	require.NoError(t, p.ConfirmSignUp(ctx, testEmail, out.last(testEmail, purposeSignUp)))
+	resp, err := p.SignIn(ctx, testEmail, testPassword)
+	require.NoError(t, err)
+	assert.Equal(t, "Bearer", resp.TokenType)
+	assert.Equal(t, int32(3600), resp.ExpiresIn)
+	assert.NotEmpty(t, resp.IdToken)
+	assert.NotEmpty(t, resp.RefreshToken)
+
+	principal, err := p.Verifier().Verify(ctx, resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, testEmail, principal.Username)
+	assert.Equal(t, DefaultClientID, principal.ClientID)
+	assert.NotEmpty(t, principal.Subject)
+}
+
+func TestAuthProvider_PasswordsAreHashed(t *testing.T) {
+	p, _ := newTestProvider(t)
+	require.NoError(t, p.SignUp(context.Background(), testEmail, testPassword))
+
+	u := p.users[testEmail]
+	assert.NotContains(t, string(u.passwordHash), testPassword)
+	assert.NoError(t, bcrypt.CompareHashAndPassword(u.passwordHash, []byte(testPassword)))
+}
+
+func TestAuthProvider_SignUpErrors(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+
+	err := p.SignUp(ctx, testEmail, "alllowercase1!")
+	assertCode(t, err, domainerrors.ErrCodeWeakPassword)
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidInput)
+
+	require.NoError(t, p.SignUp(ctx, testEmail, testPassword))
+	err = p.SignUp(ctx, testEmail, testPassword)
+	assertCode(t, err, domainerrors.ErrCodeUserAlreadyExists)
+	assert.ErrorIs(t, err, domainerrors.ErrAlreadyExists)
+}
+
+func TestAuthProvider_SignInDoesNotRevealAccounts(t *testing.T) {
+	p, out := newTestProvider(t)
+	signUpConfirmed(t, p, out)
+	ctx := context.Background()
+
+	_, wrongPassword := p.SignIn(ctx, testEmail, "Wrong-P@ss1")
+	_, unknownUser := p.SignIn(ctx, "nobody@example.com", testPassword)
+
+	assertCode(t, wrongPassword, domainerrors.ErrCodeInvalidCredentials)
+	assertCode(t, unknownUser, domainerrors.ErrCodeInvalidCredentials)
+	assert.ErrorIs(t, unknownUser, domainerrors.ErrUnauthorized)
+}
+
+func TestAuthProvider_ConfirmSignUpRejectsBadCodes(t *testing.T) {
+	p, out := newTestProvider(t)
+	ctx := context.Background()
+	require.NoError(t, p.SignUp(ctx, testEmail, testPassword))
+
+	assertCode(t, p.ConfirmSignUp(ctx, testEmail, "000000x"), domainerrors.ErrCodeInvalidConfirmationCode)
+	assertCode(t, p.ConfirmSignUp(ctx, "nobody@example.com", "123456"), domainerrors.ErrCodeInvalidConfirmationCode)
+
+	code := out.last(testEmail, purposeSignUp)
+	now := time.Now()
+	p.now = func() time.Time { return now.Add(25 * time.Hour) }
+	assertCode(t, p.ConfirmSignUp(ctx, testEmail, code), domainerrors.ErrCodeInvalidConfirmationCode)
+}
+
+func TestAuthProvider_ResendReplacesCode(t *testing.T) {
+	p, out := newTestProvider(t)
+	ctx := context.Background()
+	require.NoError(t, p.SignUp(ctx, testEmail, testPassword))
+	first := out.last(testEmail, purposeSignUp)
+
+	require.NoError(t, p.ResendConfirmationCode(ctx, testEmail))
+	second := out.last(testEmail, purposeSignUp)
+	if first != second {
+		assertCode(t, p.ConfirmSignUp(ctx, testEmail, first), domainerrors.ErrCodeInvalidConfirmationCode)
+	}
+	require.NoError(t, p.ConfirmSignUp(ctx, testEmail, second))
+
+	assertCode(t, p.ResendConfirmationCode(ctx, testEmail), domainerrors.ErrCodeValidationFailed)
+	assert.ErrorIs(t, p.ResendConfirmationCode(ctx, "nobody@example.com"), domainerrors.ErrNotFound)
+}
+
+func TestAuthProvider_RefreshAndGlobalSignOut(t *testing.T) {
+	p, out := newTestProvider(t)
+	signUpConfirmed(t, p, out)
+	ctx := context.Background()
+	resp, err := p.SignIn(ctx, testEmail, testPassword)
+	require.NoError(t, err)
+
+	refreshed, err := p.RefreshTokens(ctx, resp.RefreshToken)
+	require.NoError(t, err)
+	assert.Empty(t, refreshed.RefreshToken, "refresh tokens are not rotated")
+	_, err = p.Verifier().Verify(ctx, refreshed.AccessToken)
+	assert.NoError(t, err)
+
+	require.NoError(t, p.GlobalSignOut(ctx, resp.AccessToken))
+	_, err = p.RefreshTokens(ctx, resp.RefreshToken)
+	assertCode(t, err, domainerrors.ErrCodeInvalidToken)
+	assert.ErrorIs(t, err, domainerrors.ErrUnauthorized)
+
+	assertCode(t, p.GlobalSignOut(ctx, "not-a-token"), domainerrors.ErrCodeInvalidToken)
+}
+
+func TestAuthProvider_RefreshTokenExpires(t *testing.T) {
+	p, out := newTestProvider(t)
+	signUpConfirmed(t, p, out)
+	ctx := context.Background()
+	resp, err := p.SignIn(ctx, testEmail, testPassword)
+	require.NoError(t, err)
+
+	now := time.Now()
+	p.now = func() time.Time { return now.Add(31 * 24 * time.Hour) }
+	_, err = p.RefreshTokens(ctx, resp.RefreshToken)
+
+	assertCode(t, err, domainerrors.ErrCodeInvalidToken)
+}
+
+func TestAuthProvider_PasswordReset(t *testing.T) {
+	p, out := newTestProvider(t)
+	signUpConfirmed(t, p, out)
+	ctx := context.Background()
+	const newPassword = "N3wSecureP@ss"
+
+	require.NoError(t, p.ForgotPassword(ctx, testEmail))
+	code := out.last(testEmail, purposePasswordReset)
+
+	assertCode(t, p.ConfirmForgotPassword(ctx, testEmail, code, "weak"), domainerrors.ErrCodeWeakPassword)
+	assertCode(t, p.ConfirmForgotPassword(ctx, "nobody@example.com", code, newPassword), domainerrors.ErrCodeInvalidConfirmationCode)
+	require.NoError(t, p.ConfirmForgotPassword(ctx, testEmail, code, newPassword))
+	assertCode(t, p.ConfirmForgotPassword(ctx, testEmail, code, newPassword), domainerrors.ErrCodeInvalidConfirmationCode)
+
+	_, err := p.SignIn(ctx, testEmail, testPassword)
+	assertCode(t, err, domainerrors.ErrCodeInvalidCredentials)
+	_, err = p.SignIn(ctx, testEmail, newPassword)
+	assert.NoError(t, err)
+
+	assert.ErrorIs(t, p.ForgotPassword(ctx, "nobody@example.com"), domainerrors.ErrNotFound)
+}
+
+func TestAuthProvider_ClientCredentials(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+
+	resp, err := p.ClientCredentials(ctx, testClientID, testClientSecret, []string{model.ScopeResourcesRead})
+	require.NoError(t, err)
+	assert.Empty(t, resp.RefreshToken)
+	assert.Empty(t, resp.IdToken)
+
+	principal, err := p.Verifier().Verify(ctx, resp.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, principal.Machine)
+	assert.Equal(t, testClientID, principal.Subject)
+	assert.Equal(t, []string{model.ScopeResourcesRead}, principal.Scopes)
+
+	// No scopes asks for every allowed scope.
+	resp, err = p.ClientCredentials(ctx, testClientID, testClientSecret, nil)
+	require.NoError(t, err)
+	principal, err = p.Verifier().Verify(ctx, resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, model.APIScopes, principal.Scopes)
+}
+
+func TestAuthProvider_ClientCredentialsErrors(t *testing.T) {
+	p, _ := newTestProvider(t)
+	ctx := context.Background()
+
+	_, err := p.ClientCredentials(ctx, testClientID, "wrong", nil)
+	assertCode(t, err, domainerrors.ErrCodeInvalidCredentials)
+
+	_, err = p.ClientCredentials(ctx, "unknown", testClientSecret, nil)
+	assertCode(t, err, domainerrors.ErrCodeInvalidCredentials)
+
+	_, err = p.ClientCredentials(ctx, testClientID, testClientSecret, []string{"admin"})
+	assertCode(t, err, domainerrors.ErrCodeValidationFailed)
+}
+
+func TestJWKSHandler_VerifiesIssuedTokens(t *testing.T) {
+	p, out := newTestProvider(t)
+	signUpConfirmed(t, p, out)
+	ctx := context.Background()
+	resp, err := p.SignIn(ctx, testEmail, testPassword)
+	require.NoError(t, err)
+
+	// A resource server that only knows the published key set accepts the
+	// provider's tokens.
+	srv := httptest.NewServer(jwt.JWKSHandler(p.signer.JWKS()))
+	t.Cleanup(srv.Close)
+	res, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+
+	v, err := jwt.NewVerifier(jwt.NewRemoteKeySet(srv.URL, jwt.RemoteKeySetConfig{}), jwt.Config{
+		Issuer:    testIssuer,
+		Audiences: []string{DefaultClientID},
+		TokenUse:  "access",
+	})
+	require.NoError(t, err)
+	_, err = v.Verify(ctx, resp.AccessToken)
+	assert.NoError(t, err)
+}
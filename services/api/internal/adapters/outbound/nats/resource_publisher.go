@@ -0,0 +1,110 @@
+// Package nats provides a NATS JetStream implementation of the
+// ResourcePublisher port, for small environments that run NATS rather than
+// Kafka or SQS.
+package nats
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// ResourcePublisher publishes resource provisioning requests to a JetStream
+// subject.
+type ResourcePublisher struct {
+	js         jetstream.Publisher
+	subject    string
+	serializer envelope.Serializer
+}
+
+// Ensure ResourcePublisher implements the ResourcePublisher interface.
+var _ outbound.ResourcePublisher = (*ResourcePublisher)(nil)
+
+// NewResourcePublisher creates a publisher writing to subject, encoding events
+// with serializer. A stream must capture subject; see EnsureStream.
+func NewResourcePublisher(js jetstream.Publisher, subject string, serializer envelope.Serializer) *ResourcePublisher {
+	return &ResourcePublisher{js: js, subject: subject, serializer: serializer}
+}
+
+// EnsureStream creates a stream named stream capturing subject unless one by
+// that name exists already, in which case its configuration is left alone.
+func EnsureStream(ctx context.Context, js jetstream.StreamManager, stream, subject string) error {
+	_, err := js.Stream(ctx, stream)
+	if stderrors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: stream, Subjects: []string{subject}})
+		if stderrors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+			// Created concurrently, by the provisioner or another replica.
+			err = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("ensure stream %s: %w", stream, err)
+	}
+	return nil
+}
+
+// Publish wraps the resource in a provisioning request event and publishes it,
+// returning once the stream has stored it. The message ID doubles as the
+// JetStream Nats-Msg-Id, so the stream drops a republished message within its
+// duplicate window.
+func (p *ResourcePublisher) Publish(ctx context.Context, resource model.Resource) error {
+	event, body, err := envelope.ResourceProvisionRequested(ctx, p.serializer, resource)
+	if err != nil {
+		return errors.NewDomainError(
+			errors.ErrCodeQueueError,
+			"failed to serialize resource for publishing",
+			err,
+		)
+	}
+
+	// Stamp the event's ID for the provisioner's inbox and its content type,
+	// and inject the active trace context (W3C traceparent/tracestate/baggage)
+	// into the message headers so the provisioner can continue this trace: its
+	// ProcessMessage span becomes a child of this producer span and both
+	// services' logs share one trace_id. Injection is a no-op when tracing is
+	// disabled.
+	msg := nats.NewMsg(p.subject)
+	msg.Data = body
+	msg.Header.Set(outbound.MessageIDAttribute, event.ID)
+	msg.Header.Set(envelope.ContentTypeHeader, p.serializer.ContentType())
+	otel.GetTextMapPropagator().Inject(ctx, natsHeaderCarrier(msg.Header))
+
+	if _, err := p.js.PublishMsg(ctx, msg, jetstream.WithMsgID(event.ID)); err != nil {
+		return errors.NewDomainError(
+			errors.ErrCodeQueueError,
+			fmt.Sprintf("failed to publish resource %s to nats", resource.ID),
+			err,
+		)
+	}
+	return nil
+}
+
+// natsHeaderCarrier adapts NATS message headers to OTel's TextMapCarrier so
+// the global propagator can write trace context onto an outgoing message.
+// NATS headers are case-sensitive, like Kafka's.
+type natsHeaderCarrier nats.Header
+
+func (c natsHeaderCarrier) Get(key string) string {
+	return nats.Header(c).Get(key)
+}
+
+func (c natsHeaderCarrier) Set(key, value string) {
+	nats.Header(c).Set(key, value)
+}
+
+func (c natsHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
@@ -0,0 +1,84 @@
+package nats
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// fakeJetStream records published messages. Methods other than PublishMsg
+// panic through the nil embedded interface.
+type fakeJetStream struct {
+	jetstream.Publisher
+	msgs []*nats.Msg
+	opts [][]jetstream.PublishOpt
+	err  error
+}
+
+func (f *fakeJetStream) PublishMsg(_ context.Context, msg *nats.Msg, opts ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	f.msgs = append(f.msgs, msg)
+	f.opts = append(f.opts, opts)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &jetstream.PubAck{Stream: "RESOURCE_PROVISIONING", Sequence: uint64(len(f.msgs))}, nil
+}
+
+func TestPublish_StampsHeadersAndTraceContext(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(outbound.WithMessageID(context.Background(), "msg-1"), sc)
+
+	js := &fakeJetStream{}
+	p := NewResourcePublisher(js, "resource.provisioning", envelope.JSONSerializer{})
+	require.NoError(t, p.Publish(ctx, model.Resource{ID: "vm-1", ResourceType: "VM", CloudProvider: "AWS", RequestedBy: "rafael"}))
+
+	require.Len(t, js.msgs, 1)
+	msg := js.msgs[0]
+	assert.Equal(t, "resource.provisioning", msg.Subject)
+	assert.Equal(t, "msg-1", msg.Header.Get(outbound.MessageIDAttribute))
+	assert.Equal(t, envelope.ContentTypeJSON, msg.Header.Get(envelope.ContentTypeHeader))
+	assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", msg.Header.Get("traceparent"))
+	assert.Contains(t, string(msg.Data), `"subject":"vm-1"`)
+	assert.Len(t, js.opts[0], 1, "the message ID is published as the Nats-Msg-Id")
+}
+
+func TestPublish_Failure_IsQueueError(t *testing.T) {
+	js := &fakeJetStream{err: nats.ErrNoResponders}
+	p := NewResourcePublisher(js, "resource.provisioning", envelope.JSONSerializer{})
+
+	err := p.Publish(context.Background(), model.Resource{ID: "vm-1", ResourceType: "VM", CloudProvider: "AWS", RequestedBy: "rafael"})
+
+	require.Error(t, err)
+	assert.ErrorIs(t, err, nats.ErrNoResponders)
+	assert.Contains(t, err.Error(), "vm-1")
+}
+
+func TestNATSHeaderCarrier_IsCaseSensitive(t *testing.T) {
+	h := nats.Header{}
+	c := natsHeaderCarrier(h)
+	c.Set("traceparent", "a")
+	c.Set("Traceparent", "b")
+
+	assert.Equal(t, "a", c.Get("traceparent"))
+	assert.Equal(t, "b", c.Get("Traceparent"))
+	assert.ElementsMatch(t, []string{"traceparent", "Traceparent"}, c.Keys())
+}
@@ -0,0 +1,94 @@
+// Package outbox provides storage adapters for the ResourceOutbox port.
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// MemoryOutbox implements outbound.ResourceOutbox in process memory, writing
+// records through to an in-memory read model. It backs local mode and tests:
+// messages survive a broker outage but not a restart.
+type MemoryOutbox struct {
+	mu        sync.Mutex
+	readModel outbound.ResourceReadModel
+	messages  map[string]model.OutboxMessage
+}
+
+var _ outbound.ResourceOutbox = (*MemoryOutbox)(nil)
+
+// NewMemoryOutbox returns an empty outbox saving records to readModel.
+func NewMemoryOutbox(readModel outbound.ResourceReadModel) *MemoryOutbox {
+	return &MemoryOutbox{readModel: readModel, messages: make(map[string]model.OutboxMessage)}
+}
+
+// Enqueue creates the record and stores the message under one lock; neither
+// is written if the record exists.
+func (o *MemoryOutbox) Enqueue(ctx context.Context, record model.ResourceRecord, msg model.OutboxMessage) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if err := o.readModel.Create(ctx, record); err != nil {
+		return err
+	}
+	o.messages[msg.ID] = msg
+	return nil
+}
+
+// Claim returns due messages oldest first and leases them.
+func (o *MemoryOutbox) Claim(_ context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	now := time.Now()
+	due := make([]model.OutboxMessage, 0, limit)
+	for _, msg := range o.messages {
+		if !msg.NextAttemptAt.After(now) {
+			due = append(due, msg)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].CreatedAt.Before(due[j].CreatedAt) })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for i := range due {
+		due[i].NextAttemptAt = now.Add(lease)
+		o.messages[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+// MarkPublished removes the message.
+func (o *MemoryOutbox) MarkPublished(_ context.Context, id string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.messages, id)
+	return nil
+}
+
+// MarkFailed counts the attempt and reschedules the message.
+func (o *MemoryOutbox) MarkFailed(_ context.Context, id string, lastErr string, nextAttemptAt time.Time) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	msg, ok := o.messages[id]
+	if !ok {
+		return fmt.Errorf("outbox message %s not found", id)
+	}
+	msg.Attempts++
+	msg.LastError = lastErr
+	msg.NextAttemptAt = nextAttemptAt
+	o.messages[id] = msg
+	return nil
+}
+
+// Len reports how many messages are waiting.
+func (o *MemoryOutbox) Len() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.messages)
+}
@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/readmodel"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+func newMessage(id string, createdAt time.Time) (model.ResourceRecord, model.OutboxMessage) {
+	resource := model.Resource{ID: "res-" + id, Status: "pending"}
+	return model.ResourceRecord{Resource: resource, CreatedAt: createdAt, UpdatedAt: createdAt},
+		model.OutboxMessage{ID: id, Resource: resource, CreatedAt: createdAt, NextAttemptAt: createdAt}
+}
+
+func TestMemoryOutbox_EnqueueWritesRecord(t *testing.T) {
+	readModel := readmodel.NewMemoryStore()
+	o := NewMemoryOutbox(readModel)
+	ctx := context.Background()
+
+	record, msg := newMessage("msg-1", time.Now())
+	require.NoError(t, o.Enqueue(ctx, record, msg))
+
+	got, err := readModel.Get(ctx, "res-msg-1")
+	require.NoError(t, err)
+	assert.Equal(t, "pending", got.Status)
+	assert.Equal(t, 1, o.Len())
+}
+
+func TestMemoryOutbox_EnqueueRejectsExistingID(t *testing.T) {
+	o := NewMemoryOutbox(readmodel.NewMemoryStore())
+	ctx := context.Background()
+
+	record, msg := newMessage("msg-1", time.Now())
+	require.NoError(t, o.Enqueue(ctx, record, msg))
+	msg.ID = "msg-2"
+
+	assert.ErrorIs(t, o.Enqueue(ctx, record, msg), outbound.ErrResourceExists)
+	assert.Equal(t, 1, o.Len(), "the rejected message is not stored")
+}
+
+func TestMemoryOutbox_ClaimLeasesDueMessagesOldestFirst(t *testing.T) {
+	o := NewMemoryOutbox(readmodel.NewMemoryStore())
+	ctx := context.Background()
+	now := time.Now()
+
+	for i, id := range []string{"msg-2", "msg-1", "msg-3"} {
+		record, msg := newMessage(id, now.Add(-time.Duration(3-i)*time.Second))
+		require.NoError(t, o.Enqueue(ctx, record, msg))
+	}
+	record, later := newMessage("msg-later", now)
+	later.NextAttemptAt = now.Add(time.Hour)
+	require.NoError(t, o.Enqueue(ctx, record, later))
+
+	claimed, err := o.Claim(ctx, 2, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 2)
+	assert.Equal(t, "msg-2", claimed[0].ID)
+	assert.Equal(t, "msg-1", claimed[1].ID)
+
+	// Leased messages are skipped until the lease lapses.
+	claimed, err = o.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "msg-3", claimed[0].ID)
+}
+
+func TestMemoryOutbox_MarkFailedAndPublished(t *testing.T) {
+	o := NewMemoryOutbox(readmodel.NewMemoryStore())
+	ctx := context.Background()
+
+	record, msg := newMessage("msg-1", time.Now())
+	require.NoError(t, o.Enqueue(ctx, record, msg))
+
+	require.NoError(t, o.MarkFailed(ctx, "msg-1", "broker down", time.Now().Add(-time.Second)))
+	claimed, err := o.Claim(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.Equal(t, "broker down", claimed[0].LastError)
+
+	require.NoError(t, o.MarkPublished(ctx, "msg-1"))
+	assert.Equal(t, 0, o.Len())
+	assert.Error(t, o.MarkFailed(ctx, "msg-1", "x", time.Now()))
+}
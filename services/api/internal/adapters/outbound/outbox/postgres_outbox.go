@@ -0,0 +1,132 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/readmodel"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// PostgresOutbox implements outbound.ResourceOutbox on the outbox_messages
+// table (db/init.sql), in the same database as the read model so a record
+// and its message commit together.
+type PostgresOutbox struct {
+	db *sql.DB
+}
+
+var _ outbound.ResourceOutbox = (*PostgresOutbox)(nil)
+
+// NewPostgresOutbox wraps an open database handle. The caller owns Close().
+func NewPostgresOutbox(db *sql.DB) *PostgresOutbox {
+	return &PostgresOutbox{db: db}
+}
+
+// Enqueue inserts the record and the message in one transaction. If the
+// record exists the transaction is rolled back and ErrResourceExists returned.
+func (o *PostgresOutbox) Enqueue(ctx context.Context, record model.ResourceRecord, msg model.OutboxMessage) error {
+	payload, err := json.Marshal(msg.Resource)
+	if err != nil {
+		return fmt.Errorf("marshal outbox payload %s: %w", msg.ID, err)
+	}
+	traceContext, err := json.Marshal(msg.TraceContext)
+	if err != nil {
+		return fmt.Errorf("marshal outbox trace context %s: %w", msg.ID, err)
+	}
+
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	// Rollback after a successful Commit is a no-op.
+	defer func() { _ = tx.Rollback() }()
+
+	if err := readmodel.InsertRecord(ctx, tx, record); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO outbox_messages (id, resource_id, payload, trace_context, idempotency_key, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		msg.ID, msg.Resource.ID, payload, traceContext, msg.IdempotencyKey, msg.CreatedAt, msg.NextAttemptAt,
+	); err != nil {
+		return fmt.Errorf("insert outbox message %s: %w", msg.ID, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit outbox message %s: %w", msg.ID, err)
+	}
+	return nil
+}
+
+// Claim leases due messages with SKIP LOCKED, so concurrent relays on other
+// replicas claim disjoint batches.
+func (o *PostgresOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error) {
+	now := time.Now()
+	rows, err := o.db.QueryContext(ctx, `
+		UPDATE outbox_messages SET next_attempt_at = $2
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE next_attempt_at <= $1
+			ORDER BY created_at
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, payload, trace_context, idempotency_key, attempts, last_error, created_at, next_attempt_at`,
+		now, now.Add(lease), limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("claim outbox messages: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var messages []model.OutboxMessage
+	for rows.Next() {
+		var (
+			msg                   model.OutboxMessage
+			payload, traceContext []byte
+		)
+		if err := rows.Scan(&msg.ID, &payload, &traceContext, &msg.IdempotencyKey, &msg.Attempts, &msg.LastError, &msg.CreatedAt, &msg.NextAttemptAt); err != nil {
+			return nil, fmt.Errorf("scan outbox message: %w", err)
+		}
+		if err := json.Unmarshal(payload, &msg.Resource); err != nil {
+			return nil, fmt.Errorf("unmarshal outbox payload %s: %w", msg.ID, err)
+		}
+		if err := json.Unmarshal(traceContext, &msg.TraceContext); err != nil {
+			return nil, fmt.Errorf("unmarshal outbox trace context %s: %w", msg.ID, err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("claim outbox messages: %w", err)
+	}
+
+	// RETURNING does not preserve the subquery's order.
+	sort.Slice(messages, func(i, j int) bool { return messages[i].CreatedAt.Before(messages[j].CreatedAt) })
+	return messages, nil
+}
+
+// MarkPublished deletes the message.
+func (o *PostgresOutbox) MarkPublished(ctx context.Context, id string) error {
+	if _, err := o.db.ExecContext(ctx, `DELETE FROM outbox_messages WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete outbox message %s: %w", id, err)
+	}
+	return nil
+}
+
+// MarkFailed counts the attempt and reschedules the message.
+func (o *PostgresOutbox) MarkFailed(ctx context.Context, id string, lastErr string, nextAttemptAt time.Time) error {
+	if _, err := o.db.ExecContext(ctx, `
+		UPDATE outbox_messages
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3
+		WHERE id = $1`,
+		id, lastErr, nextAttemptAt,
+	); err != nil {
+		return fmt.Errorf("reschedule outbox message %s: %w", id, err)
+	}
+	return nil
+}
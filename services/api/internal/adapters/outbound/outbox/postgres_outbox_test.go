@@ -0,0 +1,72 @@
+package outbox
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/readmodel"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/infrastructure"
+)
+
+// We don't spin up Postgres for unit tests — set POSTGRES_TEST_DSN to a
+// database with db/init.sql applied to exercise the real implementation.
+func TestPostgresOutbox_RoundTrip(t *testing.T) {
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set; skipping Postgres integration test")
+	}
+	db, err := infrastructure.NewPostgresDB(context.Background(), infrastructure.PostgresConfig{URL: dsn, PingTimeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	o := NewPostgresOutbox(db)
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	resource := model.Resource{ID: uuid.NewString(), ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending", RequestedBy: "rafael"}
+	msg := model.OutboxMessage{
+		ID:             uuid.NewString(),
+		Resource:       resource,
+		TraceContext:   map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+		IdempotencyKey: uuid.NewString(),
+		CreatedAt:      now,
+		NextAttemptAt:  now,
+	}
+	require.NoError(t, o.Enqueue(ctx, model.ResourceRecord{Resource: resource, CreatedAt: now, UpdatedAt: now}, msg))
+
+	record, err := readmodel.NewPostgresStore(db).Get(ctx, resource.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", record.Status)
+
+	// Resubmitting the ID writes neither the record nor a second message.
+	duplicate := msg
+	duplicate.ID = uuid.NewString()
+	err = o.Enqueue(ctx, model.ResourceRecord{Resource: resource, CreatedAt: now, UpdatedAt: now}, duplicate)
+	assert.ErrorIs(t, err, outbound.ErrResourceExists)
+
+	claimed, err := o.Claim(ctx, 100, time.Minute)
+	require.NoError(t, err)
+	var found *model.OutboxMessage
+	for i := range claimed {
+		if claimed[i].ID == msg.ID {
+			found = &claimed[i]
+		}
+	}
+	for i := range claimed {
+		assert.NotEqual(t, duplicate.ID, claimed[i].ID, "the rejected message must not be enqueued")
+	}
+	require.NotNil(t, found, "the enqueued message must be claimable")
+	assert.Equal(t, resource, found.Resource)
+	assert.Equal(t, msg.TraceContext, found.TraceContext)
+	assert.Equal(t, msg.IdempotencyKey, found.IdempotencyKey)
+
+	require.NoError(t, o.MarkFailed(ctx, msg.ID, "broker down", now))
+	require.NoError(t, o.MarkPublished(ctx, msg.ID))
+}
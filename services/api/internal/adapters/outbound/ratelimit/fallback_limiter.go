@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// FallbackLimiter asks a primary limiter and, when it fails, a fallback. It
+// keeps callers limited per replica through a Redis outage instead of failing
+// open or refusing every request.
+type FallbackLimiter struct {
+	primary  outbound.RateLimiter
+	fallback outbound.RateLimiter
+	logger   logger.Logger
+	// degraded is set while the primary is failing, so the switch to the
+	// fallback and back is logged once rather than on every request.
+	degraded atomic.Bool
+}
+
+var _ outbound.RateLimiter = (*FallbackLimiter)(nil)
+
+// NewFallbackLimiter wraps primary, falling back to fallback on errors.
+func NewFallbackLimiter(primary, fallback outbound.RateLimiter, l logger.Logger) *FallbackLimiter {
+	if l == nil {
+		l = logger.NopLogger{}
+	}
+	return &FallbackLimiter{primary: primary, fallback: fallback, logger: l}
+}
+
+// Allow takes a token from the primary limiter's bucket, or the fallback's
+// when the primary is unavailable. Only the switch to the fallback and the
+// recovery are logged.
+func (l *FallbackLimiter) Allow(ctx context.Context, key string, limit outbound.RateLimit) (outbound.RateLimitDecision, error) {
+	d, err := l.primary.Allow(ctx, key, limit)
+	if err == nil {
+		if l.degraded.CompareAndSwap(true, false) {
+			l.logger.WithContext(ctx).Info("rate limiter recovered; leaving in-memory fallback")
+		}
+		return d, nil
+	}
+	if l.degraded.CompareAndSwap(false, true) {
+		l.logger.WithContext(ctx).WithError(err).Warn("rate limiter unavailable; using in-memory fallback until it recovers")
+	}
+	return l.fallback.Allow(ctx, key, limit)
+}
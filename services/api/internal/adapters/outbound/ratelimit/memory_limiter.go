@@ -0,0 +1,103 @@
+// Package ratelimit provides token bucket adapters for the RateLimiter port.
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// sweepInterval is how often MemoryLimiter drops buckets that have refilled,
+// which are indistinguishable from buckets never used.
+const sweepInterval = time.Minute
+
+// MemoryLimiter implements outbound.RateLimiter in process memory. It backs
+// local mode and deployments without Redis; each replica keeps its own
+// buckets, so the effective limit scales with the replica count.
+type MemoryLimiter struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+var _ outbound.RateLimiter = (*MemoryLimiter)(nil)
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	fullAt time.Time
+}
+
+// NewMemoryLimiter returns a limiter with no buckets.
+func NewMemoryLimiter() *MemoryLimiter {
+	return &MemoryLimiter{buckets: make(map[string]*bucket), now: time.Now}
+}
+
+// Allow takes a token from the bucket named key.
+func (l *MemoryLimiter) Allow(_ context.Context, key string, limit outbound.RateLimit) (outbound.RateLimitDecision, error) {
+	if limit.Unlimited() {
+		return outbound.RateLimitDecision{Allowed: true}, nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Requests), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = refill(limit, b.tokens, now.Sub(b.last))
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+	d := decide(limit, allowed, b.tokens, now)
+	b.fullAt = d.ResetAt
+	return d, nil
+}
+
+func (l *MemoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < sweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if !now.Before(b.fullAt) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// refill adds the tokens earned over elapsed to a bucket holding tokens.
+func refill(limit outbound.RateLimit, tokens float64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return tokens
+	}
+	earned := float64(elapsed) / float64(limit.Window) * float64(limit.Requests)
+	return math.Min(float64(limit.Requests), tokens+earned)
+}
+
+// decide describes a bucket left holding tokens at now.
+func decide(limit outbound.RateLimit, allowed bool, tokens float64, now time.Time) outbound.RateLimitDecision {
+	perToken := float64(limit.Window) / float64(limit.Requests)
+	d := outbound.RateLimitDecision{
+		Allowed:   allowed,
+		Limit:     limit.Requests,
+		Remaining: int(math.Floor(tokens)),
+		ResetAt:   now.Add(time.Duration(math.Ceil((float64(limit.Requests) - tokens) * perToken))),
+	}
+	if !allowed {
+		d.RetryAfter = time.Duration(math.Ceil((1 - tokens) * perToken))
+	}
+	return d
+}
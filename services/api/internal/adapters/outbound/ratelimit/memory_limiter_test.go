@@ -0,0 +1,134 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+var tenPerMinute = outbound.RateLimit{Requests: 10, Window: time.Minute}
+
+// exerciseLimiter drains a bucket and checks the decisions, for any backend.
+func exerciseLimiter(t *testing.T, l outbound.RateLimiter, key string) {
+	t.Helper()
+	ctx := context.Background()
+
+	for i := range tenPerMinute.Requests {
+		d, err := l.Allow(ctx, key, tenPerMinute)
+		require.NoError(t, err)
+		assert.True(t, d.Allowed, "call %d", i+1)
+		assert.Equal(t, 10, d.Limit)
+		assert.Equal(t, tenPerMinute.Requests-i-1, d.Remaining)
+		assert.Zero(t, d.RetryAfter)
+	}
+
+	d, err := l.Allow(ctx, key, tenPerMinute)
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, 0, d.Remaining)
+	assert.InDelta(t, 6*time.Second, d.RetryAfter, float64(time.Second), "one token is earned every 6s")
+	assert.WithinDuration(t, time.Now().Add(time.Minute), d.ResetAt, 2*time.Second)
+
+	other, err := l.Allow(ctx, key+"-other", tenPerMinute)
+	require.NoError(t, err)
+	assert.True(t, other.Allowed, "buckets are per key")
+}
+
+func TestMemoryLimiter_DrainsBucket(t *testing.T) {
+	exerciseLimiter(t, NewMemoryLimiter(), "principal:alice")
+}
+
+func TestMemoryLimiter_RefillsOverWindow(t *testing.T) {
+	l := NewMemoryLimiter()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	for range tenPerMinute.Requests {
+		_, err := l.Allow(ctx, "k", tenPerMinute)
+		require.NoError(t, err)
+	}
+	d, _ := l.Allow(ctx, "k", tenPerMinute)
+	require.False(t, d.Allowed)
+	assert.Equal(t, 6*time.Second, d.RetryAfter)
+	assert.Equal(t, now.Add(time.Minute), d.ResetAt)
+
+	now = now.Add(6 * time.Second)
+	d, _ = l.Allow(ctx, "k", tenPerMinute)
+	assert.True(t, d.Allowed, "one token earned after 6s")
+	assert.Equal(t, 0, d.Remaining)
+
+	now = now.Add(time.Hour)
+	d, _ = l.Allow(ctx, "k", tenPerMinute)
+	assert.True(t, d.Allowed)
+	assert.Equal(t, 9, d.Remaining, "refill stops at capacity")
+}
+
+func TestMemoryLimiter_SweepsFullBuckets(t *testing.T) {
+	l := NewMemoryLimiter()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	l.now = func() time.Time { return now }
+
+	_, _ = l.Allow(context.Background(), "idle", tenPerMinute)
+	now = now.Add(2 * time.Minute)
+	_, _ = l.Allow(context.Background(), "busy", tenPerMinute)
+
+	assert.NotContains(t, l.buckets, "idle")
+	assert.Contains(t, l.buckets, "busy")
+}
+
+func TestMemoryLimiter_Unlimited(t *testing.T) {
+	d, err := NewMemoryLimiter().Allow(context.Background(), "k", outbound.RateLimit{})
+
+	require.NoError(t, err)
+	assert.True(t, d.Allowed)
+}
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(context.Context, string, outbound.RateLimit) (outbound.RateLimitDecision, error) {
+	return outbound.RateLimitDecision{}, errors.New("connection refused")
+}
+
+func TestFallbackLimiter_UsesFallbackOnError(t *testing.T) {
+	exerciseLimiter(t, NewFallbackLimiter(failingLimiter{}, NewMemoryLimiter(), nil), "principal:alice")
+}
+
+// switchableLimiter fails while down is set and allows everything otherwise.
+type switchableLimiter struct{ down bool }
+
+func (l *switchableLimiter) Allow(ctx context.Context, key string, limit outbound.RateLimit) (outbound.RateLimitDecision, error) {
+	if l.down {
+		return failingLimiter{}.Allow(ctx, key, limit)
+	}
+	return outbound.RateLimitDecision{Allowed: true}, nil
+}
+
+func TestFallbackLimiter_LogsOnlyTheSwitches(t *testing.T) {
+	base, hook := logrustest.NewNullLogger()
+	primary := &switchableLimiter{down: true}
+	l := NewFallbackLimiter(primary, NewMemoryLimiter(), logger.NewWithLogrus(logrus.NewEntry(base)))
+
+	for range 5 {
+		_, err := l.Allow(context.Background(), "principal:alice", tenPerMinute)
+		require.NoError(t, err)
+	}
+	require.Len(t, hook.AllEntries(), 1, "an outage is logged once, not per request")
+	assert.Equal(t, logrus.WarnLevel, hook.LastEntry().Level)
+
+	primary.down = false
+	for range 3 {
+		_, _ = l.Allow(context.Background(), "principal:alice", tenPerMinute)
+	}
+	require.Len(t, hook.AllEntries(), 2)
+	assert.Equal(t, logrus.InfoLevel, hook.LastEntry().Level, "the recovery is logged once")
+}
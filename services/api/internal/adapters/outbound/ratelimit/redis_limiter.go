@@ -0,0 +1,96 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// keyPrefix scopes rate limit buckets so they don't collide with other Redis tenants.
+const keyPrefix = "ratelimit:"
+
+// RedisLimiter implements outbound.RateLimiter on top of Redis, so every
+// replica draws from the same buckets.
+//
+// Each bucket is a hash of its tokens and last refill time, updated by a Lua
+// script so the refill and take are atomic. The script reads the clock from
+// Redis rather than taking it from the caller, so replicas with skewed clocks
+// still agree.
+type RedisLimiter struct {
+	client redis.UniversalClient
+}
+
+var _ outbound.RateLimiter = (*RedisLimiter)(nil)
+
+// NewRedisLimiter wraps an existing Redis client.
+func NewRedisLimiter(client redis.UniversalClient) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+// allowScript refills the bucket in KEYS[1] for the time since its last call,
+// takes a token if there is one, and expires the bucket once it would be full
+// again. ARGV is the capacity and the window in microseconds. It returns
+// whether the call is allowed, the tokens left (as a string, since Redis
+// truncates Lua numbers to integers) and the time it used, as TIME's seconds
+// and microseconds.
+var allowScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local t = redis.call("TIME")
+local now = tonumber(t[1]) * 1000000 + tonumber(t[2])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) * capacity / window)
+end
+
+local allowed = 0
+if tokens >= 1 then
+  tokens = tokens - 1
+  allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", string.format("%.0f", now))
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) * window / capacity / 1000) + 1)
+return {allowed, tostring(tokens), t[1], t[2]}
+`)
+
+// Allow takes a token from the bucket named key.
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit outbound.RateLimit) (outbound.RateLimitDecision, error) {
+	if limit.Unlimited() {
+		return outbound.RateLimitDecision{Allowed: true}, nil
+	}
+
+	res, err := allowScript.Run(ctx, l.client, []string{keyPrefix + key}, limit.Requests, limit.Window.Microseconds()).Slice()
+	if err != nil {
+		return outbound.RateLimitDecision{}, fmt.Errorf("redis rate limit script: %w", err)
+	}
+	if len(res) != 4 {
+		return outbound.RateLimitDecision{}, fmt.Errorf("redis rate limit script: unexpected reply %v", res)
+	}
+
+	allowed, _ := res[0].(int64)
+	tokensRaw, _ := res[1].(string)
+	secRaw, _ := res[2].(string)
+	usecRaw, _ := res[3].(string)
+	tokens, err := strconv.ParseFloat(tokensRaw, 64)
+	if err != nil {
+		return outbound.RateLimitDecision{}, fmt.Errorf("parse rate limit tokens %q: %w", tokensRaw, err)
+	}
+	sec, err := strconv.ParseInt(secRaw, 10, 64)
+	if err != nil {
+		return outbound.RateLimitDecision{}, fmt.Errorf("parse rate limit time %q: %w", secRaw, err)
+	}
+	usec, err := strconv.ParseInt(usecRaw, 10, 64)
+	if err != nil {
+		return outbound.RateLimitDecision{}, fmt.Errorf("parse rate limit time %q: %w", usecRaw, err)
+	}
+	return decide(limit, allowed == 1, tokens, time.Unix(sec, usec*int64(time.Microsecond))), nil
+}
@@ -0,0 +1,29 @@
+package ratelimit
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestLimiter returns a limiter on the Redis at REDIS_TEST_ADDR (e.g. the
+// docker-compose redis service), or skips the test if it is not set.
+func newTestLimiter(t *testing.T) *RedisLimiter {
+	t.Helper()
+	addr := os.Getenv("REDIS_TEST_ADDR")
+	if addr == "" {
+		t.Skip("REDIS_TEST_ADDR not set; skipping Redis integration test")
+	}
+	client := redis.NewClient(&redis.Options{Addr: addr})
+	t.Cleanup(func() { _ = client.Close() })
+	require.NoError(t, client.Ping(context.Background()).Err())
+	return NewRedisLimiter(client)
+}
+
+func TestRedisLimiter_DrainsBucket(t *testing.T) {
+	exerciseLimiter(t, newTestLimiter(t), "test:"+uuid.NewString())
+}
@@ -0,0 +1,145 @@
+// Package readmodel provides storage adapters for the ResourceReadModel port.
+package readmodel
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// MemoryStore implements outbound.ResourceReadModel in process memory. It backs
+// local mode and tests; records do not survive a restart and are not shared
+// between replicas.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records map[string]model.ResourceRecord
+}
+
+var _ outbound.ResourceReadModel = (*MemoryStore)(nil)
+
+// NewMemoryStore returns an empty in-memory read model.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{records: make(map[string]model.ResourceRecord)}
+}
+
+// Create inserts the record for record.ID unless one exists.
+func (s *MemoryStore) Create(_ context.Context, record model.ResourceRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[record.ID]; ok {
+		return outbound.ErrResourceExists
+	}
+	s.records[record.ID] = record
+	return nil
+}
+
+// Get returns a copy of the record for id, or outbound.ErrResourceNotFound.
+func (s *MemoryStore) Get(_ context.Context, id string) (*model.ResourceRecord, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	record, ok := s.records[id]
+	if !ok {
+		return nil, outbound.ErrResourceNotFound
+	}
+	return &record, nil
+}
+
+// UpdateStatus changes the record's status if it is still from.
+func (s *MemoryStore) UpdateStatus(_ context.Context, id, from, to string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record, ok := s.records[id]
+	if !ok {
+		return outbound.ErrResourceNotFound
+	}
+	if record.Status != from {
+		return outbound.ErrStatusChanged
+	}
+	record.Status = to
+	record.UpdatedAt = at
+	s.records[id] = record
+	return nil
+}
+
+// List filters and orders every record in memory, then applies the keyset
+// bounds. Linear in the number of records, which is fine for local use.
+func (s *MemoryStore) List(_ context.Context, query model.ResourceQuery) ([]model.ResourceRecord, bool, error) {
+	s.mu.RLock()
+	matched := make([]model.ResourceRecord, 0, len(s.records))
+	for _, record := range s.records {
+		if matchesFilter(record, query.Filter) {
+			matched = append(matched, record)
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return newerThan(matched[i].Key(), matched[j].Key())
+	})
+
+	var page []model.ResourceRecord
+	switch {
+	case query.After != nil:
+		for _, record := range matched {
+			if newerThan(*query.After, record.Key()) {
+				page = append(page, record)
+			}
+		}
+	case query.Before != nil:
+		// Walk towards newer records from the key, then restore newest-first.
+		for i := len(matched) - 1; i >= 0; i-- {
+			if newerThan(matched[i].Key(), *query.Before) {
+				page = append(page, matched[i])
+			}
+		}
+		hasMore := len(page) > query.Limit
+		if hasMore {
+			page = page[:query.Limit]
+		}
+		for i, j := 0, len(page)-1; i < j; i, j = i+1, j-1 {
+			page[i], page[j] = page[j], page[i]
+		}
+		return page, hasMore, nil
+	default:
+		page = matched
+	}
+
+	hasMore := len(page) > query.Limit
+	if hasMore {
+		page = page[:query.Limit]
+	}
+	return page, hasMore, nil
+}
+
+// Count returns how many records match the filter.
+func (s *MemoryStore) Count(_ context.Context, filter model.ResourceFilter) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	n := 0
+	for _, record := range s.records {
+		if matchesFilter(record, filter) {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func matchesFilter(record model.ResourceRecord, f model.ResourceFilter) bool {
+	return (f.ResourceType == "" || record.ResourceType == f.ResourceType) &&
+		(f.CloudProvider == "" || record.CloudProvider == f.CloudProvider) &&
+		(f.Status == "" || record.Status == f.Status) &&
+		(f.RequestedBy == "" || record.RequestedBy == f.RequestedBy)
+}
+
+// newerThan orders keys newest first, breaking CreatedAt ties by descending ID
+// so the order is total and matches the SQL ORDER BY.
+func newerThan(a, b model.ResourceKey) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.After(b.CreatedAt)
+	}
+	return a.ID > b.ID
+}
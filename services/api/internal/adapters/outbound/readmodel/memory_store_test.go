@@ -0,0 +1,111 @@
+package readmodel
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+func TestMemoryStore_CreateThenGet(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+
+	require.NoError(t, store.Create(ctx, model.ResourceRecord{Resource: model.Resource{ID: "vm-1", Status: "completed", RequestedBy: "alice"}}))
+	err := store.Create(ctx, model.ResourceRecord{Resource: model.Resource{ID: "vm-1", Status: "pending", RequestedBy: "mallory"}})
+	assert.ErrorIs(t, err, outbound.ErrResourceExists)
+
+	got, err := store.Get(ctx, "vm-1")
+	require.NoError(t, err)
+	assert.Equal(t, "completed", got.Status, "Create never replaces an existing record")
+	assert.Equal(t, "alice", got.RequestedBy)
+}
+
+func TestMemoryStore_GetMissingReturnsNotFound(t *testing.T) {
+	_, err := NewMemoryStore().Get(context.Background(), "missing")
+	assert.ErrorIs(t, err, outbound.ErrResourceNotFound)
+}
+
+func TestMemoryStore_UpdateStatusIsCompareAndSet(t *testing.T) {
+	store := NewMemoryStore()
+	ctx := context.Background()
+	require.NoError(t, store.Create(ctx, model.ResourceRecord{Resource: model.Resource{ID: "vm-1", Status: "pending"}}))
+	at := time.Date(2026, 1, 1, 0, 1, 0, 0, time.UTC)
+
+	require.NoError(t, store.UpdateStatus(ctx, "vm-1", "pending", "in_progress", at))
+	assert.ErrorIs(t, store.UpdateStatus(ctx, "vm-1", "pending", "failed", at), outbound.ErrStatusChanged)
+	assert.ErrorIs(t, store.UpdateStatus(ctx, "vm-404", "pending", "failed", at), outbound.ErrResourceNotFound)
+
+	got, err := store.Get(ctx, "vm-1")
+	require.NoError(t, err)
+	assert.Equal(t, "in_progress", got.Status)
+	assert.Equal(t, at, got.UpdatedAt)
+}
+
+func seedRecords(t *testing.T, store *MemoryStore, n int) {
+	t.Helper()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		provider := "AWS"
+		if i%2 == 1 {
+			provider = "GCP"
+		}
+		require.NoError(t, store.Create(context.Background(), model.ResourceRecord{
+			Resource:  model.Resource{ID: fmt.Sprintf("r-%02d", i), CloudProvider: provider, Status: "pending"},
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+}
+
+func ids(records []model.ResourceRecord) []string {
+	out := make([]string, len(records))
+	for i, r := range records {
+		out[i] = r.ID
+	}
+	return out
+}
+
+func TestMemoryStore_ListPagesNewestFirst(t *testing.T) {
+	store := NewMemoryStore()
+	seedRecords(t, store, 5)
+	ctx := context.Background()
+
+	first, hasMore, err := store.List(ctx, model.ResourceQuery{Limit: 2})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"r-04", "r-03"}, ids(first))
+
+	last := first[len(first)-1].Key()
+	second, hasMore, err := store.List(ctx, model.ResourceQuery{Limit: 2, After: &last})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	assert.Equal(t, []string{"r-02", "r-01"}, ids(second))
+
+	top := second[0].Key()
+	back, hasMore, err := store.List(ctx, model.ResourceQuery{Limit: 2, Before: &top})
+	require.NoError(t, err)
+	assert.False(t, hasMore, "nothing is newer than the first page")
+	assert.Equal(t, []string{"r-04", "r-03"}, ids(back))
+}
+
+func TestMemoryStore_ListAndCountApplyFilter(t *testing.T) {
+	store := NewMemoryStore()
+	seedRecords(t, store, 5)
+	ctx := context.Background()
+	filter := model.ResourceFilter{CloudProvider: "GCP"}
+
+	records, hasMore, err := store.List(ctx, model.ResourceQuery{Filter: filter, Limit: 10})
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Equal(t, []string{"r-03", "r-01"}, ids(records))
+
+	n, err := store.Count(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+}
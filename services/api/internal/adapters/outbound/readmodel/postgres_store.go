@@ -0,0 +1,229 @@
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// PostgresStore implements outbound.ResourceReadModel on the resource_records
+// table (db/init.sql). It is the deployed read model: every replica sees the
+// same records.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+var _ outbound.ResourceReadModel = (*PostgresStore)(nil)
+
+// NewPostgresStore wraps an open database handle. The caller owns Close().
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+const recordColumns = `id, resource_type, cloud_provider, specification, status, requested_by, region, tags, trace_id, created_at, updated_at`
+
+// Create inserts the record. An existing record is never overwritten: its
+// status only moves through UpdateStatus and its owner never changes.
+func (s *PostgresStore) Create(ctx context.Context, r model.ResourceRecord) error {
+	return InsertRecord(ctx, s.db, r)
+}
+
+// Execer is the part of *sql.DB and *sql.Tx that InsertRecord needs.
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// InsertRecord is Create on an arbitrary handle, so other stores can write a
+// record inside their own transaction.
+func InsertRecord(ctx context.Context, db Execer, r model.ResourceRecord) error {
+	tags, err := encodeTags(r.Tags)
+	if err != nil {
+		return fmt.Errorf("encode tags of resource record %s: %w", r.ID, err)
+	}
+	res, err := db.ExecContext(ctx, `
+		INSERT INTO resource_records (`+recordColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO NOTHING`,
+		r.ID, r.ResourceType, r.CloudProvider, r.Specification, r.Status, r.RequestedBy, r.Region, tags, r.TraceID, r.CreatedAt, r.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("insert resource record %s: %w", r.ID, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("insert resource record %s: %w", r.ID, err)
+	}
+	if n == 0 {
+		return outbound.ErrResourceExists
+	}
+	return nil
+}
+
+// Get returns the record for id, or outbound.ErrResourceNotFound.
+func (s *PostgresStore) Get(ctx context.Context, id string) (*model.ResourceRecord, error) {
+	row := s.db.QueryRowContext(ctx, `SELECT `+recordColumns+` FROM resource_records WHERE id = $1`, id)
+	record, err := scanRecord(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, outbound.ErrResourceNotFound
+		}
+		return nil, fmt.Errorf("select resource record %s: %w", id, err)
+	}
+	return &record, nil
+}
+
+// UpdateStatus is a conditional UPDATE on the expected status, so of two
+// concurrent changes read from the same status only the first applies.
+func (s *PostgresStore) UpdateStatus(ctx context.Context, id, from, to string, at time.Time) error {
+	res, err := s.db.ExecContext(ctx,
+		`UPDATE resource_records SET status = $3, updated_at = $4 WHERE id = $1 AND status = $2`,
+		id, from, to, at,
+	)
+	if err != nil {
+		return fmt.Errorf("update status of resource record %s: %w", id, err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update status of resource record %s: %w", id, err)
+	}
+	if n > 0 {
+		return nil
+	}
+
+	// Nothing matched: tell a missing record from a lost race.
+	var exists bool
+	if err := s.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM resource_records WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("select resource record %s: %w", id, err)
+	}
+	if !exists {
+		return outbound.ErrResourceNotFound
+	}
+	return outbound.ErrStatusChanged
+}
+
+// List runs a keyset query over (created_at, id), backed by the matching
+// index. One extra row is fetched to learn whether another page exists.
+func (s *PostgresStore) List(ctx context.Context, query model.ResourceQuery) ([]model.ResourceRecord, bool, error) {
+	where, args := filterClause(query.Filter)
+
+	order := "DESC"
+	switch {
+	case query.After != nil:
+		args = append(args, query.After.CreatedAt, query.After.ID)
+		where = append(where, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	case query.Before != nil:
+		// Read towards newer records, then flip back to newest-first below.
+		args = append(args, query.Before.CreatedAt, query.Before.ID)
+		where = append(where, fmt.Sprintf("(created_at, id) > ($%d, $%d)", len(args)-1, len(args)))
+		order = "ASC"
+	}
+	args = append(args, query.Limit+1)
+
+	stmt := `SELECT ` + recordColumns + ` FROM resource_records` + whereSQL(where) +
+		fmt.Sprintf(" ORDER BY created_at %s, id %s LIMIT $%d", order, order, len(args))
+
+	rows, err := s.db.QueryContext(ctx, stmt, args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("list resource records: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	records := make([]model.ResourceRecord, 0, query.Limit+1)
+	for rows.Next() {
+		record, err := scanRecord(rows)
+		if err != nil {
+			return nil, false, fmt.Errorf("scan resource record: %w", err)
+		}
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, false, fmt.Errorf("list resource records: %w", err)
+	}
+
+	hasMore := len(records) > query.Limit
+	if hasMore {
+		records = records[:query.Limit]
+	}
+	if order == "ASC" {
+		for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+			records[i], records[j] = records[j], records[i]
+		}
+	}
+	return records, hasMore, nil
+}
+
+// Count returns how many records match the filter.
+func (s *PostgresStore) Count(ctx context.Context, filter model.ResourceFilter) (int, error) {
+	where, args := filterClause(filter)
+	var n int
+	if err := s.db.QueryRowContext(ctx, `SELECT count(*) FROM resource_records`+whereSQL(where), args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("count resource records: %w", err)
+	}
+	return n, nil
+}
+
+// filterClause turns the non-empty filter fields into parameterised predicates.
+func filterClause(f model.ResourceFilter) ([]string, []any) {
+	var where []string
+	var args []any
+	add := func(column, value string) {
+		if value == "" {
+			return
+		}
+		args = append(args, value)
+		where = append(where, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	add("resource_type", f.ResourceType)
+	add("cloud_provider", f.CloudProvider)
+	add("status", f.Status)
+	add("requested_by", f.RequestedBy)
+	return where, args
+}
+
+func whereSQL(predicates []string) string {
+	if len(predicates) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(predicates, " AND ")
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanRecord(row rowScanner) (model.ResourceRecord, error) {
+	var (
+		r    model.ResourceRecord
+		tags []byte
+	)
+	if err := row.Scan(&r.ID, &r.ResourceType, &r.CloudProvider, &r.Specification, &r.Status, &r.RequestedBy, &r.Region, &tags, &r.TraceID, &r.CreatedAt, &r.UpdatedAt); err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(tags, &r.Tags); err != nil {
+		return r, fmt.Errorf("decode tags of resource record %s: %w", r.ID, err)
+	}
+	if len(r.Tags) == 0 {
+		r.Tags = nil
+	}
+	r.CreatedAt = r.CreatedAt.UTC()
+	r.UpdatedAt = r.UpdatedAt.UTC()
+	return r, nil
+}
+
+// encodeTags stores absent tags as an empty object, matching the column
+// default.
+func encodeTags(tags map[string]string) (string, error) {
+	if len(tags) == 0 {
+		return "{}", nil
+	}
+	b, err := json.Marshal(tags)
+	return string(b), err
+}
@@ -0,0 +1,121 @@
+package readmodel
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/infrastructure"
+)
+
+// newTestPostgresStore returns a store on the test database, or skips the test
+// if not set. We don't spin up Postgres for unit tests — set POSTGRES_TEST_DSN
+// to a database with db/init.sql applied to exercise the real implementation.
+func newTestPostgresStore(t *testing.T) (*PostgresStore, *sql.DB) {
+	t.Helper()
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set; skipping Postgres integration test")
+	}
+	db, err := infrastructure.NewPostgresDB(context.Background(), infrastructure.PostgresConfig{URL: dsn, PingTimeout: 5 * time.Second})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	return NewPostgresStore(db), db
+}
+
+func TestPostgresStore_CreateGetRoundTrip(t *testing.T) {
+	store, _ := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	record := model.ResourceRecord{
+		Resource: model.Resource{
+			ID: uuid.NewString(), ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending", RequestedBy: "rafael",
+			Region: "eu-west-1", Tags: map[string]string{"owner": "payments"},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, store.Create(ctx, record))
+
+	resubmitted := record
+	resubmitted.Status = "completed"
+	resubmitted.RequestedBy = "mallory"
+	assert.ErrorIs(t, store.Create(ctx, resubmitted), outbound.ErrResourceExists)
+
+	got, err := store.Get(ctx, record.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", got.Status)
+	assert.Equal(t, "rafael", got.RequestedBy)
+	assert.Equal(t, "eu-west-1", got.Region)
+	assert.Equal(t, map[string]string{"owner": "payments"}, got.Tags)
+	assert.True(t, now.Equal(got.CreatedAt))
+}
+
+func TestPostgresStore_UpdateStatusIsCompareAndSet(t *testing.T) {
+	store, _ := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	id := uuid.NewString()
+	require.NoError(t, store.Create(ctx, model.ResourceRecord{
+		Resource:  model.Resource{ID: id, ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending", RequestedBy: "rafael"},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}))
+
+	require.NoError(t, store.UpdateStatus(ctx, id, "pending", "in_progress", now.Add(time.Second)))
+	assert.ErrorIs(t, store.UpdateStatus(ctx, id, "pending", "failed", now.Add(time.Second)), outbound.ErrStatusChanged)
+	assert.ErrorIs(t, store.UpdateStatus(ctx, uuid.NewString(), "pending", "failed", now), outbound.ErrResourceNotFound)
+
+	got, err := store.Get(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, "in_progress", got.Status)
+}
+
+func TestPostgresStore_GetMissingReturnsNotFound(t *testing.T) {
+	store, _ := newTestPostgresStore(t)
+	_, err := store.Get(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, outbound.ErrResourceNotFound)
+}
+
+func TestPostgresStore_ListPagesWithinFilter(t *testing.T) {
+	store, _ := newTestPostgresStore(t)
+	ctx := context.Background()
+
+	// A unique requester scopes the listing to this test's rows.
+	requester := uuid.NewString()
+	base := time.Now().UTC().Truncate(time.Microsecond)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, store.Create(ctx, model.ResourceRecord{
+			Resource:  model.Resource{ID: uuid.NewString(), ResourceType: "S3", CloudProvider: "AWS", Specification: "standard", Status: "pending", RequestedBy: requester},
+			CreatedAt: base.Add(time.Duration(i) * time.Second),
+			UpdatedAt: base,
+		}))
+	}
+	filter := model.ResourceFilter{RequestedBy: requester}
+
+	first, hasMore, err := store.List(ctx, model.ResourceQuery{Filter: filter, Limit: 2})
+	require.NoError(t, err)
+	assert.True(t, hasMore)
+	require.Len(t, first, 2)
+	assert.True(t, first[0].CreatedAt.After(first[1].CreatedAt))
+
+	last := first[1].Key()
+	rest, hasMore, err := store.List(ctx, model.ResourceQuery{Filter: filter, Limit: 2, After: &last})
+	require.NoError(t, err)
+	assert.False(t, hasMore)
+	assert.Len(t, rest, 1)
+
+	n, err := store.Count(ctx, filter)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
@@ -2,71 +2,214 @@ package sqs
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/base64"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"go.opentelemetry.io/otel"
 
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
 )
 
+// maxBatchEntries is the most messages SQS accepts in one SendMessageBatch.
+const maxBatchEntries = 10
+
 // ResourcePublisher publishes resource provisioning requests to SQS.
+//
+// On a FIFO queue (its name ends in ".fifo") every message carries the
+// resource ID as its MessageGroupId, so requests for one resource are
+// delivered in order, like the Kafka path keyed by resource ID. Its
+// MessageDeduplicationId is the client's idempotency key when the request
+// had one, and otherwise the message ID, so a retried publish within SQS's
+// five-minute deduplication window is dropped by the queue.
 type ResourcePublisher struct {
-	client   *sqs.Client
-	queueURL string
+	client     *sqs.Client
+	queueURL   string
+	serializer envelope.Serializer
+	fifo       bool
 }
 
-// Ensure ResourcePublisher implements the ResourcePublisher interface.
-var _ outbound.ResourcePublisher = (*ResourcePublisher)(nil)
+// Ensure ResourcePublisher implements the BatchResourcePublisher interface.
+var _ outbound.BatchResourcePublisher = (*ResourcePublisher)(nil)
 
-// NewResourcePublisher creates a new ResourcePublisher.
-func NewResourcePublisher(client *sqs.Client, queueURL string) *ResourcePublisher {
+// NewResourcePublisher creates a new ResourcePublisher encoding events with
+// serializer.
+func NewResourcePublisher(client *sqs.Client, queueURL string, serializer envelope.Serializer) *ResourcePublisher {
 	return &ResourcePublisher{
-		client:   client,
-		queueURL: queueURL,
+		client:     client,
+		queueURL:   queueURL,
+		serializer: serializer,
+		fifo:       strings.HasSuffix(queueURL, ".fifo"),
 	}
 }
 
-// Publish sends a resource provisioning request to the SQS queue.
+// Publish wraps the resource in a provisioning request event and sends it to
+// the SQS queue.
 func (p *ResourcePublisher) Publish(ctx context.Context, resource model.Resource) error {
-	body, err := json.Marshal(resource)
+	entry, err := p.entry(ctx, resource)
+	if err != nil {
+		return err
+	}
+
+	input := &sqs.SendMessageInput{
+		MessageBody:            entry.MessageBody,
+		QueueUrl:               aws.String(p.queueURL),
+		MessageAttributes:      entry.MessageAttributes,
+		MessageGroupId:         entry.MessageGroupId,
+		MessageDeduplicationId: entry.MessageDeduplicationId,
+	}
+
+	if _, err = p.client.SendMessage(ctx, input); err != nil {
+		return publishError(resource.ID, err)
+	}
+
+	return nil
+}
+
+// PublishBatch sends entries with SendMessageBatch, up to ten per call. SQS
+// reports success per message, so one entry failing does not fail the rest.
+//
+// On a FIFO queue a call carries at most one entry per message group, and
+// once an entry fails the group's later entries are not sent, so a retry
+// cannot deliver them ahead of it. They fail with the same error.
+func (p *ResourcePublisher) PublishBatch(ctx context.Context, entries []outbound.BatchEntry) []error {
+	errs := make([]error, len(entries))
+	failedGroups := map[string]error{}
+	pending := make([]int, len(entries))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for len(pending) > 0 {
+		// Batch entry IDs are the entries' indexes, to match up the results.
+		batch := make([]sqstypes.SendMessageBatchRequestEntry, 0, maxBatchEntries)
+		inBatch := map[string]bool{}
+		var deferred []int
+		for _, i := range pending {
+			group := p.group(entries[i].Resource)
+			if err, failed := failedGroups[group]; failed {
+				errs[i] = err
+				continue
+			}
+			if len(batch) == maxBatchEntries || inBatch[group] {
+				deferred = append(deferred, i)
+				continue
+			}
+			entry, err := p.entry(entries[i].Context, entries[i].Resource)
+			if err != nil {
+				errs[i] = err
+				p.failGroup(failedGroups, group, err)
+				continue
+			}
+			entry.Id = aws.String(strconv.Itoa(i))
+			batch = append(batch, entry)
+			if p.fifo {
+				inBatch[group] = true
+			}
+		}
+		pending = deferred
+		if len(batch) == 0 {
+			continue
+		}
+
+		out, err := p.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+			QueueUrl: aws.String(p.queueURL),
+			Entries:  batch,
+		})
+		if err != nil {
+			for _, entry := range batch {
+				i, _ := strconv.Atoi(aws.ToString(entry.Id))
+				errs[i] = publishError(entries[i].Resource.ID, err)
+				p.failGroup(failedGroups, p.group(entries[i].Resource), errs[i])
+			}
+			continue
+		}
+		for _, failed := range out.Failed {
+			i, err := strconv.Atoi(aws.ToString(failed.Id))
+			if err != nil || i < 0 || i >= len(entries) {
+				continue
+			}
+			errs[i] = publishError(entries[i].Resource.ID,
+				fmt.Errorf("%s: %s", aws.ToString(failed.Code), aws.ToString(failed.Message)))
+			p.failGroup(failedGroups, p.group(entries[i].Resource), errs[i])
+		}
+	}
+	return errs
+}
+
+// group is the message group an entry for resource is sent in.
+func (p *ResourcePublisher) group(resource model.Resource) string {
+	if !p.fifo {
+		return ""
+	}
+	return resource.ID
+}
+
+// failGroup records that group had an entry fail with err. Only FIFO queues
+// order by group, so on a standard queue nothing is recorded.
+func (p *ResourcePublisher) failGroup(failed map[string]error, group string, err error) {
+	if p.fifo {
+		failed[group] = err
+	}
+}
+
+// entry wraps the resource in an event and builds the SQS message carrying
+// it, as a batch entry without an ID.
+func (p *ResourcePublisher) entry(ctx context.Context, resource model.Resource) (sqstypes.SendMessageBatchRequestEntry, error) {
+	event, body, err := envelope.ResourceProvisionRequested(ctx, p.serializer, resource)
 	if err != nil {
-		return errors.NewDomainError(
+		return sqstypes.SendMessageBatchRequestEntry{}, errors.NewDomainError(
 			errors.ErrCodeQueueError,
 			"failed to serialize resource for publishing",
 			err,
 		)
 	}
 
-	// Inject the active trace context (W3C traceparent/tracestate/baggage) into
-	// the message attributes so the provisioner can continue this trace: its
-	// ProcessMessage span becomes a child of this producer span and both
-	// services' logs share one trace_id. No-op when tracing is disabled.
+	// Stamp the event's ID for the provisioner's inbox and its content type,
+	// and inject the active trace context (W3C traceparent/tracestate/baggage)
+	// into the message attributes so the provisioner can continue this trace:
+	// its ProcessMessage span becomes a child of this producer span and both
+	// services' logs share one trace_id. Injection is a no-op when tracing is
+	// disabled.
 	attrs := sqsAttributeCarrier{}
+	attrs.Set(outbound.MessageIDAttribute, event.ID)
+	attrs.Set(envelope.ContentTypeHeader, p.serializer.ContentType())
 	otel.GetTextMapPropagator().Inject(ctx, attrs)
 
-	input := &sqs.SendMessageInput{
-		MessageBody: aws.String(string(body)),
-		QueueUrl:    aws.String(p.queueURL),
-	}
-	if len(attrs) > 0 {
-		input.MessageAttributes = attrs
+	// SQS message bodies are text, so binary formats travel base64-encoded.
+	messageBody := string(body)
+	if p.serializer.Binary() {
+		messageBody = base64.StdEncoding.EncodeToString(body)
 	}
 
-	if _, err = p.client.SendMessage(ctx, input); err != nil {
-		return errors.NewDomainError(
-			errors.ErrCodeQueueError,
-			fmt.Sprintf("failed to publish resource %s to queue", resource.ID),
-			err,
-		)
+	entry := sqstypes.SendMessageBatchRequestEntry{
+		MessageBody:       aws.String(messageBody),
+		MessageAttributes: attrs,
+	}
+	if p.fifo {
+		dedupID := outbound.IdempotencyKeyFromContext(ctx)
+		if dedupID == "" {
+			dedupID = event.ID
+		}
+		entry.MessageGroupId = aws.String(resource.ID)
+		entry.MessageDeduplicationId = aws.String(dedupID)
 	}
+	return entry, nil
+}
 
-	return nil
+func publishError(resourceID string, err error) error {
+	return errors.NewDomainError(
+		errors.ErrCodeQueueError,
+		fmt.Sprintf("failed to publish resource %s to queue", resourceID),
+		err,
+	)
 }
 
 // sqsAttributeCarrier adapts SQS message attributes to OTel's TextMapCarrier so
@@ -0,0 +1,226 @@
+package sqs
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// sentMessage is one message as the SQS stand-in received it.
+type sentMessage struct {
+	Id                     string
+	MessageBody            string
+	MessageGroupId         string
+	MessageDeduplicationId string
+	MessageAttributes      map[string]struct{ StringValue string }
+}
+
+// sqsStandIn speaks just enough of the SQS JSON protocol for the publisher:
+// SendMessage and SendMessageBatch. Entries whose body mentions failBody are
+// reported failed; a non-zero status fails every call outright.
+type sqsStandIn struct {
+	mu       sync.Mutex
+	calls    map[string]int
+	messages []sentMessage
+	failBody string
+	status   int
+}
+
+func (s *sqsStandIn) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	action := strings.TrimPrefix(r.Header.Get("X-Amz-Target"), "AmazonSQS.")
+	s.calls[action]++
+	if s.status != 0 {
+		w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+		w.WriteHeader(s.status)
+		_, _ = w.Write([]byte(`{"__type":"com.amazonaws.sqs#InternalError","message":"stand-in failure"}`))
+		return
+	}
+
+	var in struct {
+		sentMessage
+		Entries []sentMessage
+	}
+	if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-amz-json-1.0")
+	switch action {
+	case "SendMessage":
+		s.messages = append(s.messages, in.sentMessage)
+		_ = json.NewEncoder(w).Encode(map[string]string{"MessageId": strconv.Itoa(len(s.messages))})
+	case "SendMessageBatch":
+		out := struct {
+			Successful []map[string]string
+			Failed     []map[string]any
+		}{Successful: []map[string]string{}, Failed: []map[string]any{}}
+		for _, e := range in.Entries {
+			if s.failBody != "" && strings.Contains(e.MessageBody, s.failBody) {
+				out.Failed = append(out.Failed, map[string]any{"Id": e.Id, "Code": "InvalidParameterValue", "Message": "rejected", "SenderFault": true})
+				continue
+			}
+			s.messages = append(s.messages, e)
+			out.Successful = append(out.Successful, map[string]string{"Id": e.Id, "MessageId": strconv.Itoa(len(s.messages))})
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	default:
+		http.Error(w, "unsupported action "+action, http.StatusBadRequest)
+	}
+}
+
+func newStandIn(t *testing.T) (*sqsStandIn, *sqs.Client) {
+	t.Helper()
+	standIn := &sqsStandIn{calls: map[string]int{}}
+	srv := httptest.NewServer(standIn)
+	t.Cleanup(srv.Close)
+
+	client := sqs.New(sqs.Options{
+		BaseEndpoint:                     aws.String(srv.URL),
+		Region:                           "us-east-1",
+		Credentials:                      aws.AnonymousCredentials{},
+		RetryMaxAttempts:                 1,
+		DisableMessageChecksumValidation: true,
+	})
+	return standIn, client
+}
+
+func resource(id string) model.Resource {
+	return model.Resource{ID: id, ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", RequestedBy: "rafael"}
+}
+
+func TestPublish_StandardQueue(t *testing.T) {
+	standIn, client := newStandIn(t)
+	p := NewResourcePublisher(client, "http://queue/provisioning", envelope.JSONSerializer{})
+
+	require.NoError(t, p.Publish(context.Background(), resource("vm-1")))
+
+	require.Len(t, standIn.messages, 1)
+	m := standIn.messages[0]
+	assert.Empty(t, m.MessageGroupId, "standard queues take no group ID")
+	assert.Empty(t, m.MessageDeduplicationId)
+	assert.NotEmpty(t, m.MessageAttributes[outbound.MessageIDAttribute].StringValue)
+	assert.Equal(t, envelope.ContentTypeJSON, m.MessageAttributes[envelope.ContentTypeHeader].StringValue)
+}
+
+func TestPublish_FIFOQueue_GroupsByResourceAndDedupesByIdempotencyKey(t *testing.T) {
+	standIn, client := newStandIn(t)
+	p := NewResourcePublisher(client, "http://queue/provisioning.fifo", envelope.JSONSerializer{})
+
+	keyed := outbound.WithIdempotencyKey(context.Background(), "6f1c1f2e-4b7a-4c3b-9d8e-0a1b2c3d4e5f")
+	require.NoError(t, p.Publish(keyed, resource("vm-1")))
+	unkeyed := outbound.WithMessageID(context.Background(), "msg-2")
+	require.NoError(t, p.Publish(unkeyed, resource("vm-2")))
+
+	require.Len(t, standIn.messages, 2)
+	assert.Equal(t, "vm-1", standIn.messages[0].MessageGroupId)
+	assert.Equal(t, "6f1c1f2e-4b7a-4c3b-9d8e-0a1b2c3d4e5f", standIn.messages[0].MessageDeduplicationId)
+	assert.Equal(t, "vm-2", standIn.messages[1].MessageGroupId)
+	assert.Equal(t, "msg-2", standIn.messages[1].MessageDeduplicationId,
+		"without an idempotency key the message ID deduplicates")
+}
+
+func TestPublishBatch_SplitsIntoBatchesOfTen(t *testing.T) {
+	standIn, client := newStandIn(t)
+	p := NewResourcePublisher(client, "http://queue/provisioning.fifo", envelope.JSONSerializer{})
+
+	entries := make([]outbound.BatchEntry, 12)
+	for i := range entries {
+		id := "vm-" + strconv.Itoa(i)
+		entries[i] = outbound.BatchEntry{
+			Context:  outbound.WithMessageID(context.Background(), "msg-"+strconv.Itoa(i)),
+			Resource: resource(id),
+		}
+	}
+
+	errs := p.PublishBatch(context.Background(), entries)
+
+	require.Len(t, errs, 12)
+	for i, err := range errs {
+		assert.NoError(t, err, "entry %d", i)
+	}
+	assert.Equal(t, 2, standIn.calls["SendMessageBatch"])
+	require.Len(t, standIn.messages, 12)
+	for i, m := range standIn.messages {
+		assert.Equal(t, "vm-"+strconv.Itoa(i), m.MessageGroupId, "entries keep their order")
+		assert.Equal(t, "msg-"+strconv.Itoa(i), m.MessageAttributes[outbound.MessageIDAttribute].StringValue)
+		assert.Equal(t, "msg-"+strconv.Itoa(i), m.MessageDeduplicationId)
+	}
+}
+
+func TestPublishBatch_ReportsFailedEntries(t *testing.T) {
+	standIn, client := newStandIn(t)
+	standIn.failBody = "vm-bad"
+	p := NewResourcePublisher(client, "http://queue/provisioning", envelope.JSONSerializer{})
+
+	errs := p.PublishBatch(context.Background(), []outbound.BatchEntry{
+		{Context: context.Background(), Resource: resource("vm-1")},
+		{Context: context.Background(), Resource: resource("vm-bad")},
+		{Context: context.Background(), Resource: resource("vm-3")},
+	})
+
+	require.Len(t, errs, 3)
+	assert.NoError(t, errs[0])
+	require.Error(t, errs[1])
+	assert.Contains(t, errs[1].Error(), "vm-bad")
+	assert.NoError(t, errs[2])
+	assert.Len(t, standIn.messages, 2)
+}
+
+func TestPublishBatch_FailedCallFailsEveryEntry(t *testing.T) {
+	standIn, client := newStandIn(t)
+	standIn.status = http.StatusInternalServerError
+	p := NewResourcePublisher(client, "http://queue/provisioning", envelope.JSONSerializer{})
+
+	errs := p.PublishBatch(context.Background(), []outbound.BatchEntry{
+		{Context: context.Background(), Resource: resource("vm-1")},
+		{Context: context.Background(), Resource: resource("vm-2")},
+	})
+
+	require.Len(t, errs, 2)
+	assert.Error(t, errs[0])
+	assert.Error(t, errs[1])
+}
+
+func TestPublishBatch_FIFOSkipsAGroupAfterItsFirstFailure(t *testing.T) {
+	standIn, client := newStandIn(t)
+	standIn.failBody = "msg-bad"
+	p := NewResourcePublisher(client, "http://queue/provisioning.fifo", envelope.JSONSerializer{})
+
+	entry := func(messageID, id string) outbound.BatchEntry {
+		return outbound.BatchEntry{Context: outbound.WithMessageID(context.Background(), messageID), Resource: resource(id)}
+	}
+	errs := p.PublishBatch(context.Background(), []outbound.BatchEntry{
+		entry("msg-bad", "vm-1"),
+		entry("msg-2", "vm-2"),
+		entry("msg-3", "vm-1"),
+		entry("msg-4", "vm-2"),
+	})
+
+	require.Len(t, errs, 4)
+	require.Error(t, errs[0])
+	assert.NoError(t, errs[1])
+	assert.Error(t, errs[2], "a later entry of a failed group must not overtake it")
+	assert.NoError(t, errs[3])
+	require.Len(t, standIn.messages, 2)
+	assert.Equal(t, "msg-2", standIn.messages[0].MessageDeduplicationId)
+	assert.Equal(t, "msg-4", standIn.messages[1].MessageDeduplicationId)
+	assert.Equal(t, 2, standIn.calls["SendMessageBatch"], "each call carries one entry per group")
+}
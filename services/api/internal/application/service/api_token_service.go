@@ -0,0 +1,245 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	stderrors "errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// APITokenClientID is the Principal.ClientID of callers authenticated with an
+// API token.
+const APITokenClientID = "api-token"
+
+// lastUsedResolution bounds how often a token's last-used time is written, so
+// a busy CI token does not cost a store write per request.
+const lastUsedResolution = time.Minute
+
+// APITokenConfig bounds the lifetime of issued tokens.
+type APITokenConfig struct {
+	// DefaultTTL applies when a request does not ask for a lifetime.
+	DefaultTTL time.Duration
+	// MaxTTL is the longest lifetime a request may ask for.
+	MaxTTL time.Duration
+}
+
+// APITokenService issues, lists and revokes personal API tokens, and verifies
+// them on incoming requests.
+type APITokenService struct {
+	store  outbound.APITokenStore
+	cfg    APITokenConfig
+	logger logger.Logger
+	now    func() time.Time
+}
+
+var (
+	_ inbound.APITokenService = (*APITokenService)(nil)
+	_ outbound.TokenVerifier  = (*APITokenService)(nil)
+)
+
+// NewAPITokenService wires the API token service to its store. Zero TTLs
+// default to 30 days. Tokens keep the owner's groups from issuance, so MaxTTL
+// is also how long a revoked group membership can outlive its removal.
+func NewAPITokenService(store outbound.APITokenStore, cfg APITokenConfig, log logger.Logger) *APITokenService {
+	if cfg.DefaultTTL <= 0 {
+		cfg.DefaultTTL = 30 * 24 * time.Hour
+	}
+	if cfg.MaxTTL <= 0 {
+		cfg.MaxTTL = 30 * 24 * time.Hour
+	}
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	return &APITokenService{store: store, cfg: cfg, logger: log, now: time.Now}
+}
+
+// IssueAPIToken creates a token for the caller and returns it with its
+// secret. Only callers signed in with the identity provider may issue tokens;
+// a token cannot mint further tokens.
+func (s *APITokenService) IssueAPIToken(ctx context.Context, req model.IssueAPITokenRequest) (*model.IssuedAPIToken, error) {
+	owner, err := tokenOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := s.cfg.DefaultTTL
+	if req.ExpiresInDays > 0 {
+		ttl = time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	}
+	if ttl > s.cfg.MaxTTL {
+		return nil, errors.InvalidInput(fmt.Sprintf("expires_in_days may be at most %d", int(s.cfg.MaxTTL.Hours()/24))).
+			WithDetail("field", "expires_in_days")
+	}
+	for _, scope := range req.Scopes {
+		if !slices.Contains(model.APIScopes, scope) {
+			return nil, errors.InvalidInput(fmt.Sprintf("unknown scope %q", scope)).WithDetail("field", "scopes")
+		}
+	}
+
+	secret, err := newTokenSecret()
+	if err != nil {
+		return nil, errors.Internal("failed to generate api token", err)
+	}
+	now := s.now().UTC()
+	token := model.APIToken{
+		ID:            uuid.NewString(),
+		Name:          req.Name,
+		Owner:         owner.Subject,
+		OwnerUsername: owner.Username,
+		Groups:        slices.Clone(owner.Groups),
+		Scopes:        slices.Compact(slices.Sorted(slices.Values(req.Scopes))),
+		Hash:          hashToken(secret),
+		Hint:          "…" + secret[len(secret)-4:],
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(ttl),
+	}
+	if err := s.store.Create(ctx, token); err != nil {
+		return nil, errors.Internal("failed to store api token", err)
+	}
+
+	s.logger.WithContext(ctx).Info("api token issued",
+		logger.F("token_id", token.ID),
+		logger.F("owner", token.Owner),
+		logger.F("scopes", token.Scopes),
+		logger.F("expires_at", token.ExpiresAt),
+	)
+	return &model.IssuedAPIToken{APIToken: token, Token: secret}, nil
+}
+
+// ListAPITokens returns the caller's tokens, newest first.
+func (s *APITokenService) ListAPITokens(ctx context.Context) ([]model.APIToken, error) {
+	owner, err := tokenOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := s.store.ListByOwner(ctx, owner.Subject)
+	if err != nil {
+		return nil, errors.Internal("failed to list api tokens", err)
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken revokes one of the caller's tokens. It stops authenticating
+// immediately.
+func (s *APITokenService) RevokeAPIToken(ctx context.Context, id string) error {
+	owner, err := tokenOwner(ctx)
+	if err != nil {
+		return err
+	}
+	if err := s.store.Revoke(ctx, owner.Subject, id, s.now().UTC()); err != nil {
+		if stderrors.Is(err, outbound.ErrAPITokenNotFound) {
+			return errors.NotFound("api token", id)
+		}
+		return errors.Internal("failed to revoke api token", err)
+	}
+
+	s.logger.WithContext(ctx).Info("api token revoked",
+		logger.F("token_id", id),
+		logger.F("owner", owner.Subject),
+	)
+	return nil
+}
+
+// Verify authenticates an API token, returning its owner limited to the
+// token's scopes. Unknown, revoked and expired tokens are ErrUnauthorized.
+func (s *APITokenService) Verify(ctx context.Context, secret string) (*model.Principal, error) {
+	token, err := s.store.GetByHash(ctx, hashToken(secret))
+	if err != nil {
+		if stderrors.Is(err, outbound.ErrAPITokenNotFound) {
+			return nil, errors.NewDomainError(errors.ErrCodeInvalidToken, "unknown api token", errors.ErrUnauthorized)
+		}
+		return nil, fmt.Errorf("look up api token: %w", err)
+	}
+
+	now := s.now().UTC()
+	switch {
+	case token.RevokedAt != nil:
+		return nil, errors.NewDomainError(errors.ErrCodeInvalidToken, "api token has been revoked", errors.ErrUnauthorized)
+	case !token.Active(now):
+		return nil, errors.NewDomainError(errors.ErrCodeTokenExpired, "api token has expired", errors.ErrUnauthorized)
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= lastUsedResolution {
+		// A failed write only loses usage information; the token is valid.
+		if err := s.store.TouchLastUsed(ctx, token.ID, now); err != nil {
+			s.logger.WithContext(ctx).WithError(err).Warn("failed to record api token use",
+				logger.F("token_id", token.ID),
+			)
+		}
+	}
+
+	return &model.Principal{
+		Subject:    token.Owner,
+		Username:   token.OwnerUsername,
+		ClientID:   APITokenClientID,
+		Scopes:     token.Scopes,
+		Groups:     token.Groups,
+		ExpiresAt:  token.ExpiresAt,
+		APITokenID: token.ID,
+	}, nil
+}
+
+// VerifierWith returns a TokenVerifier that checks API tokens itself and
+// hands every other bearer token to next, so both are accepted wherever next
+// was.
+func (s *APITokenService) VerifierWith(next outbound.TokenVerifier) outbound.TokenVerifier {
+	return apiTokenVerifier{tokens: s, next: next}
+}
+
+type apiTokenVerifier struct {
+	tokens *APITokenService
+	next   outbound.TokenVerifier
+}
+
+func (v apiTokenVerifier) Verify(ctx context.Context, token string) (*model.Principal, error) {
+	if strings.HasPrefix(token, model.APITokenPrefix) {
+		return v.tokens.Verify(ctx, token)
+	}
+	return v.next.Verify(ctx, token)
+}
+
+// tokenOwner is the caller managing their tokens, who must be a user signed
+// in with the identity provider.
+func tokenOwner(ctx context.Context) (model.Principal, error) {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return model.Principal{}, errors.Unauthorized("authentication is required to manage api tokens")
+	}
+	if principal.ViaAPIToken() {
+		return model.Principal{}, errors.Forbidden("api tokens cannot manage api tokens; sign in instead")
+	}
+	if principal.Machine {
+		return model.Principal{}, errors.Forbidden("api tokens belong to users; service clients use client credentials")
+	}
+	return principal, nil
+}
+
+// newTokenSecret returns a prefixed token carrying 256 random bits.
+func newTokenSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return model.APITokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashToken is the lookup key of a token. The secret is random and long, so
+// a fast unsalted hash is enough: it cannot be brute-forced from the hash.
+func hashToken(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
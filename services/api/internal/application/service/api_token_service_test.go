@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/apitoken"
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+func newTestAPITokenService(t *testing.T) (*APITokenService, *apitoken.MemoryStore, *time.Time) {
+	t.Helper()
+	store := apitoken.NewMemoryStore()
+	svc := NewAPITokenService(store, APITokenConfig{DefaultTTL: 24 * time.Hour, MaxTTL: 7 * 24 * time.Hour}, nil)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	svc.now = func() time.Time { return now }
+	return svc, store, &now
+}
+
+func ownerContext() context.Context {
+	return model.WithPrincipal(context.Background(), model.Principal{
+		Subject: "user-1", Username: "rafael", Groups: []string{"team-payments"},
+	})
+}
+
+func TestAPITokenService_IssueThenVerify(t *testing.T) {
+	svc, _, _ := newTestAPITokenService(t)
+
+	issued, err := svc.IssueAPIToken(ownerContext(), model.IssueAPITokenRequest{
+		Name: "ci", Scopes: []string{model.ScopeProvisionWrite},
+	})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(issued.Token, model.APITokenPrefix))
+	assert.NotContains(t, issued.Hash, issued.Token[len(model.APITokenPrefix):], "only a hash of the secret is kept")
+	assert.Equal(t, "…"+issued.Token[len(issued.Token)-4:], issued.Hint)
+	assert.Equal(t, issued.CreatedAt.Add(24*time.Hour), issued.ExpiresAt, "default ttl applies")
+
+	principal, err := svc.Verify(context.Background(), issued.Token)
+	require.NoError(t, err)
+	assert.Equal(t, model.Principal{
+		Subject:    "user-1",
+		Username:   "rafael",
+		ClientID:   APITokenClientID,
+		Scopes:     []string{model.ScopeProvisionWrite},
+		Groups:     []string{"team-payments"},
+		ExpiresAt:  issued.ExpiresAt,
+		APITokenID: issued.ID,
+	}, *principal)
+}
+
+func TestAPITokenService_IssueRejects(t *testing.T) {
+	svc, _, _ := newTestAPITokenService(t)
+	viaToken := model.WithPrincipal(context.Background(), model.Principal{Subject: "user-1", APITokenID: "tok-1"})
+
+	cases := map[string]struct {
+		ctx  context.Context
+		req  model.IssueAPITokenRequest
+		want error
+	}{
+		"unauthenticated": {context.Background(), model.IssueAPITokenRequest{Name: "ci", Scopes: []string{model.ScopeProvisionWrite}}, domainerrors.ErrUnauthorized},
+		"via api token":   {viaToken, model.IssueAPITokenRequest{Name: "ci", Scopes: []string{model.ScopeProvisionWrite}}, domainerrors.ErrForbidden},
+		"beyond max ttl":  {ownerContext(), model.IssueAPITokenRequest{Name: "ci", Scopes: []string{model.ScopeProvisionWrite}, ExpiresInDays: 8}, domainerrors.ErrInvalidInput},
+		"unknown scope":   {ownerContext(), model.IssueAPITokenRequest{Name: "ci", Scopes: []string{"admin"}}, domainerrors.ErrInvalidInput},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.IssueAPIToken(tc.ctx, tc.req)
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+func TestAPITokenService_VerifyRejectsUnknownExpiredAndRevoked(t *testing.T) {
+	svc, _, now := newTestAPITokenService(t)
+	ctx := ownerContext()
+
+	_, err := svc.Verify(ctx, model.APITokenPrefix+"unknown")
+	assert.ErrorIs(t, err, domainerrors.ErrUnauthorized)
+
+	expiring, err := svc.IssueAPIToken(ctx, model.IssueAPITokenRequest{Name: "a", Scopes: []string{model.ScopeProvisionWrite}, ExpiresInDays: 1})
+	require.NoError(t, err)
+	revoked, err := svc.IssueAPIToken(ctx, model.IssueAPITokenRequest{Name: "b", Scopes: []string{model.ScopeProvisionWrite}, ExpiresInDays: 7})
+	require.NoError(t, err)
+	require.NoError(t, svc.RevokeAPIToken(ctx, revoked.ID))
+
+	_, err = svc.Verify(ctx, revoked.Token)
+	assert.ErrorIs(t, err, domainerrors.ErrUnauthorized)
+
+	*now = now.Add(25 * time.Hour)
+	_, err = svc.Verify(ctx, expiring.Token)
+	assert.ErrorIs(t, err, domainerrors.ErrUnauthorized)
+}
+
+func TestAPITokenService_TracksLastUse(t *testing.T) {
+	svc, store, now := newTestAPITokenService(t)
+	ctx := ownerContext()
+	issued, err := svc.IssueAPIToken(ctx, model.IssueAPITokenRequest{Name: "ci", Scopes: []string{model.ScopeProvisionWrite}})
+	require.NoError(t, err)
+
+	lastUsed := func() time.Time {
+		tokens, err := store.ListByOwner(ctx, "user-1")
+		require.NoError(t, err)
+		require.NotNil(t, tokens[0].LastUsedAt)
+		return *tokens[0].LastUsedAt
+	}
+
+	first := *now
+	_, err = svc.Verify(ctx, issued.Token)
+	require.NoError(t, err)
+	assert.Equal(t, first, lastUsed())
+
+	*now = now.Add(10 * time.Second)
+	_, err = svc.Verify(ctx, issued.Token)
+	require.NoError(t, err)
+	assert.Equal(t, first, lastUsed(), "uses within a minute are not written")
+
+	*now = now.Add(time.Minute)
+	_, err = svc.Verify(ctx, issued.Token)
+	require.NoError(t, err)
+	assert.Equal(t, *now, lastUsed())
+}
+
+func TestAPITokenService_ListAndRevokeAreOwnerScoped(t *testing.T) {
+	svc, _, _ := newTestAPITokenService(t)
+	issued, err := svc.IssueAPIToken(ownerContext(), model.IssueAPITokenRequest{Name: "ci", Scopes: []string{model.ScopeProvisionWrite}})
+	require.NoError(t, err)
+
+	other := model.WithPrincipal(context.Background(), model.Principal{Subject: "user-2"})
+	tokens, err := svc.ListAPITokens(other)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+	assert.ErrorIs(t, svc.RevokeAPIToken(other, issued.ID), domainerrors.ErrNotFound)
+
+	tokens, err = svc.ListAPITokens(ownerContext())
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, issued.ID, tokens[0].ID)
+}
+
+func TestAPITokenService_VerifierWithRoutesByPrefix(t *testing.T) {
+	svc, _, _ := newTestAPITokenService(t)
+	issued, err := svc.IssueAPIToken(ownerContext(), model.IssueAPITokenRequest{Name: "ci", Scopes: []string{model.ScopeProvisionWrite}})
+	require.NoError(t, err)
+	jwts := &mocks.FakeTokenVerifier{Principal: model.Principal{Subject: "jwt-user"}}
+	verifier := svc.VerifierWith(jwts)
+
+	principal, err := verifier.Verify(context.Background(), issued.Token)
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, principal.APITokenID)
+	assert.Empty(t, jwts.LastToken, "api tokens never reach the JWT verifier")
+
+	principal, err = verifier.Verify(context.Background(), "eyJ.header.sig")
+	require.NoError(t, err)
+	assert.Equal(t, "jwt-user", principal.Subject)
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// DefaultAuditAdminGroup is the group allowed to read the audit log when
+// AuditConfig names none.
+const DefaultAuditAdminGroup = "platform-admins"
+
+// auditVerifyBatch is how many entries VerifyAuditLog reads at a time.
+const auditVerifyBatch = 500
+
+// AuditConfig controls who may read the audit log.
+type AuditConfig struct {
+	// AdminGroups are the identity provider groups whose members may read
+	// and verify the log.
+	AdminGroups []string
+}
+
+// AuditService appends mutating API calls to the audit log and serves the
+// log to platform admins.
+type AuditService struct {
+	log         outbound.AuditLog
+	adminGroups []string
+	logger      logger.Logger
+	now         func() time.Time
+}
+
+var _ inbound.AuditService = (*AuditService)(nil)
+
+// NewAuditService wires the audit service to its log.
+func NewAuditService(log outbound.AuditLog, cfg AuditConfig, l logger.Logger) *AuditService {
+	if len(cfg.AdminGroups) == 0 {
+		cfg.AdminGroups = []string{DefaultAuditAdminGroup}
+	}
+	if l == nil {
+		l = logger.NopLogger{}
+	}
+	return &AuditService{log: log, adminGroups: cfg.AdminGroups, logger: l, now: time.Now}
+}
+
+// RecordAudit appends entry, filling in its ID, time, trace and the caller
+// from ctx.
+func (s *AuditService) RecordAudit(ctx context.Context, entry model.AuditEntry) error {
+	entry.ID = uuid.NewString()
+	entry.Time = s.now().UTC()
+	if principal, ok := model.PrincipalFromContext(ctx); ok {
+		entry.Actor = principal.Subject
+		entry.ActorUsername = principal.Username
+		entry.ClientID = principal.ClientID
+		entry.APITokenID = principal.APITokenID
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		entry.TraceID = sc.TraceID().String()
+	}
+	if entry.Outcome == "" {
+		entry.Outcome = model.AuditOutcomeFor(entry.Status)
+	}
+
+	if _, err := s.log.Append(ctx, entry); err != nil {
+		s.logger.WithContext(ctx).WithError(err).Error("failed to append audit entry",
+			logger.F("action", entry.Action),
+			logger.F("actor", entry.Actor),
+			logger.F("target", entry.Target),
+			logger.F("status", entry.Status),
+		)
+		return errors.Internal("failed to record audit entry", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns one page of entries matching q, newest first.
+// q.Limit is clamped to [1, MaxPageSize].
+func (s *AuditService) ListAuditEntries(ctx context.Context, q model.AuditQuery) (*model.AuditPage, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if q.Outcome != "" && !slices.Contains([]string{
+		model.AuditOutcomeSuccess, model.AuditOutcomeDenied, model.AuditOutcomeRejected, model.AuditOutcomeError,
+	}, q.Outcome) {
+		return nil, errors.InvalidInput("outcome must be one of success, denied, rejected, error").WithDetail("field", "outcome")
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	if q.Limit > MaxPageSize {
+		q.Limit = MaxPageSize
+	}
+
+	// One extra entry tells whether there is an older page.
+	limit := q.Limit
+	q.Limit++
+	entries, err := s.log.Query(ctx, q)
+	if err != nil {
+		return nil, errors.Internal("failed to query audit log", err)
+	}
+	page := &model.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		page.NextBefore = page.Entries[limit-1].Seq
+	}
+	return page, nil
+}
+
+// VerifyAuditLog walks the whole log and checks its hash chain.
+func (s *AuditService) VerifyAuditLog(ctx context.Context) (*model.AuditVerification, error) {
+	if err := s.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	result := &model.AuditVerification{Valid: true}
+	var prev *model.AuditEntry
+	for {
+		batch, err := s.log.Range(ctx, result.Entries, auditVerifyBatch)
+		if err != nil {
+			return nil, errors.Internal("failed to read audit log", err)
+		}
+		intact, err := model.VerifyAuditChain(prev, batch)
+		result.Entries += int64(intact)
+		if err != nil {
+			result.Valid, result.Error = false, err.Error()
+			s.logger.WithContext(ctx).Error("audit log chain is broken", logger.F("error", err.Error()))
+			return result, nil
+		}
+		if len(batch) < auditVerifyBatch {
+			return result, nil
+		}
+		prev = &batch[len(batch)-1]
+	}
+}
+
+// requireAdmin allows users in an admin group, signed in with the identity
+// provider. Service clients and API tokens cannot read the log.
+func (s *AuditService) requireAdmin(ctx context.Context) error {
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		return errors.Unauthorized("authentication is required to read the audit log")
+	}
+	if principal.ScopeRestricted() || !slices.ContainsFunc(principal.Groups, func(g string) bool {
+		return slices.Contains(s.adminGroups, g)
+	}) {
+		return errors.Forbidden("the audit log is only available to platform admins")
+	}
+	return nil
+}
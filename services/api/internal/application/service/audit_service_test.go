@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/audit"
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+func adminContext() context.Context {
+	return model.WithPrincipal(context.Background(), model.Principal{
+		Subject: "admin-1", Groups: []string{"platform-admins"},
+	})
+}
+
+func recordProvisions(t *testing.T, svc *AuditService, n int) {
+	t.Helper()
+	for range n {
+		require.NoError(t, svc.RecordAudit(ownerContext(), model.AuditEntry{
+			Action: model.AuditActionProvision, Target: "vm-001", Method: http.MethodPost, Path: "/v1/provision",
+			Status: http.StatusAccepted,
+		}))
+	}
+}
+
+func TestAuditService_RecordAttributesCaller(t *testing.T) {
+	log := audit.NewMemoryLog()
+	svc := NewAuditService(log, AuditConfig{}, nil)
+
+	require.NoError(t, svc.RecordAudit(ownerContext(), model.AuditEntry{
+		Action: model.AuditActionProvision, Target: "vm-001", Status: http.StatusForbidden,
+	}))
+
+	entries, err := log.Query(context.Background(), model.AuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "user-1", entries[0].Actor)
+	assert.Equal(t, "rafael", entries[0].ActorUsername)
+	assert.Equal(t, model.AuditOutcomeDenied, entries[0].Outcome)
+	assert.NotEmpty(t, entries[0].ID)
+	assert.False(t, entries[0].Time.IsZero())
+}
+
+func TestAuditService_ListPagesNewestFirst(t *testing.T) {
+	svc := NewAuditService(audit.NewMemoryLog(), AuditConfig{}, nil)
+	recordProvisions(t, svc, 5)
+
+	page, err := svc.ListAuditEntries(adminContext(), model.AuditQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, int64(5), page.Entries[0].Seq)
+	assert.Equal(t, int64(4), page.NextBefore)
+
+	page, err = svc.ListAuditEntries(adminContext(), model.AuditQuery{Limit: 2, Before: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	assert.Zero(t, page.NextBefore, "last page")
+}
+
+func TestAuditService_OnlyAdminsRead(t *testing.T) {
+	svc := NewAuditService(audit.NewMemoryLog(), AuditConfig{AdminGroups: []string{"auditors"}}, nil)
+
+	_, err := svc.ListAuditEntries(context.Background(), model.AuditQuery{})
+	assert.ErrorIs(t, err, domainerrors.ErrUnauthorized)
+
+	_, err = svc.ListAuditEntries(adminContext(), model.AuditQuery{})
+	assert.ErrorIs(t, err, domainerrors.ErrForbidden, "platform-admins is not an admin group here")
+
+	auditor := model.Principal{Subject: "auditor-1", Groups: []string{"auditors"}}
+	_, err = svc.ListAuditEntries(model.WithPrincipal(context.Background(), auditor), model.AuditQuery{})
+	assert.NoError(t, err)
+
+	// An auditor's API token cannot read the log.
+	auditor.APITokenID = "t1"
+	_, err = svc.VerifyAuditLog(model.WithPrincipal(context.Background(), auditor))
+	assert.ErrorIs(t, err, domainerrors.ErrForbidden)
+
+	_, err = svc.ListAuditEntries(model.WithPrincipal(context.Background(), model.Principal{Subject: "auditor-1", Groups: []string{"auditors"}}),
+		model.AuditQuery{Outcome: "maybe"})
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidInput)
+}
+
+// tamperedLog serves a stored entry with an edited field.
+type tamperedLog struct {
+	*audit.MemoryLog
+}
+
+func (l tamperedLog) Range(ctx context.Context, after int64, limit int) ([]model.AuditEntry, error) {
+	entries, err := l.MemoryLog.Range(ctx, after, limit)
+	for i := range entries {
+		if entries[i].Seq == 3 {
+			entries[i].Target = "vm-666"
+		}
+	}
+	return entries, err
+}
+
+func TestAuditService_Verify(t *testing.T) {
+	log := audit.NewMemoryLog()
+	svc := NewAuditService(log, AuditConfig{}, nil)
+	recordProvisions(t, svc, auditVerifyBatch+5)
+
+	result, err := svc.VerifyAuditLog(adminContext())
+	require.NoError(t, err)
+	assert.Equal(t, &model.AuditVerification{Valid: true, Entries: auditVerifyBatch + 5}, result)
+
+	svc.log = tamperedLog{log}
+	result, err = svc.VerifyAuditLog(adminContext())
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, int64(2), result.Entries)
+	assert.Contains(t, result.Error, "audit entry 3")
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"strings"
 
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
@@ -28,3 +29,29 @@ func (s *AuthService) SignIn(ctx context.Context, req model.SignInRequest) (*mod
 func (s *AuthService) ConfirmSignUp(ctx context.Context, req model.ConfirmSignUpRequest) error {
 	return s.authProvider.ConfirmSignUp(ctx, req.Email, req.ConfirmationCode)
 }
+
+func (s *AuthService) RefreshTokens(ctx context.Context, req model.RefreshTokenRequest) (*model.AuthResponse, error) {
+	return s.authProvider.RefreshTokens(ctx, req.RefreshToken)
+}
+
+// SignOut revokes all of the caller's tokens, on every device.
+func (s *AuthService) SignOut(ctx context.Context, req model.SignOutRequest) error {
+	return s.authProvider.GlobalSignOut(ctx, req.AccessToken)
+}
+
+func (s *AuthService) ForgotPassword(ctx context.Context, req model.ForgotPasswordRequest) error {
+	return s.authProvider.ForgotPassword(ctx, req.Email)
+}
+
+func (s *AuthService) ConfirmForgotPassword(ctx context.Context, req model.ConfirmForgotPasswordRequest) error {
+	return s.authProvider.ConfirmForgotPassword(ctx, req.Email, req.ConfirmationCode, req.NewPassword)
+}
+
+func (s *AuthService) ResendConfirmation(ctx context.Context, req model.ResendConfirmationRequest) error {
+	return s.authProvider.ResendConfirmationCode(ctx, req.Email)
+}
+
+// ClientCredentials issues a service client an access token acting as itself.
+func (s *AuthService) ClientCredentials(ctx context.Context, req model.ClientCredentialsRequest) (*model.AuthResponse, error) {
+	return s.authProvider.ClientCredentials(ctx, req.ClientID, req.ClientSecret, strings.Fields(req.Scope))
+}
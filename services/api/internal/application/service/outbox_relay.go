@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
+)
+
+// OutboxRelayConfig tunes how the relay drains the outbox.
+type OutboxRelayConfig struct {
+	// PollInterval is how long the relay sleeps once the outbox is empty.
+	PollInterval time.Duration
+	// BatchSize is how many messages are claimed at a time.
+	BatchSize int
+	// Lease is how long a claimed message is hidden from other relays; it
+	// must comfortably exceed publishing one batch.
+	Lease time.Duration
+	// InitialBackoff doubles per failed attempt up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultOutboxRelayConfig polls every second in batches of 50, backing a
+// failing message off from 1s to 5m.
+func DefaultOutboxRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		PollInterval:   time.Second,
+		BatchSize:      50,
+		Lease:          30 * time.Second,
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Minute,
+	}
+}
+
+// OutboxRelay publishes the outbox's messages to the queue. A message is
+// removed only after the publisher accepts it, and keeps its ID across
+// attempts, so delivery is at-least-once and the provisioner's inbox drops
+// the repeats.
+type OutboxRelay struct {
+	outbox    outbound.ResourceOutbox
+	publisher outbound.ResourcePublisher
+	config    OutboxRelayConfig
+	logger    logger.Logger
+}
+
+// NewOutboxRelay creates a relay from outbox to publisher.
+func NewOutboxRelay(outbox outbound.ResourceOutbox, publisher outbound.ResourcePublisher, config OutboxRelayConfig, log logger.Logger) *OutboxRelay {
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = DefaultOutboxRelayConfig().BatchSize
+	}
+	return &OutboxRelay{outbox: outbox, publisher: publisher, config: config, logger: log}
+}
+
+// Run drains the outbox until the context is cancelled. While messages are
+// waiting it claims batch after batch; once the outbox is empty it sleeps
+// for PollInterval.
+func (r *OutboxRelay) Run(ctx context.Context) error {
+	r.logger.Info("Outbox relay started",
+		logger.F("poll_interval", r.config.PollInterval.String()),
+		logger.F("batch_size", r.config.BatchSize),
+	)
+
+	for {
+		claimed, err := r.relayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("Failed to claim outbox messages", logger.F("error", err.Error()))
+		}
+		if claimed == r.config.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// relayBatch claims one batch and publishes it, returning how many messages
+// were claimed. With an AsyncResourcePublisher the whole batch is handed off
+// at once and settled as deliveries are confirmed, and with a
+// BatchResourcePublisher it is sent in one call; either way a message is
+// removed only once the transport has it, and the batch is settled before
+// the next one is claimed.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (int, error) {
+	messages, err := r.outbox.Claim(ctx, r.config.BatchSize, r.config.Lease)
+	if err != nil {
+		return 0, err
+	}
+	if batch, ok := r.publisher.(outbound.BatchResourcePublisher); ok && len(messages) > 0 {
+		r.publishBatch(ctx, batch, messages)
+		return len(messages), nil
+	}
+
+	async, isAsync := r.publisher.(outbound.AsyncResourcePublisher)
+	// Buffered for the whole batch, so a late callback never blocks once the
+	// relay has stopped waiting.
+	outcomes := make(chan publishOutcome, len(messages))
+	pending := 0
+	for _, msg := range messages {
+		if ctx.Err() != nil {
+			// Unpublished claims lapse with their lease.
+			break
+		}
+		msgCtx := messageContext(ctx, msg)
+		if !isAsync {
+			r.settle(ctx, msgCtx, msg, r.publisher.Publish(msgCtx, msg.Resource))
+			continue
+		}
+		async.PublishAsync(msgCtx, msg.Resource, func(err error) {
+			outcomes <- publishOutcome{ctx: msgCtx, msg: msg, err: err}
+		})
+		pending++
+	}
+
+	// Outcomes are settled here rather than in the callbacks, which may run
+	// concurrently on the publisher's goroutines.
+	for ; pending > 0; pending-- {
+		select {
+		case o := <-outcomes:
+			r.settle(ctx, o.ctx, o.msg, o.err)
+		case <-ctx.Done():
+			// Unconfirmed claims lapse with their lease and are published
+			// again; the provisioner drops any that did get through.
+			return len(messages), nil
+		}
+	}
+	return len(messages), nil
+}
+
+// publishBatch publishes messages in one PublishBatch call and settles each.
+func (r *OutboxRelay) publishBatch(ctx context.Context, publisher outbound.BatchResourcePublisher, messages []model.OutboxMessage) {
+	entries := make([]outbound.BatchEntry, len(messages))
+	for i, msg := range messages {
+		entries[i] = outbound.BatchEntry{Context: messageContext(ctx, msg), Resource: msg.Resource}
+	}
+	errs := publisher.PublishBatch(ctx, entries)
+	for i, msg := range messages {
+		r.settle(ctx, entries[i].Context, msg, errs[i])
+	}
+}
+
+// publishOutcome is the result of one asynchronous publish.
+type publishOutcome struct {
+	ctx context.Context
+	msg model.OutboxMessage
+	err error
+}
+
+// messageContext carries a message's original trace, its ID, which the
+// publisher stamps on it, and the idempotency key it was accepted under.
+func messageContext(ctx context.Context, msg model.OutboxMessage) context.Context {
+	msgCtx := otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(msg.TraceContext))
+	msgCtx = outbound.WithMessageID(msgCtx, msg.ID)
+	if msg.IdempotencyKey != "" {
+		msgCtx = outbound.WithIdempotencyKey(msgCtx, msg.IdempotencyKey)
+	}
+	return msgCtx
+}
+
+// settle records the outcome of publishing one message: removes it once
+// published, or reschedules it with backoff.
+func (r *OutboxRelay) settle(ctx, msgCtx context.Context, msg model.OutboxMessage, err error) {
+	if err != nil {
+		next := time.Now().Add(r.backoff(msg.Attempts + 1))
+		r.logger.WithContext(msgCtx).Warn("Failed to publish outbox message; will retry",
+			logger.F("message_id", msg.ID),
+			logger.F("resource_id", msg.Resource.ID),
+			logger.F("attempt", msg.Attempts+1),
+			logger.F("next_attempt_at", next.UTC().Format(time.RFC3339)),
+			logger.F("error", err.Error()),
+		)
+		if err := r.outbox.MarkFailed(ctx, msg.ID, err.Error(), next); err != nil {
+			r.logger.WithContext(msgCtx).Error("Failed to reschedule outbox message",
+				logger.F("message_id", msg.ID),
+				logger.F("error", err.Error()),
+			)
+		}
+		return
+	}
+
+	// If this fails the message is published again once its lease lapses;
+	// the provisioner drops the duplicate by message ID.
+	if err := r.outbox.MarkPublished(ctx, msg.ID); err != nil {
+		r.logger.WithContext(msgCtx).Error("Failed to remove published outbox message",
+			logger.F("message_id", msg.ID),
+			logger.F("error", err.Error()),
+		)
+		return
+	}
+	r.logger.WithContext(msgCtx).Info("Published outbox message",
+		logger.F("message_id", msg.ID),
+		logger.F("resource_id", msg.Resource.ID),
+	)
+}
+
+// backoff returns the delay before the given attempt (1 = first retry).
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	d := r.config.InitialBackoff
+	for i := 1; i < attempt && d < r.config.MaxBackoff; i++ {
+		d *= 2
+	}
+	if r.config.MaxBackoff > 0 && d > r.config.MaxBackoff {
+		d = r.config.MaxBackoff
+	}
+	return d
+}
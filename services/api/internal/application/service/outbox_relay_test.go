@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/outbox"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/readmodel"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
+)
+
+func testRelayConfig() OutboxRelayConfig {
+	return OutboxRelayConfig{
+		PollInterval:   10 * time.Millisecond,
+		BatchSize:      10,
+		Lease:          time.Minute,
+		InitialBackoff: time.Second,
+		MaxBackoff:     4 * time.Second,
+	}
+}
+
+func TestOutboxRelay_PublishesAndRemoves(t *testing.T) {
+	store := &mocks.FakeResourceOutbox{ToClaim: []model.OutboxMessage{
+		{ID: "msg-1", Resource: model.Resource{ID: "vm-1"}},
+	}}
+	publisher := &mocks.FakeResourcePublisher{}
+	relay := NewOutboxRelay(store, publisher, testRelayConfig(), nil)
+
+	claimed, err := relay.relayBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Equal(t, "vm-1", publisher.LastSent.ID)
+	assert.Equal(t, "msg-1", publisher.LastMessageID, "the outbox ID is the message ID")
+	assert.Equal(t, []string{"msg-1"}, store.Published)
+}
+
+func TestOutboxRelay_FailedPublishIsRescheduled(t *testing.T) {
+	store := &mocks.FakeResourceOutbox{ToClaim: []model.OutboxMessage{
+		{ID: "msg-1", Resource: model.Resource{ID: "vm-1"}, Attempts: 2},
+	}}
+	publisher := &mocks.FakeResourcePublisher{ErrToReturn: assert.AnError}
+	relay := NewOutboxRelay(store, publisher, testRelayConfig(), nil)
+
+	before := time.Now()
+	_, err := relay.relayBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, store.Published)
+	next, ok := store.Failed["msg-1"]
+	require.True(t, ok, "a failed message must be rescheduled")
+	// Third attempt: 1s doubled twice, capped at 4s.
+	assert.WithinDuration(t, before.Add(4*time.Second), next, time.Second)
+	assert.Equal(t, assert.AnError.Error(), store.LastError)
+}
+
+// An async publisher gets the whole batch at once, and the batch is settled
+// from its delivery confirmations before relayBatch returns.
+func TestOutboxRelay_SettlesAsyncDeliveries(t *testing.T) {
+	store := &mocks.FakeResourceOutbox{ToClaim: []model.OutboxMessage{
+		{ID: "msg-1", Resource: model.Resource{ID: "vm-1"}},
+		{ID: "msg-2", Resource: model.Resource{ID: "vm-2"}},
+	}}
+	publisher := &mocks.FakeAsyncResourcePublisher{}
+	relay := NewOutboxRelay(store, publisher, testRelayConfig(), nil)
+
+	claimed, err := relay.relayBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	assert.Equal(t, []string{"msg-1", "msg-2"}, publisher.Sent)
+	assert.ElementsMatch(t, []string{"msg-1", "msg-2"}, store.Published)
+}
+
+func TestOutboxRelay_FailedAsyncDeliveryIsRescheduled(t *testing.T) {
+	store := &mocks.FakeResourceOutbox{ToClaim: []model.OutboxMessage{
+		{ID: "msg-1", Resource: model.Resource{ID: "vm-1"}},
+	}}
+	publisher := &mocks.FakeAsyncResourcePublisher{ErrToReturn: assert.AnError}
+	relay := NewOutboxRelay(store, publisher, testRelayConfig(), nil)
+
+	_, err := relay.relayBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Empty(t, store.Published)
+	assert.Contains(t, store.Failed, "msg-1")
+}
+
+// A batch publisher gets the whole batch in one call, each message under its
+// own ID and idempotency key, and the batch is settled entry by entry.
+func TestOutboxRelay_PublishesBatches(t *testing.T) {
+	store := &mocks.FakeResourceOutbox{ToClaim: []model.OutboxMessage{
+		{ID: "msg-1", Resource: model.Resource{ID: "vm-1"}, IdempotencyKey: "key-1"},
+		{ID: "msg-2", Resource: model.Resource{ID: "vm-2"}},
+	}}
+	publisher := &mocks.FakeBatchResourcePublisher{Fail: map[string]error{"vm-2": assert.AnError}}
+	relay := NewOutboxRelay(store, publisher, testRelayConfig(), nil)
+
+	claimed, err := relay.relayBatch(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, claimed)
+	assert.Equal(t, [][]string{{"msg-1", "msg-2"}}, publisher.Batches)
+	assert.Equal(t, []string{"key-1", ""}, publisher.IdempotencyKeys)
+	assert.Zero(t, publisher.TimesCalled, "nothing is published one by one")
+	assert.Equal(t, []string{"msg-1"}, store.Published)
+	assert.Contains(t, store.Failed, "msg-2")
+}
+
+// A shutdown does not wait for unconfirmed deliveries: their claims lapse
+// and they are published again.
+func TestOutboxRelay_StopsWaitingOnShutdown(t *testing.T) {
+	store := &mocks.FakeResourceOutbox{ToClaim: []model.OutboxMessage{
+		{ID: "msg-1", Resource: model.Resource{ID: "vm-1"}},
+	}}
+	relay := NewOutboxRelay(store, neverDelivers{}, testRelayConfig(), nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	claimed, err := relay.relayBatch(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, claimed)
+	assert.Empty(t, store.Published)
+	assert.Empty(t, store.Failed)
+}
+
+type neverDelivers struct{}
+
+func (neverDelivers) Publish(context.Context, model.Resource) error { return nil }
+
+func (neverDelivers) PublishAsync(context.Context, model.Resource, func(error)) {}
+
+func TestOutboxRelay_Backoff(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, testRelayConfig(), nil)
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 4*time.Second, relay.backoff(3))
+	assert.Equal(t, 4*time.Second, relay.backoff(10))
+}
+
+// End to end through the memory outbox: a request accepted while the broker
+// is down is published once it recovers.
+func TestOutboxRelay_DeliversAfterBrokerRecovers(t *testing.T) {
+	readModel := readmodel.NewMemoryStore()
+	store := outbox.NewMemoryOutbox(readModel)
+	publisher := &mocks.FakeResourcePublisher{ErrToReturn: assert.AnError}
+	service := NewResourceService(nil, store, readModel, nil)
+	cfg := testRelayConfig()
+	cfg.InitialBackoff = time.Millisecond
+	relay := NewOutboxRelay(store, publisher, cfg, nil)
+
+	require.NoError(t, service.SendProvisioningRequest(context.Background(), model.Resource{ID: "vm-1", RequestedBy: "rafael"}))
+	_, err := service.GetResource(context.Background(), "vm-1")
+	require.NoError(t, err, "the record is written with the outbox message")
+
+	_, err = relay.relayBatch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, store.Len(), "a failed publish keeps the message")
+
+	publisher.ErrToReturn = nil
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	go func() { _ = relay.Run(ctx) }()
+	assert.Eventually(t, func() bool { return store.Len() == 0 }, time.Second, 5*time.Millisecond)
+	assert.Equal(t, "vm-1", publisher.LastSent.ID)
+}
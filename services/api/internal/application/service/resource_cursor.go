@@ -0,0 +1,49 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// Cursor directions: a next cursor resumes after (older than) its key, a prev
+// cursor resumes before (newer than) it.
+const (
+	cursorNext = "next"
+	cursorPrev = "prev"
+)
+
+// resourceCursor is the decoded form of the opaque pagination cursor. It is
+// base64url-encoded JSON so clients treat it as a token, not a contract.
+type resourceCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+	Direction string    `json:"d"`
+}
+
+func encodeCursor(key model.ResourceKey, direction string) string {
+	raw, _ := json.Marshal(resourceCursor{CreatedAt: key.CreatedAt, ID: key.ID, Direction: direction})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(s string) (resourceCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return resourceCursor{}, fmt.Errorf("cursor is not valid base64: %w", err)
+	}
+	var c resourceCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return resourceCursor{}, fmt.Errorf("cursor is malformed: %w", err)
+	}
+	if c.ID == "" || (c.Direction != cursorNext && c.Direction != cursorPrev) {
+		return resourceCursor{}, fmt.Errorf("cursor is malformed")
+	}
+	return c, nil
+}
+
+func (c resourceCursor) key() *model.ResourceKey {
+	return &model.ResourceKey{CreatedAt: c.CreatedAt, ID: c.ID}
+}
@@ -3,28 +3,100 @@ package service
 import (
 	"context"
 	"encoding/json"
+	stderrors "errors"
+	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/valueobjects"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
 )
 
+// Page size bounds for ListResources.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// ResourceService implements the resource use cases: submitting provisioning
+// requests, querying them, and applying the provisioner's status reports.
 type ResourceService struct {
 	publisher outbound.ResourcePublisher
+	outbox    outbound.ResourceOutbox
+	readModel outbound.ResourceReadModel
 	logger    logger.Logger
+	policy    *model.AccessPolicy
+	engine    outbound.PolicyEngine
+}
+
+// ResourceServiceOption configures optional ResourceService behaviour.
+type ResourceServiceOption func(*ResourceService)
+
+// WithAccessPolicy makes the service authorize every provisioning request
+// against policy. Requests without an authenticated principal are then
+// denied, since there are no groups to evaluate.
+func WithAccessPolicy(policy model.AccessPolicy) ResourceServiceOption {
+	return func(s *ResourceService) {
+		s.policy = &policy
+	}
+}
+
+var (
+	_ inbound.ResourceService       = (*ResourceService)(nil)
+	_ inbound.ResourceStatusUpdater = (*ResourceService)(nil)
+)
+
+// WithPolicyEngine makes the service check every provisioning request against
+// engine's guardrails before accepting it.
+func WithPolicyEngine(engine outbound.PolicyEngine) ResourceServiceOption {
+	return func(s *ResourceService) {
+		s.engine = engine
+	}
 }
 
-func NewResourceService(publisher outbound.ResourcePublisher, log logger.Logger) *ResourceService {
+// NewResourceService wires the resource service. With an outbox, accepted
+// requests are written to it and an OutboxRelay publishes them; without one
+// they are published directly. readModel may be nil, in which case accepted
+// requests are not recorded and GetResource reports them missing.
+func NewResourceService(publisher outbound.ResourcePublisher, outbox outbound.ResourceOutbox, readModel outbound.ResourceReadModel, log logger.Logger, opts ...ResourceServiceOption) *ResourceService {
 	if log == nil {
 		log = logger.NopLogger{}
 	}
-	return &ResourceService{
+	s := &ResourceService{
 		publisher: publisher,
+		outbox:    outbox,
+		readModel: readModel,
 		logger:    log,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
+// SendProvisioningRequest accepts a provisioning request in the pending
+// status, whatever status the client sent. On an authenticated request
+// RequestedBy is the caller's subject, whatever the client sent; otherwise the
+// client must supply it. With an access policy, the caller must
+// be allowed to provision the resource type on the cloud provider; with a
+// policy engine, the request must break none of its guardrails.
 func (s *ResourceService) SendProvisioningRequest(ctx context.Context, r model.Resource) error {
+	r, violations, err := s.admit(ctx, r)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return errors.NewDomainError(errors.ErrCodePolicyViolation, "request violates provisioning policy", errors.ErrPolicyViolation).
+			WithDetail("violations", violations)
+	}
+
 	// Log the payload we're about to publish, mirroring the "received message"
 	// body log on the provisioner side. The request context is attached so the
 	// OTel bridge stamps the same trace_id the provisioner will log against,
@@ -36,5 +108,298 @@ func (s *ResourceService) SendProvisioningRequest(ctx context.Context, r model.R
 		logger.F("body", string(body)),
 	)
 
-	return s.publisher.Publish(ctx, r)
+	record := newAcceptedRecord(ctx, r)
+	if s.outbox != nil {
+		return s.enqueue(ctx, r, record)
+	}
+
+	// Without an outbox the record is written after publishing, so check for
+	// a taken ID first. The provisioner would drop the message as a duplicate
+	// and the existing record would never change.
+	if err := s.checkIDFree(ctx, r.ID); err != nil {
+		return err
+	}
+	if err := s.publisher.Publish(ctx, r); err != nil {
+		return err
+	}
+
+	s.recordAccepted(ctx, record)
+	return nil
+}
+
+// ValidateProvisioningRequest runs every check SendProvisioningRequest would,
+// without accepting the request, and returns the guardrail violations (none
+// if the request would be accepted). Other failures, such as an access policy
+// denial, are returned as errors exactly as SendProvisioningRequest would.
+func (s *ResourceService) ValidateProvisioningRequest(ctx context.Context, r model.Resource) ([]model.PolicyViolation, error) {
+	_, violations, err := s.admit(ctx, r)
+	return violations, err
+}
+
+// admit attributes the request to its requester, starts it in the pending
+// status, authorizes it and evaluates the guardrails.
+func (s *ResourceService) admit(ctx context.Context, r model.Resource) (model.Resource, []model.PolicyViolation, error) {
+	// The status is the provisioner's to move. Publishing a client-chosen one
+	// would let a request skip provisioning while the read model says pending.
+	r.Status = model.NewProvisioningRequest(r.ID).Status().String()
+
+	principal, authenticated := model.PrincipalFromContext(ctx)
+	if authenticated {
+		r.RequestedBy = principal.Subject
+	} else if r.RequestedBy == "" {
+		return r, nil, errors.InvalidInput("requested_by is required").WithDetail("field", "requested_by")
+	}
+
+	if err := s.authorize(ctx, principal, authenticated, r); err != nil {
+		return r, nil, err
+	}
+
+	if s.engine == nil {
+		return r, nil, nil
+	}
+	violations, err := s.engine.Evaluate(ctx, principal, r)
+	if err != nil {
+		return r, nil, errors.Internal("failed to evaluate provisioning policy", err)
+	}
+	if len(violations) > 0 {
+		s.logger.WithContext(ctx).Info("provisioning request violates policy",
+			logger.F("resource_id", r.ID),
+			logger.F("violations", len(violations)),
+		)
+	}
+	return r, violations, nil
+}
+
+// authorize applies the access policy, if any, to a provisioning request.
+func (s *ResourceService) authorize(ctx context.Context, principal model.Principal, authenticated bool, r model.Resource) error {
+	if s.policy == nil {
+		return nil
+	}
+	if !authenticated {
+		return errors.NewDomainError(errors.ErrCodeAccessDenied, "authentication is required to provision resources", errors.ErrForbidden)
+	}
+
+	if err := s.policy.Authorize(principal, r); err != nil {
+		s.logger.WithContext(ctx).Warn("provisioning request denied by access policy",
+			logger.F("resource_id", r.ID),
+			logger.F("resource_type", r.ResourceType),
+			logger.F("cloud_provider", r.CloudProvider),
+			logger.F("subject", principal.Subject),
+			logger.F("groups", principal.Groups),
+			logger.F("client_id", principal.ClientID),
+			logger.F("policy_version", s.policy.Version),
+		)
+		return err
+	}
+	return nil
+}
+
+// enqueue durably accepts a request: its pending record and its outbox
+// message are written together, and the relay publishes it from there. A
+// broker outage therefore delays provisioning rather than failing the call.
+func (s *ResourceService) enqueue(ctx context.Context, r model.Resource, record model.ResourceRecord) error {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	msg := model.OutboxMessage{
+		ID:             uuid.NewString(),
+		Resource:       r,
+		TraceContext:   carrier,
+		IdempotencyKey: outbound.IdempotencyKeyFromContext(ctx),
+		CreatedAt:      record.CreatedAt,
+		NextAttemptAt:  record.CreatedAt,
+	}
+
+	if err := s.outbox.Enqueue(ctx, record, msg); err != nil {
+		if stderrors.Is(err, outbound.ErrResourceExists) {
+			return errors.AlreadyExists("resource", r.ID)
+		}
+		return errors.Internal("failed to accept provisioning request", err)
+	}
+	return nil
+}
+
+// checkIDFree returns an AlreadyExists domain error if the read model holds a
+// record for id. Resource IDs are never reused: resubmitting one would not
+// reprovision the resource, only misreport it.
+func (s *ResourceService) checkIDFree(ctx context.Context, id string) error {
+	if s.readModel == nil {
+		return nil
+	}
+	_, err := s.readModel.Get(ctx, id)
+	switch {
+	case err == nil:
+		return errors.AlreadyExists("resource", id)
+	case stderrors.Is(err, outbound.ErrResourceNotFound):
+		return nil
+	default:
+		return errors.Internal("failed to check resource id", err)
+	}
+}
+
+// newAcceptedRecord builds the pending read-model record for a new request.
+func newAcceptedRecord(ctx context.Context, r model.Resource) model.ResourceRecord {
+	now := time.Now().UTC()
+	record := model.ResourceRecord{
+		Resource:  r,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		record.TraceID = sc.TraceID().String()
+	}
+	return record
+}
+
+// recordAccepted stores the pending record for a directly published request.
+// The request is already on the queue, so a read-model failure is logged
+// rather than returned: failing the call would invite a retry that provisions
+// twice.
+func (s *ResourceService) recordAccepted(ctx context.Context, record model.ResourceRecord) {
+	if s.readModel == nil {
+		return
+	}
+
+	if err := s.readModel.Create(ctx, record); err != nil {
+		s.logger.WithContext(ctx).Warn("failed to record accepted resource",
+			logger.F("resource_id", record.ID),
+			logger.F("error", err.Error()),
+		)
+	}
+}
+
+// GetResource returns the current record for a resource, or a NotFound domain
+// error when none exists.
+func (s *ResourceService) GetResource(ctx context.Context, id string) (*model.ResourceRecord, error) {
+	if s.readModel == nil {
+		return nil, errors.NotFound("resource", id)
+	}
+
+	record, err := s.readModel.Get(ctx, id)
+	if err != nil {
+		if stderrors.Is(err, outbound.ErrResourceNotFound) {
+			return nil, errors.NotFound("resource", id)
+		}
+		return nil, errors.Internal("failed to load resource", err)
+	}
+	return record, nil
+}
+
+// ListResources returns one page of resources matching the filter, newest
+// first. cursor is empty for the first page, or a NextCursor/PrevCursor from a
+// previous page; limit is clamped to [1, MaxPageSize].
+func (s *ResourceService) ListResources(ctx context.Context, filter model.ResourceFilter, cursor string, limit int) (*model.ResourcePage, error) {
+	if limit <= 0 {
+		limit = DefaultPageSize
+	}
+	if limit > MaxPageSize {
+		limit = MaxPageSize
+	}
+	if s.readModel == nil {
+		return &model.ResourcePage{Records: []model.ResourceRecord{}}, nil
+	}
+
+	query := model.ResourceQuery{Filter: filter, Limit: limit}
+	var direction string
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, errors.InvalidInput("invalid pagination cursor").WithDetail("cursor", err.Error())
+		}
+		direction = c.Direction
+		if direction == cursorNext {
+			query.After = c.key()
+		} else {
+			query.Before = c.key()
+		}
+	}
+
+	records, hasMore, err := s.readModel.List(ctx, query)
+	if err != nil {
+		return nil, errors.Internal("failed to list resources", err)
+	}
+	total, err := s.readModel.Count(ctx, filter)
+	if err != nil {
+		return nil, errors.Internal("failed to count resources", err)
+	}
+
+	page := &model.ResourcePage{Records: records, TotalItems: total}
+	if page.Records == nil {
+		page.Records = []model.ResourceRecord{}
+	}
+	if len(records) == 0 {
+		return page, nil
+	}
+
+	// Arriving via a cursor means there is a page on the side we came from;
+	// hasMore speaks for the side we are travelling towards.
+	first, last := records[0].Key(), records[len(records)-1].Key()
+	if (direction == cursorPrev && hasMore) || direction == cursorNext {
+		page.PrevCursor = encodeCursor(first, cursorPrev)
+	}
+	if (direction != cursorPrev && hasMore) || direction == cursorPrev {
+		page.NextCursor = encodeCursor(last, cursorNext)
+	}
+	return page, nil
+}
+
+// maxStatusUpdateAttempts bounds how often ApplyStatusChange re-reads a
+// record whose status changed under it before giving up.
+const maxStatusUpdateAttempts = 3
+
+// ApplyStatusChange moves a resource's record to the reported status through
+// the ProvisioningRequest state machine. The transition is checked against
+// the record's current status rather than the event's old_status, so a missed
+// event does not wedge the record. The write is conditional on that status
+// still holding; if another event got there first the record is re-read and
+// the transition checked again, so concurrent events can never move a record
+// backwards. Errors are DomainErrors: NotFound for an unknown resource,
+// InvalidStatusTransition for a duplicate or out-of-order event, InvalidInput
+// for an unknown status.
+func (s *ResourceService) ApplyStatusChange(ctx context.Context, e model.ResourceStatusChanged) error {
+	if s.readModel == nil {
+		return errors.NotFound("resource", e.ResourceID)
+	}
+
+	next, err := valueobjects.NewProvisioningStatus(e.NewStatus)
+	if err != nil {
+		return errors.NewDomainError(errors.ErrCodeInvalidStatus, err.Error(), errors.ErrInvalidInput)
+	}
+	at := e.OccurredAt
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	for attempt := 1; ; attempt++ {
+		record, err := s.readModel.Get(ctx, e.ResourceID)
+		if err != nil {
+			if stderrors.Is(err, outbound.ErrResourceNotFound) {
+				return errors.NotFound("resource", e.ResourceID)
+			}
+			return errors.Internal("failed to load resource", err)
+		}
+
+		req, err := model.RehydrateProvisioningRequest(record.ID, record.Status, nil)
+		if err != nil {
+			return err
+		}
+		t, err := req.TransitionTo(next, e.Reason, at)
+		if err != nil {
+			return err
+		}
+
+		err = s.readModel.UpdateStatus(ctx, record.ID, t.From.String(), t.To.String(), t.At)
+		if stderrors.Is(err, outbound.ErrStatusChanged) && attempt < maxStatusUpdateAttempts {
+			continue
+		}
+		if err != nil {
+			return errors.Internal("failed to save resource status", err)
+		}
+
+		s.logger.WithContext(ctx).Info("resource status updated",
+			logger.F("resource_id", record.ID),
+			logger.F("old_status", t.From.String()),
+			logger.F("new_status", t.To.String()),
+		)
+		return nil
+	}
 }
@@ -2,16 +2,22 @@ package service
 
 import (
 	"context"
+	"fmt"
+	outboxadapter "github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/outbox"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/readmodel"
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/test/mocks"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestSendProvisioningRequest_Success(t *testing.T) {
 	// Arrange
 	fakePublisher := &mocks.FakeResourcePublisher{}
-	service := NewResourceService(fakePublisher, nil)
+	service := NewResourceService(fakePublisher, nil, nil, nil)
 
 	resource := model.Resource{
 		ID:            "123",
@@ -35,7 +41,7 @@ func TestSendProvisioningRequest_Error(t *testing.T) {
 	fakePublisher := &mocks.FakeResourcePublisher{
 		ErrToReturn: assert.AnError,
 	}
-	service := NewResourceService(fakePublisher, nil)
+	service := NewResourceService(fakePublisher, nil, nil, nil)
 
 	resource := model.Resource{
 		ID:            "123",
@@ -53,3 +59,423 @@ func TestSendProvisioningRequest_Error(t *testing.T) {
 	assert.Equal(t, resource, fakePublisher.LastSent)
 	assert.Equal(t, 1, fakePublisher.TimesCalled)
 }
+
+func TestSendProvisioningRequest_RecordsPendingResource(t *testing.T) {
+	readModel := &mocks.FakeResourceReadModel{}
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, readModel, nil)
+
+	resource := model.Resource{
+		ID:            "123",
+		ResourceType:  "VM",
+		CloudProvider: "AWS",
+		Specification: "t2.micro",
+		Status:        "completed",
+		RequestedBy:   "rafael",
+	}
+
+	err := service.SendProvisioningRequest(context.Background(), resource)
+
+	assert.NoError(t, err)
+	record, ok := readModel.Records["123"]
+	assert.True(t, ok)
+	assert.Equal(t, "pending", record.Status, "a newly accepted request is always pending")
+	assert.Equal(t, "t2.micro", record.Specification)
+	assert.False(t, record.CreatedAt.IsZero())
+}
+
+func TestSendProvisioningRequest_IgnoresClientStatus(t *testing.T) {
+	resource := model.Resource{ID: "123", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "completed", RequestedBy: "rafael"}
+
+	t.Run("direct", func(t *testing.T) {
+		publisher := &mocks.FakeResourcePublisher{}
+		service := NewResourceService(publisher, nil, &mocks.FakeResourceReadModel{}, nil)
+
+		assert.NoError(t, service.SendProvisioningRequest(context.Background(), resource))
+		assert.Equal(t, "pending", publisher.LastSent.Status, "a client cannot skip provisioning")
+	})
+
+	t.Run("outbox", func(t *testing.T) {
+		outbox := &mocks.FakeResourceOutbox{}
+		service := NewResourceService(&mocks.FakeResourcePublisher{}, outbox, &mocks.FakeResourceReadModel{}, nil)
+
+		assert.NoError(t, service.SendProvisioningRequest(context.Background(), resource))
+		if assert.Len(t, outbox.EnqueuedMessages, 1) {
+			assert.Equal(t, "pending", outbox.EnqueuedMessages[0].Resource.Status, "a client cannot skip provisioning")
+		}
+	})
+}
+
+func TestSendProvisioningRequest_PublishErrorSkipsRecord(t *testing.T) {
+	readModel := &mocks.FakeResourceReadModel{}
+	service := NewResourceService(&mocks.FakeResourcePublisher{ErrToReturn: assert.AnError}, nil, readModel, nil)
+
+	err := service.SendProvisioningRequest(context.Background(), model.Resource{ID: "123", RequestedBy: "rafael"})
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, readModel.TimesCreated)
+}
+
+func TestSendProvisioningRequest_ReadModelErrorDoesNotFailRequest(t *testing.T) {
+	readModel := &mocks.FakeResourceReadModel{CreateErr: assert.AnError}
+	publisher := &mocks.FakeResourcePublisher{}
+	service := NewResourceService(publisher, nil, readModel, nil)
+
+	err := service.SendProvisioningRequest(context.Background(), model.Resource{ID: "123", RequestedBy: "rafael"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, publisher.TimesCalled)
+}
+
+func TestGetResource_ReturnsRecord(t *testing.T) {
+	readModel := &mocks.FakeResourceReadModel{Records: map[string]model.ResourceRecord{
+		"123": {Resource: model.Resource{ID: "123", Status: "in_progress"}},
+	}}
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, readModel, nil)
+
+	record, err := service.GetResource(context.Background(), "123")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "in_progress", record.Status)
+}
+
+func TestGetResource_MissingIsNotFound(t *testing.T) {
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, &mocks.FakeResourceReadModel{}, nil)
+
+	_, err := service.GetResource(context.Background(), "nope")
+
+	assert.ErrorIs(t, err, domainerrors.ErrNotFound)
+}
+
+func TestGetResource_StoreErrorIsInternal(t *testing.T) {
+	readModel := &mocks.FakeResourceReadModel{GetErr: assert.AnError}
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, readModel, nil)
+
+	_, err := service.GetResource(context.Background(), "123")
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, domainerrors.ErrNotFound)
+}
+
+func TestListResources_CursorsWalkBothDirections(t *testing.T) {
+	store := readmodel.NewMemoryStore()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		_ = store.Create(context.Background(), model.ResourceRecord{
+			Resource:  model.Resource{ID: fmt.Sprintf("r-%d", i)},
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		})
+	}
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, store, nil)
+	ctx := context.Background()
+
+	first, err := service.ListResources(ctx, model.ResourceFilter{}, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, first.TotalItems)
+	assert.Empty(t, first.PrevCursor, "the first page has nothing before it")
+	assert.NotEmpty(t, first.NextCursor)
+
+	second, err := service.ListResources(ctx, model.ResourceFilter{}, first.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "r-2", second.Records[0].ID)
+	assert.NotEmpty(t, second.PrevCursor)
+	assert.NotEmpty(t, second.NextCursor)
+
+	third, err := service.ListResources(ctx, model.ResourceFilter{}, second.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Len(t, third.Records, 1)
+	assert.Empty(t, third.NextCursor, "the last page has nothing after it")
+
+	back, err := service.ListResources(ctx, model.ResourceFilter{}, second.PrevCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, first.Records, back.Records)
+	assert.Empty(t, back.PrevCursor)
+}
+
+func TestListResources_InvalidCursorIsInvalidInput(t *testing.T) {
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, readmodel.NewMemoryStore(), nil)
+
+	_, err := service.ListResources(context.Background(), model.ResourceFilter{}, "!!not-a-cursor", 10)
+
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidInput)
+}
+
+func TestListResources_ClampsLimit(t *testing.T) {
+	readModel := &mocks.FakeResourceReadModel{}
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, readModel, nil)
+
+	_, err := service.ListResources(context.Background(), model.ResourceFilter{}, "", 10_000)
+
+	assert.NoError(t, err)
+	assert.Equal(t, MaxPageSize, readModel.LastQuery.Limit)
+}
+
+func TestListResources_DefaultsLimit(t *testing.T) {
+	readModel := &mocks.FakeResourceReadModel{}
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, readModel, nil)
+
+	_, err := service.ListResources(context.Background(), model.ResourceFilter{}, "", 0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPageSize, readModel.LastQuery.Limit)
+}
+
+func TestSendProvisioningRequest_ExistingIDIsConflict(t *testing.T) {
+	for name, withOutbox := range map[string]bool{"outbox": true, "direct": false} {
+		t.Run(name, func(t *testing.T) {
+			store := readmodel.NewMemoryStore()
+			created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+			_ = store.Create(context.Background(), model.ResourceRecord{
+				Resource:  model.Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Status: "completed", RequestedBy: "alice"},
+				CreatedAt: created,
+				UpdatedAt: created,
+			})
+			publisher := &mocks.FakeResourcePublisher{}
+			var outbox outbound.ResourceOutbox
+			if withOutbox {
+				outbox = outboxadapter.NewMemoryOutbox(store)
+			}
+			service := NewResourceService(publisher, outbox, store, nil)
+
+			err := service.SendProvisioningRequest(context.Background(), model.Resource{
+				ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", RequestedBy: "mallory",
+			})
+
+			assert.ErrorIs(t, err, domainerrors.ErrAlreadyExists)
+			assert.Equal(t, 0, publisher.TimesCalled)
+			record, _ := store.Get(context.Background(), "vm-001")
+			assert.Equal(t, "completed", record.Status, "the record keeps its status")
+			assert.Equal(t, "alice", record.RequestedBy, "the record keeps its owner")
+		})
+	}
+}
+
+func TestApplyStatusChange_AdvancesRecord(t *testing.T) {
+	store := readmodel.NewMemoryStore()
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	_ = store.Create(context.Background(), model.ResourceRecord{
+		Resource:  model.Resource{ID: "vm-001", Status: "pending"},
+		CreatedAt: created,
+		UpdatedAt: created,
+	})
+	service := NewResourceService(nil, nil, store, nil)
+	at := created.Add(time.Minute)
+
+	err := service.ApplyStatusChange(context.Background(), model.ResourceStatusChanged{
+		ResourceID: "vm-001", OldStatus: "pending", NewStatus: "in_progress", OccurredAt: at,
+	})
+
+	assert.NoError(t, err)
+	record, _ := store.Get(context.Background(), "vm-001")
+	assert.Equal(t, "in_progress", record.Status)
+	assert.Equal(t, at, record.UpdatedAt)
+	assert.Equal(t, created, record.CreatedAt)
+}
+
+func TestApplyStatusChange_RejectsIllegalTransition(t *testing.T) {
+	store := readmodel.NewMemoryStore()
+	_ = store.Create(context.Background(), model.ResourceRecord{Resource: model.Resource{ID: "vm-001", Status: "completed"}})
+	service := NewResourceService(nil, nil, store, nil)
+
+	err := service.ApplyStatusChange(context.Background(), model.ResourceStatusChanged{ResourceID: "vm-001", NewStatus: "in_progress"})
+
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidStateTransition)
+	record, _ := store.Get(context.Background(), "vm-001")
+	assert.Equal(t, "completed", record.Status)
+}
+
+// staleReadModel serves one outdated read before passing through, as if
+// another status event committed between ApplyStatusChange's read and write.
+type staleReadModel struct {
+	*readmodel.MemoryStore
+	stale *model.ResourceRecord
+}
+
+func (s *staleReadModel) Get(ctx context.Context, id string) (*model.ResourceRecord, error) {
+	if stale := s.stale; stale != nil {
+		s.stale = nil
+		return stale, nil
+	}
+	return s.MemoryStore.Get(ctx, id)
+}
+
+func TestApplyStatusChange_ConcurrentEventCannotMoveRecordBackwards(t *testing.T) {
+	store := readmodel.NewMemoryStore()
+	_ = store.Create(context.Background(), model.ResourceRecord{Resource: model.Resource{ID: "vm-001", Status: "completed"}})
+	stale := &model.ResourceRecord{Resource: model.Resource{ID: "vm-001", Status: "pending"}}
+	service := NewResourceService(nil, nil, &staleReadModel{MemoryStore: store, stale: stale}, nil)
+
+	err := service.ApplyStatusChange(context.Background(), model.ResourceStatusChanged{ResourceID: "vm-001", NewStatus: "in_progress"})
+
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidStateTransition, "the transition is re-checked against the winning status")
+	record, _ := store.Get(context.Background(), "vm-001")
+	assert.Equal(t, "completed", record.Status)
+}
+
+func TestApplyStatusChange_RetriesAfterLosingARace(t *testing.T) {
+	store := readmodel.NewMemoryStore()
+	_ = store.Create(context.Background(), model.ResourceRecord{Resource: model.Resource{ID: "vm-001", Status: "in_progress"}})
+	stale := &model.ResourceRecord{Resource: model.Resource{ID: "vm-001", Status: "pending"}}
+	service := NewResourceService(nil, nil, &staleReadModel{MemoryStore: store, stale: stale}, nil)
+
+	err := service.ApplyStatusChange(context.Background(), model.ResourceStatusChanged{ResourceID: "vm-001", NewStatus: "cancelled"})
+
+	assert.NoError(t, err)
+	record, _ := store.Get(context.Background(), "vm-001")
+	assert.Equal(t, "cancelled", record.Status)
+}
+
+func TestApplyStatusChange_UnknownResourceIsNotFound(t *testing.T) {
+	service := NewResourceService(nil, nil, readmodel.NewMemoryStore(), nil)
+
+	err := service.ApplyStatusChange(context.Background(), model.ResourceStatusChanged{ResourceID: "vm-404", NewStatus: "in_progress"})
+
+	assert.ErrorIs(t, err, domainerrors.ErrNotFound)
+}
+
+func TestSendProvisioningRequest_WithOutbox_EnqueuesInsteadOfPublishing(t *testing.T) {
+	publisher := &mocks.FakeResourcePublisher{}
+	outbox := &mocks.FakeResourceOutbox{}
+	service := NewResourceService(publisher, outbox, &mocks.FakeResourceReadModel{}, nil)
+
+	resource := model.Resource{ID: "123", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending", RequestedBy: "rafael"}
+	err := service.SendProvisioningRequest(context.Background(), resource)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, publisher.TimesCalled, "the relay publishes, not the request")
+	if assert.Len(t, outbox.EnqueuedMessages, 1) {
+		msg := outbox.EnqueuedMessages[0]
+		assert.NotEmpty(t, msg.ID)
+		assert.Equal(t, resource, msg.Resource)
+		assert.False(t, msg.NextAttemptAt.After(time.Now()), "a new message is due at once")
+	}
+	if assert.Len(t, outbox.EnqueuedRecords, 1) {
+		assert.Equal(t, "pending", outbox.EnqueuedRecords[0].Status)
+	}
+}
+
+func TestSendProvisioningRequest_WithOutbox_KeepsIdempotencyKey(t *testing.T) {
+	outbox := &mocks.FakeResourceOutbox{}
+	service := NewResourceService(nil, outbox, &mocks.FakeResourceReadModel{}, nil)
+
+	ctx := outbound.WithIdempotencyKey(context.Background(), "6f1c1f2e-4b7a-4c3b-9d8e-0a1b2c3d4e5f")
+	err := service.SendProvisioningRequest(ctx, model.Resource{ID: "123", RequestedBy: "rafael"})
+
+	assert.NoError(t, err)
+	if assert.Len(t, outbox.EnqueuedMessages, 1) {
+		assert.Equal(t, "6f1c1f2e-4b7a-4c3b-9d8e-0a1b2c3d4e5f", outbox.EnqueuedMessages[0].IdempotencyKey)
+	}
+}
+
+func TestSendProvisioningRequest_WithOutbox_EnqueueErrorIsInternal(t *testing.T) {
+	outbox := &mocks.FakeResourceOutbox{EnqueueErr: assert.AnError}
+	service := NewResourceService(nil, outbox, &mocks.FakeResourceReadModel{}, nil)
+
+	err := service.SendProvisioningRequest(context.Background(), model.Resource{ID: "123", RequestedBy: "rafael"})
+
+	var domainErr *domainerrors.DomainError
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, domainerrors.ErrCodeExternalService, domainErr.Code)
+	}
+	assert.ErrorIs(t, err, assert.AnError)
+}
+
+func TestSendProvisioningRequest_RequesterIsTokenSubject(t *testing.T) {
+	publisher := &mocks.FakeResourcePublisher{}
+	service := NewResourceService(publisher, nil, nil, nil)
+	ctx := model.WithPrincipal(context.Background(), model.Principal{Subject: "user-42"})
+
+	err := service.SendProvisioningRequest(ctx, model.Resource{ID: "123", RequestedBy: "someone-else"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-42", publisher.LastSent.RequestedBy)
+}
+
+func TestSendProvisioningRequest_UnauthenticatedNeedsRequester(t *testing.T) {
+	publisher := &mocks.FakeResourcePublisher{}
+	service := NewResourceService(publisher, nil, nil, nil)
+
+	err := service.SendProvisioningRequest(context.Background(), model.Resource{ID: "123"})
+
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidInput)
+	assert.Equal(t, 0, publisher.TimesCalled)
+}
+
+func TestSendProvisioningRequest_AccessPolicy(t *testing.T) {
+	policy := model.AccessPolicy{Version: 1, Rules: []model.AccessRule{
+		{Name: "team-payments", Groups: []string{"team-payments"}, ResourceTypes: []string{"VM", "S3"}, CloudProviders: []string{"AWS"}},
+	}}
+	publisher := &mocks.FakeResourcePublisher{}
+	service := NewResourceService(publisher, nil, nil, nil, WithAccessPolicy(policy))
+	ctx := model.WithPrincipal(context.Background(), model.Principal{Subject: "user-42", Groups: []string{"team-payments"}})
+
+	err := service.SendProvisioningRequest(ctx, model.Resource{ID: "1", ResourceType: "S3", CloudProvider: "AWS"})
+	assert.NoError(t, err)
+
+	err = service.SendProvisioningRequest(ctx, model.Resource{ID: "2", ResourceType: "RDS", CloudProvider: "AWS"})
+	assert.ErrorIs(t, err, domainerrors.ErrForbidden)
+
+	err = service.SendProvisioningRequest(context.Background(), model.Resource{ID: "3", ResourceType: "S3", CloudProvider: "AWS", RequestedBy: "rafael"})
+	assert.ErrorIs(t, err, domainerrors.ErrForbidden, "unauthenticated requests have no groups to allow")
+
+	assert.Equal(t, 1, publisher.TimesCalled)
+}
+
+// fakePolicyEngine reports the same violations for every request.
+type fakePolicyEngine struct {
+	violations []model.PolicyViolation
+	err        error
+}
+
+func (f fakePolicyEngine) Evaluate(context.Context, model.Principal, model.Resource) ([]model.PolicyViolation, error) {
+	return f.violations, f.err
+}
+
+func TestSendProvisioningRequest_PolicyViolationsBlockPublish(t *testing.T) {
+	violation := model.PolicyViolation{Rule: "approved-regions", Field: "region", Message: "region is required"}
+	publisher := &mocks.FakeResourcePublisher{}
+	service := NewResourceService(publisher, nil, nil, nil, WithPolicyEngine(fakePolicyEngine{violations: []model.PolicyViolation{violation}}))
+
+	err := service.SendProvisioningRequest(context.Background(), model.Resource{ID: "123", RequestedBy: "rafael"})
+
+	assert.ErrorIs(t, err, domainerrors.ErrPolicyViolation)
+	var domainErr *domainerrors.DomainError
+	if assert.ErrorAs(t, err, &domainErr) {
+		assert.Equal(t, []model.PolicyViolation{violation}, domainErr.Details["violations"])
+	}
+	assert.Equal(t, 0, publisher.TimesCalled)
+}
+
+func TestSendProvisioningRequest_PolicyEngineErrorIsInternal(t *testing.T) {
+	publisher := &mocks.FakeResourcePublisher{}
+	service := NewResourceService(publisher, nil, nil, nil, WithPolicyEngine(fakePolicyEngine{err: assert.AnError}))
+
+	err := service.SendProvisioningRequest(context.Background(), model.Resource{ID: "123", RequestedBy: "rafael"})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.NotErrorIs(t, err, domainerrors.ErrPolicyViolation)
+	assert.Equal(t, 0, publisher.TimesCalled)
+}
+
+func TestValidateProvisioningRequest_DoesNotPublish(t *testing.T) {
+	violation := model.PolicyViolation{Rule: "cost-allocation", Field: "tags.owner", Message: `tag "owner" is required`}
+	publisher := &mocks.FakeResourcePublisher{}
+	store := readmodel.NewMemoryStore()
+	service := NewResourceService(publisher, nil, store, nil, WithPolicyEngine(fakePolicyEngine{violations: []model.PolicyViolation{violation}}))
+
+	violations, err := service.ValidateProvisioningRequest(context.Background(), model.Resource{ID: "123", RequestedBy: "rafael"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []model.PolicyViolation{violation}, violations)
+	assert.Equal(t, 0, publisher.TimesCalled)
+	_, err = service.GetResource(context.Background(), "123")
+	assert.ErrorIs(t, err, domainerrors.ErrNotFound)
+}
+
+func TestValidateProvisioningRequest_ReportsAccessDenial(t *testing.T) {
+	service := NewResourceService(&mocks.FakeResourcePublisher{}, nil, nil, nil, WithAccessPolicy(model.AccessPolicy{Version: 1}))
+	ctx := model.WithPrincipal(context.Background(), model.Principal{Subject: "user-42"})
+
+	_, err := service.ValidateProvisioningRequest(ctx, model.Resource{ID: "123", ResourceType: "VM", CloudProvider: "AWS"})
+
+	assert.ErrorIs(t, err, domainerrors.ErrForbidden)
+}
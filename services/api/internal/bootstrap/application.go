@@ -4,21 +4,42 @@ package bootstrap
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"database/sql"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
+	"sync"
+	"time"
 
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/redis/go-redis/v9"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/inbound/events"
 	apihttp "github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/inbound/http"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/accesspolicy"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/apitoken"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/audit"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/cognito"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/envelope"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/guardrails"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/idempotency"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/jwt"
 	kafkaadapter "github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/kafka"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/localauth"
+	natsadapter "github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/nats"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/outbox"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/ratelimit"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/readmodel"
 	sqsadapter "github.com/rafaelcmd/internal-developer-platform/api/internal/adapters/outbound/sqs"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/application/service"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/config"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/infrastructure"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/logger"
@@ -43,23 +64,56 @@ type Application struct {
 
 	// Runtime configuration (loaded from Parameter Store)
 	ProvisionerQueueURL string
+	StatusQueueURL      string
 	CognitoClientID     string
+	CognitoUserPoolID   string
 	RedisAddr           string
 
+	// Authentication of protected routes. JWKSHandler is set when the API
+	// issues its own tokens (AUTH_PROVIDER=local)
+	TokenVerifier outbound.TokenVerifier
+	JWKSHandler   http.Handler
+
+	// Authorization of provisioning requests (nil: any caller may provision
+	// anything)
+	AccessPolicy *model.AccessPolicy
+
+	// Guardrails checked against every provisioning request (nil: none)
+	PolicyEngine outbound.PolicyEngine
+
 	// Idempotency layer
 	RedisClient      *redis.Client
 	IdempotencyStore outbound.IdempotencyStore
 
-	// Messaging
+	// Per-caller rate limits (nil RateLimiter: unlimited)
+	RateLimiter outbound.RateLimiter
+	RateLimits  apihttp.RateLimitPolicy
+
+	// Messaging: accepted requests go to the outbox, and the relay publishes
+	// them with ResourcePublisher
 	ResourcePublisher outbound.ResourcePublisher
+	ResourceOutbox    outbound.ResourceOutbox
+	OutboxRelay       *service.OutboxRelay
+	// NATSConn backs ResourcePublisher and StatusConsumer when NATS_URL is
+	// set
+	NATSConn *nats.Conn
+
+	// Read model backing resource status queries, kept current by the
+	// provisioner's status events
+	Database          *sql.DB
+	ResourceReadModel outbound.ResourceReadModel
+	StatusConsumer    StatusConsumer
 
 	// Services
 	ResourceService *service.ResourceService
 	AuthService     *service.AuthService
+	APITokenService *service.APITokenService
+	AuditService    *service.AuditService
 
 	// HTTP Handlers
 	ResourceHandler *apihttp.ResourceHandler
 	AuthHandler     *apihttp.AuthHandler
+	APITokenHandler *apihttp.APITokenHandler
 	HealthHandler   *apihttp.HealthHandler
 	SwaggerHandler  *apihttp.SwaggerHandler
 
@@ -67,6 +121,12 @@ type Application struct {
 	Server *server.Server
 }
 
+// StatusConsumer is a long-running consumer of the provisioner's status
+// events, over the transport chosen by config.MessagingConfig.Transport.
+type StatusConsumer interface {
+	Run(ctx context.Context) error
+}
+
 // Options holds optional configuration for the Application.
 type Options struct {
 	SwaggerPath string
@@ -136,7 +196,7 @@ func New(ctx context.Context, cfg *config.Config, opts Options) (*Application, e
 	// Redis) so the service boots for local testing of infra-free endpoints such
 	// as /metrics and /v1/health. See initializeLocal for the caveats.
 	if app.isLocalMode() {
-		return app.initializeLocal(opts)
+		return app.initializeLocal(ctx, opts)
 	}
 
 	// Initialize AWS clients
@@ -157,11 +217,41 @@ func New(ctx context.Context, cfg *config.Config, opts Options) (*Application, e
 		return nil, fmt.Errorf("failed to initialize Redis: %w", err)
 	}
 
+	// Initialize access token verification (Cognito user pool)
+	if err := app.initializeTokenVerifier(); err != nil {
+		return nil, fmt.Errorf("failed to initialize token verifier: %w", err)
+	}
+
+	// Load the provisioning access policy
+	if err := app.initializeAccessPolicy(); err != nil {
+		return nil, fmt.Errorf("failed to load access policy: %w", err)
+	}
+
+	// Load the provisioning guardrails
+	if err := app.initializeGuardrails(); err != nil {
+		return nil, fmt.Errorf("failed to load guardrails: %w", err)
+	}
+
 	// Initialize adapters
 	app.initializeAdapters(opts)
 
+	// Initialize the resource read model and outbox (Postgres or in-memory)
+	if err := app.initializeReadModel(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize read model: %w", err)
+	}
+
+	// Accept personal API tokens wherever access tokens are verified
+	app.initializeAPITokens()
+	app.initializeAudit()
+	if err := app.initializeRateLimit(); err != nil {
+		return nil, fmt.Errorf("failed to initialize rate limits: %w", err)
+	}
+
 	// Initialize services
-	app.initializeServices()
+	app.initializeAuthService()
+	if err := app.initializeMessaging(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize messaging: %w", err)
+	}
 
 	// Initialize HTTP handlers
 	app.initializeHandlers()
@@ -179,12 +269,23 @@ func New(ctx context.Context, cfg *config.Config, opts Options) (*Application, e
 func (a *Application) loadRuntimeConfig(ctx context.Context) error {
 	var err error
 
-	// Load SQS queue URL
-	a.ProvisionerQueueURL, err = a.ParameterStore.GetParameter(ctx, a.Config.AWS.ProvisionerQueueParamKey)
-	if err != nil {
-		return fmt.Errorf("failed to get provisioner queue URL: %w", err)
+	// The SQS queue URLs are needed only when SQS is the transport
+	if a.Config.Messaging.Transport() == config.TransportSQS {
+		a.ProvisionerQueueURL, err = a.ParameterStore.GetParameter(ctx, a.Config.AWS.ProvisionerQueueParamKey)
+		if err != nil {
+			return fmt.Errorf("failed to get provisioner queue URL: %w", err)
+		}
+		a.Logger.Info("Loaded provisioner queue URL", logger.F("queue_url", a.ProvisionerQueueURL))
+
+		// Load the status queue URL (subscribed to the provisioner's SNS topic)
+		if a.Config.AWS.StatusQueueParamKey != "" {
+			a.StatusQueueURL, err = a.ParameterStore.GetParameter(ctx, a.Config.AWS.StatusQueueParamKey)
+			if err != nil {
+				return fmt.Errorf("failed to get status queue URL: %w", err)
+			}
+			a.Logger.Info("Loaded status queue URL", logger.F("queue_url", a.StatusQueueURL))
+		}
 	}
-	a.Logger.Info("Loaded provisioner queue URL", logger.F("queue_url", a.ProvisionerQueueURL))
 
 	// Load Cognito Client ID
 	a.CognitoClientID, err = a.ParameterStore.GetParameter(ctx, a.Config.AWS.CognitoClientIDParamKey)
@@ -193,6 +294,16 @@ func (a *Application) loadRuntimeConfig(ctx context.Context) error {
 	}
 	a.Logger.Info("Loaded Cognito client ID", logger.F("client_id", a.CognitoClientID))
 
+	// Load the Cognito user pool ID, which names the token issuer, unless
+	// AUTH_ISSUER points the API at another one
+	if a.Config.Auth.Issuer == "" {
+		a.CognitoUserPoolID, err = a.ParameterStore.GetParameter(ctx, a.Config.AWS.CognitoUserPoolParamKey)
+		if err != nil {
+			return fmt.Errorf("failed to get Cognito user pool ID: %w", err)
+		}
+		a.Logger.Info("Loaded Cognito user pool ID", logger.F("user_pool_id", a.CognitoUserPoolID))
+	}
+
 	// REDIS_ADDR (env override) wins for local docker-compose; otherwise pull from Parameter Store
 	// where Terraform writes the ElastiCache primary endpoint.
 	if a.Config.Idempotency.RedisAddr != "" {
@@ -237,6 +348,56 @@ func (a *Application) initializeRedis(ctx context.Context) error {
 	return nil
 }
 
+// initializeTokenVerifier builds the access token verifier for protected
+// routes: tokens must be signed by a key from the issuer's JWKS (cached), and
+// carry its iss, one of the accepted audiences (the Cognito app client by
+// default), token_use=access and an unexpired exp. With no issuer configured
+// (local mode without AUTH_ISSUER) the verifier is left nil and the routes are
+// unauthenticated.
+func (a *Application) initializeTokenVerifier() error {
+	cfg := a.Config.Auth
+
+	issuer := cfg.Issuer
+	if issuer == "" && a.CognitoUserPoolID != "" {
+		issuer = cognito.Issuer(a.Config.AWS.Region, a.CognitoUserPoolID)
+	}
+	if issuer == "" {
+		a.Logger.Warn("Token verification disabled: no issuer configured; POST /v1/provision is unauthenticated")
+		return nil
+	}
+
+	jwksURL := cfg.JWKSURL
+	if jwksURL == "" {
+		jwksURL = cognito.JWKSURL(issuer)
+	}
+	audiences := cfg.Audiences
+	if len(audiences) == 0 && a.CognitoClientID != "" {
+		audiences = []string{a.CognitoClientID}
+	}
+	// Service clients' client-credentials tokens carry their own client ID.
+	audiences = append(slices.Clone(audiences), cfg.MachineClients...)
+
+	keys := jwt.NewRemoteKeySet(jwksURL, jwt.RemoteKeySetConfig{TTL: cfg.JWKSTTL})
+	verifier, err := jwt.NewVerifier(keys, jwt.Config{
+		Issuer:         issuer,
+		Audiences:      audiences,
+		TokenUse:       cfg.TokenUse,
+		Leeway:         cfg.ClockLeeway,
+		ResourceServer: cfg.ResourceServer,
+	})
+	if err != nil {
+		return err
+	}
+
+	a.TokenVerifier = verifier
+	a.Logger.Info("Token verification enabled",
+		logger.F("issuer", issuer),
+		logger.F("jwks_url", jwksURL),
+		logger.F("machine_clients", len(cfg.MachineClients)),
+	)
+	return nil
+}
+
 // isLocalMode reports whether the app is running in local development mode,
 // where AWS-backed dependencies are disabled.
 func (a *Application) isLocalMode() bool {
@@ -245,16 +406,43 @@ func (a *Application) isLocalMode() bool {
 
 // initializeLocal wires the minimal set of dependencies that need no external
 // infrastructure, for local development. AWS clients, Parameter Store, and
-// Cognito are skipped. The resource route works via Kafka (see
-// initializeKafkaResourceService) so the end-to-end provisioning flow runs
-// offline; auth routes still return 500 (recovered) since Cognito is skipped.
-func (a *Application) initializeLocal(opts Options) (*Application, error) {
-	a.Logger.Warn("Running in LOCAL mode: AWS, Parameter Store, and Cognito are disabled; queue transport is Kafka",
-		logger.F("functional_endpoints", "/v1/provision, /metrics, /v1/health, /v1/swagger"),
+// Cognito are skipped. The resource route works via NATS JetStream or Kafka
+// (see initializeMessaging), so the end-to-end provisioning flow runs
+// offline; with neither configured, publishing is disabled. Auth routes work with AUTH_PROVIDER=local (see
+// initializeLocalAuth); otherwise they return 500 (recovered) since Cognito is
+// skipped.
+func (a *Application) initializeLocal(ctx context.Context, opts Options) (*Application, error) {
+	a.Logger.Warn("Running in LOCAL mode: AWS, Parameter Store, and Cognito are disabled",
+		logger.F("functional_endpoints", "/v1/provision, /v1/resources, /metrics, /v1/health, /v1/swagger"),
+		logger.F("auth_provider", a.Config.Auth.Provider),
+		logger.F("queue_transport", a.Config.Messaging.Transport()),
 	)
 
 	a.initializeAdapters(opts)
-	a.initializeKafkaResourceService()
+	if a.Config.Auth.Provider == config.AuthProviderLocal {
+		if err := a.initializeLocalAuth(); err != nil {
+			return nil, fmt.Errorf("failed to initialize local auth provider: %w", err)
+		}
+	} else if err := a.initializeTokenVerifier(); err != nil {
+		return nil, fmt.Errorf("failed to initialize token verifier: %w", err)
+	}
+	if err := a.initializeAccessPolicy(); err != nil {
+		return nil, fmt.Errorf("failed to load access policy: %w", err)
+	}
+	if err := a.initializeGuardrails(); err != nil {
+		return nil, fmt.Errorf("failed to load guardrails: %w", err)
+	}
+	if err := a.initializeReadModel(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize read model: %w", err)
+	}
+	a.initializeAPITokens()
+	a.initializeAudit()
+	if err := a.initializeRateLimit(); err != nil {
+		return nil, fmt.Errorf("failed to initialize rate limits: %w", err)
+	}
+	if err := a.initializeMessaging(ctx); err != nil {
+		return nil, fmt.Errorf("failed to initialize messaging: %w", err)
+	}
 	a.initializeHandlers()
 
 	if err := a.initializeServer(); err != nil {
@@ -265,6 +453,105 @@ func (a *Application) initializeLocal(opts Options) (*Application, error) {
 	return a, nil
 }
 
+// initializeLocalAuth replaces Cognito with the in-process identity provider:
+// users live in memory, confirmation and reset codes are logged, and tokens
+// are signed with a key generated at startup (so they do not survive a
+// restart) and published at /.well-known/jwks.json. Protected routes verify
+// the provider's own tokens.
+func (a *Application) initializeLocalAuth() error {
+	cfg := a.Config.Auth
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return fmt.Errorf("generate signing key: %w", err)
+	}
+	signer := jwt.NewSigner(key, "local-1")
+
+	issuer := cfg.Issuer
+	if issuer == "" {
+		issuer = "http://localhost:" + a.Config.Server.Port
+	}
+	clientID := localauth.DefaultClientID
+	if len(cfg.Audiences) > 0 {
+		clientID = cfg.Audiences[0]
+	}
+	clients, err := cfg.LocalClientSecrets()
+	if err != nil {
+		return err
+	}
+
+	provider, err := localauth.NewAuthProvider(signer, localauth.Config{
+		Issuer:      issuer,
+		ClientID:    clientID,
+		ClockLeeway: cfg.ClockLeeway,
+		Clients:     clients,
+	}, a.Logger)
+	if err != nil {
+		return err
+	}
+
+	a.AuthService = service.NewAuthService(provider)
+	a.TokenVerifier = provider.Verifier()
+	a.JWKSHandler = jwt.JWKSHandler(signer.JWKS())
+	a.Logger.Warn("Auth provider is LOCAL: users are in memory and confirmation codes are logged, not emailed",
+		logger.F("issuer", issuer),
+		logger.F("client_id", clientID),
+		logger.F("service_clients", len(clients)),
+	)
+	return nil
+}
+
+// initializeAccessPolicy loads the rules deciding which groups may provision
+// which resource types on which cloud providers. Without AUTH_POLICY_FILE
+// provisioning is not authorized beyond authentication.
+func (a *Application) initializeAccessPolicy() error {
+	path := a.Config.Auth.PolicyFile
+	if path == "" {
+		a.Logger.Warn("Access policy not configured; any authenticated caller may provision any resource")
+		return nil
+	}
+
+	policy, err := accesspolicy.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	if a.TokenVerifier == nil {
+		a.Logger.Warn("Access policy loaded but token verification is disabled; every provisioning request will be denied")
+	}
+
+	a.AccessPolicy = &policy
+	a.Logger.Info("Access policy loaded",
+		logger.F("path", path),
+		logger.F("version", policy.Version),
+		logger.F("rules", len(policy.Rules)),
+	)
+	return nil
+}
+
+// initializeGuardrails loads the policy engine that checks what is being
+// provisioned (instance types, regions, tags). Without GUARDRAILS_FILE
+// requests are not checked beyond validation and the access policy.
+func (a *Application) initializeGuardrails() error {
+	path := a.Config.Guardrails.File
+	if path == "" {
+		a.Logger.Info("Guardrails not configured; provisioning requests are not policy-checked")
+		return nil
+	}
+
+	engine, err := guardrails.LoadFile(path)
+	if err != nil {
+		return err
+	}
+
+	a.PolicyEngine = engine
+	a.Logger.Info("Guardrails loaded",
+		logger.F("path", path),
+		logger.F("version", engine.Version()),
+		logger.F("rules", engine.Rules()),
+	)
+	return nil
+}
+
 // initializeMetrics constructs the OpenTelemetry MeterProvider backed by the
 // Prometheus exporter and registers it as the global provider.
 func (a *Application) initializeMetrics() error {
@@ -287,34 +574,325 @@ func (a *Application) initializeAdapters(opts Options) {
 	a.SwaggerHandler = apihttp.NewSwaggerHandler(opts.SwaggerPath)
 }
 
-// initializeServices initializes all application services.
-func (a *Application) initializeServices() {
-	// Resource service, publishing to SQS (non-local) or Kafka (local mode)
-	a.ResourcePublisher = sqsadapter.NewResourcePublisher(a.AWSClients.SQS, a.ProvisionerQueueURL)
-	a.ResourceService = service.NewResourceService(a.ResourcePublisher, a.Logger)
+// initializeReadModel backs resource status queries and the outbox with
+// Postgres. Only local mode may run without DATABASE_URL, on in-memory stores:
+// records and unpublished requests are then lost on restart, which the outbox
+// exists to prevent (see docs/adr/0005).
+func (a *Application) initializeReadModel(ctx context.Context) error {
+	if a.Config.Database.URL == "" {
+		if !a.isLocalMode() {
+			return fmt.Errorf("%w: DATABASE_URL is required outside local mode", config.ErrMissingConfig)
+		}
+		a.ResourceReadModel = readmodel.NewMemoryStore()
+		a.ResourceOutbox = outbox.NewMemoryOutbox(a.ResourceReadModel)
+		a.Logger.Warn("DATABASE_URL not set; resource read model and outbox are in memory")
+		return nil
+	}
 
-	// Auth service with Cognito provider
-	authProvider := cognito.NewCognitoAuthProvider(a.AWSClients.Cognito, a.CognitoClientID)
-	a.AuthService = service.NewAuthService(authProvider)
+	db, err := infrastructure.NewPostgresDB(ctx, infrastructure.PostgresConfig{
+		URL:          a.Config.Database.URL,
+		MaxOpenConns: a.Config.Database.MaxOpenConns,
+		PingTimeout:  a.Config.Database.PingTimeout,
+	})
+	if err != nil {
+		return err
+	}
+
+	a.Database = db
+	a.ResourceReadModel = readmodel.NewPostgresStore(db)
+	a.ResourceOutbox = outbox.NewPostgresOutbox(db)
+	a.Logger.Info("Resource read model and outbox enabled (postgres)")
+	return nil
 }
 
-// initializeKafkaResourceService wires the resource service to Kafka. Used in
-// local mode so the API -> queue -> provisioner flow works offline without AWS.
-// Leaves the service nil (route returns 500) when no brokers are configured.
-func (a *Application) initializeKafkaResourceService() {
-	if len(a.Config.Messaging.KafkaBrokers) == 0 {
-		a.Logger.Warn("Resource publishing disabled: KAFKA_BROKERS not set in local mode")
+// initializeAPITokens enables personal API tokens wherever access tokens are
+// verified, stored next to the read model (Postgres, or in memory in local
+// mode without DATABASE_URL). Without token verification there is no user to
+// own tokens, so they stay disabled.
+func (a *Application) initializeAPITokens() {
+	if a.TokenVerifier == nil {
+		a.Logger.Info("API tokens disabled: authentication is off")
 		return
 	}
 
-	a.ResourcePublisher = kafkaadapter.NewResourcePublisher(
+	var store outbound.APITokenStore = apitoken.NewMemoryStore()
+	if a.Database != nil {
+		store = apitoken.NewPostgresStore(a.Database)
+	}
+	a.APITokenService = service.NewAPITokenService(store, service.APITokenConfig{
+		DefaultTTL: a.Config.Auth.APITokenTTL,
+		MaxTTL:     a.Config.Auth.APITokenMaxTTL,
+	}, a.Logger)
+	a.TokenVerifier = a.APITokenService.VerifierWith(a.TokenVerifier)
+	a.Logger.Info("API tokens enabled",
+		logger.F("default_ttl", a.Config.Auth.APITokenTTL.String()),
+		logger.F("max_ttl", a.Config.Auth.APITokenMaxTTL.String()),
+	)
+}
+
+// initializeAudit records mutating API calls in a hash-chained audit log,
+// stored next to the read model (Postgres, or in memory in local mode
+// without DATABASE_URL).
+func (a *Application) initializeAudit() {
+	var log outbound.AuditLog = audit.NewMemoryLog()
+	store := "memory"
+	if a.Database != nil {
+		log = audit.NewPostgresLog(a.Database)
+		store = "postgres"
+	}
+	a.AuditService = service.NewAuditService(log, service.AuditConfig{
+		AdminGroups: a.Config.Audit.AdminGroups,
+	}, a.Logger)
+	a.Logger.Info("Audit log enabled",
+		logger.F("store", store),
+		logger.F("admin_groups", a.Config.Audit.AdminGroups),
+	)
+}
+
+// initializeRateLimit sizes the per-caller rate limits. Buckets are shared
+// between replicas in Redis when it is configured, falling back to memory if
+// it fails, and kept in memory otherwise.
+func (a *Application) initializeRateLimit() error {
+	if !a.Config.RateLimit.Enabled {
+		a.Logger.Warn("Rate limiting disabled")
+		return nil
+	}
+	def, routes, err := a.Config.RateLimit.Limits()
+	if err != nil {
+		return err
+	}
+	clientIP, err := config.ParseRateLimit(a.Config.RateLimit.ClientIP)
+	if err != nil {
+		return err
+	}
+	a.RateLimits = apihttp.RateLimitPolicy{
+		Default:           outbound.RateLimit(def),
+		ClientIP:          outbound.RateLimit(clientIP),
+		Routes:            make(map[string]outbound.RateLimit, len(routes)),
+		TrustForwardedFor: a.Config.RateLimit.TrustForwardedFor,
+	}
+	for pattern, limit := range routes {
+		a.RateLimits.Routes[pattern] = outbound.RateLimit(limit)
+	}
+
+	memory := ratelimit.NewMemoryLimiter()
+	a.RateLimiter = memory
+	store := "memory"
+	if a.RedisClient != nil {
+		a.RateLimiter = ratelimit.NewFallbackLimiter(ratelimit.NewRedisLimiter(a.RedisClient), memory, a.Logger)
+		store = "redis"
+	}
+	a.Logger.Info("Rate limiting enabled",
+		logger.F("store", store),
+		logger.F("default", a.Config.RateLimit.Default),
+		logger.F("routes", a.Config.RateLimit.Routes),
+		logger.F("client_ip", a.Config.RateLimit.ClientIP),
+	)
+	return nil
+}
+
+// initializeMessaging wires the resource service and the status consumer to
+// the transport chosen by config.MessagingConfig.Transport, the same rule the
+// provisioner uses, so both services always meet on one transport.
+func (a *Application) initializeMessaging(ctx context.Context) error {
+	switch a.Config.Messaging.Transport() {
+	case config.TransportNATS:
+		return a.initializeNATS(ctx)
+	case config.TransportKafka:
+		if err := a.initializeKafkaResourceService(); err != nil {
+			return fmt.Errorf("kafka publisher: %w", err)
+		}
+		a.initializeKafkaStatusConsumer()
+		return nil
+	default:
+		if a.isLocalMode() {
+			a.Logger.Warn("Resource publishing disabled: neither NATS_URL nor KAFKA_BROKERS set in local mode")
+			return nil
+		}
+		a.initializeSQSResourceService()
+		a.initializeSQSStatusConsumer()
+		return nil
+	}
+}
+
+// initializeSQSResourceService wires the resource service to the provisioner
+// queue.
+func (a *Application) initializeSQSResourceService() {
+	a.ResourcePublisher = sqsadapter.NewResourcePublisher(a.AWSClients.SQS, a.ProvisionerQueueURL, a.messageSerializer())
+	a.initializeResourceService()
+	a.Logger.Info("Resource service enabled (sqs)", logger.F("queue_url", a.ProvisionerQueueURL))
+}
+
+// initializeAuthService wires the auth service to Cognito.
+func (a *Application) initializeAuthService() {
+	// Auth service with Cognito provider; service clients get tokens from the
+	// user pool domain when AUTH_TOKEN_URL is set
+	authProvider := cognito.NewCognitoAuthProvider(a.AWSClients.Cognito, a.CognitoClientID,
+		cognito.WithTokenEndpoint(a.Config.Auth.TokenURL, a.Config.Auth.ResourceServer))
+	a.AuthService = service.NewAuthService(authProvider)
+}
+
+// initializeKafkaResourceService wires the resource service to Kafka.
+func (a *Application) initializeKafkaResourceService() error {
+	cfg := a.Config.Messaging.KafkaPublisher
+	publisher, err := kafkaadapter.NewResourcePublisher(
 		a.Config.Messaging.KafkaBrokers,
 		a.Config.Messaging.KafkaTopic,
+		a.messageSerializer(),
+		kafkaadapter.WriterConfig{
+			Async:        cfg.Async,
+			BatchSize:    cfg.BatchSize,
+			BatchBytes:   int64(cfg.BatchBytes),
+			Linger:       cfg.Linger,
+			Compression:  cfg.Compression,
+			RequiredAcks: cfg.RequiredAcks,
+		},
 	)
-	a.ResourceService = service.NewResourceService(a.ResourcePublisher, a.Logger)
-	a.Logger.Info("Resource service enabled (kafka, local mode)",
+	if err != nil {
+		return err
+	}
+	a.ResourcePublisher = publisher
+	a.initializeResourceService()
+	a.Logger.Info("Resource service enabled (kafka)",
 		logger.F("brokers", a.Config.Messaging.KafkaBrokers),
 		logger.F("topic", a.Config.Messaging.KafkaTopic),
+		logger.F("format", a.Config.Messaging.Format),
+		logger.F("async", cfg.Async),
+		logger.F("linger", cfg.Linger.String()),
+		logger.F("required_acks", cfg.RequiredAcks),
+	)
+	return nil
+}
+
+// initializeNATS connects to NATS JetStream and wires both the resource
+// service and the status consumer to it, creating the request and status
+// streams if they do not exist yet.
+func (a *Application) initializeNATS(ctx context.Context) error {
+	conn, err := nats.Connect(a.Config.Messaging.NATSURL, nats.Name(a.Config.App.ServiceName))
+	if err != nil {
+		return err
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+
+	setupCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	if err := a.initializeNATSResourceService(setupCtx, js); err != nil {
+		conn.Close()
+		return err
+	}
+	if err := a.initializeNATSStatusConsumer(setupCtx, js); err != nil {
+		conn.Close()
+		return err
+	}
+	a.NATSConn = conn
+	a.Logger.Info("Connected to NATS", logger.F("url", conn.ConnectedUrlRedacted()))
+	return nil
+}
+
+// initializeNATSResourceService wires the resource service to NATS JetStream.
+func (a *Application) initializeNATSResourceService(ctx context.Context, js jetstream.JetStream) error {
+	if err := natsadapter.EnsureStream(ctx, js, a.Config.Messaging.NATSStream, a.Config.Messaging.NATSSubject); err != nil {
+		return err
+	}
+
+	a.ResourcePublisher = natsadapter.NewResourcePublisher(js, a.Config.Messaging.NATSSubject, a.messageSerializer())
+	a.initializeResourceService()
+	a.Logger.Info("Resource service enabled (nats)",
+		logger.F("stream", a.Config.Messaging.NATSStream),
+		logger.F("subject", a.Config.Messaging.NATSSubject),
+		logger.F("format", a.Config.Messaging.Format),
+	)
+	return nil
+}
+
+// initializeNATSStatusConsumer consumes status events from NATS JetStream
+// through a durable consumer, so replicas share the events and the position
+// survives restarts.
+func (a *Application) initializeNATSStatusConsumer(ctx context.Context, js jetstream.JetStream) error {
+	cfg := a.Config.Messaging
+	if err := natsadapter.EnsureStream(ctx, js, cfg.NATSStatusStream, cfg.NATSStatusSubject); err != nil {
+		return err
+	}
+	consumer, err := js.CreateOrUpdateConsumer(ctx, cfg.NATSStatusStream, jetstream.ConsumerConfig{
+		Durable:       cfg.NATSStatusDurable,
+		FilterSubject: cfg.NATSStatusSubject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("create consumer %s: %w", cfg.NATSStatusDurable, err)
+	}
+
+	updater := service.NewResourceService(nil, nil, a.ResourceReadModel, a.Logger)
+	handler := events.NewStatusHandler(updater, a.Logger)
+	a.StatusConsumer = events.NewNATSConsumer(consumer, handler, 0, a.Logger)
+	a.Logger.Info("Status consumer enabled (nats)",
+		logger.F("stream", cfg.NATSStatusStream),
+		logger.F("subject", cfg.NATSStatusSubject),
+		logger.F("durable", cfg.NATSStatusDurable),
+	)
+	return nil
+}
+
+// messageSerializer encodes published provisioning requests in the configured
+// wire format.
+func (a *Application) messageSerializer() envelope.Serializer {
+	if a.Config.Messaging.Format == config.MessageFormatProtobuf {
+		return envelope.ProtobufSerializer{}
+	}
+	return envelope.JSONSerializer{}
+}
+
+// initializeResourceService wires the resource service to the outbox and the
+// relay from the outbox to ResourcePublisher.
+func (a *Application) initializeResourceService() {
+	var opts []service.ResourceServiceOption
+	if a.AccessPolicy != nil {
+		opts = append(opts, service.WithAccessPolicy(*a.AccessPolicy))
+	}
+	if a.PolicyEngine != nil {
+		opts = append(opts, service.WithPolicyEngine(a.PolicyEngine))
+	}
+	a.ResourceService = service.NewResourceService(nil, a.ResourceOutbox, a.ResourceReadModel, a.Logger, opts...)
+	a.OutboxRelay = service.NewOutboxRelay(a.ResourceOutbox, a.ResourcePublisher, service.OutboxRelayConfig{
+		PollInterval:   a.Config.Outbox.PollInterval,
+		BatchSize:      a.Config.Outbox.BatchSize,
+		Lease:          a.Config.Outbox.Lease,
+		InitialBackoff: a.Config.Outbox.InitialBackoff,
+		MaxBackoff:     a.Config.Outbox.MaxBackoff,
+	}, a.Logger)
+}
+
+// initializeSQSStatusConsumer consumes status events from the SNS-fed status
+// queue. Without a queue URL the read model only ever shows pending.
+func (a *Application) initializeSQSStatusConsumer() {
+	if a.StatusQueueURL == "" {
+		a.Logger.Warn("Status queue not configured; resource statuses will not advance past pending")
+		return
+	}
+
+	handler := events.NewStatusHandler(a.ResourceService, a.Logger)
+	a.StatusConsumer = events.NewSQSConsumer(a.AWSClients.SQS, a.StatusQueueURL, handler, a.Logger)
+	a.Logger.Info("Status consumer enabled (sqs)", logger.F("queue_url", a.StatusQueueURL))
+}
+
+// initializeKafkaStatusConsumer consumes status events from Kafka.
+func (a *Application) initializeKafkaStatusConsumer() {
+	// The updater needs only the read model, so it does not depend on the
+	// publishing service being wired.
+	updater := service.NewResourceService(nil, nil, a.ResourceReadModel, a.Logger)
+	handler := events.NewStatusHandler(updater, a.Logger)
+	a.StatusConsumer = events.NewKafkaConsumer(events.KafkaConsumerConfig{
+		Brokers: a.Config.Messaging.KafkaBrokers,
+		Topic:   a.Config.Messaging.KafkaStatusTopic,
+		GroupID: a.Config.Messaging.KafkaStatusGroupID,
+	}, handler, a.Logger)
+	a.Logger.Info("Status consumer enabled (kafka)",
+		logger.F("topic", a.Config.Messaging.KafkaStatusTopic),
+		logger.F("group", a.Config.Messaging.KafkaStatusGroupID),
 	)
 }
 
@@ -322,6 +900,9 @@ func (a *Application) initializeKafkaResourceService() {
 func (a *Application) initializeHandlers() {
 	a.ResourceHandler = apihttp.NewResourceHandler(a.ResourceService)
 	a.AuthHandler = apihttp.NewAuthHandler(a.AuthService, a.Logger)
+	if a.APITokenService != nil {
+		a.APITokenHandler = apihttp.NewAPITokenHandler(a.APITokenService)
+	}
 	a.HealthHandler = apihttp.NewHealthHandler()
 }
 
@@ -332,6 +913,12 @@ func (a *Application) initializeServer() error {
 		AllowedOrigins:   a.Config.App.AllowedOrigins,
 		IdempotencyStore: a.IdempotencyStore,
 		IdempotencyTTL:   a.Config.Idempotency.TTL,
+		TokenVerifier:    a.TokenVerifier,
+		APITokenHandler:  a.APITokenHandler,
+		AuditService:     a.AuditService,
+		RateLimiter:      a.RateLimiter,
+		RateLimits:       a.RateLimits,
+		JWKSHandler:      a.JWKSHandler,
 		MetricsHandler:   a.Metrics.Handler(),
 		Logger:           a.Logger,
 	}
@@ -380,6 +967,34 @@ func (a *Application) Run(ctx context.Context) error {
 		logger.F("port", a.Config.Server.Port),
 		logger.F("environment", a.Config.App.Environment),
 	)
+
+	// The status consumer and outbox relay run alongside the server. The
+	// server handles the shutdown signal itself, so they are stopped once it
+	// returns, and waited for so nothing publishes after Shutdown closes the
+	// publisher.
+	backgroundCtx, stopBackground := context.WithCancel(ctx)
+	var background sync.WaitGroup
+	defer func() {
+		stopBackground()
+		background.Wait()
+	}()
+	if a.StatusConsumer != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			if err := a.StatusConsumer.Run(backgroundCtx); err != nil && backgroundCtx.Err() == nil {
+				a.Logger.Error("Status consumer stopped", logger.F("error", err.Error()))
+			}
+		}()
+	}
+	if a.OutboxRelay != nil {
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			_ = a.OutboxRelay.Run(backgroundCtx)
+		}()
+	}
+
 	return a.Server.Start(ctx)
 }
 
@@ -393,6 +1008,20 @@ func (a *Application) Shutdown() error {
 		}
 	}
 
+	if a.Database != nil {
+		if err := a.Database.Close(); err != nil {
+			a.Logger.Warn("Failed to close database", logger.F("error", err.Error()))
+		}
+	}
+
+	// Close the status consumer (the Kafka reader holds connections; the SQS
+	// consumer is not a Closer and is skipped).
+	if closer, ok := a.StatusConsumer.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			a.Logger.Warn("Failed to close status consumer", logger.F("error", err.Error()))
+		}
+	}
+
 	// Close the resource publisher (the Kafka writer holds connections; the SQS
 	// publisher is not a Closer and is skipped).
 	if closer, ok := a.ResourcePublisher.(io.Closer); ok {
@@ -400,6 +1029,9 @@ func (a *Application) Shutdown() error {
 			a.Logger.Warn("Failed to close resource publisher", logger.F("error", err.Error()))
 		}
 	}
+	if a.NATSConn != nil {
+		a.NATSConn.Close()
+	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), a.Config.Server.ShutdownTimeout)
 	defer cancel()
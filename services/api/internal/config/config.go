@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -26,14 +27,256 @@ type Config struct {
 
 	// Messaging transport (Kafka in local dev, SQS otherwise)
 	Messaging MessagingConfig
+
+	// Postgres backing the resource read model
+	Database DatabaseConfig
+
+	// Transactional outbox relay
+	Outbox OutboxConfig
+
+	// Access token verification on authenticated routes
+	Auth AuthConfig
+
+	// Policy checks on what may be provisioned
+	Guardrails GuardrailsConfig
+
+	// Audit log of mutating API calls
+	Audit AuditConfig
+
+	// Per-caller rate limits on API and auth routes
+	RateLimit RateLimitConfig
+}
+
+// RateLimitConfig controls the per-caller rate limits. Limits are written
+// requests/window, e.g. "100/1m"; Default applies to every API and auth route
+// and Routes overrides it per route pattern, as "POST /v1/provision=10/1m"
+// ("0/1m" leaves a route unlimited). ClientIP limits each client IP across
+// the authenticated routes before its credentials are checked, so it should
+// allow for several callers behind one address. Buckets live in Redis when it is
+// configured, in memory otherwise.
+type RateLimitConfig struct {
+	Enabled  bool
+	Default  string
+	Routes   []string
+	ClientIP string
+	// TrustForwardedFor keys anonymous callers, and the ClientIP limit, by the
+	// last X-Forwarded-For hop instead of the peer address. It must be set
+	// behind a proxy and only then. The deployed path is API Gateway, a VPC
+	// link and an NLB with IP targets (k8s/api/targetgroupbinding.yaml): the
+	// peer address is an NLB ENI, so without it every caller shares a handful
+	// of buckets, while API Gateway appends the address it saw to
+	// X-Forwarded-For. Without a proxy that appends the header, clients could
+	// pick their own bucket by sending it.
+	TrustForwardedFor bool
+}
+
+// RateLimit allows Requests calls per Window; zero Requests is unlimited.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// ParseRateLimit parses a limit written requests/window, e.g. "100/1m".
+func ParseRateLimit(s string) (RateLimit, error) {
+	requests, window, ok := strings.Cut(s, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if !ok || err != nil || n < 0 {
+		return RateLimit{}, fmt.Errorf("%w: rate limit %q must be requests/window, e.g. 100/1m", ErrInvalidConfig, s)
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(window))
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("%w: rate limit %q must be requests/window, e.g. 100/1m", ErrInvalidConfig, s)
+	}
+	return RateLimit{Requests: n, Window: d}, nil
+}
+
+// Limits parses Default and Routes, the latter into limits by route pattern.
+func (r RateLimitConfig) Limits() (RateLimit, map[string]RateLimit, error) {
+	def, err := ParseRateLimit(r.Default)
+	if err != nil {
+		return RateLimit{}, nil, err
+	}
+	routes := make(map[string]RateLimit, len(r.Routes))
+	for _, entry := range r.Routes {
+		pattern, spec, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(pattern) == "" {
+			return RateLimit{}, nil, fmt.Errorf("%w: route rate limit %q must be pattern=requests/window", ErrInvalidConfig, entry)
+		}
+		limit, err := ParseRateLimit(spec)
+		if err != nil {
+			return RateLimit{}, nil, err
+		}
+		routes[strings.TrimSpace(pattern)] = limit
+	}
+	return def, routes, nil
+}
+
+// AuditConfig controls the audit log. AdminGroups are the identity provider
+// groups whose members may read it at /v1/audit.
+type AuditConfig struct {
+	AdminGroups []string
+}
+
+// GuardrailsConfig points at the versioned guardrail file checked against
+// every provisioning request (allowed instance types, regions, required
+// tags). Without File no guardrails are enforced.
+type GuardrailsConfig struct {
+	File string
+}
+
+// AuthConfig controls the identity provider and how access tokens are
+// verified. Outside local mode the issuer, key set and audience default to the
+// Cognito user pool and app client loaded from Parameter Store; the overrides
+// point the API at another issuer (in local mode, setting Issuer is what turns
+// authentication on). Provider "local" swaps Cognito for the in-process
+// provider in local mode, which issues its own tokens: Issuer then defaults to
+// http://localhost:<port> and JWKSURL is ignored. PolicyFile, when set, is the
+// versioned access policy deciding which groups may provision what; without
+// it any authenticated caller may provision anything. APITokenTTL and
+// APITokenMaxTTL are the default and longest lifetimes of personal API tokens;
+// see MaxAPITokenTTL for why the latter is bounded.
+//
+// Service clients authenticate with the OAuth2 client-credentials grant.
+// TokenURL is the Cognito user pool domain's token endpoint, ResourceServer
+// the identifier of the resource server defining the API's scopes (its prefix
+// is stripped from token scopes), and MachineClients the app client IDs whose
+// tokens are accepted besides the API's own. With the local provider,
+// LocalClients lists the service clients as id=secret pairs.
+// MaxAPITokenTTL bounds API_TOKEN_MAX_TTL. A personal API token carries its
+// owner's groups as they were when it was issued, so a group removal or a
+// disabled account reaches existing tokens only when they expire (or are
+// revoked); this is the longest that window may be.
+const MaxAPITokenTTL = 90 * 24 * time.Hour
+
+type AuthConfig struct {
+	Provider    string
+	Issuer      string
+	JWKSURL     string
+	Audiences   []string
+	TokenUse    string
+	JWKSTTL     time.Duration
+	ClockLeeway time.Duration
+	PolicyFile  string
+
+	APITokenTTL    time.Duration
+	APITokenMaxTTL time.Duration
+
+	TokenURL       string
+	ResourceServer string
+	MachineClients []string
+	LocalClients   []string
+}
+
+// LocalClientSecrets parses LocalClients into client secrets by client ID.
+func (a AuthConfig) LocalClientSecrets() (map[string]string, error) {
+	clients := make(map[string]string, len(a.LocalClients))
+	for _, entry := range a.LocalClients {
+		id, secret, ok := strings.Cut(entry, "=")
+		if !ok || id == "" || secret == "" {
+			return nil, fmt.Errorf("%w: local client %q must be id=secret", ErrInvalidConfig, entry)
+		}
+		clients[id] = secret
+	}
+	return clients, nil
+}
+
+// Identity providers selectable with AUTH_PROVIDER.
+const (
+	AuthProviderCognito = "cognito"
+	AuthProviderLocal   = "local"
+)
+
+// DatabaseConfig holds the Postgres connection used by the resource read model,
+// the outbox, API tokens and the audit log. URL is required outside local
+// mode; without it local mode keeps them in memory, lost on restart.
+type DatabaseConfig struct {
+	URL          string
+	MaxOpenConns int
+	PingTimeout  time.Duration
 }
 
-// MessagingConfig holds the local Kafka transport settings. In local mode the
-// resource publisher writes to Kafka instead of SQS, so the whole
-// API -> queue -> provisioner flow runs offline without AWS.
+// OutboxConfig tunes the relay that publishes accepted requests from the
+// outbox to the provisioning queue.
+type OutboxConfig struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	Lease          time.Duration
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// Wire formats of published provisioning requests.
+const (
+	MessageFormatJSON     = "json"
+	MessageFormatProtobuf = "protobuf"
+)
+
+// Message transports, as chosen by MessagingConfig.Transport.
+const (
+	TransportSQS   = "sqs"
+	TransportKafka = "kafka"
+	TransportNATS  = "nats"
+)
+
+// MessagingConfig holds the Kafka and NATS transport settings. Provisioning
+// requests go out, and status events come back, over one transport: see
+// Transport. Kafka and NATS need no AWS, so with either the whole API ->
+// queue -> provisioner -> API flow runs offline.
 type MessagingConfig struct {
-	KafkaBrokers []string
-	KafkaTopic   string
+	// Format is the wire format of published provisioning requests, on
+	// either transport. Consumers read both, so it can be switched without
+	// draining the queue first.
+	Format             string
+	KafkaBrokers       []string
+	KafkaTopic         string
+	KafkaStatusTopic   string
+	KafkaStatusGroupID string
+	KafkaPublisher     KafkaPublisherConfig
+	// NATSURL selects NATS JetStream as the transport. Requests are
+	// published to NATSSubject, captured by NATSStream; status events are
+	// read from NATSStatusSubject, captured by NATSStatusStream, through the
+	// durable consumer NATSStatusDurable.
+	NATSURL           string
+	NATSStream        string
+	NATSSubject       string
+	NATSStatusStream  string
+	NATSStatusSubject string
+	NATSStatusDurable string
+}
+
+// Transport is the transport for provisioning requests and status events:
+// NATS when NATS_URL is set, otherwise Kafka when KAFKA_BROKERS is, otherwise
+// SQS (and SNS for status events). The provisioner applies the same rule to
+// the same variables, so both ends always meet on one transport.
+func (m MessagingConfig) Transport() string {
+	switch {
+	case m.NATSURL != "":
+		return TransportNATS
+	case len(m.KafkaBrokers) > 0:
+		return TransportKafka
+	default:
+		return TransportSQS
+	}
+}
+
+// KafkaPublisherConfig tunes how provisioning requests are written to Kafka.
+// By default each publish waits for all in-sync replicas to acknowledge it.
+type KafkaPublisherConfig struct {
+	// Async queues each message for the next batch instead of waiting for
+	// the broker. The outbox relay still removes a message only once its
+	// delivery is confirmed; a caller publishing directly gets no
+	// confirmation, so leave it off where a write must be confirmed before
+	// the request is answered.
+	Async bool
+	// BatchSize and BatchBytes cap a batch; Linger is how long a batch waits
+	// to fill before it is sent anyway.
+	BatchSize  int
+	BatchBytes int
+	Linger     time.Duration
+	// Compression is none, gzip, snappy, lz4 or zstd.
+	Compression string
+	// RequiredAcks is none, one or all.
+	RequiredAcks string
 }
 
 // ServerConfig holds HTTP server configuration.
@@ -50,7 +293,9 @@ type AWSConfig struct {
 	Region                   string
 	ProvisionerQueueParamKey string
 	CognitoClientIDParamKey  string
+	CognitoUserPoolParamKey  string
 	RedisAddrParamKey        string
+	StatusQueueParamKey      string
 }
 
 // IdempotencyConfig holds settings for the Redis-backed idempotency layer.
@@ -83,6 +328,9 @@ type Option func(*Config)
 // ErrMissingConfig is returned when a required configuration value is missing.
 var ErrMissingConfig = errors.New("missing required configuration")
 
+// ErrInvalidConfig is returned when a configuration value is not supported.
+var ErrInvalidConfig = errors.New("invalid configuration")
+
 // NewConfig creates a new Config with default values and applies any options.
 func NewConfig(opts ...Option) *Config {
 	cfg := &Config{
@@ -97,7 +345,9 @@ func NewConfig(opts ...Option) *Config {
 			Region:                   getEnvOrDefault("AWS_REGION", "us-east-1"),
 			ProvisionerQueueParamKey: getEnvOrDefault("PROVISIONER_QUEUE_PARAM_KEY", "/INTERNAL_DEVELOPER_PLATFORM/PROVISIONER_QUEUE_URL"),
 			CognitoClientIDParamKey:  getEnvOrDefault("COGNITO_CLIENT_ID_PARAM_KEY", "/INTERNAL_DEVELOPER_PLATFORM/COGNITO_CLIENT_ID"),
+			CognitoUserPoolParamKey:  getEnvOrDefault("COGNITO_USER_POOL_ID_PARAM_KEY", "/idp/shared/identity/user_pool_id"),
 			RedisAddrParamKey:        getEnvOrDefault("REDIS_ADDR_PARAM_KEY", "/INTERNAL_DEVELOPER_PLATFORM/REDIS_ADDR"),
+			StatusQueueParamKey:      getEnvOrDefault("STATUS_QUEUE_PARAM_KEY", "/INTERNAL_DEVELOPER_PLATFORM/STATUS_QUEUE_URL"),
 		},
 		App: AppConfig{
 			Environment:    getEnvOrDefault("ENVIRONMENT", "dev"),
@@ -108,8 +358,68 @@ func NewConfig(opts ...Option) *Config {
 			Version:        getEnvOrDefault("SERVICE_VERSION", ""),
 		},
 		Messaging: MessagingConfig{
-			KafkaBrokers: getSliceEnv("KAFKA_BROKERS", nil),
-			KafkaTopic:   getEnvOrDefault("KAFKA_TOPIC", "resource-provisioning"),
+			Format:             getEnvOrDefault("MESSAGE_FORMAT", MessageFormatJSON),
+			KafkaBrokers:       getSliceEnv("KAFKA_BROKERS", nil),
+			KafkaTopic:         getEnvOrDefault("KAFKA_TOPIC", "resource-provisioning"),
+			KafkaStatusTopic:   getEnvOrDefault("KAFKA_STATUS_TOPIC", "resource-status-changed"),
+			KafkaStatusGroupID: getEnvOrDefault("KAFKA_STATUS_GROUP_ID", "internal-developer-platform-api"),
+			KafkaPublisher: KafkaPublisherConfig{
+				Async:        getBoolEnv("KAFKA_PUBLISH_ASYNC", false),
+				BatchSize:    getIntEnv("KAFKA_BATCH_SIZE", 100),
+				BatchBytes:   getIntEnv("KAFKA_BATCH_BYTES", 1<<20),
+				Linger:       getDurationEnv("KAFKA_LINGER", 10*time.Millisecond),
+				Compression:  getEnvOrDefault("KAFKA_COMPRESSION", "none"),
+				RequiredAcks: getEnvOrDefault("KAFKA_REQUIRED_ACKS", "all"),
+			},
+			NATSURL:           getEnvOrDefault("NATS_URL", ""),
+			NATSStream:        getEnvOrDefault("NATS_STREAM", "RESOURCE_PROVISIONING"),
+			NATSSubject:       getEnvOrDefault("NATS_SUBJECT", "resource.provisioning"),
+			NATSStatusStream:  getEnvOrDefault("NATS_STATUS_STREAM", "RESOURCE_STATUS"),
+			NATSStatusSubject: getEnvOrDefault("NATS_STATUS_SUBJECT", "resource.status-changed"),
+			NATSStatusDurable: getEnvOrDefault("NATS_STATUS_DURABLE", "internal-developer-platform-api"),
+		},
+		Database: DatabaseConfig{
+			URL:          getEnvOrDefault("DATABASE_URL", ""),
+			MaxOpenConns: getIntEnv("DATABASE_MAX_OPEN_CONNS", 10),
+			PingTimeout:  getDurationEnv("DATABASE_PING_TIMEOUT", 5*time.Second),
+		},
+		Outbox: OutboxConfig{
+			PollInterval:   getDurationEnv("OUTBOX_POLL_INTERVAL", time.Second),
+			BatchSize:      getIntEnv("OUTBOX_BATCH_SIZE", 50),
+			Lease:          getDurationEnv("OUTBOX_LEASE", 30*time.Second),
+			InitialBackoff: getDurationEnv("OUTBOX_INITIAL_BACKOFF", time.Second),
+			MaxBackoff:     getDurationEnv("OUTBOX_MAX_BACKOFF", 5*time.Minute),
+		},
+		Auth: AuthConfig{
+			Provider:    getEnvOrDefault("AUTH_PROVIDER", AuthProviderCognito),
+			Issuer:      getEnvOrDefault("AUTH_ISSUER", ""),
+			JWKSURL:     getEnvOrDefault("AUTH_JWKS_URL", ""),
+			Audiences:   getSliceEnv("AUTH_AUDIENCES", nil),
+			TokenUse:    getEnvOrDefault("AUTH_TOKEN_USE", "access"),
+			JWKSTTL:     getDurationEnv("AUTH_JWKS_TTL", time.Hour),
+			ClockLeeway: getDurationEnv("AUTH_CLOCK_LEEWAY", 30*time.Second),
+			PolicyFile:  getEnvOrDefault("AUTH_POLICY_FILE", ""),
+
+			APITokenTTL:    getDurationEnv("API_TOKEN_TTL", 30*24*time.Hour),
+			APITokenMaxTTL: getDurationEnv("API_TOKEN_MAX_TTL", 30*24*time.Hour),
+
+			TokenURL:       getEnvOrDefault("AUTH_TOKEN_URL", ""),
+			ResourceServer: getEnvOrDefault("AUTH_RESOURCE_SERVER", ""),
+			MachineClients: getSliceEnv("AUTH_MACHINE_CLIENT_IDS", nil),
+			LocalClients:   getSliceEnv("AUTH_LOCAL_CLIENTS", nil),
+		},
+		Guardrails: GuardrailsConfig{
+			File: getEnvOrDefault("GUARDRAILS_FILE", ""),
+		},
+		Audit: AuditConfig{
+			AdminGroups: getSliceEnv("AUDIT_ADMIN_GROUPS", []string{"platform-admins"}),
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:           getBoolEnv("RATE_LIMIT_ENABLED", true),
+			Default:           getEnvOrDefault("RATE_LIMIT_DEFAULT", "100/1m"),
+			Routes:            getSliceEnv("RATE_LIMIT_ROUTES", nil),
+			ClientIP:          getEnvOrDefault("RATE_LIMIT_CLIENT_IP", "300/1m"),
+			TrustForwardedFor: getBoolEnv("RATE_LIMIT_TRUST_FORWARDED_FOR", false),
 		},
 		Idempotency: IdempotencyConfig{
 			RedisAddr:     getEnvOrDefault("REDIS_ADDR", ""),
@@ -140,6 +450,54 @@ func (c *Config) Validate() error {
 	if c.AWS.CognitoClientIDParamKey == "" {
 		return fmt.Errorf("%w: cognito client id param key", ErrMissingConfig)
 	}
+	switch c.Auth.Provider {
+	case AuthProviderCognito:
+	case AuthProviderLocal:
+		if c.App.Environment != "local" {
+			return fmt.Errorf("%w: auth provider %q is only supported in local mode", ErrInvalidConfig, c.Auth.Provider)
+		}
+	default:
+		return fmt.Errorf("%w: unknown auth provider %q", ErrInvalidConfig, c.Auth.Provider)
+	}
+	if c.Auth.APITokenTTL <= 0 || c.Auth.APITokenTTL > c.Auth.APITokenMaxTTL {
+		return fmt.Errorf("%w: api token ttl %s must be positive and at most the max ttl %s", ErrInvalidConfig, c.Auth.APITokenTTL, c.Auth.APITokenMaxTTL)
+	}
+	if c.Auth.APITokenMaxTTL > MaxAPITokenTTL {
+		return fmt.Errorf("%w: api token max ttl %s may be at most %s", ErrInvalidConfig, c.Auth.APITokenMaxTTL, MaxAPITokenTTL)
+	}
+	if _, err := c.Auth.LocalClientSecrets(); err != nil {
+		return err
+	}
+	if c.RateLimit.Enabled {
+		if _, _, err := c.RateLimit.Limits(); err != nil {
+			return err
+		}
+		if _, err := ParseRateLimit(c.RateLimit.ClientIP); err != nil {
+			return err
+		}
+	}
+	switch c.Messaging.Format {
+	case MessageFormatJSON, MessageFormatProtobuf:
+	default:
+		return fmt.Errorf("%w: unknown message format %q", ErrInvalidConfig, c.Messaging.Format)
+	}
+	return c.Messaging.KafkaPublisher.validate()
+}
+
+func (c KafkaPublisherConfig) validate() error {
+	if c.BatchSize <= 0 || c.BatchBytes <= 0 || c.Linger < 0 {
+		return fmt.Errorf("%w: kafka batch size %d, bytes %d and linger %s must be positive", ErrInvalidConfig, c.BatchSize, c.BatchBytes, c.Linger)
+	}
+	switch c.Compression {
+	case "none", "gzip", "snappy", "lz4", "zstd":
+	default:
+		return fmt.Errorf("%w: unknown kafka compression %q", ErrInvalidConfig, c.Compression)
+	}
+	switch c.RequiredAcks {
+	case "none", "one", "all":
+	default:
+		return fmt.Errorf("%w: kafka required acks %q must be none, one or all", ErrInvalidConfig, c.RequiredAcks)
+	}
 	return nil
 }
 
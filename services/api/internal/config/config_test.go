@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -103,3 +104,231 @@ func TestConfig_Validate_MissingQueueParam(t *testing.T) {
 		t.Error("expected validation error for missing queue param key")
 	}
 }
+
+func TestConfig_Validate_AuthProvider(t *testing.T) {
+	os.Clearenv()
+	t.Setenv("AUTH_PROVIDER", AuthProviderLocal)
+
+	cfg := NewConfig(WithEnvironment("local"))
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected local provider to be valid in local mode, got %v", err)
+	}
+
+	cfg = NewConfig(WithEnvironment("production"))
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected local provider to be rejected outside local mode, got %v", err)
+	}
+
+	cfg.Auth.Provider = "okta"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected unknown provider to be rejected, got %v", err)
+	}
+}
+
+func TestConfig_Validate_MessageFormat(t *testing.T) {
+	os.Clearenv()
+
+	cfg := NewConfig()
+	if cfg.Messaging.Format != MessageFormatJSON {
+		t.Errorf("expected default message format json, got %s", cfg.Messaging.Format)
+	}
+
+	t.Setenv("MESSAGE_FORMAT", MessageFormatProtobuf)
+	cfg = NewConfig()
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected protobuf to be valid, got %v", err)
+	}
+
+	cfg.Messaging.Format = "avro"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected unknown format to be rejected, got %v", err)
+	}
+}
+
+func TestConfig_KafkaPublisher(t *testing.T) {
+	os.Clearenv()
+
+	cfg := NewConfig()
+	kp := cfg.Messaging.KafkaPublisher
+	if kp.Async || kp.BatchSize != 100 || kp.Linger != 10*time.Millisecond || kp.RequiredAcks != "all" || kp.Compression != "none" {
+		t.Errorf("unexpected kafka publisher defaults %+v", kp)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected defaults to be valid, got %v", err)
+	}
+
+	t.Setenv("KAFKA_PUBLISH_ASYNC", "true")
+	t.Setenv("KAFKA_COMPRESSION", "zstd")
+	t.Setenv("KAFKA_LINGER", "50ms")
+	cfg = NewConfig()
+	kp = cfg.Messaging.KafkaPublisher
+	if !kp.Async || kp.Compression != "zstd" || kp.Linger != 50*time.Millisecond {
+		t.Errorf("kafka publisher env not applied: %+v", kp)
+	}
+
+	cfg.Messaging.KafkaPublisher.Compression = "brotli"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected unknown compression to be rejected, got %v", err)
+	}
+	cfg.Messaging.KafkaPublisher.Compression = "none"
+	cfg.Messaging.KafkaPublisher.RequiredAcks = "two"
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected unknown required acks to be rejected, got %v", err)
+	}
+	cfg.Messaging.KafkaPublisher.RequiredAcks = "all"
+	cfg.Messaging.KafkaPublisher.BatchSize = 0
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected zero batch size to be rejected, got %v", err)
+	}
+}
+
+func TestConfig_NATS(t *testing.T) {
+	os.Clearenv()
+
+	cfg := NewConfig()
+	if cfg.Messaging.NATSURL != "" {
+		t.Errorf("expected NATS to be off by default, got %q", cfg.Messaging.NATSURL)
+	}
+	if cfg.Messaging.NATSStream != "RESOURCE_PROVISIONING" || cfg.Messaging.NATSSubject != "resource.provisioning" {
+		t.Errorf("unexpected NATS defaults %q / %q", cfg.Messaging.NATSStream, cfg.Messaging.NATSSubject)
+	}
+
+	if cfg.Messaging.NATSStatusStream != "RESOURCE_STATUS" || cfg.Messaging.NATSStatusSubject != "resource.status-changed" {
+		t.Errorf("unexpected NATS status defaults %q / %q", cfg.Messaging.NATSStatusStream, cfg.Messaging.NATSStatusSubject)
+	}
+
+	t.Setenv("NATS_URL", "nats://localhost:4222")
+	if cfg = NewConfig(); cfg.Messaging.NATSURL != "nats://localhost:4222" {
+		t.Errorf("NATS_URL not applied: %q", cfg.Messaging.NATSURL)
+	}
+}
+
+func TestMessagingConfig_Transport(t *testing.T) {
+	cases := []struct {
+		name string
+		cfg  MessagingConfig
+		want string
+	}{
+		{"nothing configured", MessagingConfig{}, TransportSQS},
+		{"kafka brokers", MessagingConfig{KafkaBrokers: []string{"kafka:9092"}}, TransportKafka},
+		{"nats url", MessagingConfig{NATSURL: "nats://nats:4222"}, TransportNATS},
+		{"nats wins over kafka", MessagingConfig{NATSURL: "nats://nats:4222", KafkaBrokers: []string{"kafka:9092"}}, TransportNATS},
+	}
+	for _, c := range cases {
+		if got := c.cfg.Transport(); got != c.want {
+			t.Errorf("%s: Transport() = %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestConfig_Validate_APITokenTTL(t *testing.T) {
+	os.Clearenv()
+
+	cfg := NewConfig()
+	if cfg.Auth.APITokenTTL != 30*24*time.Hour || cfg.Auth.APITokenMaxTTL != 30*24*time.Hour {
+		t.Errorf("unexpected api token ttl defaults %s / %s", cfg.Auth.APITokenTTL, cfg.Auth.APITokenMaxTTL)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("expected defaults to be valid, got %v", err)
+	}
+
+	t.Setenv("API_TOKEN_TTL", "2160h")
+	t.Setenv("API_TOKEN_MAX_TTL", "720h")
+	cfg = NewConfig()
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected a default ttl above the max to be rejected, got %v", err)
+	}
+
+	t.Setenv("API_TOKEN_TTL", "720h")
+	t.Setenv("API_TOKEN_MAX_TTL", "8760h")
+	cfg = NewConfig()
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected a max ttl above MaxAPITokenTTL to be rejected, got %v", err)
+	}
+}
+
+func TestConfig_LocalClients(t *testing.T) {
+	os.Clearenv()
+
+	t.Setenv("AUTH_LOCAL_CLIENTS", "ci-bot=s3cret,deployer=hunter2")
+	cfg := NewConfig()
+	clients, err := cfg.Auth.LocalClientSecrets()
+	if err != nil {
+		t.Fatalf("expected local clients to parse, got %v", err)
+	}
+	if len(clients) != 2 || clients["ci-bot"] != "s3cret" || clients["deployer"] != "hunter2" {
+		t.Errorf("unexpected local clients %v", clients)
+	}
+
+	t.Setenv("AUTH_LOCAL_CLIENTS", "ci-bot")
+	cfg = NewConfig()
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected a client without a secret to be rejected, got %v", err)
+	}
+}
+
+func TestConfig_AuditAdminGroups(t *testing.T) {
+	os.Clearenv()
+
+	if got := NewConfig().Audit.AdminGroups; len(got) != 1 || got[0] != "platform-admins" {
+		t.Errorf("expected platform-admins by default, got %v", got)
+	}
+
+	t.Setenv("AUDIT_ADMIN_GROUPS", "platform-admins,security")
+	if got := NewConfig().Audit.AdminGroups; len(got) != 2 || got[1] != "security" {
+		t.Errorf("unexpected audit admin groups %v", got)
+	}
+}
+
+func TestConfig_RateLimits(t *testing.T) {
+	os.Clearenv()
+
+	def, routes, err := NewConfig().RateLimit.Limits()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if def != (RateLimit{Requests: 100, Window: time.Minute}) || len(routes) != 0 {
+		t.Errorf("expected 100/1m and no route limits by default, got %v %v", def, routes)
+	}
+
+	t.Setenv("RATE_LIMIT_DEFAULT", "20/10s")
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /v1/provision=5/1m, POST /v1/auth/signin=0/1m")
+	def, routes, err = NewConfig().RateLimit.Limits()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if def != (RateLimit{Requests: 20, Window: 10 * time.Second}) {
+		t.Errorf("unexpected default limit %v", def)
+	}
+	if got := routes["POST /v1/provision"]; got != (RateLimit{Requests: 5, Window: time.Minute}) {
+		t.Errorf("unexpected provision limit %v", got)
+	}
+	if got, ok := routes["POST /v1/auth/signin"]; !ok || got.Requests != 0 {
+		t.Errorf("expected signin to be unlimited, got %v", got)
+	}
+
+	for _, bad := range []string{"100", "x/1m", "-1/1m", "10/0s", "10/soon"} {
+		if _, err := ParseRateLimit(bad); !errors.Is(err, ErrInvalidConfig) {
+			t.Errorf("expected ErrInvalidConfig for %q, got %v", bad, err)
+		}
+	}
+
+	if got := NewConfig().RateLimit.ClientIP; got != "300/1m" {
+		t.Errorf("expected a 300/1m client IP limit by default, got %q", got)
+	}
+	t.Setenv("RATE_LIMIT_CLIENT_IP", "lots")
+	if err := NewConfig().Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a bad client IP limit, got %v", err)
+	}
+	t.Setenv("RATE_LIMIT_CLIENT_IP", "300/1m")
+
+	t.Setenv("RATE_LIMIT_ROUTES", "POST /v1/provision")
+	cfg := NewConfig()
+	if err := cfg.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("expected ErrInvalidConfig for a route without a limit, got %v", err)
+	}
+	cfg.RateLimit.Enabled = false
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("disabled rate limits are not validated, got %v", err)
+	}
+}
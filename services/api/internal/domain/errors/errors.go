@@ -33,6 +33,12 @@ var (
 
 	// ErrUnavailable indicates that a service is temporarily unavailable.
 	ErrUnavailable = errors.New("service unavailable")
+
+	// ErrInvalidStateTransition indicates a status change the state machine forbids.
+	ErrInvalidStateTransition = errors.New("invalid state transition")
+
+	// ErrPolicyViolation indicates a request that breaks a provisioning guardrail.
+	ErrPolicyViolation = errors.New("policy violation")
 )
 
 // DomainError represents an error that occurred in the domain layer.
@@ -94,6 +100,11 @@ const (
 	ErrCodeResourceAlreadyExists = "RESOURCE_ALREADY_EXISTS"
 	ErrCodeInvalidResourceType   = "INVALID_RESOURCE_TYPE"
 	ErrCodeInvalidCloudProvider  = "INVALID_CLOUD_PROVIDER"
+	ErrCodePolicyViolation       = "POLICY_VIOLATION"
+
+	// Provisioning status errors
+	ErrCodeInvalidStatus           = "INVALID_STATUS"
+	ErrCodeInvalidStatusTransition = "INVALID_STATUS_TRANSITION"
 
 	// Auth errors
 	ErrCodeAuthFailed              = "AUTH_FAILED"
@@ -102,6 +113,12 @@ const (
 	ErrCodeInvalidCredentials      = "INVALID_CREDENTIALS"
 	ErrCodeEmailNotVerified        = "EMAIL_NOT_VERIFIED"
 	ErrCodeInvalidConfirmationCode = "INVALID_CONFIRMATION_CODE"
+	ErrCodeInvalidToken            = "INVALID_TOKEN"
+	ErrCodeTokenExpired            = "TOKEN_EXPIRED"
+	ErrCodeCodeDeliveryFailed      = "CODE_DELIVERY_FAILED"
+	ErrCodePasswordResetRequired   = "PASSWORD_RESET_REQUIRED"
+	ErrCodeTooManyRequests         = "TOO_MANY_REQUESTS"
+	ErrCodeAccessDenied            = "ACCESS_DENIED"
 
 	// Validation errors
 	ErrCodeValidationFailed = "VALIDATION_FAILED"
@@ -172,6 +189,16 @@ func InvalidInput(message string) *DomainError {
 	)
 }
 
+// InvalidStatusTransition creates an error for a status change the
+// provisioning state machine does not allow.
+func InvalidStatusTransition(id, from, to string) *DomainError {
+	return NewDomainError(
+		ErrCodeInvalidStatusTransition,
+		fmt.Sprintf("resource '%s' cannot move from %s to %s", id, from, to),
+		ErrInvalidStateTransition,
+	).WithDetail("from", from).WithDetail("to", to)
+}
+
 // Unauthorized creates an unauthorized error.
 func Unauthorized(message string) *DomainError {
 	return NewDomainError(
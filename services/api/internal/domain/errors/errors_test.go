@@ -103,6 +103,20 @@ func TestAlreadyExists(t *testing.T) {
 	}
 }
 
+func TestInvalidStatusTransition(t *testing.T) {
+	err := InvalidStatusTransition("vm-001", "completed", "pending")
+
+	if err.Code != ErrCodeInvalidStatusTransition {
+		t.Errorf("expected code %s, got %s", ErrCodeInvalidStatusTransition, err.Code)
+	}
+	if !errors.Is(err, ErrInvalidStateTransition) {
+		t.Error("InvalidStatusTransition error should wrap ErrInvalidStateTransition")
+	}
+	if err.Details["from"] != "completed" || err.Details["to"] != "pending" {
+		t.Errorf("unexpected details: %v", err.Details)
+	}
+}
+
 func TestUnauthorized(t *testing.T) {
 	err := Unauthorized("access denied")
 
@@ -0,0 +1,127 @@
+package model
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+)
+
+// AnyValue in an AccessRule list matches every group, resource type or cloud
+// provider.
+const AnyValue = "*"
+
+// AccessPolicy decides which callers may provision what. A request is allowed
+// when at least one rule applies to the caller and permits both its resource
+// type and its cloud provider; everything else is denied.
+type AccessPolicy struct {
+	// Version of the policy file the rules were loaded from.
+	Version int
+	Rules   []AccessRule
+}
+
+// AccessRule grants the members of Groups the right to provision
+// ResourceTypes on CloudProviders, e.g. "team-payments may provision VM and
+// S3 on AWS". Service clients have no groups: a rule applies to one when it
+// lists its client ID in ClientIDs or one of its scopes in Scopes. Those two
+// match only machine principals, so a user's API token cannot gain rights
+// through its scopes.
+type AccessRule struct {
+	// Name identifies the rule in denials and logs.
+	Name           string
+	Groups         []string
+	ClientIDs      []string
+	Scopes         []string
+	ResourceTypes  []string
+	CloudProviders []string
+}
+
+// Validate reports the first rule that could never match or is ambiguous.
+func (p AccessPolicy) Validate() error {
+	seen := make(map[string]bool, len(p.Rules))
+	for i, rule := range p.Rules {
+		switch {
+		case rule.Name == "":
+			return fmt.Errorf("rule %d: name is required", i)
+		case seen[rule.Name]:
+			return fmt.Errorf("rule %q: duplicate name", rule.Name)
+		case len(rule.Groups) == 0 && len(rule.ClientIDs) == 0 && len(rule.Scopes) == 0:
+			return fmt.Errorf("rule %q: at least one group, client ID or scope is required", rule.Name)
+		case len(rule.ResourceTypes) == 0:
+			return fmt.Errorf("rule %q: at least one resource type is required", rule.Name)
+		case len(rule.CloudProviders) == 0:
+			return fmt.Errorf("rule %q: at least one cloud provider is required", rule.Name)
+		}
+		seen[rule.Name] = true
+	}
+	return nil
+}
+
+// Authorize returns nil if principal may provision r, and otherwise an
+// ErrForbidden domain error whose message says why.
+func (p AccessPolicy) Authorize(principal Principal, r Resource) error {
+	var applicable []string
+	for _, rule := range p.Rules {
+		if !rule.appliesTo(principal) {
+			continue
+		}
+		if rule.permits(r) {
+			return nil
+		}
+		applicable = append(applicable, rule.Name)
+	}
+
+	var reason string
+	if len(applicable) == 0 {
+		reason = fmt.Sprintf("no provisioning rule applies to %s", describeCaller(principal))
+	} else {
+		reason = fmt.Sprintf("%s may not provision %s on %s",
+			describeCaller(principal), r.ResourceType, r.CloudProvider)
+	}
+	return errors.NewDomainError(errors.ErrCodeAccessDenied, reason, errors.ErrForbidden).
+		WithDetail("resource_type", r.ResourceType).
+		WithDetail("cloud_provider", r.CloudProvider).
+		WithDetail("rules", applicable)
+}
+
+func (r AccessRule) appliesTo(p Principal) bool {
+	if slices.Contains(r.Groups, AnyValue) || containsAny(r.Groups, p.Groups) {
+		return true
+	}
+	if !p.Machine {
+		return false
+	}
+	return slices.Contains(r.ClientIDs, p.ClientID) || containsAny(r.Scopes, p.Scopes)
+}
+
+// containsAny reports whether allowed holds any of values.
+func containsAny(allowed, values []string) bool {
+	return slices.ContainsFunc(values, func(v string) bool {
+		return slices.Contains(allowed, v)
+	})
+}
+
+func (r AccessRule) permits(res Resource) bool {
+	return matches(r.ResourceTypes, res.ResourceType) && matches(r.CloudProviders, res.CloudProvider)
+}
+
+// matches ignores case, as cloud providers are spelled differently across
+// the API ("Azure") and the provisioner ("AZURE").
+func matches(allowed []string, value string) bool {
+	return slices.ContainsFunc(allowed, func(a string) bool {
+		return a == AnyValue || strings.EqualFold(a, value)
+	})
+}
+
+// describeCaller names the caller in denials: a service client by its ID,
+// anyone else by their groups.
+func describeCaller(p Principal) string {
+	if p.Machine {
+		return "client " + p.ClientID
+	}
+	if len(p.Groups) == 0 {
+		return "your groups (none)"
+	}
+	return "your groups (" + strings.Join(p.Groups, ", ") + ")"
+}
@@ -0,0 +1,104 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+)
+
+func testPolicy() AccessPolicy {
+	return AccessPolicy{Version: 1, Rules: []AccessRule{
+		{Name: "platform-admins", Groups: []string{"platform-admins"}, ResourceTypes: []string{AnyValue}, CloudProviders: []string{AnyValue}},
+		{Name: "team-payments", Groups: []string{"team-payments"}, ResourceTypes: []string{"VM", "S3"}, CloudProviders: []string{"AWS"}},
+		{Name: "everyone-buckets", Groups: []string{AnyValue}, ResourceTypes: []string{"S3"}, CloudProviders: []string{"GCP"}},
+		{Name: "ci-deployer", ClientIDs: []string{"ci-bot"}, ResourceTypes: []string{"VM"}, CloudProviders: []string{"AWS"}},
+		{Name: "provisioning-services", Scopes: []string{"provision:write"}, ResourceTypes: []string{"RDS"}, CloudProviders: []string{"AWS"}},
+	}}
+}
+
+func TestAccessPolicy_Authorize(t *testing.T) {
+	policy := testPolicy()
+	cases := map[string]struct {
+		groups   []string
+		resource Resource
+		allowed  bool
+	}{
+		"admin anything":        {[]string{"platform-admins"}, Resource{ResourceType: "RDS", CloudProvider: "Azure"}, true},
+		"team allowed pair":     {[]string{"team-payments"}, Resource{ResourceType: "S3", CloudProvider: "AWS"}, true},
+		"team wrong provider":   {[]string{"team-payments"}, Resource{ResourceType: "VM", CloudProvider: "GCP"}, false},
+		"team wrong type":       {[]string{"team-payments"}, Resource{ResourceType: "RDS", CloudProvider: "AWS"}, false},
+		"wildcard group":        {nil, Resource{ResourceType: "S3", CloudProvider: "GCP"}, true},
+		"no matching rule":      {[]string{"interns"}, Resource{ResourceType: "VM", CloudProvider: "AWS"}, false},
+		"any of several groups": {[]string{"interns", "team-payments"}, Resource{ResourceType: "VM", CloudProvider: "AWS"}, true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := policy.Authorize(Principal{Subject: "user-1", Groups: tc.groups}, tc.resource)
+
+			if tc.allowed {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, domainerrors.ErrForbidden)
+		})
+	}
+}
+
+func TestAccessPolicy_AuthorizeMachinePrincipals(t *testing.T) {
+	policy := testPolicy()
+	machine := func(clientID string, scopes ...string) Principal {
+		return Principal{Subject: clientID, ClientID: clientID, Scopes: scopes, Machine: true}
+	}
+	vm := Resource{ResourceType: "VM", CloudProvider: "AWS"}
+	rds := Resource{ResourceType: "RDS", CloudProvider: "AWS"}
+
+	assert.NoError(t, policy.Authorize(machine("ci-bot"), vm), "matched by client ID")
+	assert.NoError(t, policy.Authorize(machine("other-bot", "provision:write"), rds), "matched by scope")
+	assert.ErrorIs(t, policy.Authorize(machine("other-bot", "provision:write"), vm), domainerrors.ErrForbidden)
+	assert.ErrorIs(t, policy.Authorize(machine("other-bot"), vm), domainerrors.ErrForbidden)
+
+	viaAPIToken := Principal{Subject: "user-1", ClientID: "ci-bot", Scopes: []string{"provision:write"}, APITokenID: "tok-1"}
+	assert.ErrorIs(t, policy.Authorize(viaAPIToken, rds), domainerrors.ErrForbidden,
+		"client IDs and scopes grant nothing to users")
+
+	var domainErr *domainerrors.DomainError
+	require.ErrorAs(t, policy.Authorize(machine("other-bot"), vm), &domainErr)
+	assert.Equal(t, "client other-bot may not provision VM on AWS", domainErr.Message)
+}
+
+func TestAccessPolicy_DenialExplainsWhy(t *testing.T) {
+	policy := testPolicy()
+
+	err := policy.Authorize(Principal{Groups: []string{"team-payments"}}, Resource{ResourceType: "VM", CloudProvider: "GCP"})
+
+	var domainErr *domainerrors.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerrors.ErrCodeAccessDenied, domainErr.Code)
+	assert.Equal(t, "your groups (team-payments) may not provision VM on GCP", domainErr.Message)
+	assert.Equal(t, []string{"team-payments", "everyone-buckets"}, domainErr.Details["rules"])
+
+	err = AccessPolicy{}.Authorize(Principal{}, Resource{ResourceType: "VM", CloudProvider: "AWS"})
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, "no provisioning rule applies to your groups (none)", domainErr.Message)
+}
+
+func TestAccessPolicy_Validate(t *testing.T) {
+	assert.NoError(t, testPolicy().Validate())
+
+	invalid := map[string]AccessRule{
+		"missing name":      {Groups: []string{"a"}, ResourceTypes: []string{"VM"}, CloudProviders: []string{"AWS"}},
+		"missing callers":   {Name: "r", ResourceTypes: []string{"VM"}, CloudProviders: []string{"AWS"}},
+		"missing types":     {Name: "r", Groups: []string{"a"}, CloudProviders: []string{"AWS"}},
+		"missing providers": {Name: "r", Groups: []string{"a"}, ResourceTypes: []string{"VM"}},
+	}
+	for name, rule := range invalid {
+		assert.Error(t, AccessPolicy{Rules: []AccessRule{rule}}.Validate(), name)
+	}
+
+	dup := testPolicy()
+	dup.Rules = append(dup.Rules, dup.Rules[0])
+	assert.Error(t, dup.Validate())
+}
@@ -0,0 +1,56 @@
+package model
+
+import "time"
+
+// APITokenPrefix starts every personal API token, so the API can tell them
+// apart from identity provider JWTs and secret scanners can spot leaked ones.
+const APITokenPrefix = "idp_pat_"
+
+// APIToken is a personal, long-lived credential for non-interactive callers
+// such as CI pipelines. It acts as its owner, limited to its scopes, until it
+// expires or is revoked. Only a hash of the secret is kept.
+type APIToken struct {
+	ID   string `json:"id" example:"5f0c7a52-3a59-4a8e-9a53-6b7c8e3d9f10"`
+	Name string `json:"name" example:"github-actions deploy"`
+	// Owner is the subject of the user who issued the token; requests made
+	// with it are attributed to them.
+	Owner string `json:"owner" example:"3f1c2b8e-7d4a-4c1e-9b2f-8a6d5e4c3b2a"`
+	// OwnerUsername and Groups are the owner's, captured at issuance, and
+	// stand in for the owner's identity on requests made with the token.
+	// They are not refreshed: an owner removed from a group or disabled keeps
+	// its rights through the token until it expires or is revoked, which is
+	// why token lifetimes are capped (see config.MaxAPITokenTTL).
+	OwnerUsername string   `json:"-"`
+	Groups        []string `json:"-"`
+	Scopes        []string `json:"scopes" example:"provision:write"`
+	// Hash is the hex SHA-256 of the secret token.
+	Hash string `json:"-"`
+	// Hint is the end of the secret, to tell tokens apart in listings.
+	Hint       string     `json:"hint" example:"…Q2xw"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Active reports whether the token is accepted at now.
+func (t APIToken) Active(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// IssuedAPIToken is a newly issued token together with its secret, which is
+// shown only this once.
+type IssuedAPIToken struct {
+	APIToken
+	Token string `json:"token" example:"idp_pat_3q2-7wEjY0kAa5T1xq9m0vOa8bZ8m6lJp4p1kQ2xw"`
+}
+
+// IssueAPITokenRequest asks for a new API token for the caller.
+type IssueAPITokenRequest struct {
+	// Name describes what the token is for
+	Name string `json:"name" validate:"required,max=100" example:"github-actions deploy"`
+	// Scopes the token is granted
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,oneof=provision:write provision:validate resources:read" example:"provision:write"`
+	// Days until the token expires; the server default applies when omitted
+	ExpiresInDays int `json:"expires_in_days,omitempty" validate:"omitempty,min=1,max=90" example:"30"`
+}
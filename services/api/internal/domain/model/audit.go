@@ -0,0 +1,158 @@
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Audited actions.
+const (
+	AuditActionProvision      = "resource.provision"
+	AuditActionIssueAPIToken  = "api_token.issue"
+	AuditActionRevokeAPIToken = "api_token.revoke"
+	AuditActionSignOut        = "auth.signout"
+
+	AuditActionSignUp                = "auth.signup"
+	AuditActionSignIn                = "auth.signin"
+	AuditActionConfirmSignUp         = "auth.confirm"
+	AuditActionRefreshTokens         = "auth.refresh"
+	AuditActionClientCredentials     = "auth.token"
+	AuditActionForgotPassword        = "auth.forgot_password"
+	AuditActionConfirmForgotPassword = "auth.confirm_forgot_password"
+	AuditActionResendConfirmation    = "auth.resend_confirmation"
+)
+
+// Audit outcomes, derived from the response status.
+const (
+	AuditOutcomeSuccess  = "success"
+	AuditOutcomeDenied   = "denied"
+	AuditOutcomeRejected = "rejected"
+	AuditOutcomeError    = "error"
+)
+
+// AuditGenesisHash is the PrevHash of the first entry of the log.
+const AuditGenesisHash = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// AuditEntry records one mutating API call. Entries form a hash chain: each
+// carries the hash of the one before it, and its own hash covers every other
+// field, so editing, removing or reordering an entry breaks the chain from
+// that point on.
+type AuditEntry struct {
+	// Seq is the entry's position in the log, from 1.
+	Seq  int64     `json:"seq" example:"42"`
+	ID   string    `json:"id" example:"8d7c7f3e-2b1a-4f4e-9c0d-5a6b7c8d9e0f"`
+	Time time.Time `json:"time"`
+	// Actor is the caller's subject; empty when the route is unauthenticated.
+	Actor         string `json:"actor" example:"3f1c2b8e-7d4a-4c1e-9b2f-8a6d5e4c3b2a"`
+	ActorUsername string `json:"actor_username,omitempty" example:"dev@example.com"`
+	ClientID      string `json:"client_id,omitempty" example:"6p3k2m9qv1example"`
+	APITokenID    string `json:"api_token_id,omitempty"`
+	Action        string `json:"action" example:"resource.provision"`
+	// Target is the resource, token or other object acted on, when known.
+	Target string `json:"target,omitempty" example:"vm-001"`
+	Method string `json:"method" example:"POST"`
+	Path   string `json:"path" example:"/v1/provision"`
+	// RequestHash is the hex SHA-256 of the request body, as used for
+	// idempotency keys. On auth routes it is taken after passwords, codes and
+	// secrets are redacted, so it cannot be used to guess them.
+	RequestHash string `json:"request_hash"`
+	Status      int    `json:"status" example:"202"`
+	Outcome     string `json:"outcome" example:"success"`
+	RequestID   string `json:"request_id,omitempty"`
+	TraceID     string `json:"trace_id,omitempty"`
+	PrevHash    string `json:"prev_hash"`
+	Hash        string `json:"hash"`
+}
+
+// AuditOutcomeFor classifies a response status.
+func AuditOutcomeFor(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return AuditOutcomeDenied
+	case status >= 500:
+		return AuditOutcomeError
+	case status >= 400:
+		return AuditOutcomeRejected
+	}
+	return AuditOutcomeSuccess
+}
+
+// Chain places e after prev (nil for the first entry), setting its Seq,
+// PrevHash and Hash. Time is cut to microseconds, the precision stores keep,
+// so the hash still matches once the entry is read back.
+func (e AuditEntry) Chain(prev *AuditEntry) AuditEntry {
+	e.Time = e.Time.UTC().Truncate(time.Microsecond)
+	e.Seq, e.PrevHash = 1, AuditGenesisHash
+	if prev != nil {
+		e.Seq, e.PrevHash = prev.Seq+1, prev.Hash
+	}
+	e.Hash = e.ComputeHash()
+	return e
+}
+
+// ComputeHash returns the hex SHA-256 of every field but Hash, in a fixed
+// encoding.
+func (e AuditEntry) ComputeHash() string {
+	e.Hash = ""
+	e.Time = e.Time.UTC()
+	// Marshalling a struct is deterministic: fields in declaration order.
+	b, _ := json.Marshal(e)
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+// VerifyAuditChain checks entries, given in sequence order and starting right
+// after prev (nil when they start the log). It returns how many entries are
+// intact and, if one is not, an error naming the first that was tampered
+// with.
+func VerifyAuditChain(prev *AuditEntry, entries []AuditEntry) (int, error) {
+	for i, e := range entries {
+		want := AuditEntry{}.Chain(prev)
+		switch {
+		case e.Seq != want.Seq:
+			return i, fmt.Errorf("audit entry %d: expected sequence %d", e.Seq, want.Seq)
+		case e.PrevHash != want.PrevHash:
+			return i, fmt.Errorf("audit entry %d: previous hash does not match entry %d", e.Seq, e.Seq-1)
+		case e.Hash != e.ComputeHash():
+			return i, fmt.Errorf("audit entry %d: hash does not match its contents", e.Seq)
+		}
+		prev = &e
+	}
+	return len(entries), nil
+}
+
+// AuditQuery filters the audit log. Empty fields match everything.
+type AuditQuery struct {
+	Actor   string
+	Action  string
+	Target  string
+	Outcome string
+	Since   time.Time
+	Until   time.Time
+	// Before restricts results to entries older than this sequence number,
+	// for paging; 0 starts from the newest entry.
+	Before int64
+	Limit  int
+}
+
+// AuditPage is one page of the audit log, newest first.
+type AuditPage struct {
+	Entries []AuditEntry `json:"entries"`
+	// NextBefore is passed as before to get the next (older) page; 0 when
+	// this is the last page.
+	NextBefore int64 `json:"next_before,omitempty" example:"17"`
+}
+
+// AuditVerification is the result of checking the whole audit log's chain.
+type AuditVerification struct {
+	Valid bool `json:"valid"`
+	// Entries is how many entries were found intact, up to the first broken
+	// one.
+	Entries int64 `json:"entries" example:"1024"`
+	// Error describes the first broken link, when the chain is not valid.
+	Error string `json:"error,omitempty"`
+}
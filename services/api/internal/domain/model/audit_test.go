@@ -0,0 +1,67 @@
+package model
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAuditChain(n int) []AuditEntry {
+	var (
+		entries []AuditEntry
+		prev    *AuditEntry
+	)
+	start := time.Date(2026, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	for i := range n {
+		e := AuditEntry{
+			ID: string(rune('a' + i)), Time: start.Add(time.Duration(i) * time.Second),
+			Actor: "user-1", Action: AuditActionProvision, Target: "vm-001", Status: http.StatusAccepted,
+		}.Chain(prev)
+		entries = append(entries, e)
+		prev = &entries[len(entries)-1]
+	}
+	return entries
+}
+
+func TestAuditEntry_Chain(t *testing.T) {
+	entries := testAuditChain(3)
+
+	assert.Equal(t, int64(1), entries[0].Seq)
+	assert.Equal(t, AuditGenesisHash, entries[0].PrevHash)
+	assert.Equal(t, entries[0].Hash, entries[1].PrevHash)
+	assert.Equal(t, int64(3), entries[2].Seq)
+	assert.Equal(t, 0, entries[0].Time.Nanosecond()%1000, "time is kept to the microsecond")
+	intact, err := VerifyAuditChain(nil, entries)
+	require.NoError(t, err)
+	assert.Equal(t, 3, intact)
+	_, err = VerifyAuditChain(&entries[0], entries[1:])
+	require.NoError(t, err)
+}
+
+func TestVerifyAuditChain_DetectsTampering(t *testing.T) {
+	cases := map[string]func([]AuditEntry) []AuditEntry{
+		"edited field":   func(e []AuditEntry) []AuditEntry { e[1].Target = "vm-002"; return e },
+		"removed entry":  func(e []AuditEntry) []AuditEntry { return append(e[:1], e[2:]...) },
+		"reordered":      func(e []AuditEntry) []AuditEntry { e[1], e[2] = e[2], e[1]; return e },
+		"rehashed entry": func(e []AuditEntry) []AuditEntry { e[1].Status = 500; e[1].Hash = e[1].ComputeHash(); return e },
+		"forged genesis": func(e []AuditEntry) []AuditEntry { e[0].PrevHash = e[2].Hash; return e },
+	}
+	for name, tamper := range cases {
+		t.Run(name, func(t *testing.T) {
+			intact, err := VerifyAuditChain(nil, tamper(testAuditChain(3)))
+			assert.Error(t, err)
+			assert.Less(t, intact, 3)
+		})
+	}
+}
+
+func TestAuditOutcomeFor(t *testing.T) {
+	assert.Equal(t, AuditOutcomeSuccess, AuditOutcomeFor(http.StatusAccepted))
+	assert.Equal(t, AuditOutcomeDenied, AuditOutcomeFor(http.StatusForbidden))
+	assert.Equal(t, AuditOutcomeDenied, AuditOutcomeFor(http.StatusUnauthorized))
+	assert.Equal(t, AuditOutcomeRejected, AuditOutcomeFor(http.StatusUnprocessableEntity))
+	assert.Equal(t, AuditOutcomeError, AuditOutcomeFor(http.StatusServiceUnavailable))
+}
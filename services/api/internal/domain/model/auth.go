@@ -24,6 +24,51 @@ type ConfirmSignUpRequest struct {
 	ConfirmationCode string `json:"confirmation_code" validate:"required,min=6,max=10,alphanum" example:"123456"`
 }
 
+// RefreshTokenRequest exchanges a refresh token for new access and ID tokens.
+type RefreshTokenRequest struct {
+	// Refresh token returned by sign in
+	RefreshToken string `json:"refresh_token" validate:"required,max=4096" example:"eyJjdHkiOiJKV1QiLCJlbmMiOi..."`
+}
+
+// ClientCredentialsRequest exchanges a service client's credentials for an
+// access token that acts as the client itself (OAuth2 client credentials).
+type ClientCredentialsRequest struct {
+	// App client ID of the calling service
+	ClientID string `json:"client_id" validate:"required,max=128" example:"6p3k2m9qv1example"`
+	// App client secret
+	ClientSecret string `json:"client_secret" validate:"required,max=512" example:"s3cr3t"`
+	// Space-separated scopes to request; all of the client's scopes when empty
+	Scope string `json:"scope,omitempty" validate:"omitempty,max=1024" example:"provision:write resources:read"`
+}
+
+// SignOutRequest revokes every token issued to the caller. AccessToken is
+// taken from the Authorization header, not the body.
+type SignOutRequest struct {
+	AccessToken string `json:"-"`
+}
+
+// ForgotPasswordRequest starts a password reset by emailing a code.
+type ForgotPasswordRequest struct {
+	// User's email address
+	Email string `json:"email" validate:"required,email,max=254" example:"user@example.com"`
+}
+
+// ConfirmForgotPasswordRequest completes a password reset.
+type ConfirmForgotPasswordRequest struct {
+	// User's email address
+	Email string `json:"email" validate:"required,email,max=254" example:"user@example.com"`
+	// Reset code sent to user's email
+	ConfirmationCode string `json:"confirmation_code" validate:"required,min=6,max=10,alphanum" example:"123456"`
+	// New password (same policy as sign up)
+	NewPassword string `json:"new_password" validate:"required,min=8,max=128" example:"N3wSecureP@ss"`
+}
+
+// ResendConfirmationRequest asks for a new sign-up confirmation code.
+type ResendConfirmationRequest struct {
+	// User's email address
+	Email string `json:"email" validate:"required,email,max=254" example:"user@example.com"`
+}
+
 type AuthResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token,omitempty"`
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// OutboxMessage is an accepted provisioning request waiting to be published.
+// It is written alongside the request's ResourceRecord, so a request the API
+// has answered 202 for is never lost even if the broker is down.
+type OutboxMessage struct {
+	// ID is stamped on the published message as its message ID; the
+	// provisioner dedupes on it, so republishing after a failure is safe.
+	ID       string
+	Resource Resource
+	// TraceContext is the W3C trace context of the accepting request, so the
+	// relay's publish continues the client's trace.
+	TraceContext map[string]string
+	// IdempotencyKey is the client's X-Idempotency-Key for the accepting
+	// request, or "" if it sent none.
+	IdempotencyKey string
+	// Attempts counts failed publish attempts; LastError is the latest one.
+	Attempts      int
+	LastError     string
+	CreatedAt     time.Time
+	NextAttemptAt time.Time
+}
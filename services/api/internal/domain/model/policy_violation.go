@@ -0,0 +1,14 @@
+package model
+
+// PolicyViolation is one way a provisioning request breaks a guardrail.
+type PolicyViolation struct {
+	// Rule names the guardrail that was broken.
+	Rule string `json:"rule"`
+	// Field is the request field at fault, e.g. "specification" or
+	// "tags.cost-center".
+	Field string `json:"field"`
+	// Message explains the violation to the caller.
+	Message string `json:"message"`
+	// Value is the offending value, if there was one.
+	Value string `json:"value,omitempty"`
+}
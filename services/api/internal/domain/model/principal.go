@@ -0,0 +1,78 @@
+package model
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Principal is the authenticated caller of a request, as established by a
+// verified access token.
+type Principal struct {
+	// Subject is the identity provider's stable, unique ID for the caller
+	// (the token's sub claim). It is what requests are attributed to.
+	Subject string
+	// Username is the caller's sign-in name, for display and logs only.
+	Username string
+	// ClientID is the app client the token was issued to.
+	ClientID string
+	// Scopes are the OAuth scopes granted to the token.
+	Scopes []string
+	// Groups are the identity provider groups the caller belongs to.
+	Groups []string
+	// ExpiresAt is when the token stops being accepted.
+	ExpiresAt time.Time
+	// APITokenID is set when the caller authenticated with a personal API
+	// token rather than an identity provider session.
+	APITokenID string
+	// Machine is set when the caller is a service acting as itself with a
+	// client-credentials token: Subject is then its app client ID, and it has
+	// no username or groups.
+	Machine bool
+}
+
+// API scopes. Service clients and personal API tokens may only call the
+// routes their scopes cover; users signed in with the identity provider act
+// with their own rights and are not restricted by them.
+const (
+	// ScopeProvisionWrite allows submitting provisioning requests.
+	ScopeProvisionWrite = "provision:write"
+	// ScopeProvisionValidate allows dry-running provisioning requests, e.g.
+	// for pull request checks that must not provision anything.
+	ScopeProvisionValidate = "provision:validate"
+	// ScopeResourcesRead allows listing and reading resources.
+	ScopeResourcesRead = "resources:read"
+)
+
+// APIScopes lists every API scope.
+var APIScopes = []string{ScopeProvisionWrite, ScopeProvisionValidate, ScopeResourcesRead}
+
+// ViaAPIToken reports whether the caller authenticated with an API token.
+func (p Principal) ViaAPIToken() bool {
+	return p.APITokenID != ""
+}
+
+// ScopeRestricted reports whether the caller may only use routes covered by
+// its scopes, i.e. it is a service client or an API token.
+func (p Principal) ScopeRestricted() bool {
+	return p.Machine || p.ViaAPIToken()
+}
+
+// HasScope reports whether the principal's token was granted scope.
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the authenticated principal.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal attached by WithPrincipal, if
+// the request was authenticated.
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
@@ -0,0 +1,79 @@
+package model
+
+import (
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/valueobjects"
+)
+
+// StatusTransition is one entry in a provisioning request's status history.
+type StatusTransition struct {
+	From   valueobjects.ProvisioningStatus `json:"from"`
+	To     valueobjects.ProvisioningStatus `json:"to"`
+	Reason string                          `json:"reason,omitempty"`
+	At     time.Time                       `json:"at"`
+}
+
+// ProvisioningRequest is the aggregate that owns a resource's provisioning
+// status. Every status change goes through TransitionTo, which enforces the
+// state machine in valueobjects.ProvisioningStatus.CanTransitionTo and records
+// the change in the history.
+type ProvisioningRequest struct {
+	id      string
+	status  valueobjects.ProvisioningStatus
+	history []StatusTransition
+}
+
+// NewProvisioningRequest starts a request in the pending status.
+func NewProvisioningRequest(id string) *ProvisioningRequest {
+	return &ProvisioningRequest{id: id, status: valueobjects.StatusPending}
+}
+
+// RehydrateProvisioningRequest rebuilds a request from stored state, e.g. a
+// read-model record. history may be nil when it was not persisted.
+func RehydrateProvisioningRequest(id, status string, history []StatusTransition) (*ProvisioningRequest, error) {
+	s, err := valueobjects.NewProvisioningStatus(status)
+	if err != nil {
+		return nil, errors.NewDomainError(errors.ErrCodeInvalidStatus, err.Error(), errors.ErrInvalidInput).
+			WithDetail("status", status)
+	}
+	return &ProvisioningRequest{
+		id:      id,
+		status:  s,
+		history: append([]StatusTransition(nil), history...),
+	}, nil
+}
+
+// ID returns the resource ID the request provisions.
+func (p *ProvisioningRequest) ID() string {
+	return p.id
+}
+
+// Status returns the current status.
+func (p *ProvisioningRequest) Status() valueobjects.ProvisioningStatus {
+	return p.status
+}
+
+// History returns the recorded transitions, oldest first.
+func (p *ProvisioningRequest) History() []StatusTransition {
+	return append([]StatusTransition(nil), p.history...)
+}
+
+// TransitionTo moves the request to next, recording the change at the given
+// time. An illegal move returns an InvalidStatusTransition DomainError and
+// leaves the request unchanged.
+func (p *ProvisioningRequest) TransitionTo(next valueobjects.ProvisioningStatus, reason string, at time.Time) (StatusTransition, error) {
+	if !next.IsValid() {
+		return StatusTransition{}, errors.NewDomainError(errors.ErrCodeInvalidStatus, "unknown provisioning status", errors.ErrInvalidInput).
+			WithDetail("status", next.String())
+	}
+	if !p.status.CanTransitionTo(next) {
+		return StatusTransition{}, errors.InvalidStatusTransition(p.id, p.status.String(), next.String())
+	}
+
+	t := StatusTransition{From: p.status, To: next, Reason: reason, At: at.UTC()}
+	p.status = next
+	p.history = append(p.history, t)
+	return t, nil
+}
@@ -0,0 +1,68 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	domainerrors "github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/valueobjects"
+)
+
+func TestProvisioningRequest_HappyPathRecordsHistory(t *testing.T) {
+	req := NewProvisioningRequest("vm-001")
+	start := time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	_, err := req.TransitionTo(valueobjects.StatusInProgress, "picked up", start)
+	require.NoError(t, err)
+	_, err = req.TransitionTo(valueobjects.StatusRetrying, "throttled", start.Add(time.Second))
+	require.NoError(t, err)
+	_, err = req.TransitionTo(valueobjects.StatusInProgress, "retry 1", start.Add(2*time.Second))
+	require.NoError(t, err)
+	last, err := req.TransitionTo(valueobjects.StatusCompleted, "", start.Add(3*time.Second))
+	require.NoError(t, err)
+
+	assert.Equal(t, valueobjects.StatusCompleted, req.Status())
+	assert.Equal(t, valueobjects.StatusInProgress, last.From)
+	history := req.History()
+	require.Len(t, history, 4)
+	assert.Equal(t, valueobjects.StatusPending, history[0].From)
+	assert.Equal(t, "throttled", history[1].Reason)
+	assert.Equal(t, start.Add(3*time.Second), history[3].At)
+}
+
+func TestProvisioningRequest_IllegalTransitionIsRejected(t *testing.T) {
+	req, err := RehydrateProvisioningRequest("vm-001", "completed", nil)
+	require.NoError(t, err)
+
+	_, err = req.TransitionTo(valueobjects.StatusPending, "", time.Now())
+
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidStateTransition)
+	var domainErr *domainerrors.DomainError
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, domainerrors.ErrCodeInvalidStatusTransition, domainErr.Code)
+	assert.Equal(t, valueobjects.StatusCompleted, req.Status(), "a rejected move must not change the status")
+	assert.Empty(t, req.History())
+}
+
+func TestProvisioningRequest_UnknownStatusIsInvalidInput(t *testing.T) {
+	_, err := RehydrateProvisioningRequest("vm-001", "exploded", nil)
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidInput)
+
+	req := NewProvisioningRequest("vm-001")
+	_, err = req.TransitionTo(valueobjects.ProvisioningStatus("exploded"), "", time.Now())
+	assert.ErrorIs(t, err, domainerrors.ErrInvalidInput)
+}
+
+func TestProvisioningRequest_HistoryIsACopy(t *testing.T) {
+	req := NewProvisioningRequest("vm-001")
+	_, err := req.TransitionTo(valueobjects.StatusInProgress, "", time.Now())
+	require.NoError(t, err)
+
+	history := req.History()
+	history[0].Reason = "tampered"
+
+	assert.Empty(t, req.History()[0].Reason)
+}
@@ -1,5 +1,7 @@
 package model
 
+import "time"
+
 // Resource represents a cloud resource provisioning request.
 type Resource struct {
 	// Unique identifier for the resource
@@ -10,8 +12,67 @@ type Resource struct {
 	CloudProvider string `json:"cloud_provider" example:"AWS" validate:"required,oneof=AWS Azure GCP" enums:"AWS,Azure,GCP"`
 	// Detailed specification of the resource configuration
 	Specification string `json:"specification" example:"t2.micro" validate:"required,min=1,max=1000"`
-	// Current status of the resource provisioning request
-	Status string `json:"status" example:"pending" validate:"required,oneof=pending in_progress completed failed" enums:"pending,in_progress,completed,failed"`
-	// Username or identifier of the person who requested the resource
-	RequestedBy string `json:"requested_by" example:"rafael" validate:"required,min=1,max=100"`
+	// Current status of the resource provisioning request. Set by the API and
+	// the provisioner; a value sent in the request body is ignored.
+	Status string `json:"status" example:"pending" enums:"pending,in_progress,completed,failed,retrying,cancelled"`
+	// Who requested the resource. On authenticated routes this is always the
+	// caller's token subject and any value in the body is ignored.
+	RequestedBy string `json:"requested_by" example:"rafael" validate:"omitempty,max=100"`
+	// Region to provision in, in the cloud provider's naming
+	Region string `json:"region,omitempty" example:"us-east-1" validate:"omitempty,max=50"`
+	// Free-form labels for ownership and cost allocation
+	Tags map[string]string `json:"tags,omitempty" validate:"omitempty,max=50,dive,keys,min=1,max=128,endkeys,max=256"`
+}
+
+// ResourceRecord is the read-side view of a provisioning request: the request as
+// submitted, its current provisioning status (carried in Resource.Status), and
+// the trace that submitted it so the provisioner's logs can be found from here.
+type ResourceRecord struct {
+	Resource
+	// W3C trace ID of the request that submitted the resource
+	TraceID string `json:"trace_id,omitempty" example:"4bf92f3577b34da6a3ce929d0e0e4736"`
+	// When the request was accepted
+	CreatedAt time.Time `json:"created_at" example:"2026-01-24T10:30:00Z"`
+	// When the status last changed
+	UpdatedAt time.Time `json:"updated_at" example:"2026-01-24T10:30:00Z"`
+}
+
+// ResourceFilter narrows a resource listing. Empty fields match everything.
+type ResourceFilter struct {
+	ResourceType  string
+	CloudProvider string
+	Status        string
+	RequestedBy   string
+}
+
+// ResourceKey is a record's position in the listing order (newest first, ties
+// broken by ID). Keyset pagination resumes from a key rather than an offset, so
+// pages stay stable while new requests arrive.
+type ResourceKey struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// ResourceQuery is one page request against the read model. At most one of
+// After (older records, i.e. the next page) and Before (newer records, the
+// previous page) is set; neither means the first page.
+type ResourceQuery struct {
+	Filter ResourceFilter
+	After  *ResourceKey
+	Before *ResourceKey
+	Limit  int
+}
+
+// ResourcePage is one page of a resource listing. The cursors are opaque and
+// empty when there is no page in that direction.
+type ResourcePage struct {
+	Records    []ResourceRecord
+	TotalItems int
+	NextCursor string
+	PrevCursor string
+}
+
+// Key returns the record's position in the listing order.
+func (r ResourceRecord) Key() ResourceKey {
+	return ResourceKey{CreatedAt: r.CreatedAt, ID: r.ID}
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/errors"
+)
+
+// EventTypeResourceStatusChanged names the event the provisioner emits after
+// every status transition it applies.
+const EventTypeResourceStatusChanged = "ResourceStatusChanged"
+
+// ResourceStatusChanged is the provisioner's report of one status transition.
+// The provisioner owns this contract; the fields mirror its JSON shape. Trace
+// context arrives in the transport's headers/attributes, not in the body.
+type ResourceStatusChanged struct {
+	EventType  string    `json:"event_type"`
+	ResourceID string    `json:"resource_id"`
+	OldStatus  string    `json:"old_status"`
+	NewStatus  string    `json:"new_status"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// DecodeResourceStatusChanged parses an event body. A body that is not a
+// well-formed ResourceStatusChanged returns an InvalidInput DomainError;
+// redelivering it cannot help.
+func DecodeResourceStatusChanged(body []byte) (ResourceStatusChanged, error) {
+	var e ResourceStatusChanged
+	if err := json.Unmarshal(body, &e); err != nil {
+		return ResourceStatusChanged{}, errors.InvalidInput("status event is not valid JSON").WithDetail("error", err.Error())
+	}
+	switch {
+	case e.EventType != EventTypeResourceStatusChanged:
+		return ResourceStatusChanged{}, errors.InvalidInput("unexpected event type").WithDetail("event_type", e.EventType)
+	case e.ResourceID == "":
+		return ResourceStatusChanged{}, errors.InvalidInput("status event is missing resource_id")
+	case e.NewStatus == "":
+		return ResourceStatusChanged{}, errors.InvalidInput("status event is missing new_status")
+	}
+	return e, nil
+}
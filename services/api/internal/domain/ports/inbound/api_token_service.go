@@ -0,0 +1,14 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// APITokenService manages the authenticated caller's personal API tokens.
+type APITokenService interface {
+	IssueAPIToken(ctx context.Context, req model.IssueAPITokenRequest) (*model.IssuedAPIToken, error)
+	ListAPITokens(ctx context.Context) ([]model.APIToken, error)
+	RevokeAPIToken(ctx context.Context, id string) error
+}
@@ -0,0 +1,15 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// AuditService records mutating API calls and lets platform admins read and
+// verify the audit log.
+type AuditService interface {
+	RecordAudit(ctx context.Context, entry model.AuditEntry) error
+	ListAuditEntries(ctx context.Context, q model.AuditQuery) (*model.AuditPage, error)
+	VerifyAuditLog(ctx context.Context) (*model.AuditVerification, error)
+}
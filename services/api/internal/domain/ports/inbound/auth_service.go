@@ -10,4 +10,10 @@ type AuthService interface {
 	SignUp(ctx context.Context, req model.SignUpRequest) error
 	SignIn(ctx context.Context, req model.SignInRequest) (*model.AuthResponse, error)
 	ConfirmSignUp(ctx context.Context, req model.ConfirmSignUpRequest) error
+	RefreshTokens(ctx context.Context, req model.RefreshTokenRequest) (*model.AuthResponse, error)
+	SignOut(ctx context.Context, req model.SignOutRequest) error
+	ForgotPassword(ctx context.Context, req model.ForgotPasswordRequest) error
+	ConfirmForgotPassword(ctx context.Context, req model.ConfirmForgotPasswordRequest) error
+	ResendConfirmation(ctx context.Context, req model.ResendConfirmationRequest) error
+	ClientCredentials(ctx context.Context, req model.ClientCredentialsRequest) (*model.AuthResponse, error)
 }
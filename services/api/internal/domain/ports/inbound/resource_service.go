@@ -7,4 +7,7 @@ import (
 
 type ResourceService interface {
 	SendProvisioningRequest(ctx context.Context, r model.Resource) error
+	ValidateProvisioningRequest(ctx context.Context, r model.Resource) ([]model.PolicyViolation, error)
+	GetResource(ctx context.Context, id string) (*model.ResourceRecord, error)
+	ListResources(ctx context.Context, filter model.ResourceFilter, cursor string, limit int) (*model.ResourcePage, error)
 }
@@ -0,0 +1,13 @@
+package inbound
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// ResourceStatusUpdater applies status changes reported by the provisioner to
+// the resource read model.
+type ResourceStatusUpdater interface {
+	ApplyStatusChange(ctx context.Context, e model.ResourceStatusChanged) error
+}
@@ -0,0 +1,30 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// ErrAPITokenNotFound is returned when no API token matches a hash or an
+// owner's token ID.
+var ErrAPITokenNotFound = errors.New("api token not found")
+
+// APITokenStore keeps issued API tokens, keyed by ID and looked up by the hash
+// of their secret.
+//
+// ListByOwner returns the owner's tokens, revoked and expired ones included,
+// newest first.
+// Revoke marks the owner's token revoked at the given time; revoking it again
+// keeps the first time. A token owned by someone else is ErrAPITokenNotFound.
+// TouchLastUsed records that the token authenticated a request at the given
+// time.
+type APITokenStore interface {
+	Create(ctx context.Context, token model.APIToken) error
+	GetByHash(ctx context.Context, hash string) (*model.APIToken, error)
+	ListByOwner(ctx context.Context, owner string) ([]model.APIToken, error)
+	Revoke(ctx context.Context, owner, id string, at time.Time) error
+	TouchLastUsed(ctx context.Context, id string, at time.Time) error
+}
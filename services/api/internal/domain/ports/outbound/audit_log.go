@@ -0,0 +1,22 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// AuditLog is the append-only store of audit entries. Entries are never
+// updated or deleted.
+//
+// Append chains the entry after the newest one (see model.AuditEntry.Chain)
+// and stores it, atomically with respect to other appends, returning it with
+// its Seq and hashes set.
+// Query returns entries matching q, newest first, at most q.Limit of them.
+// Range returns up to limit entries with Seq greater than after, in sequence
+// order, for walking the whole chain.
+type AuditLog interface {
+	Append(ctx context.Context, entry model.AuditEntry) (model.AuditEntry, error)
+	Query(ctx context.Context, q model.AuditQuery) ([]model.AuditEntry, error)
+	Range(ctx context.Context, after int64, limit int) ([]model.AuditEntry, error)
+}
@@ -6,8 +6,17 @@ import (
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 )
 
+// AuthProvider is the identity provider behind the /v1/auth routes.
+// ClientCredentials issues an access token to a service client acting as
+// itself; with no scopes requested the token carries all the client's scopes.
 type AuthProvider interface {
 	SignUp(ctx context.Context, email, password string) error
 	SignIn(ctx context.Context, email, password string) (*model.AuthResponse, error)
 	ConfirmSignUp(ctx context.Context, email, confirmationCode string) error
+	RefreshTokens(ctx context.Context, refreshToken string) (*model.AuthResponse, error)
+	GlobalSignOut(ctx context.Context, accessToken string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ConfirmForgotPassword(ctx context.Context, email, confirmationCode, newPassword string) error
+	ResendConfirmationCode(ctx context.Context, email string) error
+	ClientCredentials(ctx context.Context, clientID, clientSecret string, scopes []string) (*model.AuthResponse, error)
 }
@@ -0,0 +1,15 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// PolicyEngine evaluates guardrails on what is provisioned. Evaluate returns
+// every violation of the request by principal (the zero Principal for an
+// unauthenticated request); none means the request may proceed. An error
+// means the policy could not be evaluated, not that the request is invalid.
+type PolicyEngine interface {
+	Evaluate(ctx context.Context, principal model.Principal, r model.Resource) ([]model.PolicyViolation, error)
+}
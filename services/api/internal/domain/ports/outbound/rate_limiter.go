@@ -0,0 +1,42 @@
+package outbound
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit allows Requests calls per Window. Limits are token buckets: a
+// bucket holds up to Requests tokens, refilled evenly over Window, so callers
+// may burst up to Requests at once and then sustain Requests per Window.
+// A zero Requests means unlimited.
+type RateLimit struct {
+	Requests int
+	Window   time.Duration
+}
+
+// Unlimited reports whether the limit lets every call through.
+func (l RateLimit) Unlimited() bool {
+	return l.Requests <= 0 || l.Window <= 0
+}
+
+// RateLimitDecision is the outcome of taking a token from a bucket.
+type RateLimitDecision struct {
+	Allowed bool
+	// Limit and Remaining are the bucket's capacity and the whole tokens left
+	// after this call.
+	Limit     int
+	Remaining int
+	// ResetAt is when the bucket will be full again.
+	ResetAt time.Time
+	// RetryAfter is how long to wait for the next token when the call was
+	// refused; zero when it was allowed.
+	RetryAfter time.Duration
+}
+
+// RateLimiter is the contract every backend (Redis, in-memory) implements.
+//
+// Allow takes a token from the bucket named key, sized by limit, and must be
+// atomic across concurrent callers sharing the backend.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit RateLimit) (RateLimitDecision, error)
+}
@@ -0,0 +1,27 @@
+package outbound
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// ResourceOutbox durably holds accepted provisioning requests until the relay
+// has published them.
+//
+// Enqueue writes the request's read-model record and its outbox message in
+// one atomic step. It returns ErrResourceExists, writing neither, if a record
+// with the ID exists.
+// Claim returns up to limit messages due for publishing (NextAttemptAt has
+// passed), oldest first, and pushes their NextAttemptAt out by lease so that
+// other relays skip them while they are being published.
+// MarkPublished removes a published message.
+// MarkFailed counts a failed attempt, records its error and schedules the
+// next one.
+type ResourceOutbox interface {
+	Enqueue(ctx context.Context, record model.ResourceRecord, msg model.OutboxMessage) error
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id string) error
+	MarkFailed(ctx context.Context, id string, lastErr string, nextAttemptAt time.Time) error
+}
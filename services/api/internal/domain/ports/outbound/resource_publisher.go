@@ -5,6 +5,65 @@ import (
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 )
 
+// MessageIDAttribute is the message header / attribute under which publishers
+// stamp a unique ID on every message. The provisioner dedupes redeliveries on
+// it, so a message must keep its ID however often the transport redelivers it.
+const MessageIDAttribute = "message-id"
+
 type ResourcePublisher interface {
 	Publish(ctx context.Context, resource model.Resource) error
 }
+
+// AsyncResourcePublisher is a ResourcePublisher that can hand a message off
+// without waiting for the transport to accept it. done is called exactly
+// once, possibly from another goroutine, with the outcome of the delivery.
+type AsyncResourcePublisher interface {
+	ResourcePublisher
+	PublishAsync(ctx context.Context, resource model.Resource, done func(error))
+}
+
+// BatchResourcePublisher is a ResourcePublisher that can send many messages
+// in one round trip. Each entry is published under its own context, which
+// carries its trace, message ID and idempotency key.
+type BatchResourcePublisher interface {
+	ResourcePublisher
+	// PublishBatch returns one error per entry, nil for each entry that was
+	// published, in the order of entries.
+	PublishBatch(ctx context.Context, entries []BatchEntry) []error
+}
+
+// BatchEntry is one message of a PublishBatch call.
+type BatchEntry struct {
+	Context  context.Context
+	Resource model.Resource
+}
+
+type (
+	messageIDKey      struct{}
+	idempotencyKeyKey struct{}
+)
+
+// WithMessageID asks the publisher to stamp id rather than a fresh ID, so a
+// message republished from the outbox keeps its identity.
+func WithMessageID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, messageIDKey{}, id)
+}
+
+// MessageIDFromContext returns the ID set by WithMessageID, or "".
+func MessageIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(messageIDKey{}).(string)
+	return id
+}
+
+// WithIdempotencyKey records the client-supplied idempotency key a request
+// was accepted under, so publishers that deduplicate sends can derive their
+// deduplication ID from it.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key set by WithIdempotencyKey, or "".
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyKey{}).(string)
+	return key
+}
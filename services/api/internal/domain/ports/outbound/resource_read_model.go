@@ -0,0 +1,40 @@
+package outbound
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// ErrResourceNotFound is returned when the read model holds no record for an ID.
+var ErrResourceNotFound = errors.New("resource record not found")
+
+// ErrResourceExists is returned by Create when a record with the ID exists.
+var ErrResourceExists = errors.New("resource record already exists")
+
+// ErrStatusChanged is returned by UpdateStatus when the record's status is no
+// longer the one the caller read: another status change landed in between.
+var ErrStatusChanged = errors.New("resource status changed concurrently")
+
+// ResourceReadModel is the query-side store of provisioning requests. The API
+// records each accepted request here so clients can look it up after the 202;
+// status changes are applied to the same record.
+//
+// Create inserts the record keyed by Resource.ID, or returns
+// ErrResourceExists, leaving the existing record untouched, if there is one.
+// UpdateStatus moves the record for id from status from to status to, as a
+// compare-and-set: it returns ErrStatusChanged if the current status is not
+// from, or ErrResourceNotFound if there is no such record.
+// List returns up to query.Limit records matching the filter, newest first,
+// starting after/before the given key; hasMore reports whether further records
+// exist beyond the page in the direction of travel.
+// Count returns how many records match the filter.
+type ResourceReadModel interface {
+	Create(ctx context.Context, record model.ResourceRecord) error
+	Get(ctx context.Context, id string) (*model.ResourceRecord, error)
+	UpdateStatus(ctx context.Context, id, from, to string, at time.Time) error
+	List(ctx context.Context, query model.ResourceQuery) (records []model.ResourceRecord, hasMore bool, err error)
+	Count(ctx context.Context, filter model.ResourceFilter) (int, error)
+}
@@ -0,0 +1,17 @@
+package outbound
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+)
+
+// TokenVerifier authenticates bearer tokens.
+type TokenVerifier interface {
+	// Verify checks the token's signature and claims and returns the caller
+	// it was issued to. A token that is malformed, forged, expired or meant
+	// for someone else yields an error wrapping errors.ErrUnauthorized; any
+	// other error means the token could not be checked (e.g. the key set is
+	// unreachable).
+	Verify(ctx context.Context, token string) (*model.Principal, error)
+}
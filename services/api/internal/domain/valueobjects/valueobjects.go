@@ -138,13 +138,23 @@ const (
 	StatusInProgress ProvisioningStatus = "in_progress"
 	StatusCompleted  ProvisioningStatus = "completed"
 	StatusFailed     ProvisioningStatus = "failed"
+	StatusRetrying   ProvisioningStatus = "retrying"
+	StatusCancelled  ProvisioningStatus = "cancelled"
 )
 
+// statusTransitions lists, for each status, the statuses it may move to.
+// Final statuses have no entry.
+var statusTransitions = map[ProvisioningStatus][]ProvisioningStatus{
+	StatusPending:    {StatusInProgress, StatusCancelled},
+	StatusInProgress: {StatusCompleted, StatusFailed, StatusRetrying, StatusCancelled},
+	StatusRetrying:   {StatusInProgress, StatusFailed, StatusCancelled},
+}
+
 // NewProvisioningStatus creates a new ProvisioningStatus from a string.
 func NewProvisioningStatus(value string) (ProvisioningStatus, error) {
 	normalized := strings.ToLower(strings.TrimSpace(value))
 	switch ProvisioningStatus(normalized) {
-	case StatusPending, StatusInProgress, StatusCompleted, StatusFailed:
+	case StatusPending, StatusInProgress, StatusCompleted, StatusFailed, StatusRetrying, StatusCancelled:
 		return ProvisioningStatus(normalized), nil
 	default:
 		return "", fmt.Errorf("invalid provisioning status: %s", value)
@@ -159,7 +169,7 @@ func (s ProvisioningStatus) String() string {
 // IsValid checks if the status is valid.
 func (s ProvisioningStatus) IsValid() bool {
 	switch s {
-	case StatusPending, StatusInProgress, StatusCompleted, StatusFailed:
+	case StatusPending, StatusInProgress, StatusCompleted, StatusFailed, StatusRetrying, StatusCancelled:
 		return true
 	default:
 		return false
@@ -168,7 +178,17 @@ func (s ProvisioningStatus) IsValid() bool {
 
 // IsFinal checks if the status is a final state.
 func (s ProvisioningStatus) IsFinal() bool {
-	return s == StatusCompleted || s == StatusFailed
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// CanTransitionTo reports whether a request in this status may move to next.
+func (s ProvisioningStatus) CanTransitionTo(next ProvisioningStatus) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
 }
 
 // ResourceID represents a validated resource identifier.
@@ -143,6 +143,8 @@ func TestNewProvisioningStatus_Valid(t *testing.T) {
 		{"in_progress", StatusInProgress},
 		{"completed", StatusCompleted},
 		{"failed", StatusFailed},
+		{"retrying", StatusRetrying},
+		{"cancelled", StatusCancelled},
 	}
 
 	for _, tt := range tests {
@@ -171,6 +173,54 @@ func TestProvisioningStatus_IsFinal(t *testing.T) {
 	if StatusInProgress.IsFinal() {
 		t.Error("StatusInProgress.IsFinal() should return false")
 	}
+	if !StatusCancelled.IsFinal() {
+		t.Error("StatusCancelled.IsFinal() should return true")
+	}
+	if StatusRetrying.IsFinal() {
+		t.Error("StatusRetrying.IsFinal() should return false")
+	}
+}
+
+func TestProvisioningStatus_CanTransitionTo(t *testing.T) {
+	tests := []struct {
+		from, to ProvisioningStatus
+		allowed  bool
+	}{
+		{StatusPending, StatusInProgress, true},
+		{StatusPending, StatusCancelled, true},
+		{StatusPending, StatusCompleted, false},
+		{StatusInProgress, StatusCompleted, true},
+		{StatusInProgress, StatusFailed, true},
+		{StatusInProgress, StatusRetrying, true},
+		{StatusRetrying, StatusInProgress, true},
+		{StatusInProgress, StatusPending, false},
+		{StatusCompleted, StatusPending, false},
+		{StatusFailed, StatusInProgress, false},
+		{StatusCancelled, StatusInProgress, false},
+		{StatusPending, StatusPending, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"->"+string(tt.to), func(t *testing.T) {
+			if got := tt.from.CanTransitionTo(tt.to); got != tt.allowed {
+				t.Errorf("%s.CanTransitionTo(%s) = %v, want %v", tt.from, tt.to, got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestProvisioningStatus_FinalStatusesHaveNoTransitions(t *testing.T) {
+	all := []ProvisioningStatus{StatusPending, StatusInProgress, StatusCompleted, StatusFailed, StatusRetrying, StatusCancelled}
+	for _, from := range all {
+		if !from.IsFinal() {
+			continue
+		}
+		for _, to := range all {
+			if from.CanTransitionTo(to) {
+				t.Errorf("final status %s must not transition to %s", from, to)
+			}
+		}
+	}
 }
 
 func TestNewResourceID_Valid(t *testing.T) {
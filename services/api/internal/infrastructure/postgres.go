@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	// Registers the "pgx" database/sql driver.
+	_ "github.com/jackc/pgx/v5/stdlib"
+)
+
+// PostgresConfig collects the runtime parameters needed to open Postgres.
+type PostgresConfig struct {
+	URL          string
+	MaxOpenConns int
+	PingTimeout  time.Duration
+}
+
+// NewPostgresDB opens a pgx-backed database handle and verifies connectivity
+// with a ping. The caller owns Close().
+func NewPostgresDB(ctx context.Context, cfg PostgresConfig) (*sql.DB, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("postgres URL is required")
+	}
+
+	db, err := sql.Open("pgx", cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, cfg.PingTimeout)
+	defer cancel()
+	if err := db.PingContext(pingCtx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("postgres ping failed: %w", err)
+	}
+	return db, nil
+}
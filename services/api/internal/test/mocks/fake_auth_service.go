@@ -0,0 +1,72 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+)
+
+// FakeAuthService records the last request per operation and returns
+// ResponseToReturn / ErrToReturn from every call.
+type FakeAuthService struct {
+	ResponseToReturn *model.AuthResponse
+	ErrToReturn      error
+
+	LastSignUp                model.SignUpRequest
+	LastSignIn                model.SignInRequest
+	LastConfirmSignUp         model.ConfirmSignUpRequest
+	LastRefresh               model.RefreshTokenRequest
+	LastSignOut               model.SignOutRequest
+	LastForgotPassword        model.ForgotPasswordRequest
+	LastConfirmForgotPassword model.ConfirmForgotPasswordRequest
+	LastResendConfirmation    model.ResendConfirmationRequest
+	LastClientCredentials     model.ClientCredentialsRequest
+}
+
+var _ inbound.AuthService = &FakeAuthService{}
+
+func (f *FakeAuthService) SignUp(ctx context.Context, req model.SignUpRequest) error {
+	f.LastSignUp = req
+	return f.ErrToReturn
+}
+
+func (f *FakeAuthService) SignIn(ctx context.Context, req model.SignInRequest) (*model.AuthResponse, error) {
+	f.LastSignIn = req
+	return f.ResponseToReturn, f.ErrToReturn
+}
+
+func (f *FakeAuthService) ConfirmSignUp(ctx context.Context, req model.ConfirmSignUpRequest) error {
+	f.LastConfirmSignUp = req
+	return f.ErrToReturn
+}
+
+func (f *FakeAuthService) RefreshTokens(ctx context.Context, req model.RefreshTokenRequest) (*model.AuthResponse, error) {
+	f.LastRefresh = req
+	return f.ResponseToReturn, f.ErrToReturn
+}
+
+func (f *FakeAuthService) SignOut(ctx context.Context, req model.SignOutRequest) error {
+	f.LastSignOut = req
+	return f.ErrToReturn
+}
+
+func (f *FakeAuthService) ForgotPassword(ctx context.Context, req model.ForgotPasswordRequest) error {
+	f.LastForgotPassword = req
+	return f.ErrToReturn
+}
+
+func (f *FakeAuthService) ConfirmForgotPassword(ctx context.Context, req model.ConfirmForgotPasswordRequest) error {
+	f.LastConfirmForgotPassword = req
+	return f.ErrToReturn
+}
+
+func (f *FakeAuthService) ResendConfirmation(ctx context.Context, req model.ResendConfirmationRequest) error {
+	f.LastResendConfirmation = req
+	return f.ErrToReturn
+}
+
+func (f *FakeAuthService) ClientCredentials(ctx context.Context, req model.ClientCredentialsRequest) (*model.AuthResponse, error) {
+	f.LastClientCredentials = req
+	return f.ResponseToReturn, f.ErrToReturn
+}
@@ -0,0 +1,58 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+type FakeResourceOutbox struct {
+	EnqueuedRecords  []model.ResourceRecord
+	EnqueuedMessages []model.OutboxMessage
+	EnqueueErr       error
+
+	ToClaim   []model.OutboxMessage
+	ClaimErr  error
+	Published []string
+	Failed    map[string]time.Time
+	LastError string
+}
+
+var _ outbound.ResourceOutbox = &FakeResourceOutbox{}
+
+func (f *FakeResourceOutbox) Enqueue(ctx context.Context, record model.ResourceRecord, msg model.OutboxMessage) error {
+	if f.EnqueueErr != nil {
+		return f.EnqueueErr
+	}
+	f.EnqueuedRecords = append(f.EnqueuedRecords, record)
+	f.EnqueuedMessages = append(f.EnqueuedMessages, msg)
+	return nil
+}
+
+func (f *FakeResourceOutbox) Claim(ctx context.Context, limit int, lease time.Duration) ([]model.OutboxMessage, error) {
+	if f.ClaimErr != nil {
+		return nil, f.ClaimErr
+	}
+	claimed := f.ToClaim
+	if len(claimed) > limit {
+		claimed = claimed[:limit]
+	}
+	f.ToClaim = f.ToClaim[len(claimed):]
+	return claimed, nil
+}
+
+func (f *FakeResourceOutbox) MarkPublished(ctx context.Context, id string) error {
+	f.Published = append(f.Published, id)
+	return nil
+}
+
+func (f *FakeResourceOutbox) MarkFailed(ctx context.Context, id string, lastErr string, nextAttemptAt time.Time) error {
+	if f.Failed == nil {
+		f.Failed = make(map[string]time.Time)
+	}
+	f.Failed[id] = nextAttemptAt
+	f.LastError = lastErr
+	return nil
+}
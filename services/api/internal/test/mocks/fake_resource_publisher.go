@@ -2,20 +2,73 @@ package mocks
 
 import (
 	"context"
+	"sync"
+	"time"
+
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
 	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
 )
 
 type FakeResourcePublisher struct {
-	LastSent    model.Resource
-	TimesCalled int
-	ErrToReturn error
+	LastSent      model.Resource
+	LastMessageID string
+	TimesCalled   int
+	ErrToReturn   error
 }
 
 var _ outbound.ResourcePublisher = &FakeResourcePublisher{}
 
 func (f *FakeResourcePublisher) Publish(ctx context.Context, resource model.Resource) error {
 	f.LastSent = resource
+	f.LastMessageID = outbound.MessageIDFromContext(ctx)
 	f.TimesCalled++
 	return f.ErrToReturn
 }
+
+// FakeAsyncResourcePublisher confirms each delivery from another goroutine,
+// as a batching publisher does.
+type FakeAsyncResourcePublisher struct {
+	mu          sync.Mutex
+	Sent        []string
+	ErrToReturn error
+}
+
+var _ outbound.AsyncResourcePublisher = &FakeAsyncResourcePublisher{}
+
+func (f *FakeAsyncResourcePublisher) Publish(ctx context.Context, resource model.Resource) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.Sent = append(f.Sent, outbound.MessageIDFromContext(ctx))
+	return f.ErrToReturn
+}
+
+func (f *FakeAsyncResourcePublisher) PublishAsync(ctx context.Context, resource model.Resource, done func(error)) {
+	err := f.Publish(ctx, resource)
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		done(err)
+	}()
+}
+
+// FakeBatchResourcePublisher records each PublishBatch call and fails the
+// entries for the resource IDs in Fail.
+type FakeBatchResourcePublisher struct {
+	FakeResourcePublisher
+	Batches         [][]string
+	IdempotencyKeys []string
+	Fail            map[string]error
+}
+
+var _ outbound.BatchResourcePublisher = &FakeBatchResourcePublisher{}
+
+func (f *FakeBatchResourcePublisher) PublishBatch(_ context.Context, entries []outbound.BatchEntry) []error {
+	ids := make([]string, len(entries))
+	errs := make([]error, len(entries))
+	for i, e := range entries {
+		ids[i] = outbound.MessageIDFromContext(e.Context)
+		f.IdempotencyKeys = append(f.IdempotencyKeys, outbound.IdempotencyKeyFromContext(e.Context))
+		errs[i] = f.Fail[e.Resource.ID]
+	}
+	f.Batches = append(f.Batches, ids)
+	return errs
+}
@@ -0,0 +1,86 @@
+package mocks
+
+import (
+	"context"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+type FakeResourceReadModel struct {
+	Records      map[string]model.ResourceRecord
+	CreateErr    error
+	GetErr       error
+	TimesCreated int
+
+	// UpdateStatus behaviour
+	UpdateErr    error
+	TimesUpdated int
+
+	// List/Count behaviour
+	ListRecords []model.ResourceRecord
+	ListHasMore bool
+	ListErr     error
+	CountResult int
+	CountErr    error
+	LastQuery   model.ResourceQuery
+}
+
+var _ outbound.ResourceReadModel = &FakeResourceReadModel{}
+
+func (f *FakeResourceReadModel) Create(ctx context.Context, record model.ResourceRecord) error {
+	f.TimesCreated++
+	if f.CreateErr != nil {
+		return f.CreateErr
+	}
+	if f.Records == nil {
+		f.Records = make(map[string]model.ResourceRecord)
+	}
+	if _, ok := f.Records[record.ID]; ok {
+		return outbound.ErrResourceExists
+	}
+	f.Records[record.ID] = record
+	return nil
+}
+
+func (f *FakeResourceReadModel) Get(ctx context.Context, id string) (*model.ResourceRecord, error) {
+	if f.GetErr != nil {
+		return nil, f.GetErr
+	}
+	record, ok := f.Records[id]
+	if !ok {
+		return nil, outbound.ErrResourceNotFound
+	}
+	return &record, nil
+}
+
+func (f *FakeResourceReadModel) UpdateStatus(ctx context.Context, id, from, to string, at time.Time) error {
+	f.TimesUpdated++
+	if f.UpdateErr != nil {
+		return f.UpdateErr
+	}
+	record, ok := f.Records[id]
+	if !ok {
+		return outbound.ErrResourceNotFound
+	}
+	if record.Status != from {
+		return outbound.ErrStatusChanged
+	}
+	record.Status = to
+	record.UpdatedAt = at
+	f.Records[id] = record
+	return nil
+}
+
+func (f *FakeResourceReadModel) List(ctx context.Context, query model.ResourceQuery) ([]model.ResourceRecord, bool, error) {
+	f.LastQuery = query
+	if f.ListErr != nil {
+		return nil, false, f.ListErr
+	}
+	return f.ListRecords, f.ListHasMore, nil
+}
+
+func (f *FakeResourceReadModel) Count(ctx context.Context, filter model.ResourceFilter) (int, error) {
+	return f.CountResult, f.CountErr
+}
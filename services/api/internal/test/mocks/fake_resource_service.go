@@ -10,6 +10,23 @@ type FakeResourceService struct {
 	LastReceived model.Resource
 	TimesCalled  int
 	ErrToReturn  error
+
+	// ValidateProvisioningRequest behaviour
+	ViolationsToReturn  []model.PolicyViolation
+	ValidateErrToReturn error
+	LastValidated       model.Resource
+
+	// GetResource behaviour
+	RecordToReturn  *model.ResourceRecord
+	GetErrToReturn  error
+	LastRequestedID string
+
+	// ListResources behaviour
+	PageToReturn    *model.ResourcePage
+	ListErrToReturn error
+	LastFilter      model.ResourceFilter
+	LastCursor      string
+	LastLimit       int
 }
 
 var _ inbound.ResourceService = &FakeResourceService{}
@@ -19,3 +36,26 @@ func (f *FakeResourceService) SendProvisioningRequest(ctx context.Context, r mod
 	f.TimesCalled++
 	return f.ErrToReturn
 }
+
+func (f *FakeResourceService) ValidateProvisioningRequest(ctx context.Context, r model.Resource) ([]model.PolicyViolation, error) {
+	f.LastValidated = r
+	return f.ViolationsToReturn, f.ValidateErrToReturn
+}
+
+func (f *FakeResourceService) GetResource(ctx context.Context, id string) (*model.ResourceRecord, error) {
+	f.LastRequestedID = id
+	return f.RecordToReturn, f.GetErrToReturn
+}
+
+func (f *FakeResourceService) ListResources(ctx context.Context, filter model.ResourceFilter, cursor string, limit int) (*model.ResourcePage, error) {
+	f.LastFilter = filter
+	f.LastCursor = cursor
+	f.LastLimit = limit
+	if f.ListErrToReturn != nil {
+		return nil, f.ListErrToReturn
+	}
+	if f.PageToReturn == nil {
+		return &model.ResourcePage{}, nil
+	}
+	return f.PageToReturn, nil
+}
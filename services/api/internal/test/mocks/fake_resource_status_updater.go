@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/inbound"
+)
+
+type FakeResourceStatusUpdater struct {
+	Applied     []model.ResourceStatusChanged
+	ErrToReturn error
+	// FailTimes limits ErrToReturn to the first FailTimes calls; zero means
+	// every call fails with it.
+	FailTimes int
+}
+
+var _ inbound.ResourceStatusUpdater = &FakeResourceStatusUpdater{}
+
+func (f *FakeResourceStatusUpdater) ApplyStatusChange(ctx context.Context, e model.ResourceStatusChanged) error {
+	f.Applied = append(f.Applied, e)
+	if f.FailTimes > 0 && len(f.Applied) > f.FailTimes {
+		return nil
+	}
+	return f.ErrToReturn
+}
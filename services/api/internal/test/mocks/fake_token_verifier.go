@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/model"
+	"github.com/rafaelcmd/internal-developer-platform/api/internal/domain/ports/outbound"
+)
+
+// FakeTokenVerifier accepts every token as Principal unless Err is set.
+type FakeTokenVerifier struct {
+	Principal model.Principal
+	Err       error
+	LastToken string
+}
+
+var _ outbound.TokenVerifier = &FakeTokenVerifier{}
+
+func (f *FakeTokenVerifier) Verify(ctx context.Context, token string) (*model.Principal, error) {
+	f.LastToken = token
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	p := f.Principal
+	return &p, nil
+}
@@ -6,27 +6,37 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"strconv"
 	"strings"
 	"syscall"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/aws/aws-sdk-go-v2/otelaws"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/consumer"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/driver"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/events"
 	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
 	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/telemetry"
 )
 
 const serviceName = "resource-provisioner-consumer"
 
-var errEmptyQueueURL = errors.New("SQS queue URL is empty")
+var (
+	errEmptyQueueURL = errors.New("SQS queue URL is empty")
+	errEmptyTopicARN = errors.New("SNS topic ARN is empty")
+)
 
 func main() {
 	// Root context cancels on SIGINT/SIGTERM so the consumer drains and the
@@ -66,30 +76,67 @@ func main() {
 	tracer := otel.Tracer(serviceName)
 	metrics := consumer.NewMetrics(otel.Meter(serviceName))
 
-	// Kafka is the local-dev transport: when brokers are configured we consume
-	// from Kafka and never touch AWS. Otherwise fall back to SQS.
+	repo, closeRepo, err := newRepository(ctx, log)
+	if err != nil {
+		log.WithContext(ctx).Error("unable to connect to the database", logger.F("error", err.Error()))
+		os.Exit(1)
+	}
+	defer closeRepo()
+
+	// The transport is chosen by the same rule as the API's
+	// MessagingConfig.Transport: NATS JetStream when NATS_URL is set,
+	// otherwise Kafka when KAFKA_BROKERS is, otherwise SQS in and SNS out.
+	// Requests and status events always travel over the chosen transport.
+	if url := os.Getenv("NATS_URL"); url != "" {
+		if err := runNATS(ctx, url, repo, tracer, metrics, log); err != nil && ctx.Err() == nil {
+			log.WithContext(ctx).Error("nats consumer error", logger.F("error", err.Error()))
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Kafka: consume from Kafka, publish status changes back to Kafka, and
+	// never touch AWS.
 	if brokers := splitBrokers(os.Getenv("KAFKA_BROKERS")); len(brokers) > 0 {
+		publisher := events.NewKafkaPublisher(brokers, envOrDefault("KAFKA_STATUS_TOPIC", "resource-status-changed"))
+		defer func() {
+			if err := publisher.Close(); err != nil {
+				log.WithContext(ctx).Warn("failed to close status publisher", logger.F("error", err.Error()))
+			}
+		}()
+		dispatcher, err := newDispatcher(envOrDefault("PROVISIONER_DRIVERS", driversFake), log)
+		if err != nil {
+			log.WithContext(ctx).Error("failed to configure drivers", logger.F("error", err.Error()))
+			os.Exit(1)
+		}
+		handler := consumer.NewProcessor(repo, dispatcher, publisher, log)
+
+		topic := envOrDefault("KAFKA_TOPIC", "resource-provisioning")
 		cfg := consumer.KafkaConfig{
-			Brokers: brokers,
-			Topic:   envOrDefault("KAFKA_TOPIC", "resource-provisioning"),
-			GroupID: envOrDefault("KAFKA_GROUP_ID", "resource-provisioner"),
+			Brokers:  brokers,
+			Topic:    topic,
+			GroupID:  envOrDefault("KAFKA_GROUP_ID", "resource-provisioner"),
+			DLQTopic: envOrDefault("KAFKA_DLQ_TOPIC", topic+".dlq"),
+			Retry:    retryPolicyFromEnv(),
+			Pool:     poolConfigFromEnv(),
 		}
-		if err := consumer.RunKafka(ctx, cfg, tracer, metrics, log); err != nil && ctx.Err() == nil {
+		if err := consumer.RunKafka(ctx, cfg, handler, tracer, metrics, log); err != nil && ctx.Err() == nil {
 			log.WithContext(ctx).Error("kafka consumer error", logger.F("error", err.Error()))
 			os.Exit(1)
 		}
 		return
 	}
 
-	if err := runSQS(ctx, tracer, metrics, log); err != nil && ctx.Err() == nil {
+	if err := runSQS(ctx, repo, tracer, metrics, log); err != nil && ctx.Err() == nil {
 		log.WithContext(ctx).Error("sqs consumer error", logger.F("error", err.Error()))
 		os.Exit(1)
 	}
 }
 
-// runSQS loads AWS config, resolves the queue URL from Parameter Store, and
-// consumes from SQS. Isolated from the Kafka path so local dev needs no AWS.
-func runSQS(ctx context.Context, tracer trace.Tracer, metrics consumer.Metrics, log logger.Logger) error {
+// runSQS loads AWS config, resolves the queue URL and status topic from
+// Parameter Store, and consumes from SQS, publishing status changes to SNS.
+// Isolated from the Kafka path so local dev needs no AWS.
+func runSQS(ctx context.Context, repo repository.Repository, tracer trace.Tracer, metrics consumer.Metrics, log logger.Logger) error {
 	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion("us-east-1"))
 	if err != nil {
 		return fmt.Errorf("load AWS config: %w", err)
@@ -104,7 +151,97 @@ func runSQS(ctx context.Context, tracer trace.Tracer, metrics consumer.Metrics,
 	if err != nil {
 		return err
 	}
-	return consumer.RunSQS(ctx, sqsClient, queueURL, tracer, metrics, log)
+	topicARN, err := getStatusTopicARN(ctx, tracer, ssmClient)
+	if err != nil {
+		return err
+	}
+	// The redrive policy on the queue still dead-letters without it, so a
+	// missing DLQ parameter is not fatal.
+	dlqURL, err := getDLQURL(ctx, tracer, ssmClient)
+	if err != nil {
+		log.WithContext(ctx).Warn("DLQ URL unavailable; relying on the queue's redrive policy", logger.F("error", err.Error()))
+	}
+
+	dispatcher, err := newDispatcher(envOrDefault("PROVISIONER_DRIVERS", driversNone), log)
+	if err != nil {
+		return err
+	}
+	publisher := events.NewSNSPublisher(sns.NewFromConfig(cfg), topicARN)
+	handler := consumer.NewProcessor(repo, dispatcher, publisher, log)
+	return consumer.RunSQS(ctx, sqsClient, consumer.SQSConfig{
+		QueueURL:    queueURL,
+		DLQURL:      dlqURL,
+		MaxReceives: intEnvOrDefault("SQS_MAX_RECEIVES", 5),
+		Retry:       retryPolicyFromEnv(),
+		Pool:        poolConfigFromEnv(),
+	}, handler, tracer, metrics, log)
+}
+
+// runNATS consumes from NATS JetStream at url and publishes status changes
+// back to it, creating the status stream if it does not exist yet. Like the
+// Kafka path it never touches AWS.
+func runNATS(ctx context.Context, url string, repo repository.Repository, tracer trace.Tracer, metrics consumer.Metrics, log logger.Logger) error {
+	conn, err := nats.Connect(url, nats.Name(serviceName))
+	if err != nil {
+		return fmt.Errorf("connect to nats: %w", err)
+	}
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return fmt.Errorf("create jetstream context: %w", err)
+	}
+
+	statusSubject := envOrDefault("NATS_STATUS_SUBJECT", "resource.status-changed")
+	if err := consumer.EnsureNATSStream(ctx, js, envOrDefault("NATS_STATUS_STREAM", "RESOURCE_STATUS"), statusSubject); err != nil {
+		return err
+	}
+	publisher := events.NewNATSPublisher(js, statusSubject)
+
+	dispatcher, err := newDispatcher(envOrDefault("PROVISIONER_DRIVERS", driversFake), log)
+	if err != nil {
+		return err
+	}
+	handler := consumer.NewProcessor(repo, dispatcher, publisher, log)
+
+	ackWait := time.Minute
+	if d, err := time.ParseDuration(os.Getenv("NATS_ACK_WAIT")); err == nil && d > 0 {
+		ackWait = d
+	}
+	subject := envOrDefault("NATS_SUBJECT", "resource.provisioning")
+	return consumer.RunNATS(ctx, js, consumer.NATSConfig{
+		Stream:        envOrDefault("NATS_STREAM", "RESOURCE_PROVISIONING"),
+		Subject:       subject,
+		Durable:       envOrDefault("NATS_DURABLE", "resource-provisioner"),
+		DLQSubject:    envOrDefault("NATS_DLQ_SUBJECT", subject+".dlq"),
+		MaxDeliveries: intEnvOrDefault("NATS_MAX_DELIVERIES", 5),
+		AckWait:       ackWait,
+		Retry:         retryPolicyFromEnv(),
+		Pool:          poolConfigFromEnv(),
+	}, handler, tracer, metrics, log)
+}
+
+// newRepository connects to Postgres when DATABASE_URL is set. Without it the
+// provisioner falls back to an in-memory repository so local runs without a
+// database still work — consumed requests are then lost on restart. The
+// returned func closes the connection.
+func newRepository(ctx context.Context, log logger.Logger) (repository.Repository, func(), error) {
+	dsn := os.Getenv("DATABASE_URL")
+	if dsn == "" {
+		log.WithContext(ctx).Warn("DATABASE_URL not set; persisting to an in-memory repository")
+		return repository.NewMemory(), func() {}, nil
+	}
+
+	db, err := repository.OpenPostgres(ctx, dsn)
+	if err != nil {
+		return nil, nil, err
+	}
+	closeDB := func() {
+		if err := db.Close(); err != nil {
+			log.WithContext(ctx).Warn("failed to close database", logger.F("error", err.Error()))
+		}
+	}
+	log.WithContext(ctx).Info("persisting to postgres")
+	return repository.NewPostgres(db), closeDB, nil
 }
 
 // getQueueURL reads the provisioning queue URL from Parameter Store within its
@@ -129,6 +266,89 @@ func getQueueURL(ctx context.Context, tracer trace.Tracer, ssmClient *ssm.Client
 	return queueURL, nil
 }
 
+// getStatusTopicARN reads the status-change SNS topic ARN from Parameter
+// Store within its own span.
+func getStatusTopicARN(ctx context.Context, tracer trace.Tracer, ssmClient *ssm.Client) (string, error) {
+	ctx, span := tracer.Start(ctx, "GetStatusTopicARN")
+	defer span.End()
+
+	param, err := ssmClient.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(envOrDefault("STATUS_TOPIC_PARAM_KEY", "/INTERNAL_DEVELOPER_PLATFORM/STATUS_TOPIC_ARN")),
+	})
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+
+	topicARN := aws.ToString(param.Parameter.Value)
+	if topicARN == "" {
+		return "", errEmptyTopicARN
+	}
+	return topicARN, nil
+}
+
+// getDLQURL reads the provisioning queue's redrive (dead-letter) queue URL from
+// Parameter Store within its own span.
+func getDLQURL(ctx context.Context, tracer trace.Tracer, ssmClient *ssm.Client) (string, error) {
+	ctx, span := tracer.Start(ctx, "GetSQSDLQURL")
+	defer span.End()
+
+	param, err := ssmClient.GetParameter(ctx, &ssm.GetParameterInput{
+		Name: aws.String(envOrDefault("PROVISIONER_DLQ_PARAM_KEY", "/INTERNAL_DEVELOPER_PLATFORM/PROVISIONER_DLQ_URL")),
+	})
+	if err != nil {
+		span.RecordError(err)
+		return "", err
+	}
+	return aws.ToString(param.Parameter.Value), nil
+}
+
+// Values of PROVISIONER_DRIVERS.
+const (
+	driversNone = "none"
+	driversFake = "fake"
+)
+
+// newDispatcher builds the driver dispatcher PROVISIONER_DRIVERS selects:
+// "fake" registers the deterministic fake driver for every provider and
+// resource type (the local default), "none" provisions nothing and leaves
+// requests in progress (the default on AWS until real drivers land).
+func newDispatcher(mode string, log logger.Logger) (*driver.Dispatcher, error) {
+	switch mode {
+	case driversNone:
+		return nil, nil
+	case driversFake:
+		registry := driver.NewRegistry()
+		driver.RegisterFake(registry)
+		return driver.NewDispatcher(registry, log), nil
+	default:
+		return nil, fmt.Errorf("unknown PROVISIONER_DRIVERS %q", mode)
+	}
+}
+
+// retryPolicyFromEnv overrides the default in-process retry policy with
+// MESSAGE_MAX_ATTEMPTS and MESSAGE_RETRY_BACKOFF when set.
+func retryPolicyFromEnv() consumer.RetryPolicy {
+	policy := consumer.DefaultRetryPolicy()
+	policy.MaxAttempts = intEnvOrDefault("MESSAGE_MAX_ATTEMPTS", policy.MaxAttempts)
+	if d, err := time.ParseDuration(os.Getenv("MESSAGE_RETRY_BACKOFF")); err == nil && d > 0 {
+		policy.InitialBackoff = d
+	}
+	return policy
+}
+
+// poolConfigFromEnv overrides the default worker pool with WORKER_COUNT,
+// WORKER_QUEUE_DEPTH and SHUTDOWN_DRAIN_TIMEOUT when set.
+func poolConfigFromEnv() consumer.PoolConfig {
+	pool := consumer.DefaultPoolConfig()
+	pool.Workers = intEnvOrDefault("WORKER_COUNT", pool.Workers)
+	pool.QueueDepth = intEnvOrDefault("WORKER_QUEUE_DEPTH", pool.QueueDepth)
+	if d, err := time.ParseDuration(os.Getenv("SHUTDOWN_DRAIN_TIMEOUT")); err == nil && d > 0 {
+		pool.DrainTimeout = d
+	}
+	return pool
+}
+
 func envOrDefault(key, fallback string) string {
 	if v := os.Getenv(key); v != "" {
 		return v
@@ -136,6 +356,13 @@ func envOrDefault(key, fallback string) string {
 	return fallback
 }
 
+func intEnvOrDefault(key string, fallback int) int {
+	if v, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return v
+	}
+	return fallback
+}
+
 // splitBrokers parses a comma-separated broker list, trimming blanks.
 func splitBrokers(csv string) []string {
 	var brokers []string
@@ -1,14 +1,44 @@
+-- Resource IDs are client-chosen strings (e.g. "vm-001"), validated by the API
+-- to at most 100 characters, so they are TEXT rather than UUID.
 CREATE TABLE resources (
-    id UUID PRIMARY KEY,
+    id TEXT PRIMARY KEY,
     type TEXT NOT NULL,
     status TEXT NOT NULL,
     provider TEXT NOT NULL,
-    metadata JSONB
+    specification TEXT NOT NULL,
+    requested_by TEXT NOT NULL,
+    metadata JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
 );
 
+-- One row per AWS VM request. The AMI is resolved at launch time, so it is
+-- empty until the instance exists.
 CREATE TABLE aws_ec2_instances (
-    id UUID PRIMARY KEY,
-    resource_id UUID REFERENCES resources(id),
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    resource_id TEXT NOT NULL UNIQUE REFERENCES resources(id),
     instance_type TEXT NOT NULL,
-    ami_id TEXT NOT NULL
-);
\ No newline at end of file
+    ami_id TEXT
+);
+
+-- Append-only record of every status change, written in the same transaction
+-- as the resources.status update.
+CREATE TABLE resource_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    resource_id TEXT NOT NULL REFERENCES resources(id),
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX resource_status_history_resource_idx ON resource_status_history (resource_id, changed_at);
+
+-- Inbox of consumed messages, keyed by the message ID the API stamps on
+-- publish. A row is inserted in the same transaction as the resource, so a
+-- redelivered message finds its ID taken and is dropped.
+CREATE TABLE processed_messages (
+    message_id TEXT PRIMARY KEY,
+    resource_id TEXT NOT NULL REFERENCES resources(id) DEFERRABLE INITIALLY DEFERRED,
+    processed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
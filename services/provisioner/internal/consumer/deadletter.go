@@ -0,0 +1,92 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+)
+
+// Dead-letter metadata keys, set as Kafka or NATS headers or SQS message
+// attributes alongside the original trace context.
+const (
+	dlqReasonKey   = "dlq-reason"
+	dlqAttemptsKey = "dlq-attempts"
+	dlqSourceKey   = "dlq-source"
+)
+
+// kafkaDeadLetter builds the DLQ copy of a message: same key, value and
+// headers (so the trace context survives), plus why and where it failed.
+func kafkaDeadLetter(message kafka.Message, reason error, attempts int) kafka.Message {
+	headers := make([]kafka.Header, 0, len(message.Headers)+3)
+	headers = append(headers, message.Headers...)
+	headers = append(headers,
+		kafka.Header{Key: dlqReasonKey, Value: []byte(reason.Error())},
+		kafka.Header{Key: dlqAttemptsKey, Value: []byte(strconv.Itoa(attempts))},
+		kafka.Header{Key: dlqSourceKey, Value: []byte(fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset))},
+	)
+	return kafka.Message{Key: message.Key, Value: message.Value, Headers: headers}
+}
+
+// natsDeadLetter builds the DLQ copy of a message for subject: same data and
+// headers (so the trace context survives), plus why and where it failed.
+func natsDeadLetter(message jetstream.Msg, subject string, reason error, attempts int) *nats.Msg {
+	dl := nats.NewMsg(subject)
+	dl.Data = message.Data()
+	for k, v := range message.Headers() {
+		dl.Header[k] = append([]string(nil), v...)
+	}
+	dl.Header.Set(dlqReasonKey, reason.Error())
+	dl.Header.Set(dlqAttemptsKey, strconv.Itoa(attempts))
+	if meta, err := message.Metadata(); err == nil {
+		dl.Header.Set(dlqSourceKey, fmt.Sprintf("%s/%d", meta.Stream, meta.Sequence.Stream))
+	}
+	return dl
+}
+
+// sqsDeadLetter sends a copy of a message to the redrive queue, keeping its
+// attributes (and so its trace context) and recording why it failed.
+func sqsDeadLetter(ctx context.Context, client *sqs.Client, dlqURL string, message sqstypes.Message, reason error, attempts int) error {
+	_, err := client.SendMessage(ctx, sqsDeadLetterInput(dlqURL, message, reason, attempts))
+	return err
+}
+
+// sqsDeadLetterInput builds the DLQ copy of a message. A FIFO DLQ requires a
+// message group and deduplication ID: the copy keeps the source message's
+// group, or the resource ID when the source queue is not FIFO, and is
+// deduplicated by message ID so dead-lettering a redelivery is a no-op.
+func sqsDeadLetterInput(dlqURL string, message sqstypes.Message, reason error, attempts int) *sqs.SendMessageInput {
+	attrs := make(map[string]sqstypes.MessageAttributeValue, len(message.MessageAttributes)+3)
+	for k, v := range message.MessageAttributes {
+		attrs[k] = v
+	}
+	attrs[dlqReasonKey] = stringAttribute(reason.Error())
+	attrs[dlqAttemptsKey] = stringAttribute(strconv.Itoa(attempts))
+	attrs[dlqSourceKey] = stringAttribute(aws.ToString(message.MessageId))
+
+	input := &sqs.SendMessageInput{
+		QueueUrl:          aws.String(dlqURL),
+		MessageBody:       message.Body,
+		MessageAttributes: attrs,
+	}
+	if strings.HasSuffix(dlqURL, ".fifo") {
+		group := message.Attributes[string(sqstypes.MessageSystemAttributeNameMessageGroupId)]
+		if group == "" {
+			group = sqsKey(message)
+		}
+		input.MessageGroupId = aws.String(group)
+		input.MessageDeduplicationId = aws.String(sqsMessageID(message))
+	}
+	return input
+}
+
+func stringAttribute(v string) sqstypes.MessageAttributeValue {
+	return sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+}
@@ -0,0 +1,106 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+func TestKafkaDeadLetter_KeepsPayloadAndTraceContext(t *testing.T) {
+	original := kafka.Message{
+		Topic:     "resource-provisioning",
+		Partition: 2,
+		Offset:    41,
+		Key:       []byte("vm-001"),
+		Value:     []byte(validBody),
+		Headers:   []kafka.Header{{Key: "traceparent", Value: []byte("00-abc-def-01")}},
+	}
+
+	dl := kafkaDeadLetter(original, errors.New("boom"), 3)
+
+	if string(dl.Key) != "vm-001" || string(dl.Value) != validBody {
+		t.Errorf("dead letter changed the payload: %+v", dl)
+	}
+	carrier := kafkaHeaderCarrier(dl.Headers)
+	for key, want := range map[string]string{
+		"traceparent":  "00-abc-def-01",
+		dlqReasonKey:   "boom",
+		dlqAttemptsKey: "3",
+		dlqSourceKey:   "resource-provisioning/2/41",
+	} {
+		if got := carrier.Get(key); got != want {
+			t.Errorf("header %s = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestShouldDeadLetter(t *testing.T) {
+	transient := errors.New("connection reset")
+	malformed := fmt.Errorf("%w: not json", model.ErrMalformedMessage)
+	cfg := SQSConfig{DLQURL: "https://sqs/dlq", MaxReceives: 5}
+
+	cases := []struct {
+		name     string
+		err      error
+		receives int
+		cfg      SQSConfig
+		want     bool
+	}{
+		{"malformed goes straight to the DLQ", malformed, 1, cfg, true},
+		{"transient before the last delivery is redelivered", transient, 4, cfg, false},
+		{"transient on the last delivery is dead-lettered", transient, 5, cfg, true},
+		{"shutdown is never dead-lettered", context.Canceled, 5, cfg, false},
+		{"without a DLQ URL the redrive policy decides", malformed, 5, SQSConfig{MaxReceives: 5}, false},
+	}
+	for _, c := range cases {
+		if got := shouldDeadLetter(c.err, c.receives, c.cfg); got != c.want {
+			t.Errorf("%s: shouldDeadLetter = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestReceiveCount(t *testing.T) {
+	m := sqstypes.Message{Attributes: map[string]string{"ApproximateReceiveCount": "4"}}
+	if got := receiveCount(m); got != 4 {
+		t.Errorf("receiveCount = %d, want 4", got)
+	}
+	if got := receiveCount(sqstypes.Message{}); got != 0 {
+		t.Errorf("receiveCount without attribute = %d, want 0", got)
+	}
+}
+
+func TestSQSDeadLetterInput_FIFOKeepsGroupAndDedupesByMessageID(t *testing.T) {
+	original := sqstypes.Message{
+		MessageId: aws.String("sqs-1"),
+		Body:      aws.String(validBody),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			MessageIDHeader: stringAttribute("msg-1"),
+		},
+	}
+
+	standard := sqsDeadLetterInput("https://sqs/dlq", original, errors.New("boom"), 3)
+	if standard.MessageGroupId != nil || standard.MessageDeduplicationId != nil {
+		t.Errorf("a standard DLQ takes no group or deduplication ID: %+v", standard)
+	}
+
+	fromStandard := sqsDeadLetterInput("https://sqs/dlq.fifo", original, errors.New("boom"), 3)
+	if got := aws.ToString(fromStandard.MessageGroupId); got != "vm-001" {
+		t.Errorf("group = %q, want the resource ID", got)
+	}
+	if got := aws.ToString(fromStandard.MessageDeduplicationId); got != "msg-1" {
+		t.Errorf("deduplication ID = %q, want the message ID", got)
+	}
+
+	original.Attributes = map[string]string{"MessageGroupId": "source-group"}
+	fromFIFO := sqsDeadLetterInput("https://sqs/dlq.fifo", original, errors.New("boom"), 3)
+	if got := aws.ToString(fromFIFO.MessageGroupId); got != "source-group" {
+		t.Errorf("group = %q, want the source message's group", got)
+	}
+}
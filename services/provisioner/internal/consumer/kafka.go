@@ -2,11 +2,15 @@ package consumer
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sync"
 
 	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
 )
 
 // KafkaConfig configures the Kafka consumer.
@@ -14,12 +18,30 @@ type KafkaConfig struct {
 	Brokers []string
 	Topic   string
 	GroupID string
+	// DLQTopic receives messages that are malformed or still failing after
+	// Retry is exhausted.
+	DLQTopic string
+	Retry    RetryPolicy
+	Pool     PoolConfig
 }
 
 // RunKafka consumes the provisioning topic with a consumer group until the
-// context is cancelled. Offsets are committed only after a message is processed
-// (at-least-once), mirroring the SQS delete-after-process semantics.
-func RunKafka(ctx context.Context, cfg KafkaConfig, tracer trace.Tracer, metrics Metrics, log logger.Logger) error {
+// context is cancelled. Messages are processed concurrently on a worker pool
+// keyed by message key (the resource ID), so one resource's messages are still
+// handled in order. Offsets are committed only after the handler succeeds or
+// the message has been dead-lettered (at-least-once), mirroring the SQS
+// delete-after-process semantics; because messages of a partition can finish
+// out of order, a commit never advances past a message still in flight.
+//
+// A failing message is retried in-process per cfg.Retry. If it still fails, or
+// is malformed, it is written to cfg.DLQTopic and committed past so one poison
+// message cannot stall the partition. Kafka offsets are cumulative, so if the
+// DLQ write itself fails the loop stops and returns: the process exits,
+// restarts, and re-reads from the last committed offset.
+//
+// On cancellation fetching stops and in-flight messages are drained per
+// cfg.Pool before RunKafka returns.
+func RunKafka(ctx context.Context, cfg KafkaConfig, handler Handler, tracer trace.Tracer, metrics Metrics, log logger.Logger) error {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: cfg.Brokers,
 		Topic:   cfg.Topic,
@@ -31,16 +53,48 @@ func RunKafka(ctx context.Context, cfg KafkaConfig, tracer trace.Tracer, metrics
 		}
 	}()
 
+	dlq := &kafka.Writer{
+		Addr:                   kafka.TCP(cfg.Brokers...),
+		Topic:                  cfg.DLQTopic,
+		Balancer:               &kafka.Hash{},
+		AllowAutoTopicCreation: true,
+	}
+	defer func() {
+		if err := dlq.Close(); err != nil {
+			log.WithContext(ctx).Warn("failed to close Kafka DLQ writer", logger.F("error", err.Error()))
+		}
+	}()
+
 	log.WithContext(ctx).Info("consuming messages from Kafka",
 		logger.F("topic", cfg.Topic),
 		logger.F("group", cfg.GroupID),
+		logger.F("dlq_topic", cfg.DLQTopic),
+		logger.F("workers", cfg.Pool.Workers),
+	)
+
+	// fetchCtx stops the fetch loop either on shutdown or when a worker hits
+	// a failure that must not be committed past.
+	fetchCtx, stopFetching := context.WithCancel(ctx)
+	defer stopFetching()
+	var (
+		fatalOnce sync.Once
+		fatalErr  error
 	)
+	fail := func(err error) {
+		fatalOnce.Do(func() {
+			fatalErr = err
+			stopFetching()
+		})
+	}
+
+	workers := newPool(cfg.Pool, metrics.InFlight)
+	offsets := newOffsetTracker()
 
-	for ctx.Err() == nil {
-		message, err := reader.FetchMessage(ctx)
+	for fetchCtx.Err() == nil {
+		message, err := reader.FetchMessage(fetchCtx)
 		if err != nil {
 			// A cancelled context is a clean shutdown, not a fetch failure.
-			if ctx.Err() != nil {
+			if fetchCtx.Err() != nil {
 				break
 			}
 			log.WithContext(ctx).Error("failed to fetch message", logger.F("error", err.Error()))
@@ -52,23 +106,90 @@ func RunKafka(ctx context.Context, cfg KafkaConfig, tracer trace.Tracer, metrics
 		// message headers becomes the parent of ProcessMessage, so the whole
 		// provisioning flow is one distributed trace and the logs below share
 		// the API's trace_id.
-		msgCtx := extractKafka(ctx, message.Headers)
-		processCtx, span := tracer.Start(msgCtx, "ProcessMessage")
-		log.WithContext(processCtx).Info("received message", logger.F("body", string(message.Value)))
-
-		// Save message data in RDS
-
-		// Commit the offset after processing.
-		if err := reader.CommitMessages(processCtx, message); err != nil {
-			metrics.Failed.Add(processCtx, 1)
-			span.RecordError(err)
-			log.WithContext(processCtx).Error("failed to commit message", logger.F("error", err.Error()))
-		} else {
-			metrics.Processed.Add(processCtx, 1)
-			log.WithContext(processCtx).Info("message committed")
+		msgCtx := WithMessageID(extractKafka(ctx, message.Headers), kafkaMessageID(message))
+		msgCtx = WithContentType(msgCtx, kafkaHeaderCarrier(message.Headers).Get(ContentTypeHeader))
+		tracked := offsets.track(message)
+		err = workers.submit(fetchCtx, msgCtx, kafkaKey(message), func(jobCtx context.Context) {
+			if err := processKafkaMessage(jobCtx, message, handler, dlq, cfg.Retry, tracer, metrics, log); err != nil {
+				fail(err)
+				return
+			}
+			offsets.finish(tracked, func(last kafka.Message, count int) {
+				commitKafkaMessages(jobCtx, reader, last, count, metrics, log)
+			})
+		})
+		if err != nil {
+			break
 		}
-		span.End()
 	}
 
+	workers.drain()
+	if fatalErr != nil {
+		return fatalErr
+	}
 	return ctx.Err()
 }
+
+// processKafkaMessage handles one message, dead-lettering it if it cannot be
+// processed. A non-nil error means the message was neither processed nor
+// dead-lettered and its offset must not be committed.
+func processKafkaMessage(ctx context.Context, message kafka.Message, handler Handler, dlq *kafka.Writer, policy RetryPolicy, tracer trace.Tracer, metrics Metrics, log logger.Logger) error {
+	processCtx, span := tracer.Start(ctx, "ProcessMessage")
+	defer span.End()
+	log.WithContext(processCtx).Info("received message", logger.F("body", string(message.Value)))
+
+	attempts, err := handleWithRetry(processCtx, handler, message.Value, policy, log)
+	if errors.Is(err, repository.ErrDuplicateMessage) {
+		metrics.Duplicates.Add(processCtx, 1)
+		return nil
+	}
+	if err == nil {
+		return nil
+	}
+	metrics.Failed.Add(processCtx, 1)
+	span.RecordError(err)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	log.WithContext(processCtx).Error("dead-lettering message",
+		logger.F("attempts", attempts),
+		logger.F("error", err.Error()),
+	)
+	if dlqErr := dlq.WriteMessages(processCtx, kafkaDeadLetter(message, err, attempts)); dlqErr != nil {
+		span.RecordError(dlqErr)
+		return fmt.Errorf("dead-letter message at offset %d: %w", message.Offset, dlqErr)
+	}
+	metrics.DeadLettered.Add(processCtx, 1)
+	return nil
+}
+
+// commitKafkaMessages commits the consumer group's offset past message,
+// acknowledging it and the count-1 finished messages before it on its
+// partition.
+func commitKafkaMessages(ctx context.Context, reader *kafka.Reader, message kafka.Message, count int, metrics Metrics, log logger.Logger) {
+	if err := reader.CommitMessages(ctx, message); err != nil {
+		metrics.Failed.Add(ctx, int64(count))
+		log.WithContext(ctx).Error("failed to commit message",
+			logger.F("partition", message.Partition),
+			logger.F("offset", message.Offset),
+			logger.F("error", err.Error()),
+		)
+		return
+	}
+	metrics.Processed.Add(ctx, int64(count))
+	log.WithContext(ctx).Info("message committed",
+		logger.F("partition", message.Partition),
+		logger.F("offset", message.Offset),
+		logger.F("count", count),
+	)
+}
+
+// kafkaKey is the pool key for a message: its Kafka key, which the API sets to
+// the resource ID, or its partition and offset when unkeyed.
+func kafkaKey(message kafka.Message) string {
+	if len(message.Key) > 0 {
+		return string(message.Key)
+	}
+	return fmt.Sprintf("%d/%d", message.Partition, message.Offset)
+}
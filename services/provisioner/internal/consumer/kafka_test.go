@@ -8,6 +8,7 @@ import (
 	"go.opentelemetry.io/otel"
 
 	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
 )
 
 // RunKafka must return promptly when the context is already cancelled, without
@@ -23,7 +24,7 @@ func TestRunKafka_CancelledContextReturnsPromptly(t *testing.T) {
 			Brokers: []string{"127.0.0.1:9092"},
 			Topic:   "test-topic",
 			GroupID: "test-group",
-		}, otel.Tracer("test"), metrics, logger.NopLogger{})
+		}, NewProcessor(repository.NewMemory(), nil, nil, nil), otel.Tracer("test"), metrics, logger.NopLogger{})
 	}()
 
 	select {
@@ -0,0 +1,95 @@
+package consumer
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// MessageIDHeader is the Kafka or NATS header / SQS message attribute the API
+// stamps with a unique ID per published message. The Processor dedupes on it.
+const MessageIDHeader = "message-id"
+
+type messageIDKey struct{}
+
+// WithMessageID returns a context carrying the ID of the message being
+// handled.
+func WithMessageID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, messageIDKey{}, id)
+}
+
+// MessageIDFromContext returns the ID set by WithMessageID, or "".
+func MessageIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(messageIDKey{}).(string)
+	return id
+}
+
+// ContentTypeHeader is the Kafka or NATS header / SQS message attribute the API
+// stamps with the wire format of a message's body (see model.ContentTypeJSON).
+const ContentTypeHeader = "content-type"
+
+type contentTypeKey struct{}
+
+// WithContentType returns a context carrying the content type of the message
+// being handled.
+func WithContentType(ctx context.Context, contentType string) context.Context {
+	return context.WithValue(ctx, contentTypeKey{}, contentType)
+}
+
+// ContentTypeFromContext returns the content type set by WithContentType, or
+// "" — which decodes as JSON, the format of messages published before the API
+// stamped one.
+func ContentTypeFromContext(ctx context.Context) string {
+	contentType, _ := ctx.Value(contentTypeKey{}).(string)
+	return contentType
+}
+
+// kafkaMessageID is the API-stamped ID of a Kafka message, falling back to its
+// position in the topic, which is just as stable across redeliveries.
+func kafkaMessageID(message kafka.Message) string {
+	if id := kafkaHeaderCarrier(message.Headers).Get(MessageIDHeader); id != "" {
+		return id
+	}
+	return fmt.Sprintf("%s/%d/%d", message.Topic, message.Partition, message.Offset)
+}
+
+// sqsMessageID is the API-stamped ID of an SQS message, falling back to the
+// ID SQS assigned it, which redeliveries keep.
+func sqsMessageID(message sqstypes.Message) string {
+	if id := sqsAttributeCarrier(message.MessageAttributes).Get(MessageIDHeader); id != "" {
+		return id
+	}
+	return aws.ToString(message.MessageId)
+}
+
+// natsMessageID is the API-stamped ID of a NATS message, falling back to its
+// position in the stream, which redeliveries keep.
+func natsMessageID(message jetstream.Msg) string {
+	if id := natsHeaderCarrier(message.Headers()).Get(MessageIDHeader); id != "" {
+		return id
+	}
+	if meta, err := message.Metadata(); err == nil {
+		return fmt.Sprintf("%s/%d", meta.Stream, meta.Sequence.Stream)
+	}
+	return ""
+}
+
+// sqsBody is the payload of an SQS message. SQS bodies are text, so the API
+// base64-encodes binary formats; a body that does not decode is passed on
+// as is, to be rejected as malformed.
+func sqsBody(message sqstypes.Message) []byte {
+	body := aws.ToString(message.Body)
+	if sqsAttributeCarrier(message.MessageAttributes).Get(ContentTypeHeader) == model.ContentTypeProtobuf {
+		if decoded, err := base64.StdEncoding.DecodeString(body); err == nil {
+			return decoded
+		}
+	}
+	return []byte(body)
+}
@@ -1,24 +1,33 @@
 // Package consumer holds the message-consumption loops. The transport is chosen
-// at startup: Kafka for local dev (no AWS), SQS otherwise. Both share the same
-// telemetry (spans + these counters).
+// at startup: Kafka or NATS JetStream for local dev (no AWS), SQS otherwise.
+// All share the same telemetry (spans + these counters).
 package consumer
 
 import "go.opentelemetry.io/otel/metric"
 
-// Metrics are the counters both transports report.
+// Metrics are the counters and gauges every transport reports.
 type Metrics struct {
-	Received  metric.Int64Counter
-	Processed metric.Int64Counter
-	Failed    metric.Int64Counter
+	Received     metric.Int64Counter
+	Processed    metric.Int64Counter
+	Failed       metric.Int64Counter
+	DeadLettered metric.Int64Counter
+	Duplicates   metric.Int64Counter
+	InFlight     metric.Int64UpDownCounter
 }
 
-// NewMetrics creates the provisioner message counters on the given meter.
+// NewMetrics creates the provisioner message instruments on the given meter.
 func NewMetrics(meter metric.Meter) Metrics {
 	received, _ := meter.Int64Counter("provisioner.messages.received",
 		metric.WithDescription("Messages received from the provisioning queue"))
 	processed, _ := meter.Int64Counter("provisioner.messages.processed",
 		metric.WithDescription("Messages processed and acknowledged successfully"))
 	failed, _ := meter.Int64Counter("provisioner.messages.failed",
-		metric.WithDescription("Messages that failed to acknowledge after processing"))
-	return Metrics{Received: received, Processed: processed, Failed: failed}
+		metric.WithDescription("Messages that failed processing or acknowledgement"))
+	deadLettered, _ := meter.Int64Counter("provisioner.messages.dead_lettered",
+		metric.WithDescription("Messages moved to the dead-letter destination after exhausting retries or being malformed"))
+	duplicates, _ := meter.Int64Counter("provisioner.messages.duplicates",
+		metric.WithDescription("Redelivered messages dropped because the inbox had already processed them"))
+	inFlight, _ := meter.Int64UpDownCounter("provisioner.messages.in_flight",
+		metric.WithDescription("Messages handed to the worker pool and not yet finished"))
+	return Metrics{Received: received, Processed: processed, Failed: failed, DeadLettered: deadLettered, Duplicates: duplicates, InFlight: inFlight}
 }
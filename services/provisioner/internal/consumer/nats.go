@@ -0,0 +1,216 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
+)
+
+// NATSConfig configures the NATS JetStream consumer.
+type NATSConfig struct {
+	// Stream captures Subject. It is created if missing, like the API does,
+	// along with a <Stream>_DLQ stream capturing DLQSubject.
+	Stream  string
+	Subject string
+	// Durable names the consumer, so its position survives restarts and
+	// replicas share its messages.
+	Durable string
+	// DLQSubject receives messages that are malformed, or still failing on
+	// their MaxDeliveries-th delivery. When empty nothing is dead-lettered
+	// and a failing message is redelivered indefinitely.
+	DLQSubject    string
+	MaxDeliveries int
+	// AckWait is how long a message may go unacknowledged before JetStream
+	// redelivers it. It must cover a message's queueing and in-process
+	// retries; redeliveries of a message still being handled are dropped
+	// as duplicates.
+	AckWait time.Duration
+	Retry   RetryPolicy
+	Pool    PoolConfig
+}
+
+// RunNATS consumes the provisioning subject through a durable pull consumer
+// with explicit acks until the context is cancelled. Messages are processed
+// concurrently on a worker pool keyed by resource ID, so one resource's
+// messages are still handled in order, and each is acknowledged only after
+// the handler succeeds or it has been dead-lettered (at-least-once),
+// mirroring the SQS delete-after-process semantics. A message that still
+// fails after cfg.Retry is left unacknowledged and redelivered once
+// cfg.AckWait lapses; on its last allowed delivery, or straight away if it is
+// malformed, it is published to cfg.DLQSubject.
+//
+// On cancellation fetching stops and in-flight messages are drained per
+// cfg.Pool before RunNATS returns.
+func RunNATS(ctx context.Context, js jetstream.JetStream, cfg NATSConfig, handler Handler, tracer trace.Tracer, metrics Metrics, log logger.Logger) error {
+	if err := EnsureNATSStream(ctx, js, cfg.Stream, cfg.Subject); err != nil {
+		return err
+	}
+	if cfg.DLQSubject != "" {
+		if err := EnsureNATSStream(ctx, js, cfg.Stream+"_DLQ", cfg.DLQSubject); err != nil {
+			return err
+		}
+	}
+
+	consumer, err := js.CreateOrUpdateConsumer(ctx, cfg.Stream, jetstream.ConsumerConfig{
+		Durable:       cfg.Durable,
+		FilterSubject: cfg.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       cfg.AckWait,
+	})
+	if err != nil {
+		return fmt.Errorf("create consumer %s: %w", cfg.Durable, err)
+	}
+	messages, err := consumer.Messages()
+	if err != nil {
+		return fmt.Errorf("consume %s: %w", cfg.Subject, err)
+	}
+	// Stopping the iterator unblocks Next on shutdown. Buffered messages are
+	// discarded unacknowledged, so JetStream redelivers them.
+	stopOnCancel := context.AfterFunc(ctx, messages.Stop)
+	defer stopOnCancel()
+	defer messages.Stop()
+
+	log.WithContext(ctx).Info("consuming messages from NATS JetStream",
+		logger.F("stream", cfg.Stream),
+		logger.F("subject", cfg.Subject),
+		logger.F("durable", cfg.Durable),
+		logger.F("dlq_subject", cfg.DLQSubject),
+		logger.F("workers", cfg.Pool.Workers),
+	)
+
+	workers := newPool(cfg.Pool, metrics.InFlight)
+	defer workers.drain()
+
+	for ctx.Err() == nil {
+		message, err := messages.Next()
+		if err != nil {
+			// A stopped iterator is a clean shutdown, not a fetch failure.
+			if errors.Is(err, jetstream.ErrMsgIteratorClosed) || ctx.Err() != nil {
+				break
+			}
+			log.WithContext(ctx).Error("failed to fetch message", logger.F("error", err.Error()))
+			continue
+		}
+		metrics.Received.Add(ctx, 1)
+
+		// Continue the API's trace: the producer span it injected into the
+		// message headers becomes the parent of ProcessMessage, so the whole
+		// provisioning flow is one distributed trace and the logs below share
+		// the API's trace_id.
+		msgCtx := WithMessageID(extractNATS(ctx, message.Headers()), natsMessageID(message))
+		msgCtx = WithContentType(msgCtx, natsHeaderCarrier(message.Headers()).Get(ContentTypeHeader))
+		err = workers.submit(ctx, msgCtx, natsKey(message), func(jobCtx context.Context) {
+			processNATSMessage(jobCtx, js, cfg, message, handler, tracer, metrics, log)
+		})
+		if err != nil {
+			break
+		}
+	}
+
+	return ctx.Err()
+}
+
+// EnsureNATSStream creates a stream named stream capturing subject unless one
+// by that name exists already, in which case its configuration is left alone.
+func EnsureNATSStream(ctx context.Context, js jetstream.StreamManager, stream, subject string) error {
+	_, err := js.Stream(ctx, stream)
+	if errors.Is(err, jetstream.ErrStreamNotFound) {
+		_, err = js.CreateStream(ctx, jetstream.StreamConfig{Name: stream, Subjects: []string{subject}})
+		if errors.Is(err, jetstream.ErrStreamNameAlreadyInUse) {
+			// Created concurrently, by the API or another replica.
+			err = nil
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("ensure stream %s: %w", stream, err)
+	}
+	return nil
+}
+
+// processNATSMessage handles one message and acknowledges it once processed
+// or dead-lettered. On failure the message is left for redelivery.
+func processNATSMessage(ctx context.Context, js jetstream.Publisher, cfg NATSConfig, message jetstream.Msg, handler Handler, tracer trace.Tracer, metrics Metrics, log logger.Logger) {
+	processCtx, span := tracer.Start(ctx, "ProcessMessage")
+	defer span.End()
+	log.WithContext(processCtx).Info("received message", logger.F("body", string(message.Data())))
+
+	attempts, err := handleWithRetry(processCtx, handler, message.Data(), cfg.Retry, log)
+	if errors.Is(err, repository.ErrDuplicateMessage) {
+		// Already processed on an earlier delivery: just acknowledge it.
+		metrics.Duplicates.Add(processCtx, 1)
+		err = nil
+	}
+	if err != nil {
+		metrics.Failed.Add(processCtx, 1)
+		span.RecordError(err)
+		deliveries := numDelivered(message)
+		if !shouldDeadLetterNATS(err, deliveries, cfg) {
+			log.WithContext(processCtx).Error("failed to process message; leaving it for redelivery", logger.F("error", err.Error()))
+			return
+		}
+
+		log.WithContext(processCtx).Error("dead-lettering message",
+			logger.F("attempts", attempts),
+			logger.F("deliveries", deliveries),
+			logger.F("error", err.Error()),
+		)
+		if _, dlqErr := js.PublishMsg(processCtx, natsDeadLetter(message, cfg.DLQSubject, err, attempts)); dlqErr != nil {
+			span.RecordError(dlqErr)
+			log.WithContext(processCtx).Error("failed to dead-letter message; leaving it for redelivery", logger.F("error", dlqErr.Error()))
+			return
+		}
+		metrics.DeadLettered.Add(processCtx, 1)
+	}
+
+	// Acknowledge the message after processing or dead-lettering, waiting for
+	// the server to confirm so a lost ack is reported like a failed delete.
+	if err := message.DoubleAck(processCtx); err != nil {
+		metrics.Failed.Add(processCtx, 1)
+		span.RecordError(err)
+		log.WithContext(processCtx).Error("failed to acknowledge message", logger.F("error", err.Error()))
+		return
+	}
+	metrics.Processed.Add(processCtx, 1)
+	log.WithContext(processCtx).Info("message acknowledged")
+}
+
+// natsKey is the pool key for a message: the resource ID its envelope names
+// as subject, or its message ID when the body does not decode (it will be
+// dead-lettered as malformed anyway).
+func natsKey(message jetstream.Msg) string {
+	contentType := natsHeaderCarrier(message.Headers()).Get(ContentTypeHeader)
+	if e, err := model.DecodeEnvelope(contentType, message.Data()); err == nil && e.Subject != "" {
+		return e.Subject
+	}
+	return natsMessageID(message)
+}
+
+// shouldDeadLetterNATS reports whether a failed message should be published
+// to the DLQ now rather than left for redelivery.
+func shouldDeadLetterNATS(err error, deliveries int, cfg NATSConfig) bool {
+	if cfg.DLQSubject == "" || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, model.ErrMalformedMessage) {
+		return true
+	}
+	return cfg.MaxDeliveries > 0 && deliveries >= cfg.MaxDeliveries
+}
+
+// numDelivered is how many times JetStream has delivered the message, or 0
+// if unknown.
+func numDelivered(message jetstream.Msg) int {
+	meta, err := message.Metadata()
+	if err != nil {
+		return 0
+	}
+	return int(meta.NumDelivered)
+}
@@ -0,0 +1,191 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// fakeNATSMsg is a delivered JetStream message that records its
+// acknowledgement. Methods it does not override panic through the nil
+// embedded interface.
+type fakeNATSMsg struct {
+	jetstream.Msg
+	data      []byte
+	headers   nats.Header
+	delivered uint64
+	acked     bool
+}
+
+func (m *fakeNATSMsg) Data() []byte         { return m.data }
+func (m *fakeNATSMsg) Headers() nats.Header { return m.headers }
+
+func (m *fakeNATSMsg) Metadata() (*jetstream.MsgMetadata, error) {
+	return &jetstream.MsgMetadata{
+		Stream:       "RESOURCE_PROVISIONING",
+		Sequence:     jetstream.SequencePair{Stream: 41},
+		NumDelivered: m.delivered,
+	}, nil
+}
+
+func (m *fakeNATSMsg) DoubleAck(context.Context) error {
+	m.acked = true
+	return nil
+}
+
+// dlqRecorder records messages published to the DLQ subject.
+type dlqRecorder struct {
+	jetstream.Publisher
+	msgs []*nats.Msg
+}
+
+func (r *dlqRecorder) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	r.msgs = append(r.msgs, msg)
+	return &jetstream.PubAck{Stream: "RESOURCE_PROVISIONING_DLQ"}, nil
+}
+
+var testNATSConfig = NATSConfig{DLQSubject: "resource.provisioning.dlq", MaxDeliveries: 3, Retry: fastRetry}
+
+func processNATS(message *fakeNATSMsg, handler Handler) *dlqRecorder {
+	dlq := &dlqRecorder{}
+	processNATSMessage(context.Background(), dlq, testNATSConfig, message, handler, otel.Tracer("test"), NewMetrics(otel.Meter("test")), logger.NopLogger{})
+	return dlq
+}
+
+func TestProcessNATSMessage_AcksProcessedMessage(t *testing.T) {
+	message := &fakeNATSMsg{data: []byte(validBody), headers: nats.Header{}, delivered: 1}
+
+	dlq := processNATS(message, &flakyHandler{})
+
+	if !message.acked {
+		t.Error("processed message was not acknowledged")
+	}
+	if len(dlq.msgs) != 0 {
+		t.Errorf("dead-lettered %d messages, want 0", len(dlq.msgs))
+	}
+}
+
+func TestProcessNATSMessage_LeavesFailureForRedelivery(t *testing.T) {
+	message := &fakeNATSMsg{data: []byte(validBody), headers: nats.Header{}, delivered: 2}
+
+	dlq := processNATS(message, &flakyHandler{failures: 10, err: errors.New("connection reset")})
+
+	if message.acked {
+		t.Error("failed message was acknowledged before its last delivery")
+	}
+	if len(dlq.msgs) != 0 {
+		t.Errorf("dead-lettered %d messages, want 0", len(dlq.msgs))
+	}
+}
+
+func TestProcessNATSMessage_DeadLettersOnLastDelivery(t *testing.T) {
+	message := &fakeNATSMsg{data: []byte(validBody), headers: nats.Header{}, delivered: 3}
+
+	dlq := processNATS(message, &flakyHandler{failures: 10, err: errors.New("connection reset")})
+
+	if len(dlq.msgs) != 1 || dlq.msgs[0].Subject != "resource.provisioning.dlq" {
+		t.Fatalf("dead letters = %+v, want one on resource.provisioning.dlq", dlq.msgs)
+	}
+	if !message.acked {
+		t.Error("dead-lettered message was not acknowledged")
+	}
+}
+
+func TestProcessNATSMessage_DeadLettersMalformedStraightAway(t *testing.T) {
+	message := &fakeNATSMsg{data: []byte("not json"), headers: nats.Header{}, delivered: 1}
+
+	dlq := processNATS(message, NewProcessor(nil, nil, nil, nil))
+
+	if len(dlq.msgs) != 1 {
+		t.Fatalf("dead-lettered %d messages, want 1", len(dlq.msgs))
+	}
+	if !message.acked {
+		t.Error("dead-lettered message was not acknowledged")
+	}
+}
+
+func TestNATSDeadLetter_KeepsPayloadAndTraceContext(t *testing.T) {
+	original := &fakeNATSMsg{
+		data:    []byte(validBody),
+		headers: nats.Header{"traceparent": []string{"00-abc-def-01"}},
+	}
+
+	dl := natsDeadLetter(original, "resource.provisioning.dlq", errors.New("boom"), 3)
+
+	if dl.Subject != "resource.provisioning.dlq" || string(dl.Data) != validBody {
+		t.Errorf("dead letter changed the payload: %+v", dl)
+	}
+	for key, want := range map[string]string{
+		"traceparent":  "00-abc-def-01",
+		dlqReasonKey:   "boom",
+		dlqAttemptsKey: "3",
+		dlqSourceKey:   "RESOURCE_PROVISIONING/41",
+	} {
+		if got := dl.Header.Get(key); got != want {
+			t.Errorf("header %s = %q, want %q", key, got, want)
+		}
+	}
+	if _, ok := original.headers[dlqReasonKey]; ok {
+		t.Error("dead-lettering modified the original message's headers")
+	}
+}
+
+func TestShouldDeadLetterNATS(t *testing.T) {
+	transient := errors.New("connection reset")
+	malformed := fmt.Errorf("%w: not json", model.ErrMalformedMessage)
+
+	cases := []struct {
+		name       string
+		err        error
+		deliveries int
+		cfg        NATSConfig
+		want       bool
+	}{
+		{"malformed goes straight to the DLQ", malformed, 1, testNATSConfig, true},
+		{"transient before the last delivery is redelivered", transient, 2, testNATSConfig, false},
+		{"transient on the last delivery is dead-lettered", transient, 3, testNATSConfig, true},
+		{"shutdown is never dead-lettered", context.Canceled, 3, testNATSConfig, false},
+		{"without a DLQ subject nothing is dead-lettered", malformed, 3, NATSConfig{MaxDeliveries: 3}, false},
+	}
+	for _, c := range cases {
+		if got := shouldDeadLetterNATS(c.err, c.deliveries, c.cfg); got != c.want {
+			t.Errorf("%s: shouldDeadLetterNATS = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestNATSMessageID_FallsBackToStreamSequence(t *testing.T) {
+	stamped := &fakeNATSMsg{headers: nats.Header{MessageIDHeader: []string{"msg-1"}}}
+	if got := natsMessageID(stamped); got != "msg-1" {
+		t.Errorf("natsMessageID = %q, want msg-1", got)
+	}
+	if got := natsMessageID(&fakeNATSMsg{headers: nats.Header{}}); got != "RESOURCE_PROVISIONING/41" {
+		t.Errorf("natsMessageID fallback = %q, want RESOURCE_PROVISIONING/41", got)
+	}
+}
+
+func TestExtractNATS_ContinuesTheProducerTrace(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	headers := nats.Header{"traceparent": []string{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}}
+	sc := trace.SpanContextFromContext(extractNATS(context.Background(), headers))
+
+	if got := sc.TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace ID = %s, want the producer's", got)
+	}
+	if !sc.IsRemote() {
+		t.Error("extracted span context is not marked remote")
+	}
+}
@@ -0,0 +1,56 @@
+package consumer
+
+import (
+	"sync"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// offsetTracker decides which Kafka offsets are safe to commit when messages
+// of one partition finish out of order. Offsets are cumulative, so a commit
+// may only advance over a contiguous run of finished messages; a message that
+// never finishes holds its partition's commits back.
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[int][]*trackedMessage
+}
+
+type trackedMessage struct {
+	message kafka.Message
+	done    bool
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[int][]*trackedMessage)}
+}
+
+// track registers a fetched message. Messages must be tracked in fetch order.
+func (t *offsetTracker) track(m kafka.Message) *trackedMessage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tm := &trackedMessage{message: m}
+	t.partitions[m.Partition] = append(t.partitions[m.Partition], tm)
+	return tm
+}
+
+// finish marks tm done and, if that extends its partition's finished prefix,
+// calls commit with the last message of the prefix and the number of
+// messages that commit acknowledges. commit runs under the
+// tracker's lock so commits for a partition are never reordered.
+func (t *offsetTracker) finish(tm *trackedMessage, commit func(last kafka.Message, count int)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tm.done = true
+
+	pending := t.partitions[tm.message.Partition]
+	n := 0
+	for n < len(pending) && pending[n].done {
+		n++
+	}
+	if n == 0 {
+		return
+	}
+	last := pending[n-1].message
+	t.partitions[tm.message.Partition] = pending[n:]
+	commit(last, n)
+}
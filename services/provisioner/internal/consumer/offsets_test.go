@@ -0,0 +1,68 @@
+package consumer
+
+import (
+	"testing"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type commitRecorder struct {
+	offsets []int64
+	counts  []int
+}
+
+func (r *commitRecorder) commit(m kafka.Message, count int) {
+	r.offsets = append(r.offsets, m.Offset)
+	r.counts = append(r.counts, count)
+}
+
+// A message finishing ahead of an earlier one on its partition is held back
+// until the gap closes, then both are committed with one offset.
+func TestOffsetTracker_CommitsContiguousPrefix(t *testing.T) {
+	tr := newOffsetTracker()
+	m0 := tr.track(kafka.Message{Partition: 0, Offset: 10})
+	m1 := tr.track(kafka.Message{Partition: 0, Offset: 11})
+	m2 := tr.track(kafka.Message{Partition: 0, Offset: 12})
+
+	var rec commitRecorder
+	tr.finish(m1, rec.commit)
+	if len(rec.offsets) != 0 {
+		t.Fatalf("committed past an unfinished message: %v", rec.offsets)
+	}
+	tr.finish(m0, rec.commit)
+	tr.finish(m2, rec.commit)
+
+	if want := []int64{11, 12}; !equalOffsets(rec.offsets, want) {
+		t.Fatalf("expected commits at %v, got %v", want, rec.offsets)
+	}
+	if rec.counts[0] != 2 || rec.counts[1] != 1 {
+		t.Fatalf("expected counts [2 1], got %v", rec.counts)
+	}
+}
+
+// Partitions are tracked independently: a stuck message on one does not hold
+// back another.
+func TestOffsetTracker_PartitionsAreIndependent(t *testing.T) {
+	tr := newOffsetTracker()
+	tr.track(kafka.Message{Partition: 0, Offset: 5})
+	p1 := tr.track(kafka.Message{Partition: 1, Offset: 7})
+
+	var rec commitRecorder
+	tr.finish(p1, rec.commit)
+
+	if want := []int64{7}; !equalOffsets(rec.offsets, want) {
+		t.Fatalf("expected commits at %v, got %v", want, rec.offsets)
+	}
+}
+
+func equalOffsets(got, want []int64) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			return false
+		}
+	}
+	return true
+}
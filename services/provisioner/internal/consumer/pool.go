@@ -0,0 +1,129 @@
+package consumer
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+)
+
+// PoolConfig sizes the worker pool both transports process messages on.
+type PoolConfig struct {
+	// Workers is the number of messages processed concurrently.
+	Workers int
+	// QueueDepth is how many messages may wait for each worker. Keep it small
+	// on SQS: a queued message's visibility timeout is already running.
+	QueueDepth int
+	// DrainTimeout bounds how long in-flight messages may run after shutdown
+	// begins before their contexts are cancelled.
+	DrainTimeout time.Duration
+}
+
+// DefaultPoolConfig is four workers, four queued messages each, and a 25s
+// drain — inside Kubernetes' default 30s termination grace period.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{Workers: 4, QueueDepth: 4, DrainTimeout: 25 * time.Second}
+}
+
+// pool runs jobs on a fixed set of workers. Jobs with the same key always go
+// to the same worker, so they run one at a time in submission order; jobs
+// with different keys run concurrently. Keying by resource ID keeps each
+// resource's messages ordered, as the API's Kafka partitioning does.
+type pool struct {
+	queues       []chan func(context.Context)
+	wg           sync.WaitGroup
+	inFlight     metric.Int64UpDownCounter
+	drainTimeout time.Duration
+
+	// workCtx is what jobs ultimately run under. It is independent of the
+	// consume loop's context so that shutdown lets in-flight jobs finish;
+	// drain cancels it only once DrainTimeout has passed.
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+}
+
+func newPool(cfg PoolConfig, inFlight metric.Int64UpDownCounter) *pool {
+	workers := cfg.Workers
+	if workers < 1 {
+		workers = 1
+	}
+	workCtx, cancelWork := context.WithCancel(context.Background())
+	p := &pool{
+		queues:       make([]chan func(context.Context), workers),
+		inFlight:     inFlight,
+		drainTimeout: cfg.DrainTimeout,
+		workCtx:      workCtx,
+		cancelWork:   cancelWork,
+	}
+	for i := range p.queues {
+		p.queues[i] = make(chan func(context.Context), cfg.QueueDepth)
+		p.wg.Add(1)
+		go p.work(p.queues[i])
+	}
+	return p
+}
+
+func (p *pool) work(queue <-chan func(context.Context)) {
+	defer p.wg.Done()
+	for job := range queue {
+		job(p.workCtx)
+	}
+}
+
+// submit hands run to the worker owning key, blocking while that worker's
+// queue is full. run receives a context derived from msgCtx's values (trace
+// context, logger fields) that is cancelled only by drain's timeout, not by
+// msgCtx. It returns ctx's error if ctx ends before the job is queued.
+func (p *pool) submit(ctx, msgCtx context.Context, key string, run func(context.Context)) error {
+	p.inFlight.Add(msgCtx, 1)
+	job := func(workCtx context.Context) {
+		jobCtx, cancel := context.WithCancel(context.WithoutCancel(msgCtx))
+		stop := context.AfterFunc(workCtx, cancel)
+		defer func() {
+			stop()
+			cancel()
+			p.inFlight.Add(jobCtx, -1)
+		}()
+		run(jobCtx)
+	}
+
+	select {
+	case p.queues[p.worker(key)] <- job:
+		return nil
+	case <-ctx.Done():
+		p.inFlight.Add(msgCtx, -1)
+		return ctx.Err()
+	}
+}
+
+func (p *pool) worker(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// drain stops accepting jobs and waits for queued and running ones. After
+// the drain timeout, running jobs' contexts are cancelled and drain waits for
+// them to return. submit must not be called once drain has started.
+func (p *pool) drain() {
+	for _, q := range p.queues {
+		close(q)
+	}
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	if p.drainTimeout > 0 {
+		select {
+		case <-done:
+		case <-time.After(p.drainTimeout):
+			p.cancelWork()
+		}
+	}
+	<-done
+	p.cancelWork()
+}
@@ -0,0 +1,184 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+)
+
+func testPool(cfg PoolConfig) *pool {
+	return newPool(cfg, NewMetrics(otel.Meter("test")).InFlight)
+}
+
+// Jobs sharing a key run one at a time, in submission order, even when the
+// pool has spare workers.
+func TestPool_PreservesOrderPerKey(t *testing.T) {
+	p := testPool(PoolConfig{Workers: 4, QueueDepth: 1})
+	ctx := context.Background()
+
+	var (
+		mu      sync.Mutex
+		got     = map[string][]int{}
+		running = map[string]bool{}
+	)
+	for i := 0; i < 20; i++ {
+		for _, key := range []string{"res-a", "res-b", "res-c"} {
+			if err := p.submit(ctx, ctx, key, func(context.Context) {
+				mu.Lock()
+				if running[key] {
+					t.Errorf("two jobs for %s ran concurrently", key)
+				}
+				running[key] = true
+				mu.Unlock()
+
+				time.Sleep(time.Millisecond)
+
+				mu.Lock()
+				running[key] = false
+				got[key] = append(got[key], i)
+				mu.Unlock()
+			}); err != nil {
+				t.Fatalf("submit: %v", err)
+			}
+		}
+	}
+	p.drain()
+
+	for key, seq := range got {
+		if len(seq) != 20 {
+			t.Fatalf("%s: expected 20 jobs, got %d", key, len(seq))
+		}
+		for i, n := range seq {
+			if n != i {
+				t.Fatalf("%s: out of order at %d: %v", key, i, seq)
+			}
+		}
+	}
+}
+
+// Jobs for different keys overlap rather than queueing behind each other.
+func TestPool_RunsDistinctKeysConcurrently(t *testing.T) {
+	p := testPool(PoolConfig{Workers: 8})
+	ctx := context.Background()
+
+	// Pick keys that land on different workers so the test does not depend
+	// on how the hash spreads them.
+	keys := map[int]string{}
+	for i := 0; len(keys) < 3; i++ {
+		key := fmt.Sprintf("res-%d", i)
+		if _, taken := keys[p.worker(key)]; !taken {
+			keys[p.worker(key)] = key
+		}
+	}
+
+	var started sync.WaitGroup
+	started.Add(len(keys))
+	release := make(chan struct{})
+	for _, key := range keys {
+		if err := p.submit(ctx, ctx, key, func(context.Context) {
+			started.Done()
+			<-release
+		}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+
+	waited := make(chan struct{})
+	go func() {
+		started.Wait()
+		close(waited)
+	}()
+	select {
+	case <-waited:
+	case <-time.After(2 * time.Second):
+		t.Fatal("jobs for distinct keys did not run concurrently")
+	}
+	close(release)
+	p.drain()
+}
+
+// Cancelling the submitting context must not cancel jobs already handed over:
+// drain waits for them to finish.
+func TestPool_DrainWaitsForInFlightJobs(t *testing.T) {
+	p := testPool(PoolConfig{Workers: 2, QueueDepth: 2, DrainTimeout: 5 * time.Second})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var finished atomic.Int32
+	for i := 0; i < 4; i++ {
+		if err := p.submit(ctx, ctx, fmt.Sprintf("res-%d", i), func(jobCtx context.Context) {
+			select {
+			case <-time.After(20 * time.Millisecond):
+				finished.Add(1)
+			case <-jobCtx.Done():
+			}
+		}); err != nil {
+			t.Fatalf("submit: %v", err)
+		}
+	}
+	cancel()
+	p.drain()
+
+	if n := finished.Load(); n != 4 {
+		t.Fatalf("expected all 4 jobs to finish, got %d", n)
+	}
+}
+
+// A job still running at the drain timeout has its context cancelled.
+func TestPool_DrainTimeoutCancelsJobs(t *testing.T) {
+	p := testPool(PoolConfig{Workers: 1, DrainTimeout: 20 * time.Millisecond})
+	ctx := context.Background()
+
+	cancelled := make(chan struct{})
+	if err := p.submit(ctx, ctx, "res-1", func(jobCtx context.Context) {
+		<-jobCtx.Done()
+		close(cancelled)
+	}); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	drained := make(chan struct{})
+	go func() {
+		p.drain()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+	case <-time.After(2 * time.Second):
+		t.Fatal("drain did not return after its timeout")
+	}
+	select {
+	case <-cancelled:
+	default:
+		t.Fatal("job context was not cancelled")
+	}
+}
+
+// submit gives up when its context ends while the worker's queue is full.
+func TestPool_SubmitHonoursContextWhenQueueFull(t *testing.T) {
+	p := testPool(PoolConfig{Workers: 1})
+	release := make(chan struct{})
+	bg := context.Background()
+	if err := p.submit(bg, bg, "res-1", func(context.Context) { <-release }); err != nil {
+		t.Fatalf("submit: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(bg, 20*time.Millisecond)
+	defer cancel()
+	// The worker may not have taken the first job yet, so the queue has room
+	// for at most one more before submit must block.
+	err := p.submit(ctx, bg, "res-1", func(context.Context) {})
+	if err == nil {
+		err = p.submit(ctx, bg, "res-1", func(context.Context) {})
+	}
+	if err == nil {
+		t.Fatal("expected submit to fail once its context ended")
+	}
+
+	close(release)
+	p.drain()
+}
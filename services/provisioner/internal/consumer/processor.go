@@ -0,0 +1,198 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/driver"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/events"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
+)
+
+// Handler processes one message body. Both consume loops acknowledge a message
+// (commit the offset / delete it from SQS) only after Handle returns nil.
+type Handler interface {
+	Handle(ctx context.Context, body []byte) error
+}
+
+// Processor is the Handler the provisioner runs: it decodes the API's
+// provisioning request, persists it, marks it in progress and, when a
+// dispatcher is configured, provisions it through the matching cloud driver
+// before the message is acknowledged.
+type Processor struct {
+	repo       repository.Repository
+	dispatcher *driver.Dispatcher
+	publisher  events.Publisher
+	log        logger.Logger
+}
+
+var _ Handler = (*Processor)(nil)
+
+// NewProcessor creates a Processor writing through the given repository,
+// provisioning through dispatcher and announcing status changes on publisher.
+// A nil dispatcher leaves requests in progress for something else to pick up;
+// a nil publisher emits nothing.
+func NewProcessor(repo repository.Repository, dispatcher *driver.Dispatcher, publisher events.Publisher, log logger.Logger) *Processor {
+	if publisher == nil {
+		publisher = events.NopPublisher{}
+	}
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	return &Processor{repo: repo, dispatcher: dispatcher, publisher: publisher, log: log}
+}
+
+// Handle decodes, saves and provisions one provisioning request. A body that
+// cannot be decoded returns an error wrapping model.ErrMalformedMessage; a
+// message already processed returns one wrapping
+// repository.ErrDuplicateMessage; any other error is a persistence or driver
+// failure worth retrying.
+//
+// The inbox claim and the move to in progress are committed before the driver
+// runs, so a delivery that dies in between leaves a claimed message and an
+// unfinished request behind. A redelivery that finds its request unfinished
+// therefore resumes it rather than being dropped; drivers' Create is
+// idempotent, so running it again is safe.
+func (p *Processor) Handle(ctx context.Context, body []byte) (err error) {
+	resource, err := model.DecodeResource(ContentTypeFromContext(ctx), body)
+	if err != nil {
+		return err
+	}
+
+	messageID := MessageIDFromContext(ctx)
+	if err := p.save(ctx, messageID, resource); err != nil {
+		if !errors.Is(err, repository.ErrDuplicateMessage) {
+			return err
+		}
+		status, sErr := p.repo.ResourceStatus(ctx, resource.ID)
+		if sErr != nil {
+			return sErr
+		}
+		if status.IsFinal() {
+			p.log.WithContext(ctx).Info("duplicate message; skipping",
+				logger.F("resource_id", resource.ID),
+				logger.F("message_id", messageID),
+			)
+			return err
+		}
+		p.log.WithContext(ctx).Warn("redelivered message was not finished; resuming",
+			logger.F("resource_id", resource.ID),
+			logger.F("message_id", messageID),
+			logger.F("status", string(status)),
+		)
+	}
+	// The message is claimed in the inbox from here on. If handling fails it
+	// must be released, or its redelivery would be dropped as a duplicate.
+	defer func() {
+		if err != nil && messageID != "" {
+			p.release(ctx, messageID)
+		}
+	}()
+
+	p.log.WithContext(ctx).Info("resource persisted",
+		logger.F("resource_id", resource.ID),
+		logger.F("resource_type", resource.ResourceType),
+		logger.F("cloud_provider", resource.CloudProvider),
+	)
+
+	if err := p.transition(ctx, resource.ID, model.StatusInProgress, "accepted by provisioner"); err != nil {
+		if !errors.Is(err, model.ErrInvalidTransition) {
+			return err
+		}
+		// Either an earlier delivery finished the request, and this one is
+		// acknowledged, or it stopped after marking it in progress, and this
+		// one carries on from there. (A retrying request moves back to in
+		// progress above and is provisioned again.)
+		status, sErr := p.repo.ResourceStatus(ctx, resource.ID)
+		if sErr != nil {
+			return sErr
+		}
+		if status != model.StatusInProgress {
+			p.log.WithContext(ctx).Info("resource already finished; skipping",
+				logger.F("resource_id", resource.ID),
+				logger.F("status", string(status)),
+			)
+			return nil
+		}
+	}
+
+	if p.dispatcher == nil {
+		return nil
+	}
+	return p.provision(ctx, resource)
+}
+
+// provision runs an in-progress request through its driver and records the
+// outcome. A transient driver error moves the request to retrying and is
+// returned so the message is redelivered; the next delivery moves it back to
+// in progress and tries again.
+func (p *Processor) provision(ctx context.Context, resource model.Resource) error {
+	result, err := p.dispatcher.Provision(ctx, resource)
+	switch {
+	case err == nil && result.State == driver.StateReady:
+		p.log.WithContext(ctx).Info("resource provisioned",
+			logger.F("resource_id", resource.ID),
+			logger.F("provider_id", result.ProviderID),
+		)
+		return p.transition(ctx, resource.ID, model.StatusCompleted, "provisioned")
+	case err == nil:
+		return p.transition(ctx, resource.ID, model.StatusFailed, result.Reason)
+	case driver.IsPermanent(err):
+		p.log.WithContext(ctx).Warn("resource cannot be provisioned",
+			logger.F("resource_id", resource.ID),
+			logger.F("error", err.Error()),
+		)
+		return p.transition(ctx, resource.ID, model.StatusFailed, err.Error())
+	}
+
+	if tErr := p.transition(ctx, resource.ID, model.StatusRetrying, err.Error()); tErr != nil {
+		p.log.WithContext(ctx).Warn("failed to mark resource retrying",
+			logger.F("resource_id", resource.ID),
+			logger.F("error", tErr.Error()),
+		)
+	}
+	return err
+}
+
+// save persists the resource, claiming messageID in the inbox atomically with
+// it. Messages without an ID are saved without deduplication.
+func (p *Processor) save(ctx context.Context, messageID string, resource model.Resource) error {
+	if messageID == "" {
+		return p.repo.SaveResource(ctx, resource)
+	}
+	return p.repo.SaveResourceForMessage(ctx, messageID, resource)
+}
+
+// release drops a failed message's inbox claim so its redelivery is handled.
+// If that fails too, the redelivery still finds the request unfinished
+// (retrying) and resumes it.
+func (p *Processor) release(ctx context.Context, messageID string) {
+	if err := p.repo.ReleaseMessage(context.WithoutCancel(ctx), messageID); err != nil {
+		p.log.WithContext(ctx).Error("failed to release message claim",
+			logger.F("message_id", messageID),
+			logger.F("error", err.Error()),
+		)
+	}
+}
+
+// transition applies a status change through the repository and announces it.
+// The change is committed before the event is published, so a publish failure
+// cannot be retried by redelivering the message — it is logged and the
+// message still acknowledged.
+func (p *Processor) transition(ctx context.Context, id string, next model.Status, reason string) error {
+	t, err := p.repo.TransitionStatus(ctx, id, next, reason)
+	if err != nil {
+		return err
+	}
+
+	if err := p.publisher.PublishStatusChanged(ctx, model.NewResourceStatusChanged(id, t)); err != nil {
+		p.log.WithContext(ctx).Warn("failed to publish status change",
+			logger.F("resource_id", id),
+			logger.F("new_status", string(t.To)),
+			logger.F("error", err.Error()),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/segmentio/kafka-go"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/driver"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
+)
+
+const validBody = `{"id":"vm-001","resource_type":"VM","cloud_provider":"AWS","specification":"t2.micro","status":"pending","requested_by":"rafael"}`
+
+func TestProcessor_PersistsDecodedResource(t *testing.T) {
+	repo := repository.NewMemory()
+	p := NewProcessor(repo, nil, nil, nil)
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+
+	got, ok := repo.Get("vm-001")
+	if !ok {
+		t.Fatal("resource was not persisted")
+	}
+	if got.Specification != "t2.micro" || got.RequestedBy != "rafael" {
+		t.Errorf("persisted resource = %+v", got)
+	}
+}
+
+func TestProcessor_MalformedBodyIsNotPersisted(t *testing.T) {
+	repo := repository.NewMemory()
+	p := NewProcessor(repo, nil, nil, nil)
+
+	for _, body := range []string{"not json", `{"resource_type":"VM","cloud_provider":"AWS"}`} {
+		err := p.Handle(context.Background(), []byte(body))
+		if !errors.Is(err, model.ErrMalformedMessage) {
+			t.Errorf("Handle(%q) = %v, want ErrMalformedMessage", body, err)
+		}
+	}
+	if repo.Len() != 0 {
+		t.Errorf("repository holds %d resources, want 0", repo.Len())
+	}
+}
+
+// A persistence failure must surface as a retryable (non-malformed) error so
+// the consume loop withholds the ack.
+func TestProcessor_RepositoryErrorIsRetryable(t *testing.T) {
+	repo := repository.NewMemory()
+	repo.Err = errors.New("connection refused")
+	p := NewProcessor(repo, nil, nil, nil)
+
+	err := p.Handle(context.Background(), []byte(validBody))
+	if err == nil {
+		t.Fatal("expected an error when the repository fails")
+	}
+	if errors.Is(err, model.ErrMalformedMessage) {
+		t.Error("repository failure must not be classified as malformed")
+	}
+}
+
+func TestProcessor_MarksResourceInProgress(t *testing.T) {
+	repo := repository.NewMemory()
+	p := NewProcessor(repo, nil, nil, nil)
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+
+	got, _ := repo.Get("vm-001")
+	if got.Status != string(model.StatusInProgress) {
+		t.Errorf("status = %q, want in_progress", got.Status)
+	}
+	history := repo.History("vm-001")
+	if len(history) != 1 || history[0].From != model.StatusPending || history[0].To != model.StatusInProgress {
+		t.Errorf("history = %+v, want one pending -> in_progress entry", history)
+	}
+}
+
+// A redelivery of an already-processed message must be acknowledged without
+// a second transition.
+func TestProcessor_RedeliveryIsAcknowledged(t *testing.T) {
+	repo := repository.NewMemory()
+	p := NewProcessor(repo, nil, nil, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+			t.Fatalf("delivery %d: Handle returned error: %v", i+1, err)
+		}
+	}
+	if got := len(repo.History("vm-001")); got != 1 {
+		t.Errorf("history has %d entries, want 1", got)
+	}
+}
+
+type recordingPublisher struct {
+	events []model.ResourceStatusChanged
+	err    error
+}
+
+func (r *recordingPublisher) PublishStatusChanged(_ context.Context, e model.ResourceStatusChanged) error {
+	r.events = append(r.events, e)
+	return r.err
+}
+
+func TestProcessor_PublishesStatusChange(t *testing.T) {
+	publisher := &recordingPublisher{}
+	p := NewProcessor(repository.NewMemory(), nil, publisher, nil)
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+
+	if len(publisher.events) != 1 {
+		t.Fatalf("published %d events, want 1", len(publisher.events))
+	}
+	e := publisher.events[0]
+	if e.EventType != model.EventTypeResourceStatusChanged || e.ResourceID != "vm-001" ||
+		e.OldStatus != model.StatusPending || e.NewStatus != model.StatusInProgress {
+		t.Errorf("event = %+v", e)
+	}
+}
+
+// The transition is already committed when publishing fails, so the message
+// is still acknowledged.
+func TestProcessor_PublishFailureDoesNotFailMessage(t *testing.T) {
+	publisher := &recordingPublisher{err: errors.New("broker down")}
+	p := NewProcessor(repository.NewMemory(), nil, publisher, nil)
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+}
+
+func fakeDispatcher() *driver.Dispatcher {
+	registry := driver.NewRegistry()
+	driver.RegisterFake(registry)
+	return driver.NewDispatcher(registry, nil)
+}
+
+func statuses(history []model.Transition) []model.Status {
+	out := make([]model.Status, len(history))
+	for i, t := range history {
+		out[i] = t.To
+	}
+	return out
+}
+
+func TestProcessor_ProvisionsThroughDriver(t *testing.T) {
+	repo := repository.NewMemory()
+	publisher := &recordingPublisher{}
+	p := NewProcessor(repo, fakeDispatcher(), publisher, nil)
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+
+	got, _ := repo.Get("vm-001")
+	if got.Status != string(model.StatusCompleted) {
+		t.Errorf("status = %q, want completed", got.Status)
+	}
+	if len(publisher.events) != 2 || publisher.events[1].NewStatus != model.StatusCompleted {
+		t.Errorf("events = %+v, want in_progress then completed", publisher.events)
+	}
+}
+
+func TestProcessor_DriverFailureMarksFailed(t *testing.T) {
+	repo := repository.NewMemory()
+	p := NewProcessor(repo, fakeDispatcher(), nil, nil)
+	body := `{"id":"vm-002","resource_type":"VM","cloud_provider":"AWS","specification":"` + driver.FailMarker + `"}`
+
+	if err := p.Handle(context.Background(), []byte(body)); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+	got, _ := repo.Get("vm-002")
+	if got.Status != string(model.StatusFailed) {
+		t.Errorf("status = %q, want failed", got.Status)
+	}
+}
+
+// No driver for the pair can never succeed: the request fails and the
+// message is acknowledged rather than retried.
+func TestProcessor_UnsupportedPairMarksFailed(t *testing.T) {
+	repo := repository.NewMemory()
+	p := NewProcessor(repo, driver.NewDispatcher(driver.NewRegistry(), nil), nil, nil)
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+		t.Fatalf("Handle returned error: %v", err)
+	}
+	got, _ := repo.Get("vm-001")
+	if got.Status != string(model.StatusFailed) {
+		t.Errorf("status = %q, want failed", got.Status)
+	}
+}
+
+// flakyDriver fails Create with a transient error the first fails times.
+type flakyDriver struct {
+	*driver.Fake
+	fails int
+}
+
+func (f *flakyDriver) Create(ctx context.Context, r model.Resource) (driver.Result, error) {
+	if f.fails > 0 {
+		f.fails--
+		return driver.Result{}, errors.New("throttled")
+	}
+	return f.Fake.Create(ctx, r)
+}
+
+// A transient driver error parks the request in retrying and fails the
+// message; the redelivery takes it back through in_progress to completed.
+func TestProcessor_TransientDriverErrorRetries(t *testing.T) {
+	repo := repository.NewMemory()
+	registry := driver.NewRegistry()
+	registry.Register(driver.ProviderAWS, driver.ResourceTypeVM, &flakyDriver{Fake: driver.NewFake(), fails: 1})
+	p := NewProcessor(repo, driver.NewDispatcher(registry, nil), nil, nil)
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err == nil {
+		t.Fatal("expected the transient driver error to be returned")
+	}
+	if got, _ := repo.Get("vm-001"); got.Status != string(model.StatusRetrying) {
+		t.Fatalf("status = %q, want retrying", got.Status)
+	}
+
+	if err := p.Handle(context.Background(), []byte(validBody)); err != nil {
+		t.Fatalf("redelivery: Handle returned error: %v", err)
+	}
+	want := []model.Status{model.StatusInProgress, model.StatusRetrying, model.StatusInProgress, model.StatusCompleted}
+	if got := statuses(repo.History("vm-001")); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("history = %v, want %v", got, want)
+	}
+}
+
+// A redelivered message carrying an already-processed ID is dropped before it
+// reaches the driver.
+func TestProcessor_DropsDuplicateMessage(t *testing.T) {
+	repo := repository.NewMemory()
+	p := NewProcessor(repo, fakeDispatcher(), nil, nil)
+	ctx := WithMessageID(context.Background(), "msg-1")
+
+	if err := p.Handle(ctx, []byte(validBody)); err != nil {
+		t.Fatalf("first delivery: Handle returned error: %v", err)
+	}
+	err := p.Handle(ctx, []byte(validBody))
+	if !errors.Is(err, repository.ErrDuplicateMessage) {
+		t.Fatalf("redelivery: Handle = %v, want ErrDuplicateMessage", err)
+	}
+	if got := len(repo.History("vm-001")); got != 2 {
+		t.Errorf("history has %d entries, want 2 (in_progress, completed)", got)
+	}
+}
+
+// A delivery that crashes after claiming its message leaves the claim and an
+// unfinished request behind. The redelivery must finish the request rather
+// than be dropped as a duplicate or skipped as already picked up.
+func TestProcessor_ResumesAfterCrash(t *testing.T) {
+	cases := map[string][]model.Status{
+		"before marking in progress": nil,
+		"before calling the driver":  {model.StatusInProgress},
+	}
+	for name, before := range cases {
+		t.Run(name, func(t *testing.T) {
+			repo := repository.NewMemory()
+			ctx := WithMessageID(context.Background(), "msg-1")
+			resource, err := model.DecodeResource("", []byte(validBody))
+			if err != nil {
+				t.Fatalf("DecodeResource: %v", err)
+			}
+			// What the crashed delivery committed.
+			if err := repo.SaveResourceForMessage(ctx, "msg-1", resource); err != nil {
+				t.Fatalf("SaveResourceForMessage: %v", err)
+			}
+			for _, status := range before {
+				if _, err := repo.TransitionStatus(ctx, resource.ID, status, "accepted by provisioner"); err != nil {
+					t.Fatalf("TransitionStatus: %v", err)
+				}
+			}
+
+			p := NewProcessor(repo, fakeDispatcher(), nil, nil)
+			if err := p.Handle(ctx, []byte(validBody)); err != nil {
+				t.Fatalf("redelivery: Handle returned error: %v", err)
+			}
+
+			want := []model.Status{model.StatusInProgress, model.StatusCompleted}
+			if got := statuses(repo.History("vm-001")); fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("history = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// A message whose handling fails gives up its inbox claim, so the redelivery
+// is processed rather than dropped.
+func TestProcessor_FailedMessageIsReleased(t *testing.T) {
+	repo := repository.NewMemory()
+	registry := driver.NewRegistry()
+	registry.Register(driver.ProviderAWS, driver.ResourceTypeVM, &flakyDriver{Fake: driver.NewFake(), fails: 1})
+	p := NewProcessor(repo, driver.NewDispatcher(registry, nil), nil, nil)
+	ctx := WithMessageID(context.Background(), "msg-1")
+
+	if err := p.Handle(ctx, []byte(validBody)); err == nil {
+		t.Fatal("expected the transient driver error to be returned")
+	}
+	if err := p.Handle(ctx, []byte(validBody)); err != nil {
+		t.Fatalf("redelivery: Handle returned error: %v", err)
+	}
+	if got, _ := repo.Get("vm-001"); got.Status != string(model.StatusCompleted) {
+		t.Errorf("status = %q, want completed", got.Status)
+	}
+}
+
+func TestMessageID_FallsBackToTransportIDs(t *testing.T) {
+	stamped := kafka.Message{Headers: []kafka.Header{{Key: MessageIDHeader, Value: []byte("msg-1")}}}
+	if got := kafkaMessageID(stamped); got != "msg-1" {
+		t.Errorf("kafkaMessageID = %q, want msg-1", got)
+	}
+	if got := kafkaMessageID(kafka.Message{Topic: "t", Partition: 2, Offset: 7}); got != "t/2/7" {
+		t.Errorf("kafkaMessageID fallback = %q, want t/2/7", got)
+	}
+	if got := sqsMessageID(sqstypes.Message{MessageId: aws.String("sqs-1")}); got != "sqs-1" {
+		t.Errorf("sqsMessageID fallback = %q, want sqs-1", got)
+	}
+}
+
+func TestSQSBody_DecodesBinaryFormats(t *testing.T) {
+	protobuf := sqstypes.Message{
+		Body:              aws.String("AQID"),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{ContentTypeHeader: stringAttribute(model.ContentTypeProtobuf)},
+	}
+	if got := sqsBody(protobuf); string(got) != "\x01\x02\x03" {
+		t.Errorf("sqsBody(protobuf) = %q, want base64-decoded bytes", got)
+	}
+	if got := sqsBody(sqstypes.Message{Body: aws.String(validBody)}); string(got) != validBody {
+		t.Errorf("sqsBody(json) = %q, want the body unchanged", got)
+	}
+}
+
+func TestSQSKey_IsTheEnvelopeSubject(t *testing.T) {
+	enveloped := `{"specversion":"1.0","id":"evt-1","type":"` + model.EventTypeResourceProvisionRequested + `","source":"/api","subject":"vm-001","data":{}}`
+	cases := map[string]sqstypes.Message{
+		"enveloped": {Body: aws.String(enveloped), MessageId: aws.String("sqs-1")},
+		"legacy":    {Body: aws.String(validBody), MessageId: aws.String("sqs-1")},
+	}
+	for name, message := range cases {
+		if got := sqsKey(message); got != "vm-001" {
+			t.Errorf("%s: sqsKey = %q, want vm-001", name, got)
+		}
+	}
+	if got := sqsKey(sqstypes.Message{Body: aws.String("not json"), MessageId: aws.String("sqs-1")}); got != "sqs-1" {
+		t.Errorf("sqsKey of a malformed body = %q, want sqs-1", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/nats-io/nats.go"
 	"github.com/segmentio/kafka-go"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
@@ -28,6 +29,12 @@ func extractSQS(ctx context.Context, attrs map[string]sqstypes.MessageAttributeV
 	return otel.GetTextMapPropagator().Extract(ctx, sqsAttributeCarrier(attrs))
 }
 
+// extractNATS returns a context carrying the trace context found in a NATS
+// message's headers. Missing/empty headers yield the parent context unchanged.
+func extractNATS(ctx context.Context, headers nats.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, natsHeaderCarrier(headers))
+}
+
 // kafkaHeaderCarrier is a read-only TextMapCarrier over Kafka message headers.
 type kafkaHeaderCarrier []kafka.Header
 
@@ -77,3 +84,25 @@ func (c sqsAttributeCarrier) Keys() []string {
 // Set is unused on the consume side (extraction only) but required by the
 // interface.
 func (c sqsAttributeCarrier) Set(string, string) {}
+
+// natsHeaderCarrier is a read-only TextMapCarrier over NATS message headers.
+// Like Kafka's, NATS headers are case-sensitive.
+type natsHeaderCarrier nats.Header
+
+var _ propagation.TextMapCarrier = natsHeaderCarrier(nil)
+
+func (c natsHeaderCarrier) Get(key string) string {
+	return nats.Header(c).Get(key)
+}
+
+func (c natsHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// Set is unused on the consume side (extraction only) but required by the
+// interface.
+func (c natsHeaderCarrier) Set(string, string) {}
@@ -0,0 +1,69 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
+)
+
+// RetryPolicy bounds how often a message is re-handled in-process before it is
+// given up on. Backoff doubles from InitialBackoff up to MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is three attempts with 200ms, then 400ms, between them.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, InitialBackoff: 200 * time.Millisecond, MaxBackoff: 5 * time.Second}
+}
+
+// backoff returns the wait before the given retry (1 = first retry).
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < retry && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	return d
+}
+
+// handleWithRetry runs the handler until it succeeds, fails with a malformed
+// or duplicate message (which retrying cannot change), or MaxAttempts is
+// reached. It returns
+// the last error and the number of attempts made. A cancelled context stops
+// the retries and returns the context's error.
+func handleWithRetry(ctx context.Context, handler Handler, body []byte, policy RetryPolicy, log logger.Logger) (int, error) {
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = handler.Handle(ctx, body)
+		if err == nil || errors.Is(err, model.ErrMalformedMessage) ||
+			errors.Is(err, repository.ErrDuplicateMessage) || attempt == maxAttempts {
+			return attempt, err
+		}
+
+		wait := policy.backoff(attempt)
+		log.WithContext(ctx).Warn("message processing failed; retrying",
+			logger.F("attempt", attempt),
+			logger.F("backoff", wait.String()),
+			logger.F("error", err.Error()),
+		)
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
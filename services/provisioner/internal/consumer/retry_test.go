@@ -0,0 +1,93 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
+)
+
+// flakyHandler fails the first failures calls with err, then succeeds.
+type flakyHandler struct {
+	failures int
+	err      error
+	calls    int
+}
+
+func (h *flakyHandler) Handle(context.Context, []byte) error {
+	h.calls++
+	if h.calls <= h.failures {
+		return h.err
+	}
+	return nil
+}
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+func TestHandleWithRetry_RecoversFromTransientFailure(t *testing.T) {
+	h := &flakyHandler{failures: 2, err: errors.New("connection reset")}
+
+	attempts, err := handleWithRetry(context.Background(), h, nil, fastRetry, logger.NopLogger{})
+
+	if err != nil || attempts != 3 {
+		t.Errorf("got attempts=%d err=%v, want 3 attempts and success", attempts, err)
+	}
+}
+
+func TestHandleWithRetry_GivesUpAfterMaxAttempts(t *testing.T) {
+	h := &flakyHandler{failures: 10, err: errors.New("connection reset")}
+
+	attempts, err := handleWithRetry(context.Background(), h, nil, fastRetry, logger.NopLogger{})
+
+	if err == nil || attempts != 3 || h.calls != 3 {
+		t.Errorf("got attempts=%d calls=%d err=%v, want 3 attempts and an error", attempts, h.calls, err)
+	}
+}
+
+func TestHandleWithRetry_DoesNotRetryMalformed(t *testing.T) {
+	h := &flakyHandler{failures: 10, err: fmt.Errorf("%w: missing id", model.ErrMalformedMessage)}
+
+	attempts, err := handleWithRetry(context.Background(), h, nil, fastRetry, logger.NopLogger{})
+
+	if !errors.Is(err, model.ErrMalformedMessage) || attempts != 1 {
+		t.Errorf("got attempts=%d err=%v, want 1 attempt and ErrMalformedMessage", attempts, err)
+	}
+}
+
+func TestHandleWithRetry_StopsOnCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h := &flakyHandler{failures: 10, err: errors.New("connection reset")}
+	slow := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Hour, MaxBackoff: time.Hour}
+
+	_, err := handleWithRetry(ctx, h, nil, slow, logger.NopLogger{})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRetryPolicy_BackoffDoublesUpToMax(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond}
+
+	for retry, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 3: 300 * time.Millisecond, 8: 300 * time.Millisecond} {
+		if got := p.backoff(retry); got != want {
+			t.Errorf("backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+}
+
+func TestHandleWithRetry_DoesNotRetryDuplicate(t *testing.T) {
+	h := &flakyHandler{failures: 10, err: fmt.Errorf("%w: msg-1", repository.ErrDuplicateMessage)}
+
+	attempts, err := handleWithRetry(context.Background(), h, nil, fastRetry, logger.NopLogger{})
+
+	if !errors.Is(err, repository.ErrDuplicateMessage) || attempts != 1 {
+		t.Errorf("got attempts=%d err=%v, want 1 attempt and ErrDuplicateMessage", attempts, err)
+	}
+}
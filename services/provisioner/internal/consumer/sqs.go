@@ -2,29 +2,68 @@ package consumer
 
 import (
 	"context"
+	"errors"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/repository"
 )
 
-// RunSQS long-polls the queue and deletes each message after processing
-// (at-least-once) until the context is cancelled.
-func RunSQS(ctx context.Context, client *sqs.Client, queueURL string, tracer trace.Tracer, metrics Metrics, log logger.Logger) error {
-	log.WithContext(ctx).Info("polling messages from SQS queue", logger.F("queue_url", queueURL))
+// SQSConfig configures the SQS consumer.
+type SQSConfig struct {
+	QueueURL string
+	// DLQURL is the queue's redrive target. Messages are moved there
+	// explicitly when malformed, or when they fail on their MaxReceives-th
+	// delivery. When empty, poison messages are left for the queue's own
+	// redrive policy to move.
+	DLQURL string
+	// MaxReceives should match the redrive policy's maxReceiveCount.
+	MaxReceives int
+	Retry       RetryPolicy
+	Pool        PoolConfig
+}
+
+// RunSQS long-polls the queue and deletes each message after the handler
+// succeeds (at-least-once) until the context is cancelled. Received messages
+// are processed concurrently on a worker pool keyed by resource ID, so one
+// resource's messages are still handled in order. A message that still fails
+// after cfg.Retry is left on the queue and redelivered once its visibility
+// timeout lapses; on its last allowed delivery, or straight away if it is
+// malformed, it is moved to the dead-letter queue.
+//
+// On cancellation polling stops and in-flight messages are drained per
+// cfg.Pool before RunSQS returns.
+func RunSQS(ctx context.Context, client *sqs.Client, cfg SQSConfig, handler Handler, tracer trace.Tracer, metrics Metrics, log logger.Logger) error {
+	log.WithContext(ctx).Info("polling messages from SQS queue",
+		logger.F("queue_url", cfg.QueueURL),
+		logger.F("dlq_url", cfg.DLQURL),
+		logger.F("workers", cfg.Pool.Workers),
+	)
+
+	workers := newPool(cfg.Pool, metrics.InFlight)
+	defer workers.drain()
 
 	for ctx.Err() == nil {
 		pollCtx, pollSpan := tracer.Start(ctx, "PollSQSMessages")
 
 		output, err := client.ReceiveMessage(pollCtx, &sqs.ReceiveMessageInput{
-			QueueUrl:            aws.String(queueURL),
+			QueueUrl:            aws.String(cfg.QueueURL),
 			MaxNumberOfMessages: 5,
 			WaitTimeSeconds:     10,
 			// Ask SQS to return the trace-context attributes the API injected on
 			// publish; without this they are dropped and the trace breaks.
 			MessageAttributeNames: []string{"All"},
+			MessageSystemAttributeNames: []sqstypes.MessageSystemAttributeName{
+				sqstypes.MessageSystemAttributeNameApproximateReceiveCount,
+				// Kept on the dead-letter copy when the DLQ is FIFO.
+				sqstypes.MessageSystemAttributeNameMessageGroupId,
+			},
 		})
 		if err != nil {
 			pollSpan.RecordError(err)
@@ -43,26 +82,16 @@ func RunSQS(ctx context.Context, client *sqs.Client, queueURL string, tracer tra
 			// message attributes becomes the parent of ProcessMessage, so the
 			// whole provisioning flow is one distributed trace and the logs
 			// below share the API's trace_id.
-			msgCtx := extractSQS(pollCtx, message.MessageAttributes)
-			processCtx, span := tracer.Start(msgCtx, "ProcessMessage")
-			log.WithContext(processCtx).Info("received message", logger.F("body", aws.ToString(message.Body)))
-
-			// Save message data in RDS
-
-			// Delete the message after processing.
-			_, err := client.DeleteMessage(processCtx, &sqs.DeleteMessageInput{
-				QueueUrl:      aws.String(queueURL),
-				ReceiptHandle: message.ReceiptHandle,
+			msgCtx := WithMessageID(extractSQS(pollCtx, message.MessageAttributes), sqsMessageID(message))
+			msgCtx = WithContentType(msgCtx, sqsAttributeCarrier(message.MessageAttributes).Get(ContentTypeHeader))
+			err := workers.submit(ctx, msgCtx, sqsKey(message), func(jobCtx context.Context) {
+				processSQSMessage(jobCtx, client, cfg, message, handler, tracer, metrics, log)
 			})
 			if err != nil {
-				metrics.Failed.Add(processCtx, 1)
-				span.RecordError(err)
-				log.WithContext(processCtx).Error("failed to delete message", logger.F("error", err.Error()))
-			} else {
-				metrics.Processed.Add(processCtx, 1)
-				log.WithContext(processCtx).Info("message deleted")
+				// Shutting down: messages not yet handed to a worker simply
+				// become visible again once their visibility timeout lapses.
+				break
 			}
-			span.End()
 		}
 
 		pollSpan.End()
@@ -70,3 +99,82 @@ func RunSQS(ctx context.Context, client *sqs.Client, queueURL string, tracer tra
 
 	return ctx.Err()
 }
+
+// processSQSMessage handles one message and deletes it once processed or
+// dead-lettered. On failure the message is left for redelivery.
+func processSQSMessage(ctx context.Context, client *sqs.Client, cfg SQSConfig, message sqstypes.Message, handler Handler, tracer trace.Tracer, metrics Metrics, log logger.Logger) {
+	processCtx, span := tracer.Start(ctx, "ProcessMessage")
+	defer span.End()
+	log.WithContext(processCtx).Info("received message", logger.F("body", aws.ToString(message.Body)))
+
+	attempts, err := handleWithRetry(processCtx, handler, sqsBody(message), cfg.Retry, log)
+	if errors.Is(err, repository.ErrDuplicateMessage) {
+		// Already processed on an earlier delivery: just delete it.
+		metrics.Duplicates.Add(processCtx, 1)
+		err = nil
+	}
+	if err != nil {
+		metrics.Failed.Add(processCtx, 1)
+		span.RecordError(err)
+		if !shouldDeadLetter(err, receiveCount(message), cfg) {
+			log.WithContext(processCtx).Error("failed to process message; leaving it for redelivery", logger.F("error", err.Error()))
+			return
+		}
+
+		log.WithContext(processCtx).Error("dead-lettering message",
+			logger.F("attempts", attempts),
+			logger.F("receive_count", receiveCount(message)),
+			logger.F("error", err.Error()),
+		)
+		if dlqErr := sqsDeadLetter(processCtx, client, cfg.DLQURL, message, err, attempts); dlqErr != nil {
+			span.RecordError(dlqErr)
+			log.WithContext(processCtx).Error("failed to dead-letter message; leaving it for redelivery", logger.F("error", dlqErr.Error()))
+			return
+		}
+		metrics.DeadLettered.Add(processCtx, 1)
+	}
+
+	// Delete the message after processing or dead-lettering.
+	_, err = client.DeleteMessage(processCtx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(cfg.QueueURL),
+		ReceiptHandle: message.ReceiptHandle,
+	})
+	if err != nil {
+		metrics.Failed.Add(processCtx, 1)
+		span.RecordError(err)
+		log.WithContext(processCtx).Error("failed to delete message", logger.F("error", err.Error()))
+		return
+	}
+	metrics.Processed.Add(processCtx, 1)
+	log.WithContext(processCtx).Info("message deleted")
+}
+
+// sqsKey is the pool key for a message: the resource ID its envelope names as
+// subject, or its SQS message ID when the body does not decode (it will be
+// dead-lettered as malformed anyway).
+func sqsKey(message sqstypes.Message) string {
+	contentType := sqsAttributeCarrier(message.MessageAttributes).Get(ContentTypeHeader)
+	if e, err := model.DecodeEnvelope(contentType, sqsBody(message)); err == nil && e.Subject != "" {
+		return e.Subject
+	}
+	return aws.ToString(message.MessageId)
+}
+
+// shouldDeadLetter reports whether a failed message should be moved to the
+// DLQ now rather than left for redelivery. Without a DLQ URL nothing is moved
+// explicitly; the queue's redrive policy is the backstop.
+func shouldDeadLetter(err error, receives int, cfg SQSConfig) bool {
+	if cfg.DLQURL == "" || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, model.ErrMalformedMessage) {
+		return true
+	}
+	return cfg.MaxReceives > 0 && receives >= cfg.MaxReceives
+}
+
+// receiveCount reads SQS's ApproximateReceiveCount, or 0 if absent.
+func receiveCount(message sqstypes.Message) int {
+	n, _ := strconv.Atoi(message.Attributes[string(sqstypes.MessageSystemAttributeNameApproximateReceiveCount)])
+	return n
+}
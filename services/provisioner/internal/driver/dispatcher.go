@@ -0,0 +1,100 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/logger"
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// Default polling for drivers that create asynchronously.
+const (
+	DefaultPollInterval = 5 * time.Second
+	DefaultPollTimeout  = 10 * time.Minute
+)
+
+// Dispatcher runs provisioning requests through the registered drivers.
+type Dispatcher struct {
+	registry *Registry
+	log      logger.Logger
+
+	// PollInterval and PollTimeout bound how Provision waits on a driver
+	// that reports StateCreating.
+	PollInterval time.Duration
+	PollTimeout  time.Duration
+}
+
+// NewDispatcher creates a Dispatcher over registry with the default polling.
+func NewDispatcher(registry *Registry, log logger.Logger) *Dispatcher {
+	if log == nil {
+		log = logger.NopLogger{}
+	}
+	return &Dispatcher{
+		registry:     registry,
+		log:          log,
+		PollInterval: DefaultPollInterval,
+		PollTimeout:  DefaultPollTimeout,
+	}
+}
+
+// Provision creates the resource and waits until its driver reports it ready
+// or failed. A StateFailed result is the provider's verdict and comes back
+// with a nil error; a non-nil error means the outcome is unknown, and is
+// permanent (see IsPermanent) only if retrying cannot help.
+func (d *Dispatcher) Provision(ctx context.Context, r model.Resource) (Result, error) {
+	drv, err := d.registry.Lookup(r)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result, err := drv.Create(ctx, r)
+	if err != nil {
+		return Result{}, fmt.Errorf("create %s: %w", r.ID, err)
+	}
+	if result.State != StateCreating {
+		return result, nil
+	}
+
+	d.log.WithContext(ctx).Info("waiting for resource to become ready",
+		logger.F("resource_id", r.ID),
+		logger.F("provider_id", result.ProviderID),
+	)
+	ctx, cancel := context.WithTimeout(ctx, d.PollTimeout)
+	defer cancel()
+	ticker := time.NewTicker(d.PollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return result, fmt.Errorf("wait for %s: %w", r.ID, ctx.Err())
+		case <-ticker.C:
+		}
+		result, err = drv.Describe(ctx, r)
+		if err != nil {
+			return Result{}, fmt.Errorf("describe %s: %w", r.ID, err)
+		}
+		if result.State != StateCreating {
+			return result, nil
+		}
+	}
+}
+
+// Describe reports on a resource through its driver.
+func (d *Dispatcher) Describe(ctx context.Context, r model.Resource) (Result, error) {
+	drv, err := d.registry.Lookup(r)
+	if err != nil {
+		return Result{}, err
+	}
+	return drv.Describe(ctx, r)
+}
+
+// Delete removes a resource through its driver.
+func (d *Dispatcher) Delete(ctx context.Context, r model.Resource) error {
+	drv, err := d.registry.Lookup(r)
+	if err != nil {
+		return err
+	}
+	return drv.Delete(ctx, r)
+}
@@ -0,0 +1,107 @@
+// Package driver is the seam between the provisioner and the clouds it
+// provisions into. A Driver creates, describes and deletes one resource type
+// on one provider; the Registry maps (CloudProvider, ResourceType) pairs to
+// drivers and the Dispatcher runs a provisioning request through the right one.
+package driver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// CloudProvider names a provider as the API publishes it.
+type CloudProvider string
+
+// Providers the API accepts.
+const (
+	ProviderAWS   CloudProvider = "AWS"
+	ProviderAzure CloudProvider = "AZURE"
+	ProviderGCP   CloudProvider = "GCP"
+)
+
+// ResourceType names a resource type as the API publishes it.
+type ResourceType string
+
+// Resource types the API accepts.
+const (
+	ResourceTypeVM     ResourceType = "VM"
+	ResourceTypeRDS    ResourceType = "RDS"
+	ResourceTypeS3     ResourceType = "S3"
+	ResourceTypeLambda ResourceType = "Lambda"
+	ResourceTypeVPC    ResourceType = "VPC"
+	ResourceTypeELB    ResourceType = "ELB"
+)
+
+// Providers lists every provider the API accepts.
+func Providers() []CloudProvider {
+	return []CloudProvider{ProviderAWS, ProviderAzure, ProviderGCP}
+}
+
+// ResourceTypes lists every resource type the API accepts.
+func ResourceTypes() []ResourceType {
+	return []ResourceType{
+		ResourceTypeVM, ResourceTypeRDS, ResourceTypeS3,
+		ResourceTypeLambda, ResourceTypeVPC, ResourceTypeELB,
+	}
+}
+
+// State is where a resource stands on its provider.
+type State string
+
+const (
+	// StateCreating means the provider accepted the request but the
+	// resource is not usable yet; poll Describe for the outcome.
+	StateCreating State = "creating"
+	// StateReady means the resource exists and is usable.
+	StateReady State = "ready"
+	// StateFailed means the provider gave up on the resource. Result.Reason
+	// says why.
+	StateFailed State = "failed"
+)
+
+// Result is a driver's report on one resource.
+type Result struct {
+	State State
+	// ProviderID is the provider's identifier for the resource (an ARN,
+	// an Azure resource ID, a GCP self link).
+	ProviderID string
+	// Outputs are the connection details callers need, e.g. an endpoint.
+	Outputs map[string]string
+	Reason  string
+}
+
+// Driver provisions one resource type on one provider. Implementations must
+// be safe for concurrent use and Create must be idempotent on the resource
+// ID: a redelivered request calls it again.
+type Driver interface {
+	Create(ctx context.Context, r model.Resource) (Result, error)
+	Describe(ctx context.Context, r model.Resource) (Result, error)
+	Delete(ctx context.Context, r model.Resource) error
+}
+
+var (
+	// ErrUnsupported is returned when no driver is registered for a
+	// resource's provider and type.
+	ErrUnsupported = errors.New("no driver for provider and resource type")
+	// ErrInvalidSpecification is returned by drivers for a specification
+	// they cannot act on.
+	ErrInvalidSpecification = errors.New("invalid resource specification")
+	// ErrNotFound is returned by Describe and Delete for a resource the
+	// provider does not know.
+	ErrNotFound = errors.New("resource not found on provider")
+)
+
+// IsPermanent reports whether err means the request can never succeed as
+// sent, so retrying it is pointless.
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrUnsupported) || errors.Is(err, ErrInvalidSpecification)
+}
+
+// keyOf normalizes a resource's provider and type the way the API does.
+func keyOf(r model.Resource) (CloudProvider, ResourceType) {
+	return CloudProvider(strings.ToUpper(strings.TrimSpace(r.CloudProvider))),
+		ResourceType(strings.TrimSpace(r.ResourceType))
+}
@@ -0,0 +1,146 @@
+package driver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+func resource(provider, resourceType, spec string) model.Resource {
+	return model.Resource{ID: "res-1", CloudProvider: provider, ResourceType: resourceType, Specification: spec}
+}
+
+func TestRegistry_LookupNormalizesProvider(t *testing.T) {
+	r := NewRegistry()
+	f := NewFake()
+	r.Register(ProviderAzure, ResourceTypeVM, f)
+
+	got, err := r.Lookup(resource(" azure ", "VM", ""))
+	if err != nil {
+		t.Fatalf("Lookup: %v", err)
+	}
+	if got != f {
+		t.Error("Lookup returned a different driver")
+	}
+}
+
+func TestRegistry_LookupUnsupported(t *testing.T) {
+	r := NewRegistry()
+	r.Register(ProviderAWS, ResourceTypeS3, NewFake())
+
+	_, err := r.Lookup(resource("GCP", "S3", ""))
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("Lookup = %v, want ErrUnsupported", err)
+	}
+	if !IsPermanent(err) {
+		t.Error("ErrUnsupported must be permanent")
+	}
+}
+
+func TestRegisterFake_CoversEveryPair(t *testing.T) {
+	r := NewRegistry()
+	RegisterFake(r)
+	if got, want := len(r.Keys()), len(Providers())*len(ResourceTypes()); got != want {
+		t.Fatalf("registered %d pairs, want %d", got, want)
+	}
+}
+
+func TestFake_IsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	a, err := NewFake().Create(ctx, resource("AWS", "RDS", "db.t3.micro"))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	b, _ := NewFake().Create(ctx, resource("AWS", "RDS", "db.t3.micro"))
+
+	if a.State != StateReady || a.ProviderID != "fake://aws/rds/res-1" {
+		t.Errorf("result = %+v", a)
+	}
+	if a.ProviderID != b.ProviderID || a.Outputs["endpoint"] != b.Outputs["endpoint"] {
+		t.Errorf("two fakes disagree: %+v vs %+v", a, b)
+	}
+}
+
+func TestFake_FailMarker(t *testing.T) {
+	got, err := NewFake().Create(context.Background(), resource("GCP", "VM", "e2-small "+FailMarker))
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if got.State != StateFailed || got.Reason == "" {
+		t.Errorf("result = %+v, want failed with a reason", got)
+	}
+}
+
+func TestFake_DescribeAndDelete(t *testing.T) {
+	ctx := context.Background()
+	f := NewFake()
+	r := resource("AWS", "S3", "")
+
+	if _, err := f.Describe(ctx, r); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Describe before Create = %v, want ErrNotFound", err)
+	}
+	created, _ := f.Create(ctx, r)
+	described, err := f.Describe(ctx, r)
+	if err != nil || described.ProviderID != created.ProviderID {
+		t.Fatalf("Describe = %+v, %v", described, err)
+	}
+	if err := f.Delete(ctx, r); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := f.Delete(ctx, r); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("second Delete = %v, want ErrNotFound", err)
+	}
+}
+
+// slowDriver reports creating until Describe has been called ready times.
+type slowDriver struct {
+	Fake
+	ready, described int
+}
+
+func (s *slowDriver) Create(context.Context, model.Resource) (Result, error) {
+	return Result{State: StateCreating}, nil
+}
+
+func (s *slowDriver) Describe(context.Context, model.Resource) (Result, error) {
+	s.described++
+	if s.described < s.ready {
+		return Result{State: StateCreating}, nil
+	}
+	return Result{State: StateReady, ProviderID: "slow-1"}, nil
+}
+
+func TestDispatcher_PollsUntilReady(t *testing.T) {
+	r := NewRegistry()
+	slow := &slowDriver{ready: 3}
+	r.Register(ProviderAWS, ResourceTypeVM, slow)
+	d := NewDispatcher(r, nil)
+	d.PollInterval = time.Millisecond
+
+	got, err := d.Provision(context.Background(), resource("AWS", "VM", ""))
+	if err != nil {
+		t.Fatalf("Provision: %v", err)
+	}
+	if got.State != StateReady || slow.described != 3 {
+		t.Errorf("result = %+v after %d describes", got, slow.described)
+	}
+}
+
+func TestDispatcher_PollTimeoutIsTransient(t *testing.T) {
+	r := NewRegistry()
+	r.Register(ProviderAWS, ResourceTypeVM, &slowDriver{ready: 1 << 30})
+	d := NewDispatcher(r, nil)
+	d.PollInterval = time.Millisecond
+	d.PollTimeout = 10 * time.Millisecond
+
+	_, err := d.Provision(context.Background(), resource("AWS", "VM", ""))
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Provision = %v, want DeadlineExceeded", err)
+	}
+	if IsPermanent(err) {
+		t.Error("a poll timeout must be retryable")
+	}
+}
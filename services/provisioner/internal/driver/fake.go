@@ -0,0 +1,86 @@
+package driver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// FailMarker in a resource's specification makes the Fake driver report the
+// resource as failed, so the failure path can be exercised locally.
+const FailMarker = "fake:fail"
+
+// Fake is an in-memory driver for local runs and tests. Its outcomes depend
+// only on the request: every resource is ready at once, with a provider ID
+// derived from its provider, type and ID, unless its specification contains
+// FailMarker.
+type Fake struct {
+	mu        sync.Mutex
+	resources map[string]Result
+}
+
+var _ Driver = (*Fake)(nil)
+
+// NewFake returns an empty Fake driver.
+func NewFake() *Fake {
+	return &Fake{resources: make(map[string]Result)}
+}
+
+// RegisterFake installs one Fake for every provider and resource type the API
+// accepts, and returns it.
+func RegisterFake(r *Registry) *Fake {
+	f := NewFake()
+	for _, p := range Providers() {
+		for _, t := range ResourceTypes() {
+			r.Register(p, t, f)
+		}
+	}
+	return f
+}
+
+// Create records the resource and returns its deterministic outcome. Creating
+// an existing resource returns the recorded result.
+func (f *Fake) Create(_ context.Context, r model.Resource) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if result, ok := f.resources[r.ID]; ok {
+		return result, nil
+	}
+
+	provider, resourceType := keyOf(r)
+	result := Result{
+		State:      StateReady,
+		ProviderID: fmt.Sprintf("fake://%s/%s/%s", strings.ToLower(string(provider)), strings.ToLower(string(resourceType)), r.ID),
+		Outputs:    map[string]string{"endpoint": fmt.Sprintf("%s.fake.local", r.ID)},
+	}
+	if strings.Contains(r.Specification, FailMarker) {
+		result = Result{State: StateFailed, ProviderID: result.ProviderID, Reason: "simulated failure requested by specification"}
+	}
+	f.resources[r.ID] = result
+	return result, nil
+}
+
+// Describe returns the recorded result, or ErrNotFound.
+func (f *Fake) Describe(_ context.Context, r model.Resource) (Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	result, ok := f.resources[r.ID]
+	if !ok {
+		return Result{}, fmt.Errorf("%w: %s", ErrNotFound, r.ID)
+	}
+	return result, nil
+}
+
+// Delete forgets the resource, or returns ErrNotFound.
+func (f *Fake) Delete(_ context.Context, r model.Resource) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.resources[r.ID]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, r.ID)
+	}
+	delete(f.resources, r.ID)
+	return nil
+}
@@ -0,0 +1,57 @@
+package driver
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// Key identifies the driver for a provider and resource type.
+type Key struct {
+	Provider CloudProvider
+	Type     ResourceType
+}
+
+// Registry maps provider and resource type pairs to drivers. It is safe for
+// concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	drivers map[Key]Driver
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{drivers: make(map[Key]Driver)}
+}
+
+// Register installs d for provider and type, replacing any earlier driver.
+func (r *Registry) Register(provider CloudProvider, resourceType ResourceType, d Driver) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.drivers[Key{Provider: provider, Type: resourceType}] = d
+}
+
+// Lookup returns the driver for a resource's provider and type, or an error
+// wrapping ErrUnsupported.
+func (r *Registry) Lookup(res model.Resource) (Driver, error) {
+	provider, resourceType := keyOf(res)
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	d, ok := r.drivers[Key{Provider: provider, Type: resourceType}]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s/%s", ErrUnsupported, provider, resourceType)
+	}
+	return d, nil
+}
+
+// Keys returns the registered pairs, in no particular order.
+func (r *Registry) Keys() []Key {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]Key, 0, len(r.drivers))
+	for k := range r.drivers {
+		keys = append(keys, k)
+	}
+	return keys
+}
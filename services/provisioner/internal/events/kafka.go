@@ -0,0 +1,93 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// KafkaPublisher writes status-change events to a Kafka topic.
+type KafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+var _ Publisher = (*KafkaPublisher)(nil)
+
+// NewKafkaPublisher creates a publisher for the given topic. Events are keyed
+// by resource ID, so each resource's transitions stay ordered on one partition.
+func NewKafkaPublisher(brokers []string, topic string) *KafkaPublisher {
+	return &KafkaPublisher{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{},
+			AllowAutoTopicCreation: true,
+		},
+	}
+}
+
+// PublishStatusChanged implements Publisher.
+func (p *KafkaPublisher) PublishStatusChanged(ctx context.Context, e model.ResourceStatusChanged) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal status event: %w", err)
+	}
+
+	var headers []kafka.Header
+	otel.GetTextMapPropagator().Inject(ctx, kafkaHeaderCarrier{headers: &headers})
+
+	if err := p.writer.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(e.ResourceID),
+		Value:   body,
+		Headers: headers,
+	}); err != nil {
+		return fmt.Errorf("publish status event for %s: %w", e.ResourceID, err)
+	}
+	return nil
+}
+
+// Close flushes and releases the underlying writer.
+func (p *KafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// kafkaHeaderCarrier is a writable TextMapCarrier over outgoing Kafka headers,
+// the inject-side counterpart of consumer.kafkaHeaderCarrier.
+type kafkaHeaderCarrier struct {
+	headers *[]kafka.Header
+}
+
+var _ propagation.TextMapCarrier = kafkaHeaderCarrier{}
+
+func (c kafkaHeaderCarrier) Get(key string) string {
+	for _, h := range *c.headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func (c kafkaHeaderCarrier) Set(key, value string) {
+	for i := range *c.headers {
+		if (*c.headers)[i].Key == key {
+			(*c.headers)[i].Value = []byte(value)
+			return
+		}
+	}
+	*c.headers = append(*c.headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (c kafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.headers))
+	for _, h := range *c.headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+func TestKafkaPublisher_UnreachableBrokerReturnsError(t *testing.T) {
+	p := NewKafkaPublisher([]string{"127.0.0.1:1"}, "test-topic")
+	defer func() { _ = p.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	err := p.PublishStatusChanged(ctx, model.ResourceStatusChanged{ResourceID: "vm-001"})
+	if err == nil {
+		t.Fatal("publishing to an unreachable broker must error")
+	}
+}
+
+func TestKafkaHeaderCarrier_SetOverwrites(t *testing.T) {
+	var headers []kafka.Header
+	c := kafkaHeaderCarrier{headers: &headers}
+
+	c.Set("traceparent", "a")
+	c.Set("traceparent", "b")
+
+	if len(headers) != 1 || c.Get("traceparent") != "b" {
+		t.Errorf("headers = %+v, want a single traceparent=b", headers)
+	}
+}
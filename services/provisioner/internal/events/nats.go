@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// NATSPublisher publishes status-change events to a NATS JetStream subject.
+type NATSPublisher struct {
+	js      jetstream.Publisher
+	subject string
+}
+
+var _ Publisher = (*NATSPublisher)(nil)
+
+// NewNATSPublisher creates a publisher for subject, which a stream must
+// capture (see consumer.EnsureNATSStream).
+func NewNATSPublisher(js jetstream.Publisher, subject string) *NATSPublisher {
+	return &NATSPublisher{js: js, subject: subject}
+}
+
+// PublishStatusChanged implements Publisher. It waits for the stream to
+// acknowledge the event, so a returned nil means it was stored.
+func (p *NATSPublisher) PublishStatusChanged(ctx context.Context, e model.ResourceStatusChanged) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal status event: %w", err)
+	}
+
+	msg := nats.NewMsg(p.subject)
+	msg.Data = body
+	otel.GetTextMapPropagator().Inject(ctx, natsHeaderCarrier(msg.Header))
+
+	if _, err := p.js.PublishMsg(ctx, msg); err != nil {
+		return fmt.Errorf("publish status event for %s: %w", e.ResourceID, err)
+	}
+	return nil
+}
+
+// natsHeaderCarrier is a writable TextMapCarrier over outgoing NATS headers,
+// the inject-side counterpart of consumer.natsHeaderCarrier. NATS header keys
+// are case-sensitive, so keys are stored exactly as the propagator sets them.
+type natsHeaderCarrier nats.Header
+
+var _ propagation.TextMapCarrier = natsHeaderCarrier{}
+
+func (c natsHeaderCarrier) Get(key string) string {
+	if v := c[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c natsHeaderCarrier) Set(key, value string) {
+	c[key] = []string{value}
+}
+
+func (c natsHeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
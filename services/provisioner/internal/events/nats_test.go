@@ -0,0 +1,69 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// fakeJetStream records published messages. Methods it does not override
+// panic through the nil embedded interface.
+type fakeJetStream struct {
+	jetstream.Publisher
+	msgs []*nats.Msg
+	err  error
+}
+
+func (f *fakeJetStream) PublishMsg(_ context.Context, msg *nats.Msg, _ ...jetstream.PublishOpt) (*jetstream.PubAck, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.msgs = append(f.msgs, msg)
+	return &jetstream.PubAck{Stream: "RESOURCE_STATUS"}, nil
+}
+
+func TestNATSPublisher_PublishesEventWithTraceContext(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled,
+	}))
+
+	js := &fakeJetStream{}
+	p := NewNATSPublisher(js, "resource.status-changed")
+	if err := p.PublishStatusChanged(ctx, model.ResourceStatusChanged{ResourceID: "vm-001", NewStatus: model.StatusCompleted}); err != nil {
+		t.Fatalf("PublishStatusChanged: %v", err)
+	}
+
+	if len(js.msgs) != 1 || js.msgs[0].Subject != "resource.status-changed" {
+		t.Fatalf("published %+v, want one message on resource.status-changed", js.msgs)
+	}
+	var got model.ResourceStatusChanged
+	if err := json.Unmarshal(js.msgs[0].Data, &got); err != nil || got.ResourceID != "vm-001" || got.NewStatus != model.StatusCompleted {
+		t.Errorf("body = %s, want the status event", js.msgs[0].Data)
+	}
+	if tp := js.msgs[0].Header.Get("traceparent"); tp != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("traceparent = %q, want the caller's span", tp)
+	}
+}
+
+func TestNATSPublisher_PublishFailureIsReturned(t *testing.T) {
+	p := NewNATSPublisher(&fakeJetStream{err: errors.New("no responders")}, "resource.status-changed")
+
+	if err := p.PublishStatusChanged(context.Background(), model.ResourceStatusChanged{ResourceID: "vm-001"}); err == nil {
+		t.Fatal("a failed publish must error")
+	}
+}
@@ -0,0 +1,28 @@
+// Package events publishes the provisioner's outbound events — today the
+// ResourceStatusChanged notifications the API consumes to keep its read model
+// current. Kafka carries them locally, SNS in AWS.
+package events
+
+import (
+	"context"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// Publisher emits status-change events. Implementations inject the W3C trace
+// context of ctx into the message metadata so the API's consumer continues the
+// provisioning trace.
+type Publisher interface {
+	PublishStatusChanged(ctx context.Context, e model.ResourceStatusChanged) error
+}
+
+// NopPublisher discards every event. Used in tests and when no transport is
+// configured.
+type NopPublisher struct{}
+
+var _ Publisher = NopPublisher{}
+
+// PublishStatusChanged implements Publisher.
+func (NopPublisher) PublishStatusChanged(context.Context, model.ResourceStatusChanged) error {
+	return nil
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	snstypes "github.com/aws/aws-sdk-go-v2/service/sns/types"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// SNSPublisher publishes status-change events to an SNS topic. The API's
+// status queue subscribes with raw message delivery, so the body and the
+// trace-context attributes arrive on SQS unchanged.
+type SNSPublisher struct {
+	client   *sns.Client
+	topicARN string
+}
+
+var _ Publisher = (*SNSPublisher)(nil)
+
+// NewSNSPublisher creates a publisher for the given topic.
+func NewSNSPublisher(client *sns.Client, topicARN string) *SNSPublisher {
+	return &SNSPublisher{client: client, topicARN: topicARN}
+}
+
+// PublishStatusChanged implements Publisher.
+func (p *SNSPublisher) PublishStatusChanged(ctx context.Context, e model.ResourceStatusChanged) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal status event: %w", err)
+	}
+
+	attrs := snsAttributeCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, attrs)
+
+	input := &sns.PublishInput{
+		TopicArn: aws.String(p.topicARN),
+		Message:  aws.String(string(body)),
+	}
+	if len(attrs) > 0 {
+		input.MessageAttributes = attrs
+	}
+	if _, err := p.client.Publish(ctx, input); err != nil {
+		return fmt.Errorf("publish status event for %s: %w", e.ResourceID, err)
+	}
+	return nil
+}
+
+// snsAttributeCarrier is a writable TextMapCarrier over SNS message attributes.
+type snsAttributeCarrier map[string]snstypes.MessageAttributeValue
+
+var _ propagation.TextMapCarrier = snsAttributeCarrier(nil)
+
+func (c snsAttributeCarrier) Get(key string) string {
+	if v, ok := c[key]; ok && v.StringValue != nil {
+		return *v.StringValue
+	}
+	return ""
+}
+
+func (c snsAttributeCarrier) Set(key, value string) {
+	c[key] = snstypes.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(value),
+	}
+}
+
+func (c snsAttributeCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
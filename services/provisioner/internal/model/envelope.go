@@ -0,0 +1,139 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Envelope attributes of the events the API publishes. Messages are
+// CloudEvents 1.0 in structured mode: the event's attributes and its data in
+// one document, encoded as JSON or Protobuf per the message's content type.
+const (
+	SpecVersion = "1.0"
+
+	// Content types of the wire formats, as stamped by the API on every
+	// message. Messages without one predate the envelope and are JSON.
+	ContentTypeJSON     = "application/cloudevents+json"
+	ContentTypeProtobuf = "application/cloudevents+protobuf"
+
+	// EventTypePrefix is the reverse-DNS prefix of every event type.
+	EventTypePrefix = "com.internal-developer-platform."
+
+	// EventTypeResourceProvisionRequested is the type of a provisioning
+	// request accepted by the API; its data is a Resource.
+	EventTypeResourceProvisionRequested = EventTypePrefix + "resource.provision.requested"
+
+	// SchemaBaseURI prefixes every dataschema: <base><type suffix>/v<n>.json.
+	SchemaBaseURI = "https://internal-developer-platform.com/schemas/"
+)
+
+// Envelope is a CloudEvents envelope. DataSchema names the data's schema
+// and, in its last path segment, the version it was written with. Data is
+// always held as JSON; envelopes decoded from other formats have it
+// transcoded.
+type Envelope struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Type            string          `json:"type"`
+	Source          string          `json:"source"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype,omitempty"`
+	DataSchema      string          `json:"dataschema"`
+	Data            json.RawMessage `json:"data"`
+}
+
+// SchemaURI is the dataschema of version v of eventType's data.
+func SchemaURI(eventType string, v int) string {
+	return schemaPrefix(eventType) + strconv.Itoa(v) + ".json"
+}
+
+func schemaPrefix(eventType string) string {
+	return SchemaBaseURI + strings.TrimPrefix(eventType, EventTypePrefix) + "/v"
+}
+
+// Version is the data version named by DataSchema.
+func (e Envelope) Version() (int, error) {
+	rest, ok := strings.CutPrefix(e.DataSchema, schemaPrefix(e.Type))
+	if !ok {
+		return 0, fmt.Errorf("dataschema %q is not a schema of %s", e.DataSchema, e.Type)
+	}
+	digits, ok := strings.CutSuffix(rest, ".json")
+	v, err := strconv.Atoi(digits)
+	if !ok || err != nil || v < 1 {
+		return 0, fmt.Errorf("dataschema %q does not name a version", e.DataSchema)
+	}
+	return v, nil
+}
+
+// DecodeEnvelope parses a message body of the given content type into an
+// envelope and checks its required attributes. A body published before the
+// envelope existed — a bare JSON object with no specversion and no content
+// type — is wrapped as version 1 of a provisioning request, which is the shape
+// such bodies have. Errors wrap ErrMalformedMessage.
+func DecodeEnvelope(contentType string, body []byte) (Envelope, error) {
+	var (
+		e   Envelope
+		err error
+	)
+	switch contentType {
+	case "", ContentTypeJSON:
+		e, err = decodeJSONEnvelope(body)
+	case ContentTypeProtobuf:
+		e, err = decodeProtobufEnvelope(body)
+	default:
+		return Envelope{}, fmt.Errorf("%w: unsupported content type %q", ErrMalformedMessage, contentType)
+	}
+	if err != nil {
+		return Envelope{}, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+
+	switch {
+	case e.SpecVersion != SpecVersion:
+		return Envelope{}, fmt.Errorf("%w: unsupported specversion %q", ErrMalformedMessage, e.SpecVersion)
+	case e.ID == "":
+		return Envelope{}, fmt.Errorf("%w: missing envelope id", ErrMalformedMessage)
+	case e.Type == "":
+		return Envelope{}, fmt.Errorf("%w: missing envelope type", ErrMalformedMessage)
+	case e.Source == "":
+		return Envelope{}, fmt.Errorf("%w: missing envelope source", ErrMalformedMessage)
+	case len(e.Data) == 0 || string(e.Data) == "null":
+		return Envelope{}, fmt.Errorf("%w: missing envelope data", ErrMalformedMessage)
+	}
+	return e, nil
+}
+
+// legacySource stands in for the source of bodies published without an
+// envelope, which were all published by the API.
+const legacySource = "/internal-developer-platform/api"
+
+func decodeJSONEnvelope(body []byte) (Envelope, error) {
+	var probe struct {
+		SpecVersion *string `json:"specversion"`
+		ID          string  `json:"id"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return Envelope{}, err
+	}
+	if probe.SpecVersion == nil {
+		// The resource ID doubles as the event ID: the inbox dedupes on the
+		// transport's message ID, not this one.
+		return Envelope{
+			SpecVersion: SpecVersion,
+			ID:          probe.ID,
+			Type:        EventTypeResourceProvisionRequested,
+			Source:      legacySource,
+			Subject:     probe.ID,
+			DataSchema:  SchemaURI(EventTypeResourceProvisionRequested, 1),
+			Data:        bytes.TrimSpace(body),
+		}, nil
+	}
+
+	var e Envelope
+	err := json.Unmarshal(body, &e)
+	return e, err
+}
@@ -0,0 +1,205 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const resourceData = `{"id":"vm-001","resource_type":"VM","cloud_provider":"AWS","specification":"t2.micro","status":"pending","requested_by":"rafael","region":"us-east-1","tags":{"team":"platform"}}`
+
+func envelopeBody(t *testing.T, mutate func(e map[string]any)) []byte {
+	t.Helper()
+	e := map[string]any{
+		"specversion":     SpecVersion,
+		"id":              "8d7c7f3e-2b1a-4f4e-9c0d-5a6b7c8d9e0f",
+		"type":            EventTypeResourceProvisionRequested,
+		"source":          "/internal-developer-platform/api",
+		"subject":         "vm-001",
+		"time":            "2026-01-24T10:30:00Z",
+		"datacontenttype": "application/json",
+		"dataschema":      SchemaURI(EventTypeResourceProvisionRequested, 1),
+		"data":            json.RawMessage(resourceData),
+	}
+	if mutate != nil {
+		mutate(e)
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+	return b
+}
+
+func TestDecodeResource_Envelope(t *testing.T) {
+	r, err := DecodeResource(ContentTypeJSON, envelopeBody(t, nil))
+	if err != nil {
+		t.Fatalf("DecodeResource: %v", err)
+	}
+	if r.ID != "vm-001" || r.Specification != "t2.micro" || r.RequestedBy != "rafael" {
+		t.Errorf("decoded resource = %+v", r)
+	}
+}
+
+// Bodies published before the envelope existed are still in flight during a
+// rollout, and must decode as they always have.
+func TestDecodeResource_LegacyBody(t *testing.T) {
+	r, err := DecodeResource("", []byte(resourceData))
+	if err != nil {
+		t.Fatalf("DecodeResource: %v", err)
+	}
+	if r.ID != "vm-001" || r.CloudProvider != "AWS" {
+		t.Errorf("decoded resource = %+v", r)
+	}
+}
+
+func TestDecodeResource_RejectsInvalidMessages(t *testing.T) {
+	tests := map[string][]byte{
+		"not json":           []byte("not json"),
+		"legacy missing id":  []byte(`{"resource_type":"VM","cloud_provider":"AWS"}`),
+		"specversion 0.3":    envelopeBody(t, func(e map[string]any) { e["specversion"] = "0.3" }),
+		"missing id":         envelopeBody(t, func(e map[string]any) { delete(e, "id") }),
+		"missing source":     envelopeBody(t, func(e map[string]any) { delete(e, "source") }),
+		"missing data":       envelopeBody(t, func(e map[string]any) { delete(e, "data") }),
+		"unknown type":       envelopeBody(t, func(e map[string]any) { e["type"] = EventTypePrefix + "resource.deleted" }),
+		"no version":         envelopeBody(t, func(e map[string]any) { e["dataschema"] = SchemaBaseURI + "resource.provision.requested/latest.json" }),
+		"foreign dataschema": envelopeBody(t, func(e map[string]any) { e["dataschema"] = "https://example.com/v1.json" }),
+		"newer version":      envelopeBody(t, func(e map[string]any) { e["dataschema"] = SchemaURI(EventTypeResourceProvisionRequested, 99) }),
+		"data missing cloud_provider": envelopeBody(t, func(e map[string]any) {
+			e["data"] = json.RawMessage(`{"id":"vm-001","resource_type":"VM"}`)
+		}),
+		"data with non-string tag": envelopeBody(t, func(e map[string]any) {
+			e["data"] = json.RawMessage(`{"id":"vm-001","resource_type":"VM","cloud_provider":"AWS","tags":{"cost":1}}`)
+		}),
+	}
+	for name, body := range tests {
+		if _, err := DecodeResource(ContentTypeJSON, body); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("%s: DecodeResource = %v, want ErrMalformedMessage", name, err)
+		}
+	}
+}
+
+const (
+	testType = EventTypePrefix + "widget.created"
+	widgetV1 = `{"type":"object","required":["name"],"properties":{"name":{"type":"string"}}}`
+	widgetV2 = `{"type":"object","required":["display_name"],"properties":{"display_name":{"type":"string"}},"not":{"required":["name"]}}`
+)
+
+// renameName upcasts widget v1 to v2, which renamed name to display_name.
+func renameName(data json.RawMessage) (json.RawMessage, error) {
+	var v1 map[string]any
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return nil, err
+	}
+	v1["display_name"] = v1["name"]
+	delete(v1, "name")
+	return json.Marshal(v1)
+}
+
+func widgetRegistry(t *testing.T) *SchemaRegistry {
+	t.Helper()
+	r := NewSchemaRegistry()
+	if err := r.Register(testType, 1, []byte(widgetV1), nil); err != nil {
+		t.Fatalf("register v1: %v", err)
+	}
+	if err := r.Register(testType, 2, []byte(widgetV2), renameName); err != nil {
+		t.Fatalf("register v2: %v", err)
+	}
+	return r
+}
+
+func widget(v int, data string) Envelope {
+	return Envelope{Type: testType, DataSchema: SchemaURI(testType, v), Data: json.RawMessage(data)}
+}
+
+func TestSchemaRegistry_UpcastsOlderVersions(t *testing.T) {
+	r := widgetRegistry(t)
+
+	tests := []struct {
+		event Envelope
+		want  string
+	}{
+		{widget(1, `{"name":"gizmo"}`), `{"display_name":"gizmo"}`},
+		{widget(2, `{"display_name":"gizmo"}`), `{"display_name":"gizmo"}`},
+	}
+	for _, tt := range tests {
+		got, err := r.Decode(tt.event)
+		if err != nil {
+			t.Fatalf("Decode(%s): %v", tt.event.DataSchema, err)
+		}
+		if !bytes.Equal(got, []byte(tt.want)) {
+			t.Errorf("Decode(%s) = %s, want %s", tt.event.DataSchema, got, tt.want)
+		}
+	}
+}
+
+// Data is validated against the schema of the version it claims, not the
+// latest one.
+func TestSchemaRegistry_ValidatesAgainstOwnVersion(t *testing.T) {
+	r := widgetRegistry(t)
+
+	for _, event := range []Envelope{
+		widget(1, `{"display_name":"gizmo"}`),
+		widget(2, `{"name":"gizmo"}`),
+	} {
+		if _, err := r.Decode(event); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("Decode(%s, %s) = %v, want ErrMalformedMessage", event.DataSchema, event.Data, err)
+		}
+	}
+}
+
+func TestSchemaRegistry_RegisterRequiresOrderAndUpcasters(t *testing.T) {
+	r := NewSchemaRegistry()
+	if err := r.Register(testType, 2, []byte(widgetV2), renameName); err == nil {
+		t.Error("registering v2 before v1 should fail")
+	}
+	if err := r.Register(testType, 1, []byte(widgetV1), nil); err != nil {
+		t.Fatalf("register v1: %v", err)
+	}
+	if err := r.Register(testType, 2, []byte(widgetV2), nil); err == nil {
+		t.Error("registering v2 without an upcaster should fail")
+	}
+	if err := r.Register(testType, 2, []byte(`{"type":`), renameName); err == nil {
+		t.Error("registering an unparsable schema should fail")
+	}
+}
+
+// The API owns the schemas; the provisioner's copies must not drift from
+// them. Skipped when the API's sources are not checked out next to these.
+func TestSchemas_MatchAPI(t *testing.T) {
+	assertMatchesAPI(t, schemaFiles, "schemas", filepath.Join("envelope", "schemas"))
+}
+
+// assertMatchesAPI checks that every file under root in ours is identical to
+// its counterpart in dir, relative to the API's outbound adapters.
+func assertMatchesAPI(t *testing.T, ours fs.FS, root, dir string) {
+	t.Helper()
+	theirs := filepath.Join("..", "..", "..", "api", "internal", "adapters", "outbound", dir)
+	if _, err := os.Stat(theirs); err != nil {
+		t.Skipf("API sources not found at %s", theirs)
+	}
+
+	err := fs.WalkDir(ours, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		ourFile, _ := fs.ReadFile(ours, name)
+		theirFile, err := os.ReadFile(filepath.Join(theirs, strings.TrimPrefix(name, root+"/")))
+		if err != nil {
+			t.Errorf("%s has no counterpart in the API: %v", name, err)
+			return nil
+		}
+		if !bytes.Equal(ourFile, theirFile) {
+			t.Errorf("%s differs from the API's copy", name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,31 @@
+package model
+
+import "time"
+
+// EventTypeResourceStatusChanged names the event the provisioner emits after
+// every status transition. The API consumes it to keep its read model current.
+const EventTypeResourceStatusChanged = "ResourceStatusChanged"
+
+// ResourceStatusChanged reports one applied status transition. Trace context
+// travels in the transport's headers/attributes, not in the body, exactly as
+// on the API -> provisioner leg.
+type ResourceStatusChanged struct {
+	EventType  string    `json:"event_type"`
+	ResourceID string    `json:"resource_id"`
+	OldStatus  Status    `json:"old_status"`
+	NewStatus  Status    `json:"new_status"`
+	Reason     string    `json:"reason,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// NewResourceStatusChanged builds the event for a transition of resource id.
+func NewResourceStatusChanged(id string, t Transition) ResourceStatusChanged {
+	return ResourceStatusChanged{
+		EventType:  EventTypeResourceStatusChanged,
+		ResourceID: id,
+		OldStatus:  t.From,
+		NewStatus:  t.To,
+		Reason:     t.Reason,
+		OccurredAt: t.At,
+	}
+}
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model/eventspb"
+)
+
+// protoData maps each dataschema to the Protobuf message of that version of
+// the data. Every schema in schemas/ needs an entry here; the eventspb .proto
+// files mirror the JSON Schemas field for field.
+var protoData = map[string]func() proto.Message{
+	SchemaURI(EventTypeResourceProvisionRequested, 1): func() proto.Message { return new(eventspb.ResourceProvisionRequestedV1) },
+}
+
+// decodeProtobufEnvelope parses a CloudEvent in the Protobuf format. Binary
+// Protobuf data is transcoded to JSON, so it is validated and upcast exactly
+// like data that arrived as JSON.
+func decodeProtobufEnvelope(body []byte) (Envelope, error) {
+	var ce eventspb.CloudEvent
+	if err := proto.Unmarshal(body, &ce); err != nil {
+		return Envelope{}, err
+	}
+	attrs := ce.GetAttributes()
+	e := Envelope{
+		SpecVersion:     ce.GetSpecVersion(),
+		ID:              ce.GetId(),
+		Type:            ce.GetType(),
+		Source:          ce.GetSource(),
+		Subject:         attrs["subject"].GetCeString(),
+		DataContentType: attrs["datacontenttype"].GetCeString(),
+		DataSchema:      attrs["dataschema"].GetCeUri(),
+	}
+	if ts := attrs["time"].GetCeTimestamp(); ts != nil {
+		e.Time = ts.AsTime()
+	}
+
+	switch data := ce.GetData().(type) {
+	case nil:
+		// Left empty for DecodeEnvelope to reject.
+	case *eventspb.CloudEvent_TextData:
+		if e.DataContentType != "application/json" {
+			return Envelope{}, fmt.Errorf("unsupported text data content type %q", e.DataContentType)
+		}
+		e.Data = json.RawMessage(data.TextData)
+	case *eventspb.CloudEvent_BinaryData:
+		if e.DataContentType != "application/protobuf" {
+			return Envelope{}, fmt.Errorf("unsupported binary data content type %q", e.DataContentType)
+		}
+		newData, ok := protoData[e.DataSchema]
+		if !ok {
+			return Envelope{}, fmt.Errorf("no Protobuf message for dataschema %q", e.DataSchema)
+		}
+		m := newData()
+		if err := proto.Unmarshal(data.BinaryData, m); err != nil {
+			return Envelope{}, err
+		}
+		raw, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+		if err != nil {
+			return Envelope{}, err
+		}
+		e.Data = raw
+	default:
+		return Envelope{}, errors.New("proto_data is not supported; data must be binary or text")
+	}
+	return e, nil
+}
@@ -0,0 +1,142 @@
+package model
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model/eventspb"
+)
+
+func stringAttr(v string) *eventspb.CloudEvent_CloudEventAttributeValue {
+	return &eventspb.CloudEvent_CloudEventAttributeValue{Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeString{CeString: v}}
+}
+
+func protobufBody(t *testing.T, data proto.Message, mutate func(ce *eventspb.CloudEvent)) []byte {
+	t.Helper()
+	binary, err := proto.Marshal(data)
+	if err != nil {
+		t.Fatalf("marshal data: %v", err)
+	}
+	ce := &eventspb.CloudEvent{
+		Id:          "8d7c7f3e-2b1a-4f4e-9c0d-5a6b7c8d9e0f",
+		Source:      "/internal-developer-platform/api",
+		SpecVersion: SpecVersion,
+		Type:        EventTypeResourceProvisionRequested,
+		Attributes: map[string]*eventspb.CloudEvent_CloudEventAttributeValue{
+			"subject":         stringAttr("vm-001"),
+			"datacontenttype": stringAttr("application/protobuf"),
+			"dataschema": {Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeUri{
+				CeUri: SchemaURI(EventTypeResourceProvisionRequested, 1),
+			}},
+			"time": {Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeTimestamp{
+				CeTimestamp: timestamppb.New(time.Date(2026, 1, 24, 10, 30, 0, 0, time.UTC)),
+			}},
+		},
+		Data: &eventspb.CloudEvent_BinaryData{BinaryData: binary},
+	}
+	if mutate != nil {
+		mutate(ce)
+	}
+	b, err := proto.Marshal(ce)
+	if err != nil {
+		t.Fatalf("marshal envelope: %v", err)
+	}
+	return b
+}
+
+func resourceV1() *eventspb.ResourceProvisionRequestedV1 {
+	return &eventspb.ResourceProvisionRequestedV1{
+		Id: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro",
+		Status: "pending", RequestedBy: "rafael", Region: "us-east-1", Tags: map[string]string{"team": "platform"},
+	}
+}
+
+func TestDecodeEnvelope_Protobuf(t *testing.T) {
+	e, err := DecodeEnvelope(ContentTypeProtobuf, protobufBody(t, resourceV1(), nil))
+	if err != nil {
+		t.Fatalf("DecodeEnvelope: %v", err)
+	}
+	if e.Subject != "vm-001" || e.Source != "/internal-developer-platform/api" {
+		t.Errorf("decoded envelope = %+v", e)
+	}
+	if want := time.Date(2026, 1, 24, 10, 30, 0, 0, time.UTC); !e.Time.Equal(want) {
+		t.Errorf("time = %s, want %s", e.Time, want)
+	}
+}
+
+func TestDecodeResource_Protobuf(t *testing.T) {
+	r, err := DecodeResource(ContentTypeProtobuf, protobufBody(t, resourceV1(), nil))
+	if err != nil {
+		t.Fatalf("DecodeResource: %v", err)
+	}
+	want := Resource{ID: "vm-001", ResourceType: "VM", CloudProvider: "AWS", Specification: "t2.micro", Status: "pending", RequestedBy: "rafael"}
+	if r != want {
+		t.Errorf("decoded resource = %+v, want %+v", r, want)
+	}
+}
+
+func TestDecodeResource_ProtobufRejectsInvalidMessages(t *testing.T) {
+	incomplete := resourceV1()
+	incomplete.CloudProvider = ""
+
+	tests := map[string][]byte{
+		"not protobuf": []byte("not json"),
+		"missing id":   protobufBody(t, resourceV1(), func(ce *eventspb.CloudEvent) { ce.Id = "" }),
+		"missing data": protobufBody(t, resourceV1(), func(ce *eventspb.CloudEvent) { ce.Data = nil }),
+		"json in binary data": protobufBody(t, resourceV1(), func(ce *eventspb.CloudEvent) {
+			ce.Attributes["datacontenttype"] = stringAttr("application/json")
+		}),
+		"unknown dataschema": protobufBody(t, resourceV1(), func(ce *eventspb.CloudEvent) {
+			ce.Attributes["dataschema"] = &eventspb.CloudEvent_CloudEventAttributeValue{Attr: &eventspb.CloudEvent_CloudEventAttributeValue_CeUri{
+				CeUri: SchemaURI(EventTypeResourceProvisionRequested, 99),
+			}}
+		}),
+		"data missing cloud_provider": protobufBody(t, incomplete, nil),
+	}
+	for name, body := range tests {
+		if _, err := DecodeResource(ContentTypeProtobuf, body); !errors.Is(err, ErrMalformedMessage) {
+			t.Errorf("%s: DecodeResource = %v, want ErrMalformedMessage", name, err)
+		}
+	}
+}
+
+func TestDecodeEnvelope_RejectsUnknownContentTypes(t *testing.T) {
+	if _, err := DecodeEnvelope("application/avro", envelopeBody(t, nil)); !errors.Is(err, ErrMalformedMessage) {
+		t.Errorf("DecodeEnvelope = %v, want ErrMalformedMessage", err)
+	}
+}
+
+// Every schema the provisioner knows must be decodable from Protobuf.
+func TestProtoData_CoversEverySchema(t *testing.T) {
+	err := fs.WalkDir(schemaFiles, "schemas", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		eventType, file, _ := strings.Cut(strings.TrimPrefix(name, "schemas/"), "/")
+		v, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(file, "v"), ".json"))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, ok := protoData[SchemaURI(EventTypePrefix+eventType, v)]; !ok {
+			t.Errorf("no Protobuf message for %s", name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// The .proto files and generated code are the API's, like the schemas.
+func TestEventspb_MatchesAPI(t *testing.T) {
+	assertMatchesAPI(t, os.DirFS("."), "eventspb", filepath.Join("envelope", "eventspb"))
+}
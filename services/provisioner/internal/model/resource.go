@@ -0,0 +1,50 @@
+// Package model holds the provisioner's view of the messages it consumes. The
+// API owns the wire contract (its model.Resource); these types mirror its JSON
+// shape so the provisioner can decode a message without importing the API.
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Resource is a provisioning request as published by the API.
+type Resource struct {
+	ID            string `json:"id"`
+	ResourceType  string `json:"resource_type"`
+	CloudProvider string `json:"cloud_provider"`
+	Specification string `json:"specification"`
+	Status        string `json:"status"`
+	RequestedBy   string `json:"requested_by"`
+}
+
+// ErrMalformedMessage marks a message body that can never be processed — it is
+// not valid JSON or lacks the fields persistence keys on. Retrying it cannot
+// succeed, so callers should treat it differently from a transient failure.
+var ErrMalformedMessage = errors.New("malformed provisioning message")
+
+// DecodeResource parses a message body of the given content type — a
+// provisioning request event, or a bare Resource published before events had
+// an envelope — validates its data against the schema of the version it was
+// written with and decodes it, upcast to the latest version. Errors wrap
+// ErrMalformedMessage.
+func DecodeResource(contentType string, body []byte) (Resource, error) {
+	e, err := DecodeEnvelope(contentType, body)
+	if err != nil {
+		return Resource{}, err
+	}
+	if e.Type != EventTypeResourceProvisionRequested {
+		return Resource{}, fmt.Errorf("%w: unexpected event type %q", ErrMalformedMessage, e.Type)
+	}
+	data, err := schemas.Decode(e)
+	if err != nil {
+		return Resource{}, err
+	}
+
+	var r Resource
+	if err := json.Unmarshal(data, &r); err != nil {
+		return Resource{}, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	return r, nil
+}
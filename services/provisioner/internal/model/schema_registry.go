@@ -0,0 +1,149 @@
+package model
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// schemaFiles holds the JSON Schema of every version of every event's data,
+// as schemas/<type suffix>/v<n>.json — copies of the API's, which owns them.
+//
+//go:embed schemas
+var schemaFiles embed.FS
+
+// Upcaster rewrites one version of an event's data into the next.
+type Upcaster func(data json.RawMessage) (json.RawMessage, error)
+
+// upcasters turn each version of an event's data into the next, keyed by
+// event type and the version they produce. When the API publishes a new
+// version n, add its schema and the upcaster producing it from n-1 here;
+// messages of older versions still in flight are then validated against
+// their own schema and upcast before they are decoded.
+var upcasters = map[string]map[int]Upcaster{}
+
+// SchemaRegistry validates the data of incoming events against the schema of
+// the version they were written with and upcasts it to the latest version.
+type SchemaRegistry struct {
+	events map[string]*eventSchemas
+}
+
+type eventSchemas struct {
+	schemas   map[int]*jsonschema.Resolved
+	upcasters map[int]Upcaster
+	latest    int
+}
+
+// NewSchemaRegistry returns an empty registry.
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{events: make(map[string]*eventSchemas)}
+}
+
+// Register adds version v of eventType's data. Versions must be registered in
+// order from 1; every version after the first needs the upcaster producing it
+// from the one before.
+func (r *SchemaRegistry) Register(eventType string, v int, schema []byte, upcast Upcaster) error {
+	var s jsonschema.Schema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return fmt.Errorf("parse schema %s v%d: %w", eventType, v, err)
+	}
+	resolved, err := s.Resolve(nil)
+	if err != nil {
+		return fmt.Errorf("resolve schema %s v%d: %w", eventType, v, err)
+	}
+
+	es, ok := r.events[eventType]
+	if !ok {
+		es = &eventSchemas{schemas: make(map[int]*jsonschema.Resolved), upcasters: make(map[int]Upcaster)}
+		r.events[eventType] = es
+	}
+	switch {
+	case v != es.latest+1:
+		return fmt.Errorf("schema %s v%d registered out of order after v%d", eventType, v, es.latest)
+	case v > 1 && upcast == nil:
+		return fmt.Errorf("schema %s v%d needs an upcaster from v%d", eventType, v, v-1)
+	}
+	es.schemas[v] = resolved
+	es.upcasters[v] = upcast
+	es.latest = v
+	return nil
+}
+
+// Decode validates e's data against the schema its dataschema names and
+// returns it upcast to the latest version. Errors wrap ErrMalformedMessage:
+// an event this registry does not know, or whose data does not match its
+// schema, will never be processable by this build.
+func (r *SchemaRegistry) Decode(e Envelope) (json.RawMessage, error) {
+	es, ok := r.events[e.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown event type %q", ErrMalformedMessage, e.Type)
+	}
+	v, err := e.Version()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	if v > es.latest {
+		return nil, fmt.Errorf("%w: %s v%d is newer than the latest known version v%d", ErrMalformedMessage, e.Type, v, es.latest)
+	}
+
+	var instance any
+	if err := json.Unmarshal(e.Data, &instance); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformedMessage, err)
+	}
+	if err := es.schemas[v].Validate(instance); err != nil {
+		return nil, fmt.Errorf("%w: %s v%d: %v", ErrMalformedMessage, e.Type, v, err)
+	}
+
+	data := e.Data
+	for next := v + 1; next <= es.latest; next++ {
+		if data, err = es.upcasters[next](data); err != nil {
+			return nil, fmt.Errorf("%w: upcast %s to v%d: %v", ErrMalformedMessage, e.Type, next, err)
+		}
+	}
+	return data, nil
+}
+
+// schemas is the registry of the events the provisioner consumes, loaded from
+// schemaFiles.
+var schemas = mustLoadSchemas()
+
+func mustLoadSchemas() *SchemaRegistry {
+	r, err := loadSchemas(schemaFiles, upcasters)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// loadSchemas registers every schemas/<type suffix>/v<n>.json in fsys, in
+// version order.
+func loadSchemas(fsys fs.FS, upcasters map[string]map[int]Upcaster) (*SchemaRegistry, error) {
+	r := NewSchemaRegistry()
+	dirs, err := fs.ReadDir(fsys, "schemas")
+	if err != nil {
+		return nil, err
+	}
+	for _, dir := range dirs {
+		eventType := EventTypePrefix + dir.Name()
+		files, err := fs.ReadDir(fsys, path.Join("schemas", dir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		for v := 1; v <= len(files); v++ {
+			name := "v" + strconv.Itoa(v) + ".json"
+			schema, err := fs.ReadFile(fsys, path.Join("schemas", dir.Name(), name))
+			if err != nil {
+				return nil, fmt.Errorf("schemas of %s must be v1.json to v%d.json: %w", eventType, len(files), err)
+			}
+			if err := r.Register(eventType, v, schema, upcasters[eventType][v]); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return r, nil
+}
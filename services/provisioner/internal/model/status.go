@@ -0,0 +1,108 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Status is a provisioning request's lifecycle state. It mirrors the API's
+// valueobjects.ProvisioningStatus; the two must agree on values and on the
+// transitions below.
+type Status string
+
+const (
+	StatusPending    Status = "pending"
+	StatusInProgress Status = "in_progress"
+	StatusCompleted  Status = "completed"
+	StatusFailed     Status = "failed"
+	StatusRetrying   Status = "retrying"
+	StatusCancelled  Status = "cancelled"
+)
+
+// statusTransitions lists, for each status, the statuses it may move to.
+// Final statuses have no entry.
+var statusTransitions = map[Status][]Status{
+	StatusPending:    {StatusInProgress, StatusCancelled},
+	StatusInProgress: {StatusCompleted, StatusFailed, StatusRetrying, StatusCancelled},
+	StatusRetrying:   {StatusInProgress, StatusFailed, StatusCancelled},
+}
+
+// IsValid reports whether s is a known status.
+func (s Status) IsValid() bool {
+	switch s {
+	case StatusPending, StatusInProgress, StatusCompleted, StatusFailed, StatusRetrying, StatusCancelled:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFinal reports whether s ends the lifecycle.
+func (s Status) IsFinal() bool {
+	return s == StatusCompleted || s == StatusFailed || s == StatusCancelled
+}
+
+// CanTransitionTo reports whether a request in status s may move to next.
+func (s Status) CanTransitionTo(next Status) bool {
+	for _, allowed := range statusTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrInvalidTransition marks a status change the state machine forbids.
+// Errors returned by ProvisioningRequest.TransitionTo wrap it.
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// Transition is one entry in a provisioning request's status history.
+type Transition struct {
+	From   Status
+	To     Status
+	Reason string
+	At     time.Time
+}
+
+// ProvisioningRequest owns a resource's provisioning status. Every status
+// change the provisioner makes goes through TransitionTo.
+type ProvisioningRequest struct {
+	ID      string
+	status  Status
+	history []Transition
+}
+
+// NewProvisioningRequest rebuilds a request in its stored status. An empty
+// status is treated as pending, the status every request starts in.
+func NewProvisioningRequest(id string, status Status) (*ProvisioningRequest, error) {
+	if status == "" {
+		status = StatusPending
+	}
+	if !status.IsValid() {
+		return nil, fmt.Errorf("resource %s has unknown status %q", id, status)
+	}
+	return &ProvisioningRequest{ID: id, status: status}, nil
+}
+
+// Status returns the current status.
+func (p *ProvisioningRequest) Status() Status {
+	return p.status
+}
+
+// History returns the transitions applied to this request, oldest first.
+func (p *ProvisioningRequest) History() []Transition {
+	return append([]Transition(nil), p.history...)
+}
+
+// TransitionTo moves the request to next. An illegal move returns an error
+// wrapping ErrInvalidTransition and leaves the request unchanged.
+func (p *ProvisioningRequest) TransitionTo(next Status, reason string, at time.Time) (Transition, error) {
+	if !p.status.CanTransitionTo(next) {
+		return Transition{}, fmt.Errorf("%w: resource %s cannot move from %s to %s", ErrInvalidTransition, p.ID, p.status, next)
+	}
+	t := Transition{From: p.status, To: next, Reason: reason, At: at.UTC()}
+	p.status = next
+	p.history = append(p.history, t)
+	return t, nil
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestProvisioningRequest_LegalPathRecordsHistory(t *testing.T) {
+	req, err := NewProvisioningRequest("vm-001", "")
+	if err != nil {
+		t.Fatalf("NewProvisioningRequest: %v", err)
+	}
+	now := time.Now()
+	for _, next := range []Status{StatusInProgress, StatusRetrying, StatusInProgress, StatusFailed} {
+		if _, err := req.TransitionTo(next, "", now); err != nil {
+			t.Fatalf("TransitionTo(%s): %v", next, err)
+		}
+	}
+	if req.Status() != StatusFailed {
+		t.Errorf("status = %s, want failed", req.Status())
+	}
+	if got := len(req.History()); got != 4 {
+		t.Errorf("history has %d entries, want 4", got)
+	}
+}
+
+func TestProvisioningRequest_IllegalTransition(t *testing.T) {
+	tests := []struct{ from, to Status }{
+		{StatusCompleted, StatusPending},
+		{StatusPending, StatusCompleted},
+		{StatusFailed, StatusRetrying},
+		{StatusCancelled, StatusInProgress},
+		{StatusInProgress, StatusInProgress},
+	}
+	for _, tt := range tests {
+		req, err := NewProvisioningRequest("vm-001", tt.from)
+		if err != nil {
+			t.Fatalf("NewProvisioningRequest: %v", err)
+		}
+		_, err = req.TransitionTo(tt.to, "", time.Now())
+		if !errors.Is(err, ErrInvalidTransition) {
+			t.Errorf("%s -> %s: err = %v, want ErrInvalidTransition", tt.from, tt.to, err)
+		}
+		if req.Status() != tt.from || len(req.History()) != 0 {
+			t.Errorf("%s -> %s: rejected move changed the request", tt.from, tt.to)
+		}
+	}
+}
+
+func TestNewProvisioningRequest_UnknownStatus(t *testing.T) {
+	if _, err := NewProvisioningRequest("vm-001", "exploded"); err == nil {
+		t.Error("expected an error for an unknown status")
+	}
+}
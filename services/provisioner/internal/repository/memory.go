@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// Memory is an in-process Repository. It backs unit tests and lets the
+// provisioner run when no database is configured; nothing survives a restart.
+type Memory struct {
+	mu        sync.Mutex
+	resources map[string]model.Resource
+	history   map[string][]model.Transition
+	processed map[string]string
+	// Err, when set, is returned by the Save methods, TransitionStatus and
+	// ResourceStatus instead of touching the store — lets tests exercise the consumer's failure path.
+	Err error
+}
+
+var _ Repository = (*Memory)(nil)
+
+// NewMemory returns an empty in-memory repository.
+func NewMemory() *Memory {
+	return &Memory{
+		resources: make(map[string]model.Resource),
+		history:   make(map[string][]model.Transition),
+		processed: make(map[string]string),
+	}
+}
+
+// SaveResource stores the resource unless one with the same ID already exists,
+// matching the Postgres insert-or-ignore semantics.
+func (m *Memory) SaveResource(_ context.Context, r model.Resource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	m.saveLocked(r)
+	return nil
+}
+
+// SaveResourceForMessage records the message and stores the resource under
+// one lock, or returns ErrDuplicateMessage if the message is already recorded.
+func (m *Memory) SaveResourceForMessage(_ context.Context, messageID string, r model.Resource) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return m.Err
+	}
+	if _, ok := m.processed[messageID]; ok {
+		return fmt.Errorf("%w: %s", ErrDuplicateMessage, messageID)
+	}
+	m.processed[messageID] = r.ID
+	m.saveLocked(r)
+	return nil
+}
+
+// ReleaseMessage forgets the message so it can be processed again.
+func (m *Memory) ReleaseMessage(_ context.Context, messageID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.processed, messageID)
+	return nil
+}
+
+func (m *Memory) saveLocked(r model.Resource) {
+	if _, ok := m.resources[r.ID]; ok {
+		return
+	}
+	if r.Status == "" {
+		r.Status = defaultStatus
+	}
+	m.resources[r.ID] = r
+}
+
+// TransitionStatus applies the change through the state machine and appends
+// it to the resource's history.
+func (m *Memory) TransitionStatus(_ context.Context, id string, next model.Status, reason string) (model.Transition, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return model.Transition{}, m.Err
+	}
+	r, ok := m.resources[id]
+	if !ok {
+		return model.Transition{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+
+	req, err := model.NewProvisioningRequest(id, model.Status(r.Status))
+	if err != nil {
+		return model.Transition{}, err
+	}
+	t, err := req.TransitionTo(next, reason, time.Now())
+	if err != nil {
+		return model.Transition{}, err
+	}
+
+	r.Status = string(t.To)
+	m.resources[id] = r
+	m.history[id] = append(m.history[id], t)
+	return t, nil
+}
+
+// ResourceStatus returns the stored resource's status.
+func (m *Memory) ResourceStatus(_ context.Context, id string) (model.Status, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.Err != nil {
+		return "", m.Err
+	}
+	r, ok := m.resources[id]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	return model.Status(r.Status), nil
+}
+
+// History returns the transitions recorded for a resource, oldest first.
+func (m *Memory) History(id string) []model.Transition {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]model.Transition(nil), m.history[id]...)
+}
+
+// Get returns a stored resource by ID.
+func (m *Memory) Get(id string) (model.Resource, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	r, ok := m.resources[id]
+	return r, ok
+}
+
+// Len reports how many resources are stored.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.resources)
+}
@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	// Registers the "pgx" database/sql driver.
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// defaultStatus is stored when a message arrives without a status; the API
+// always publishes new requests as pending.
+const defaultStatus = string(model.StatusPending)
+
+// Postgres implements Repository against the schema in db/init.sql.
+type Postgres struct {
+	db *sql.DB
+}
+
+var _ Repository = (*Postgres)(nil)
+
+// NewPostgres wraps an open database handle. The caller owns Close().
+func NewPostgres(db *sql.DB) *Postgres {
+	return &Postgres{db: db}
+}
+
+// OpenPostgres opens a pgx-backed handle for the given DSN and verifies
+// connectivity with a ping, mirroring how the API dials Redis at startup.
+func OpenPostgres(ctx context.Context, dsn string) (*sql.DB, error) {
+	db, err := sql.Open("pgx", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("open postgres: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("postgres ping failed: %w", err)
+	}
+	return db, nil
+}
+
+// SaveResource inserts the resource and its type-specific row in a single
+// transaction. Conflicting inserts are ignored, so a redelivered message is a
+// no-op rather than an error.
+func (p *Postgres) SaveResource(ctx context.Context, r model.Resource) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	// Rollback after a successful Commit is a no-op.
+	defer func() { _ = tx.Rollback() }()
+
+	if err := saveResource(ctx, tx, r); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit resource %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// SaveResourceForMessage claims messageID in processed_messages and saves the
+// resource in the same transaction. A message ID that is already claimed
+// rolls everything back and returns ErrDuplicateMessage.
+func (p *Postgres) SaveResourceForMessage(ctx context.Context, messageID string, r model.Resource) error {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO processed_messages (message_id, resource_id)
+		VALUES ($1, $2)
+		ON CONFLICT (message_id) DO NOTHING`,
+		messageID, r.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("claim message %s: %w", messageID, err)
+	}
+	claimed, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("claim message %s: %w", messageID, err)
+	}
+	if claimed == 0 {
+		return fmt.Errorf("%w: %s", ErrDuplicateMessage, messageID)
+	}
+
+	if err := saveResource(ctx, tx, r); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit resource %s: %w", r.ID, err)
+	}
+	return nil
+}
+
+// ReleaseMessage deletes the message's claim. Releasing an unclaimed message
+// is a no-op.
+func (p *Postgres) ReleaseMessage(ctx context.Context, messageID string) error {
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM processed_messages WHERE message_id = $1`, messageID); err != nil {
+		return fmt.Errorf("release message %s: %w", messageID, err)
+	}
+	return nil
+}
+
+// saveResource writes the generic resources row and any type-specific row
+// within tx.
+func saveResource(ctx context.Context, tx *sql.Tx, r model.Resource) error {
+	metadata, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("marshal resource metadata: %w", err)
+	}
+
+	status := r.Status
+	if status == "" {
+		status = defaultStatus
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO resources (id, type, status, provider, specification, requested_by, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO NOTHING`,
+		r.ID, r.ResourceType, status, r.CloudProvider, r.Specification, r.RequestedBy, metadata,
+	); err != nil {
+		return fmt.Errorf("insert resource %s: %w", r.ID, err)
+	}
+
+	return insertTypeSpecific(ctx, tx, r)
+}
+
+// insertTypeSpecific writes the per-(provider, type) detail row, if the schema
+// has a table for the combination. Combinations without one are stored in the
+// generic resources row only.
+func insertTypeSpecific(ctx context.Context, tx *sql.Tx, r model.Resource) error {
+	switch {
+	case r.CloudProvider == "AWS" && r.ResourceType == "VM":
+		// The API's specification for a VM is the instance type (e.g. t2.micro);
+		// the AMI is chosen when the instance is actually launched.
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO aws_ec2_instances (resource_id, instance_type)
+			VALUES ($1, $2)
+			ON CONFLICT (resource_id) DO NOTHING`,
+			r.ID, r.Specification,
+		); err != nil {
+			return fmt.Errorf("insert ec2 instance for %s: %w", r.ID, err)
+		}
+	}
+	return nil
+}
+
+// TransitionStatus locks the resource row, applies the change through the
+// state machine and writes the new status and a history row in one
+// transaction.
+func (p *Postgres) TransitionStatus(ctx context.Context, id string, next model.Status, reason string) (model.Transition, error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return model.Transition{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM resources WHERE id = $1 FOR UPDATE`, id).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Transition{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return model.Transition{}, fmt.Errorf("load status of %s: %w", id, err)
+	}
+
+	req, err := model.NewProvisioningRequest(id, model.Status(current))
+	if err != nil {
+		return model.Transition{}, err
+	}
+	t, err := req.TransitionTo(next, reason, time.Now())
+	if err != nil {
+		return model.Transition{}, err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE resources SET status = $2, updated_at = $3 WHERE id = $1`,
+		id, string(t.To), t.At,
+	); err != nil {
+		return model.Transition{}, fmt.Errorf("update status of %s: %w", id, err)
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO resource_status_history (resource_id, from_status, to_status, reason, changed_at)
+		VALUES ($1, $2, $3, $4, $5)`,
+		id, string(t.From), string(t.To), t.Reason, t.At,
+	); err != nil {
+		return model.Transition{}, fmt.Errorf("record status history of %s: %w", id, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.Transition{}, fmt.Errorf("commit status of %s: %w", id, err)
+	}
+	return t, nil
+}
+
+// ResourceStatus reads the resource's current status.
+func (p *Postgres) ResourceStatus(ctx context.Context, id string) (model.Status, error) {
+	var status string
+	err := p.db.QueryRowContext(ctx, `SELECT status FROM resources WHERE id = $1`, id).Scan(&status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return "", fmt.Errorf("load status of %s: %w", id, err)
+	}
+	return model.Status(status), nil
+}
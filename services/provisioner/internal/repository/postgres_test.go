@@ -0,0 +1,152 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+// postgresFromEnv opens the test database, or skips the test if not set. We
+// don't spin up Postgres for unit tests — set POSTGRES_TEST_DSN (e.g. against
+// the db/ image, which applies init.sql) to exercise the real implementation.
+func postgresFromEnv(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("POSTGRES_TEST_DSN")
+	if dsn == "" {
+		t.Skip("POSTGRES_TEST_DSN not set; skipping Postgres integration test")
+	}
+	db, err := OpenPostgres(context.Background(), dsn)
+	if err != nil {
+		t.Fatalf("OpenPostgres: %v", err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func TestPostgres_SaveResourceWritesTypeSpecificRow(t *testing.T) {
+	db := postgresFromEnv(t)
+	repo := NewPostgres(db)
+	ctx := context.Background()
+
+	r := model.Resource{
+		ID:            "vm-" + uuid.NewString(),
+		ResourceType:  "VM",
+		CloudProvider: "AWS",
+		Specification: "t3.small",
+		RequestedBy:   "rafael",
+	}
+	if err := repo.SaveResource(ctx, r); err != nil {
+		t.Fatalf("SaveResource: %v", err)
+	}
+
+	var status, instanceType string
+	if err := db.QueryRowContext(ctx, `SELECT status FROM resources WHERE id = $1`, r.ID).Scan(&status); err != nil {
+		t.Fatalf("select resource: %v", err)
+	}
+	if status != "pending" {
+		t.Errorf("status = %q, want pending", status)
+	}
+	if err := db.QueryRowContext(ctx, `SELECT instance_type FROM aws_ec2_instances WHERE resource_id = $1`, r.ID).Scan(&instanceType); err != nil {
+		t.Fatalf("select ec2 row: %v", err)
+	}
+	if instanceType != "t3.small" {
+		t.Errorf("instance_type = %q, want t3.small", instanceType)
+	}
+}
+
+func TestPostgres_SaveResourceIsIdempotent(t *testing.T) {
+	repo := NewPostgres(postgresFromEnv(t))
+	ctx := context.Background()
+
+	r := model.Resource{ID: "s3-" + uuid.NewString(), ResourceType: "S3", CloudProvider: "AWS", Specification: "standard", RequestedBy: "rafael"}
+	if err := repo.SaveResource(ctx, r); err != nil {
+		t.Fatalf("first SaveResource: %v", err)
+	}
+	if err := repo.SaveResource(ctx, r); err != nil {
+		t.Fatalf("redelivered SaveResource must be a no-op, got: %v", err)
+	}
+}
+
+func TestPostgres_TransitionStatusRecordsHistory(t *testing.T) {
+	db := postgresFromEnv(t)
+	repo := NewPostgres(db)
+	ctx := context.Background()
+
+	r := model.Resource{ID: "s3-" + uuid.NewString(), ResourceType: "S3", CloudProvider: "AWS", Specification: "standard", RequestedBy: "rafael"}
+	if err := repo.SaveResource(ctx, r); err != nil {
+		t.Fatalf("SaveResource: %v", err)
+	}
+	if _, err := repo.TransitionStatus(ctx, r.ID, model.StatusInProgress, "picked up"); err != nil {
+		t.Fatalf("TransitionStatus: %v", err)
+	}
+	if _, err := repo.TransitionStatus(ctx, r.ID, model.StatusPending, ""); !errors.Is(err, model.ErrInvalidTransition) {
+		t.Fatalf("in_progress -> pending: err = %v, want ErrInvalidTransition", err)
+	}
+
+	var status string
+	var entries int
+	if err := db.QueryRowContext(ctx, `SELECT status FROM resources WHERE id = $1`, r.ID).Scan(&status); err != nil {
+		t.Fatalf("select resource: %v", err)
+	}
+	if err := db.QueryRowContext(ctx, `SELECT count(*) FROM resource_status_history WHERE resource_id = $1`, r.ID).Scan(&entries); err != nil {
+		t.Fatalf("select history: %v", err)
+	}
+	if status != "in_progress" || entries != 1 {
+		t.Errorf("status = %q with %d history rows, want in_progress with 1", status, entries)
+	}
+}
+
+func TestPostgres_TransitionStatusUnknownResource(t *testing.T) {
+	repo := NewPostgres(postgresFromEnv(t))
+	if _, err := repo.TransitionStatus(context.Background(), "missing-"+uuid.NewString(), model.StatusInProgress, ""); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestPostgres_SaveResourceForMessageDetectsDuplicates(t *testing.T) {
+	db := postgresFromEnv(t)
+	repo := NewPostgres(db)
+	ctx := context.Background()
+
+	messageID := uuid.NewString()
+	r := model.Resource{ID: "s3-" + uuid.NewString(), ResourceType: "S3", CloudProvider: "AWS", Specification: "standard", RequestedBy: "rafael"}
+	if err := repo.SaveResourceForMessage(ctx, messageID, r); err != nil {
+		t.Fatalf("first SaveResourceForMessage: %v", err)
+	}
+	if err := repo.SaveResourceForMessage(ctx, messageID, r); !errors.Is(err, ErrDuplicateMessage) {
+		t.Fatalf("redelivered SaveResourceForMessage = %v, want ErrDuplicateMessage", err)
+	}
+
+	if err := repo.ReleaseMessage(ctx, messageID); err != nil {
+		t.Fatalf("ReleaseMessage: %v", err)
+	}
+	if err := repo.SaveResourceForMessage(ctx, messageID, r); err != nil {
+		t.Fatalf("SaveResourceForMessage after release: %v", err)
+	}
+}
+
+func TestPostgres_ResourceStatus(t *testing.T) {
+	repo := NewPostgres(postgresFromEnv(t))
+	ctx := context.Background()
+
+	r := model.Resource{ID: "s3-" + uuid.NewString(), ResourceType: "S3", CloudProvider: "AWS", Specification: "standard", RequestedBy: "rafael"}
+	if err := repo.SaveResource(ctx, r); err != nil {
+		t.Fatalf("SaveResource: %v", err)
+	}
+	if _, err := repo.TransitionStatus(ctx, r.ID, model.StatusInProgress, "accepted"); err != nil {
+		t.Fatalf("TransitionStatus: %v", err)
+	}
+
+	if status, err := repo.ResourceStatus(ctx, r.ID); err != nil || status != model.StatusInProgress {
+		t.Errorf("ResourceStatus = %q, %v, want in_progress", status, err)
+	}
+	if _, err := repo.ResourceStatus(ctx, "missing-"+uuid.NewString()); !errors.Is(err, ErrNotFound) {
+		t.Errorf("err = %v, want ErrNotFound", err)
+	}
+}
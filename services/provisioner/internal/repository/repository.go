@@ -0,0 +1,49 @@
+// Package repository persists consumed provisioning requests. The Repository
+// interface is the seam the consume loops write through: Postgres in deployed
+// and docker-compose environments, an in-memory store in tests and when no
+// database is configured.
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rafaelcmd/internal-developer-platform/resource-provisioner-service/internal/model"
+)
+
+var (
+	// ErrNotFound is returned when a status change targets a resource that
+	// has not been saved.
+	ErrNotFound = errors.New("resource not found")
+	// ErrDuplicateMessage is returned by SaveResourceForMessage when the
+	// message has already been processed.
+	ErrDuplicateMessage = errors.New("message already processed")
+)
+
+// Repository stores provisioning requests.
+//
+// SaveResource must be atomic — the generic resources row and any
+// type-specific row (e.g. aws_ec2_instances) are written together or not at
+// all — and idempotent, because both transports deliver at least once and a
+// redelivered message must not fail or duplicate rows.
+//
+// SaveResourceForMessage is SaveResource with an inbox: it records messageID
+// as processed in the same transaction as the resource, so a redelivered
+// message is detected atomically with the write and returns an error wrapping
+// ErrDuplicateMessage. ReleaseMessage drops the record again, for a message
+// whose processing failed after the save and should run again on redelivery.
+//
+// TransitionStatus applies a status change through model.ProvisioningRequest
+// and records it in the resource's history, atomically with respect to other
+// transitions of the same resource. An illegal move returns an error wrapping
+// model.ErrInvalidTransition and changes nothing.
+//
+// ResourceStatus returns a saved resource's current status, or an error
+// wrapping ErrNotFound.
+type Repository interface {
+	SaveResource(ctx context.Context, r model.Resource) error
+	SaveResourceForMessage(ctx context.Context, messageID string, r model.Resource) error
+	ReleaseMessage(ctx context.Context, messageID string) error
+	TransitionStatus(ctx context.Context, id string, next model.Status, reason string) (model.Transition, error)
+	ResourceStatus(ctx context.Context, id string) (model.Status, error)
+}